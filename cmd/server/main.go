@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
+	"net"
 	"net/http"
 	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/currency"
 	"northwind-api/internal/handler"
 	"northwind-api/internal/middleware"
+	"northwind-api/internal/money"
+	"northwind-api/internal/monitor"
+	"northwind-api/internal/outbox"
 	database "northwind-api/internal/repository"
+	"northwind-api/internal/rpc"
+	"northwind-api/internal/stream"
+	"northwind-api/internal/webhook"
 	"os"
 	"time"
 
@@ -33,6 +42,15 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to laod configuration")
 	}
 
+	// Configure money rounding before anything is calculated or encoded
+	rounding, err := money.ParseRoundingMode(cfg.MoneyRounding)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse the money rounding mode")
+	}
+	if err := money.Configure(cfg.MoneyDecimalPlaces, rounding); err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure money rounding")
+	}
+
 	// Initialize database
 	db, err := database.New(cfg)
 	if err != nil {
@@ -40,15 +58,82 @@ func main() {
 	}
 	defer db.Close()
 
-	// Start cleanup job in the background
-	// ctx := context.Background()
+	if cfg.ExchangeRatesCSV != "" {
+		if err := loadExchangeRates(db, cfg.ExchangeRatesCSV); err != nil {
+			log.Fatal().Err(err).Str("path", cfg.ExchangeRatesCSV).Msg("Failed to load exchange rates")
+		}
+	}
+
+	// Start background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Deliver committed changes to the registered webhooks
+	webhooks := webhook.New(db, cfg)
+	webhooks.Start(ctx)
+
+	// Relay the events recorded in the outbox to the configured sinks
+	sinks, err := outbox.NewSinks(cfg, webhooks)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize outbox sinks")
+	}
+	outbox.New(db, sinks, cfg).Start(ctx)
+
+	// Follow the events committed by every instance for the event stream
+	dsn, err := cfg.GetDatabaseURL()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to get the database URL")
+	}
+	eventHub := stream.NewHub(cfg.EventsReplaySize)
+	if err := stream.Listen(ctx, eventHub, db, dsn); err != nil {
+		log.Fatal().Err(err).Msg("Failed to start the event listener")
+	}
+
+	if cfg.MonitorEnabled {
+		notifier, err := monitor.NewNotifier(cfg)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize order monitor notifier")
+		}
+		monitor.New(db, notifier, cfg.MonitorInterval, cfg.MonitorAtRiskDays).Start(ctx)
+	}
+
+	go syncPrices(ctx, db, cfg.PriceSyncInterval)
 
 	// Initialize handlers
-	handler := handler.New(db, cfg)
+	handler, err := handler.New(db, cfg, eventHub)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize handlers")
+	}
 
 	// Set up router with middlewear
 	router := setupRouter(handler)
 
+	// Document the routes. Undocumented routes are caught by the tests; one
+	// that slips through is reported rather than keeping the service down.
+	problems, err := handler.DocumentRoutes(router)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build the OpenAPI document")
+	}
+	for _, problem := range problems {
+		log.Warn().Str("problem", problem).Msg("OpenAPI document does not match the routes")
+	}
+
+	// Check requests, and optionally responses, against the OpenAPI document
+	if cfg.OpenAPIValidation != "off" {
+		responses := middleware.ResponsesOff
+		switch cfg.OpenAPIValidation {
+		case "responses":
+			responses = middleware.ResponsesLog
+		case "strict":
+			responses = middleware.ResponsesFail
+		}
+		router.Use(middleware.OpenAPIValidation(middleware.OpenAPIConfig{
+			Document:     handler.OpenAPI(),
+			Responses:    responses,
+			MaxBodyBytes: cfg.BulkMaxBodyBytes,
+		}))
+	}
+
 	// Initialize CORS middlewear with configuration
 	corsConfig := middleware.CORSConfig{
 		AllowedOrigins: cfg.GetAllowedOrigins(),
@@ -61,9 +146,24 @@ func main() {
 		),
 	)
 
+	// Start the gRPC server on its own port, stopping it when the service exits
+	if cfg.GRPCEnabled {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatal().Err(err).Str("port", cfg.GRPCPort).Msg("Failed to listen for gRPC")
+		}
+		go func() {
+			if err := rpc.New(db, cfg).ServeUntil(ctx, lis); err != nil {
+				log.Fatal().Err(err).Msg("gRPC server failed")
+			}
+		}()
+	}
+
 	// Start server
 	log.Info().Str("port", cfg.ServerPort).Msg("Northwind Service starting")
 
+	// The event stream lifts the WriteTimeout for its responses, which stay
+	// open for as long as their clients follow them
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
 		Handler:      httpHandler,
@@ -76,19 +176,126 @@ func main() {
 
 }
 
+// syncPrices keeps products.unit_price in line with scheduled price changes as they come into effect
+func syncPrices(ctx context.Context, db *database.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := db.SyncCurrentPrices(); err != nil {
+				log.Error().Err(err).Msg("Failed to sync current prices")
+			}
+		}
+	}
+}
+
+// loadExchangeRates saves the exchange rates in a CSV file
+func loadExchangeRates(db *database.DB, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rates, err := currency.ParseRatesCSV(f)
+	if err != nil {
+		return err
+	}
+	return db.SaveExchangeRates(rates)
+}
+
 // Setup router configures all of the API routes
 func setupRouter(h *handler.Handler) *mux.Router {
 	router := mux.NewRouter()
 
+	// GraphQL
+	router.HandleFunc("/graphql", h.GraphQL).Methods("GET", "POST")
+
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
 
+	// API documentation
+	api.HandleFunc("/openapi.json", h.GetOpenAPI).Methods("GET")
+	api.HandleFunc("/docs", h.GetAPIDocs).Methods("GET")
+
+	// Search
+	api.HandleFunc("/search", h.SearchProducts).Methods("GET")
+
 	// Categories
 	api.HandleFunc("/categories", h.GetCategories).Methods("GET")
 	api.HandleFunc("/categories/{categoryId}", h.GetCategoryById).Methods("GET")
 	api.HandleFunc("/categories", h.CreateCategory).Methods("POST")
+	api.HandleFunc("/categories/bulk", h.BulkCategories).Methods("POST")
 	api.HandleFunc("/categories/{categoryId}", h.UpdateCategory).Methods("PUT")
+	api.HandleFunc("/categories/{categoryId}", h.PatchCategory).Methods("PATCH")
 	api.HandleFunc("/categories/{categoryId}", h.DeleteCategory).Methods("DELETE")
 
+	// Customers
+	api.HandleFunc("/customers/{customerId}", h.PatchCustomer).Methods("PATCH")
+	api.HandleFunc("/customers/{customerId}/orders", h.GetCustomerOrders).Methods("GET")
+	api.HandleFunc("/customers/{customerId}/statement", h.GetCustomerStatement).Methods("GET")
+
+	// Employees and suppliers
+	api.HandleFunc("/employees/{employeeId}", h.PatchEmployee).Methods("PATCH")
+	api.HandleFunc("/suppliers/{supplierId}", h.PatchSupplier).Methods("PATCH")
+
+	// Products
+	api.HandleFunc("/products", h.GetProducts).Methods("GET")
+	api.HandleFunc("/products/{productId}", h.GetProductById).Methods("GET")
+	api.HandleFunc("/products/bulk", h.BulkProducts).Methods("POST")
+	api.HandleFunc("/products/{productId}", h.PatchProduct).Methods("PATCH")
+	api.HandleFunc("/products/{productId}/prices", h.GetProductPrices).Methods("GET")
+	api.HandleFunc("/products/{productId}/prices", h.ScheduleProductPrice).Methods("POST")
+	api.HandleFunc("/prices/adjustments", h.AdjustPrices).Methods("POST")
+
+	// Shippers and freight
+	api.HandleFunc("/shippers/{shipperId}", h.PatchShipper).Methods("PATCH")
+	api.HandleFunc("/shippers/{shipperId}/rates", h.GetFreightRates).Methods("GET")
+	api.HandleFunc("/shippers/{shipperId}/rates", h.CreateFreightRate).Methods("POST")
+	api.HandleFunc("/shippers/{shipperId}/rates/{rateId}", h.PatchFreightRate).Methods("PATCH")
+	api.HandleFunc("/shippers/{shipperId}/rates/{rateId}", h.DeleteFreightRate).Methods("DELETE")
+	api.HandleFunc("/freight/quote", h.QuoteFreight).Methods("POST")
+
+	// Exchange rates
+	api.HandleFunc("/exchange-rates", h.GetExchangeRates).Methods("GET")
+	api.HandleFunc("/exchange-rates", h.SaveExchangeRates).Methods("POST")
+
+	// Imports
+	api.HandleFunc("/import/jobs/{jobId}", h.GetImportJob).Methods("GET")
+	api.HandleFunc("/import/{resource}", h.ImportResource).Methods("POST")
+
+	// Promotions
+	api.HandleFunc("/promotions", h.GetPromotions).Methods("GET")
+	api.HandleFunc("/promotions/{promotionId}", h.GetPromotionById).Methods("GET")
+	api.HandleFunc("/promotions", h.CreatePromotion).Methods("POST")
+	api.HandleFunc("/promotions/{promotionId}", h.PatchPromotion).Methods("PATCH")
+	api.HandleFunc("/promotions/{promotionId}", h.DeletePromotion).Methods("DELETE")
+
+	// Orders
+	api.HandleFunc("/orders", h.GetOrders).Methods("GET")
+	api.HandleFunc("/orders", h.CreateOrder).Methods("POST")
+	api.HandleFunc("/orders/quote", h.QuoteOrder).Methods("POST")
+	api.HandleFunc("/orders/late", h.GetLateOrders).Methods("GET")
+	api.HandleFunc("/orders/at-risk", h.GetAtRiskOrders).Methods("GET")
+	api.HandleFunc("/orders/{orderId}", h.GetOrderById).Methods("GET")
+	api.HandleFunc("/orders/{orderId}", h.PatchOrder).Methods("PATCH")
+	api.HandleFunc("/orders/{orderId}/invoice", h.GetOrderInvoice).Methods("GET")
+
+	// Webhooks
+	api.HandleFunc("/webhooks", h.GetWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks", h.CreateWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/dead-letters", h.GetDeadDeliveries).Methods("GET")
+	api.HandleFunc("/webhooks/deliveries/{deliveryId}/redeliver", h.RedeliverWebhookDelivery).Methods("POST")
+	api.HandleFunc("/webhooks/{webhookId}", h.GetWebhookById).Methods("GET")
+	api.HandleFunc("/webhooks/{webhookId}", h.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/webhooks/{webhookId}/deliveries", h.GetWebhookDeliveries).Methods("GET")
+
+	// Events
+	api.HandleFunc("/events", h.StreamEvents).Methods("GET")
+
 	return router
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/handler"
+	"northwind-api/internal/stream"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// pathParam matches the variables of a mux path template, which OpenAPI
+// writes without their patterns
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	h, err := handler.New(nil, &appconfig.Config{}, stream.NewHub(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := setupRouter(h)
+
+	problems, err := h.DocumentRoutes(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+
+	doc := h.OpenAPI()
+	routes := 0
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes++
+			item := doc.Paths[pathParam.ReplaceAllString(path, "{$1}")]
+			if item[strings.ToLower(method)] == nil {
+				t.Errorf("%s %s is not in the OpenAPI document", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes == 0 {
+		t.Fatal("the router has no routes")
+	}
+}
@@ -8,6 +8,13 @@ DROP TABLE IF EXISTS orders CASCADE;
 DROP TABLE IF EXISTS products CASCADE;
 DROP TABLE IF EXISTS shippers CASCADE;
 DROP TABLE IF EXISTS suppliers CASCADE;
+DROP TABLE IF EXISTS product_prices CASCADE;
+DROP TABLE IF EXISTS promotions CASCADE;
+DROP TABLE IF EXISTS freight_rates CASCADE;
+DROP TABLE IF EXISTS exchange_rates CASCADE;
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
+DROP TABLE IF EXISTS outbox CASCADE;
 
 -- ---------------------------------------------------------------------- --
 -- Tables                                                                 -- -- ---------------------------------------------------------------------- --
@@ -34,6 +41,7 @@ CREATE TABLE customers (
     postal_code VARCHAR(10),
     country VARCHAR(15),
     phone VARCHAR(24),
+    currency CHAR(3),
     CONSTRAINT pk_customers PRIMARY KEY (customer_id)
 );
 
@@ -65,6 +73,7 @@ CREATE TABLE order_details (
     product_id INTEGER NOT NULL,
     unit_price DECIMAL(10,4) NOT NULL DEFAULT 0,
     quantity SMALLINT NOT NULL DEFAULT 1,
+    discount DECIMAL(10,4) NOT NULL DEFAULT 0,
     CONSTRAINT pk_order_details PRIMARY KEY (order_id, product_id)
 );
 
@@ -86,6 +95,8 @@ CREATE TABLE orders (
     ship_city VARCHAR(15),
     ship_postal_code VARCHAR(10),
     ship_country VARCHAR(15),
+    currency CHAR(3),
+    exchange_rate DECIMAL(18,8),
     CONSTRAINT pk_orders PRIMARY KEY (order_id)
 );
 
@@ -1315,4 +1326,229 @@ INSERT INTO suppliers VALUES(26,'Pasta Buttini s.r.l.','Giovanni Giudici','Order
 INSERT INTO suppliers VALUES(27,'Escargots Nouveaux','Marie Delamare','Sales Manager','22, rue H. Voiron','Montceau',NULL,'71300','France','85.57.00.07',NULL);
 INSERT INTO suppliers VALUES(28,'Gai pturage','Eliane Noz','Sales Representative','Bat. B
 3, rue des Alpes','Annecy',NULL,'74000','France','38.76.98.06','38.76.98.58');
-INSERT INTO suppliers VALUES(29,'Forts d''rables','Chantal Goulet','Accounting Manager','148 rue Chasseur','Ste-Hyacinthe','Qubec','J2S 7S8','Canada','(514) 555-2955','(514) 555-2921');
\ No newline at end of file
+INSERT INTO suppliers VALUES(29,'Forts d''rables','Chantal Goulet','Accounting Manager','148 rue Chasseur','Ste-Hyacinthe','Qubec','J2S 7S8','Canada','(514) 555-2955','(514) 555-2921');
+
+-- ---------------------------------------------------------------------- --
+-- Add search indexes                                                     -- -- ---------------------------------------------------------------------- --
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_products_name_fts ON products USING GIN (to_tsvector('english', product_name));
+CREATE INDEX idx_products_name_trgm ON products USING GIN (product_name gin_trgm_ops);
+CREATE INDEX idx_categories_fts ON categories USING GIN (to_tsvector('english', category_name || ' ' || COALESCE(description, '')));
+CREATE INDEX idx_categories_name_trgm ON categories USING GIN (category_name gin_trgm_ops);
+CREATE INDEX idx_suppliers_name_fts ON suppliers USING GIN (to_tsvector('english', company_name));
+CREATE INDEX idx_suppliers_name_trgm ON suppliers USING GIN (company_name gin_trgm_ops);
+
+
+-- ---------------------------------------------------------------------- --
+-- Add table "product_prices"                                             -- -- ---------------------------------------------------------------------- --
+
+-- Price history per product. Periods of a product never overlap and an
+-- effective_to of NULL means the price applies until further notice.
+CREATE TABLE product_prices (
+    price_id SERIAL,
+    product_id INTEGER NOT NULL,
+    unit_price DECIMAL(10,4) NOT NULL,
+    effective_from TIMESTAMP NOT NULL,
+    effective_to TIMESTAMP,
+    CONSTRAINT pk_product_prices PRIMARY KEY (price_id),
+    CONSTRAINT fk_product_prices_products FOREIGN KEY (product_id) REFERENCES products (product_id) ON DELETE CASCADE,
+    CONSTRAINT uq_product_prices_from UNIQUE (product_id, effective_from)
+);
+
+-- Seed the history with the current list prices, effective before the first order
+INSERT INTO product_prices (product_id, unit_price, effective_from)
+SELECT product_id, COALESCE(unit_price, 0), '1990-01-01' FROM products;
+
+-- ---------------------------------------------------------------------- --
+-- Advance sequences past the explicitly inserted ids                     -- -- ---------------------------------------------------------------------- --
+
+SELECT setval('orders_order_id_seq', (SELECT MAX(order_id) FROM orders));
+SELECT setval('products_product_id_seq', (SELECT MAX(product_id) FROM products));
+SELECT setval('suppliers_supplier_id_seq', (SELECT MAX(supplier_id) FROM suppliers));
+SELECT setval('shippers_shipper_id_seq', (SELECT MAX(shipper_id) FROM shippers));
+
+-- ---------------------------------------------------------------------- --
+-- Add table "promotions"                                                 -- -- ---------------------------------------------------------------------- --
+
+-- Discount rules evaluated when orders are priced. A rule applies to a line
+-- when every non-NULL scope column (product, category, customer) matches,
+-- the line quantity reaches min_quantity and the order date falls inside the
+-- validity window. Percent discounts are a percentage of the line amount,
+-- fixed discounts an amount off each unit.
+CREATE TABLE promotions (
+    promotion_id SERIAL,
+    name VARCHAR(60) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL,
+    discount_value DECIMAL(10,4) NOT NULL,
+    min_quantity SMALLINT NOT NULL DEFAULT 1,
+    product_id INTEGER,
+    category_id INTEGER,
+    customer_id VARCHAR(5),
+    valid_from TIMESTAMP,
+    valid_to TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT pk_promotions PRIMARY KEY (promotion_id),
+    CONSTRAINT ck_promotions_type CHECK (discount_type IN ('percent', 'fixed')),
+    CONSTRAINT ck_promotions_value CHECK (discount_value >= 0)
+);
+
+-- ---------------------------------------------------------------------- --
+-- Add table "freight_rates"                                              -- -- ---------------------------------------------------------------------- --
+
+-- Freight rate table per shipper. A NULL ship_country or ship_region matches
+-- any destination and a NULL max_quantity is unbounded; the most specific
+-- matching band wins. Freight = base_charge + per_unit_charge * total units.
+CREATE TABLE freight_rates (
+    rate_id SERIAL,
+    shipper_id INTEGER NOT NULL,
+    ship_country VARCHAR(15),
+    ship_region VARCHAR(60),
+    min_quantity INTEGER NOT NULL DEFAULT 0,
+    max_quantity INTEGER,
+    base_charge DECIMAL(10,4) NOT NULL DEFAULT 0,
+    per_unit_charge DECIMAL(10,4) NOT NULL DEFAULT 0,
+    CONSTRAINT pk_freight_rates PRIMARY KEY (rate_id),
+    CONSTRAINT fk_freight_rates_shippers FOREIGN KEY (shipper_id) REFERENCES shippers (shipper_id) ON DELETE CASCADE,
+    CONSTRAINT ck_freight_rates_band CHECK (max_quantity IS NULL OR max_quantity >= min_quantity)
+);
+
+INSERT INTO freight_rates (shipper_id, ship_country, ship_region, min_quantity, max_quantity, base_charge, per_unit_charge) VALUES
+(1, NULL, NULL, 0, 49, 12.50, 0.40),
+(1, NULL, NULL, 50, NULL, 20.00, 0.25),
+(1, 'USA', NULL, 0, NULL, 8.00, 0.20),
+(2, NULL, NULL, 0, 99, 15.00, 0.30),
+(2, NULL, NULL, 100, NULL, 25.00, 0.18),
+(2, 'Canada', NULL, 0, NULL, 10.00, 0.22),
+(3, NULL, NULL, 0, 49, 18.00, 0.35),
+(3, NULL, NULL, 50, NULL, 30.00, 0.20),
+(3, 'Germany', NULL, 0, NULL, 11.00, 0.24);
+
+-- ---------------------------------------------------------------------- --
+-- Add table "exchange_rates"                                             -- -- ---------------------------------------------------------------------- --
+
+-- Exchange rates against the base currency (BASE_CURRENCY, USD by default):
+-- rate is how much of the currency one unit of the base currency buys. A
+-- customer or order with a NULL currency uses the base currency, and orders
+-- snapshot the rate in effect when they were placed in orders.exchange_rate.
+CREATE TABLE exchange_rates (
+    currency CHAR(3) NOT NULL,
+    rate DECIMAL(18,8) NOT NULL,
+    effective_from TIMESTAMP NOT NULL,
+    CONSTRAINT pk_exchange_rates PRIMARY KEY (currency, effective_from),
+    CONSTRAINT ck_exchange_rates_rate CHECK (rate > 0)
+);
+
+-- Sample rates against USD
+INSERT INTO exchange_rates (currency, rate, effective_from) VALUES
+('EUR', 0.92, '1990-01-01'),
+('GBP', 0.79, '1990-01-01'),
+('CAD', 1.36, '1990-01-01'),
+('MXN', 17.10, '1990-01-01'),
+('BRL', 4.95, '1990-01-01'),
+('ARS', 350.00, '1990-01-01'),
+('VES', 35.50, '1990-01-01'),
+('SEK', 10.45, '1990-01-01'),
+('NOK', 10.60, '1990-01-01'),
+('DKK', 6.86, '1990-01-01'),
+('PLN', 4.00, '1990-01-01'),
+('CHF', 0.88, '1990-01-01');
+
+UPDATE customers SET currency = CASE country
+    WHEN 'Austria' THEN 'EUR'
+    WHEN 'Belgium' THEN 'EUR'
+    WHEN 'Finland' THEN 'EUR'
+    WHEN 'France' THEN 'EUR'
+    WHEN 'Germany' THEN 'EUR'
+    WHEN 'Ireland' THEN 'EUR'
+    WHEN 'Italy' THEN 'EUR'
+    WHEN 'Portugal' THEN 'EUR'
+    WHEN 'Spain' THEN 'EUR'
+    WHEN 'UK' THEN 'GBP'
+    WHEN 'Canada' THEN 'CAD'
+    WHEN 'Mexico' THEN 'MXN'
+    WHEN 'Brazil' THEN 'BRL'
+    WHEN 'Argentina' THEN 'ARS'
+    WHEN 'Venezuela' THEN 'VES'
+    WHEN 'Sweden' THEN 'SEK'
+    WHEN 'Norway' THEN 'NOK'
+    WHEN 'Denmark' THEN 'DKK'
+    WHEN 'Poland' THEN 'PLN'
+    WHEN 'Switzerland' THEN 'CHF'
+END;
+
+-- ---------------------------------------------------------------------- --
+-- Add table "webhooks"                                                   -- -- ---------------------------------------------------------------------- --
+
+-- Partner URLs told about the events their filters match. A filter is an
+-- event type such as 'order.shipped', every event of a resource such as
+-- 'order.*', or '*' for every event. Deliveries are signed with the secret.
+CREATE TABLE webhooks (
+    webhook_id SERIAL,
+    url VARCHAR(2048) NOT NULL,
+    events TEXT[] NOT NULL,
+    description VARCHAR(200),
+    secret VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_webhooks PRIMARY KEY (webhook_id),
+    CONSTRAINT ck_webhooks_events CHECK (cardinality(events) > 0)
+);
+
+-- ---------------------------------------------------------------------- --
+-- Add table "webhook_deliveries"                                         -- -- ---------------------------------------------------------------------- --
+
+-- One event to be delivered to one webhook. Pending deliveries are sent once
+-- next_attempt_at has passed and retried with exponential backoff; those that
+-- run out of attempts are dead until they are redelivered. The payload is
+-- kept as JSON rather than JSONB so the bytes signed are the bytes stored.
+CREATE TABLE webhook_deliveries (
+    delivery_id SERIAL,
+    webhook_id INTEGER NOT NULL,
+    event_id VARCHAR(40) NOT NULL,
+    event_type VARCHAR(40) NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts SMALLINT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ,
+    last_status SMALLINT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    CONSTRAINT pk_webhook_deliveries PRIMARY KEY (delivery_id),
+    CONSTRAINT fk_webhook_deliveries_webhooks FOREIGN KEY (webhook_id) REFERENCES webhooks (webhook_id) ON DELETE CASCADE,
+    CONSTRAINT uq_webhook_deliveries_event UNIQUE (webhook_id, event_id),
+    CONSTRAINT ck_webhook_deliveries_status CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_dead ON webhook_deliveries (delivery_id) WHERE status = 'dead';
+
+-- ---------------------------------------------------------------------- --
+-- Add table "outbox"                                                     -- -- ---------------------------------------------------------------------- --
+
+-- Events recorded by the transaction making the change they describe, so an
+-- event exists if and only if its change was committed. The relay publishes
+-- unpublished events to the configured sinks in outbox_id order and marks
+-- them published; published events are pruned once they are old enough. The
+-- event_id is the idempotency key sinks and their consumers deduplicate by.
+-- xid is the recording transaction, whose events are held back by the relay
+-- until every transaction older than it has ended.
+CREATE TABLE outbox (
+    outbox_id BIGSERIAL,
+    event_id VARCHAR(40) NOT NULL,
+    event_type VARCHAR(40) NOT NULL,
+    resource VARCHAR(40) NOT NULL,
+    resource_id VARCHAR(40) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    payload JSON NOT NULL,
+    published_at TIMESTAMPTZ,
+    xid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    CONSTRAINT pk_outbox PRIMARY KEY (outbox_id),
+    CONSTRAINT uq_outbox_event UNIQUE (event_id)
+);
+
+CREATE INDEX idx_outbox_unpublished ON outbox (outbox_id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_published ON outbox (published_at) WHERE published_at IS NOT NULL;
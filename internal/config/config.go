@@ -2,9 +2,12 @@ package appconfig
 
 import (
 	"fmt"
+	"northwind-api/internal/currency"
+	"northwind-api/internal/money"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
@@ -15,6 +18,13 @@ type Config struct {
 	// Server Configuration
 	ServerPort string `env:"SERVER_PORT" envDefault:"8080"`
 
+	// gRPC Configuration: whether the gRPC services are served, on a port of
+	// their own beside the HTTP API, and whether server reflection is
+	// registered for tools such as grpcurl. Both are off unless opted in to.
+	GRPCEnabled    bool   `env:"GRPC_ENABLED" envDefault:"false"`
+	GRPCPort       string `env:"GRPC_PORT" envDefault:"9090"`
+	GRPCReflection bool   `env:"GRPC_REFLECTION" envDefault:"false"`
+
 	// Database Configuration
 	PostgresHost         string `env:"POSTGRES_HOST"`
 	PostgresPort         string `env:"POSTGRES_PORT"`
@@ -31,8 +41,100 @@ type Config struct {
 	// Allowed Origins
 	AllowedOrigins string `env:"ALLOWED_ORIGINS"`
 
+	// Largest request body accepted, in bytes
+	MaxBodyBytes int64 `env:"MAX_BODY_BYTES" envDefault:"1048576"`
+
+	// Bulk Configuration: items run per transaction chunk and the largest bulk
+	// or import body, in bytes
+	BulkChunkSize    int   `env:"BULK_CHUNK_SIZE" envDefault:"100"`
+	BulkMaxBodyBytes int64 `env:"BULK_MAX_BODY_BYTES" envDefault:"33554432"`
+
+	// Import Configuration: files with up to this many rows are imported within
+	// the request, larger ones by a background job kept for polling this long after it ends
+	ImportSyncRows     int           `env:"IMPORT_SYNC_ROWS" envDefault:"1000"`
+	ImportJobRetention time.Duration `env:"IMPORT_JOB_RETENTION" envDefault:"1h"`
+
+	// OpenAPI Configuration: whether requests are checked against the OpenAPI
+	// document: off, requests, responses (requests, and responses that drift
+	// from the document are logged) or strict (drifting responses fail with a
+	// 500, for tests and development)
+	OpenAPIValidation string `env:"OPENAPI_VALIDATION" envDefault:"off"`
+
+	// GraphQL Configuration: how deeply fields may be nested and the largest
+	// estimated number of fields a query may resolve, counting the fields
+	// under a list once for each item it may hold
+	GraphQLMaxDepth      int `env:"GRAPHQL_MAX_DEPTH" envDefault:"10"`
+	GraphQLMaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" envDefault:"5000"`
+
 	// Secrets Configuration
 	SecretsPath string `env:"SECRETS_PATH"`
+
+	// Invoice Configuration
+	InvoiceTemplateDir string `env:"INVOICE_TEMPLATE_DIR"`
+
+	// Order Monitor Configuration. The monitor remembers what it notified in
+	// memory, so it is off by default and should be enabled on one instance only
+	// or each instance sends the same notifications.
+	MonitorEnabled    bool          `env:"MONITOR_ENABLED" envDefault:"false"`
+	MonitorInterval   time.Duration `env:"MONITOR_INTERVAL" envDefault:"1h"`
+	MonitorAtRiskDays int           `env:"MONITOR_AT_RISK_DAYS" envDefault:"3"`
+	MonitorNotifiers  string        `env:"MONITOR_NOTIFIERS" envDefault:"log"`
+	MonitorWebhookURL string        `env:"MONITOR_WEBHOOK_URL"`
+
+	// Webhook Configuration: attempts made to deliver an event before it is
+	// dead-lettered, the backoff between them (doubling from the base up to the
+	// max), the timeout of each attempt and how often due deliveries are looked
+	// for. Webhook URLs on loopback and private addresses are refused unless
+	// allowed, such as for a receiver on the same machine in development.
+	WebhookMaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookBackoffBase      time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"30s"`
+	WebhookBackoffMax       time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"6h"`
+	WebhookTimeout          time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookPollInterval     time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	WebhookAllowPrivateURLs bool          `env:"WEBHOOK_ALLOW_PRIVATE_URLS" envDefault:"false"`
+
+	// Outbox Configuration: the sinks committed events are relayed to (webhooks,
+	// log, nats and kafka), how often and how many at a time unpublished events
+	// are relayed, and how long published events are kept in the outbox
+	OutboxSinks        string        `env:"OUTBOX_SINKS" envDefault:"webhooks"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	OutboxRetention    time.Duration `env:"OUTBOX_RETENTION" envDefault:"168h"`
+
+	// Event Stream Configuration: how many of the latest events are kept for
+	// clients of /api/events resuming from a Last-Event-ID, and how often an
+	// idle stream is sent a heartbeat so proxies do not close it
+	EventsReplaySize        int           `env:"EVENTS_REPLAY_SIZE" envDefault:"1000"`
+	EventsHeartbeatInterval time.Duration `env:"EVENTS_HEARTBEAT_INTERVAL" envDefault:"15s"`
+
+	// NATS Configuration for the nats sink: the server URL and the prefix of the
+	// subjects events are published on as <prefix>.<event type>, which a
+	// JetStream stream must capture
+	NATSURL           string `env:"NATS_URL"`
+	NATSSubjectPrefix string `env:"NATS_SUBJECT_PREFIX" envDefault:"northwind"`
+
+	// Kafka Configuration for the kafka sink: comma separated brokers and the topic events are written to
+	KafkaBrokers string `env:"KAFKA_BROKERS"`
+	KafkaTopic   string `env:"KAFKA_TOPIC" envDefault:"northwind.events"`
+
+	// Money Configuration: decimal places and rounding (half_up, half_even or down)
+	// used for calculated amounts and for amounts on invoices and exports
+	MoneyDecimalPlaces int    `env:"MONEY_DECIMAL_PLACES" envDefault:"2"`
+	MoneyRounding      string `env:"MONEY_ROUNDING" envDefault:"half_even"`
+
+	// Currency Configuration: the currency stored amounts are in, and an
+	// optional CSV of exchange rates (currency,rate,effective_from) loaded at startup
+	BaseCurrency     string `env:"BASE_CURRENCY" envDefault:"USD"`
+	ExchangeRatesCSV string `env:"EXCHANGE_RATES_CSV"`
+
+	// How often products.unit_price is refreshed from scheduled price changes
+	PriceSyncInterval time.Duration `env:"PRICE_SYNC_INTERVAL" envDefault:"15m"`
+
+	// SMTP Configuration (defaults point at a local stand-in such as MailHog)
+	SMTPHost string `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort string `env:"SMTP_PORT" envDefault:"1025"`
+	SMTPFrom string `env:"SMTP_FROM" envDefault:"northwind@localhost"`
+	SMTPTo   string `env:"SMTP_TO"`
 }
 
 // Load loads the configuration from envrionment variables and .env files
@@ -87,6 +189,127 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("SECRETS_PATH is required when using relative paths for POSTGRES_PASSWORD_FILE")
 	}
 
+	if c.GRPCEnabled && c.GRPCPort == c.ServerPort {
+		return fmt.Errorf("GRPC_PORT must differ from SERVER_PORT")
+	}
+
+	if c.MaxBodyBytes < 1 {
+		return fmt.Errorf("MAX_BODY_BYTES must be positive")
+	}
+
+	if c.BulkChunkSize < 1 {
+		return fmt.Errorf("BULK_CHUNK_SIZE must be at least 1")
+	}
+	if c.BulkMaxBodyBytes < 1 {
+		return fmt.Errorf("BULK_MAX_BODY_BYTES must be positive")
+	}
+
+	if c.ImportSyncRows < 0 {
+		return fmt.Errorf("IMPORT_SYNC_ROWS must not be negative")
+	}
+	if c.ImportJobRetention <= 0 {
+		return fmt.Errorf("IMPORT_JOB_RETENTION must be positive")
+	}
+
+	switch c.OpenAPIValidation {
+	case "off", "requests", "responses", "strict":
+	default:
+		return fmt.Errorf("OPENAPI_VALIDATION must be off, requests, responses or strict")
+	}
+
+	if c.GraphQLMaxDepth < 1 {
+		return fmt.Errorf("GRAPHQL_MAX_DEPTH must be positive")
+	}
+	if c.GraphQLMaxComplexity < 1 {
+		return fmt.Errorf("GRAPHQL_MAX_COMPLEXITY must be positive")
+	}
+
+	if _, err := money.ParseRoundingMode(c.MoneyRounding); err != nil {
+		return fmt.Errorf("MONEY_ROUNDING is invalid: %w", err)
+	}
+	if c.MoneyDecimalPlaces < 0 || c.MoneyDecimalPlaces > money.Scale {
+		return fmt.Errorf("MONEY_DECIMAL_PLACES must be between 0 and %d", money.Scale)
+	}
+
+	if !currency.ValidCode(c.BaseCurrency) {
+		return fmt.Errorf("BASE_CURRENCY must be a three letter ISO 4217 code")
+	}
+
+	if c.PriceSyncInterval <= 0 {
+		return fmt.Errorf("PRICE_SYNC_INTERVAL must be positive")
+	}
+
+	// Check the webhook delivery settings
+	if c.WebhookMaxAttempts < 1 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.WebhookBackoffBase <= 0 || c.WebhookBackoffMax < c.WebhookBackoffBase {
+		return fmt.Errorf("WEBHOOK_BACKOFF_BASE must be positive and at most WEBHOOK_BACKOFF_MAX")
+	}
+	if c.WebhookTimeout <= 0 {
+		return fmt.Errorf("WEBHOOK_TIMEOUT must be positive")
+	}
+	if c.WebhookPollInterval <= 0 {
+		return fmt.Errorf("WEBHOOK_POLL_INTERVAL must be positive")
+	}
+
+	// Check the outbox relay settings
+	if c.OutboxPollInterval <= 0 {
+		return fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive")
+	}
+	if c.OutboxBatchSize < 1 {
+		return fmt.Errorf("OUTBOX_BATCH_SIZE must be at least 1")
+	}
+	if c.OutboxRetention <= 0 {
+		return fmt.Errorf("OUTBOX_RETENTION must be positive")
+	}
+	for _, sink := range c.GetOutboxSinks() {
+		switch sink {
+		case "webhooks", "log":
+		case "nats":
+			if c.NATSURL == "" {
+				return fmt.Errorf("NATS_URL is required when using the nats sink")
+			}
+		case "kafka":
+			if len(c.GetKafkaBrokers()) == 0 || c.KafkaTopic == "" {
+				return fmt.Errorf("KAFKA_BROKERS and KAFKA_TOPIC are required when using the kafka sink")
+			}
+		default:
+			return fmt.Errorf("unknown sink %q in OUTBOX_SINKS", sink)
+		}
+	}
+
+	// Check the event stream settings
+	if c.EventsReplaySize < 1 {
+		return fmt.Errorf("EVENTS_REPLAY_SIZE must be at least 1")
+	}
+	if c.EventsHeartbeatInterval <= 0 {
+		return fmt.Errorf("EVENTS_HEARTBEAT_INTERVAL must be positive")
+	}
+
+	// Check the order monitor settings
+	if c.MonitorInterval <= 0 {
+		return fmt.Errorf("MONITOR_INTERVAL must be positive")
+	}
+	if c.MonitorAtRiskDays < 1 {
+		return fmt.Errorf("MONITOR_AT_RISK_DAYS must be at least 1")
+	}
+	for _, notifier := range c.GetMonitorNotifiers() {
+		switch notifier {
+		case "log":
+		case "webhook":
+			if c.MonitorWebhookURL == "" {
+				return fmt.Errorf("MONITOR_WEBHOOK_URL is required when using the webhook notifier")
+			}
+		case "smtp":
+			if len(c.GetSMTPRecipients()) == 0 {
+				return fmt.Errorf("SMTP_TO is required when using the smtp notifier")
+			}
+		default:
+			return fmt.Errorf("unknown notifier %q in MONITOR_NOTIFIERS", notifier)
+		}
+	}
+
 	return nil
 }
 
@@ -155,10 +378,34 @@ func (c *Config) GetAllowedOrigins() []string {
 	}
 
 	// Split comma-separated origins and trim whitespace
-	origins := strings.Split(c.AllowedOrigins, ",")
-	result := make([]string, 0, len(origins))
-	for _, origin := range origins {
-		trimmed := strings.TrimSpace(origin)
+	return splitList(c.AllowedOrigins)
+}
+
+// GetOutboxSinks returns the sinks the outbox relay publishes events to
+func (c *Config) GetOutboxSinks() []string {
+	return splitList(c.OutboxSinks)
+}
+
+// GetKafkaBrokers returns the addresses of the Kafka brokers
+func (c *Config) GetKafkaBrokers() []string {
+	return splitList(c.KafkaBrokers)
+}
+
+// GetMonitorNotifiers returns the notifiers the order monitor should use
+func (c *Config) GetMonitorNotifiers() []string {
+	return splitList(c.MonitorNotifiers)
+}
+
+// GetSMTPRecipients returns the addresses monitor emails are sent to
+func (c *Config) GetSMTPRecipients() []string {
+	return splitList(c.SMTPTo)
+}
+
+// splitList splits a comma-separated value, trimming whitespace and dropping empty entries
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		trimmed := strings.TrimSpace(item)
 		if trimmed != "" {
 			result = append(result, trimmed)
 		}
@@ -0,0 +1,101 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"strings"
+	"time"
+)
+
+// ValidCode reports whether code looks like an ISO 4217 currency code
+func ValidCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRatesCSV reads exchange rates from CSV with a header row naming the
+// currency, rate and effective_from columns. effective_from is a date
+// (2006-01-02) or an RFC 3339 timestamp.
+func ParseRatesCSV(r io.Reader) ([]model.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rate CSV header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "rate", "effective_from"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("exchange rate CSV is missing the %s column", name)
+		}
+	}
+
+	var rates []model.ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exchange rate CSV: %w", err)
+		}
+
+		code := strings.ToUpper(strings.TrimSpace(record[columns["currency"]]))
+		if !ValidCode(code) {
+			return nil, fmt.Errorf("line %d: invalid currency %q", line, code)
+		}
+
+		rate, err := money.ParseRate(record[columns["rate"]])
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[columns["rate"]])
+		}
+
+		from, err := ParseDate(record[columns["effective_from"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rates = append(rates, model.ExchangeRate{Currency: code, Rate: rate, EffectiveFrom: from})
+	}
+
+	return rates, nil
+}
+
+// ParseDate parses a date (2006-01-02) or an RFC 3339 timestamp
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
+
+// ConvertStatement converts the amounts of a statement from the base currency
+func ConvertStatement(statement *model.CustomerStatement, code string, rate money.Rate) {
+	statement.Currency = code
+	statement.LifetimeRevenue = statement.LifetimeRevenue.Convert(rate).Rounded()
+	statement.OutstandingValue = statement.OutstandingValue.Convert(rate).Rounded()
+	for i := range statement.TopProducts {
+		statement.TopProducts[i].Revenue = statement.TopProducts[i].Revenue.Convert(rate).Rounded()
+	}
+}
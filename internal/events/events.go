@@ -0,0 +1,112 @@
+// Package events describes the changes to the Northwind domain that partners
+// can be told about, such as an order being placed or shipped
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types of events
+const (
+	CategoryCreated = "category.created"
+	CategoryUpdated = "category.updated"
+	CategoryDeleted = "category.deleted"
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	OrderPlaced     = "order.placed"
+	OrderUpdated    = "order.updated"
+	OrderShipped    = "order.shipped"
+	// A product's units in stock fell to or below its reorder level
+	StockLow = "stock.low"
+)
+
+// Types lists every type of event
+var Types = []string{
+	CategoryCreated, CategoryUpdated, CategoryDeleted,
+	ProductCreated, ProductUpdated, ProductDeleted,
+	OrderPlaced, OrderUpdated, OrderShipped,
+	StockLow,
+}
+
+// Resources lists the resources events are about, in the order of Types
+func Resources() []string {
+	var resources []string
+	for _, t := range Types {
+		resource, _, _ := strings.Cut(t, ".")
+		if !slices.Contains(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// Event is a change to a resource. Data is the resource as it is after the
+// change, or just its id once it has been deleted. The id is unique to the
+// event and stays the same however many times it is delivered, so consumers
+// can use it as an idempotency key.
+type Event struct {
+	Id         string          `json:"id" db:"event_id"`
+	Type       string          `json:"type" db:"event_type"`
+	Resource   string          `json:"resource" db:"resource"`
+	ResourceId string          `json:"resource_id" db:"resource_id"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+	Data       json.RawMessage `json:"data" db:"payload"`
+}
+
+// New returns an event of a type about a resource, with a new id
+func New(eventType string, resourceId any, data any) (Event, error) {
+	resource, _, _ := strings.Cut(eventType, ".")
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode the %s event: %w", eventType, err)
+	}
+
+	return Event{
+		Id:         newId(),
+		Type:       eventType,
+		Resource:   resource,
+		ResourceId: fmt.Sprint(resourceId),
+		OccurredAt: time.Now().UTC(),
+		Data:       encoded,
+	}, nil
+}
+
+// Returns a random event id
+func newId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
+
+// ValidFilter reports whether a filter matches any events: an event type, every
+// event of a resource such as "order.*", or "*" for every event
+func ValidFilter(filter string) bool {
+	if filter == "*" || slices.Contains(Types, filter) {
+		return true
+	}
+	resource, ok := strings.CutSuffix(filter, ".*")
+	return ok && slices.ContainsFunc(Types, func(t string) bool { return strings.HasPrefix(t, resource+".") })
+}
+
+// Filters returns the filters that match an event type, most specific first
+func Filters(eventType string) []string {
+	resource, _, _ := strings.Cut(eventType, ".")
+	return []string{eventType, resource + ".*", "*"}
+}
+
+// Matches reports whether any of the filters matches an event type
+func Matches(filters []string, eventType string) bool {
+	for _, f := range Filters(eventType) {
+		if slices.Contains(filters, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,583 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Requests
+
+// Request is a GraphQL request to execute against a schema
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	// ReadOnly rejects mutations, for requests made with GET
+	ReadOnly bool
+	Limits   Limits
+}
+
+// Limits bound the size of the queries a request may run. Zero disables a limit.
+type Limits struct {
+	// MaxDepth is how deeply fields may be nested
+	MaxDepth int
+	// MaxComplexity bounds the estimated number of fields resolved, where the
+	// fields under a list count once for each item the list may hold
+	MaxComplexity int
+	// ListSize is the number of items assumed for lists without a limit argument
+	ListSize int
+}
+
+// Error codes of request errors, found in the code extension
+const (
+	CodeParseFailed        = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed   = "GRAPHQL_VALIDATION_FAILED"
+	CodeBadUserInput       = "BAD_USER_INPUT"
+	CodeQueryTooComplex    = "QUERY_TOO_COMPLEX"
+	CodeMutationNotAllowed = "MUTATION_NOT_ALLOWED"
+	CodeUnknownOperation   = "OPERATION_RESOLUTION_FAILURE"
+)
+
+// Error is a GraphQL error. Field errors have a path; request errors a code.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Pos          `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(pos Pos, format string, args ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Pos{pos}}
+}
+
+func withCode(errs []*Error, code string) []*Error {
+	for _, err := range errs {
+		err.Extensions = map[string]any{"code": code}
+	}
+	return errs
+}
+
+// Response is the result of a request. Data is only set when the request was
+// valid and executed, as field errors leave partial data.
+type Response struct {
+	Data     any
+	Errors   []*Error
+	executed bool
+}
+
+// Executed reports whether the request was executed; otherwise it was
+// rejected with request errors before any field was resolved
+func (r *Response) Executed() bool {
+	return r.executed
+}
+
+// Code returns the code of a rejected request, or "" if it was executed
+func (r *Response) Code() string {
+	if r.executed || len(r.Errors) == 0 {
+		return ""
+	}
+	code, _ := r.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+// MarshalJSON writes data, null if an error reached the root, only for
+// executed requests, as the GraphQL response format asks
+func (r *Response) MarshalJSON() ([]byte, error) {
+	type response struct {
+		Data   *any     `json:"data,omitempty"`
+		Errors []*Error `json:"errors,omitempty"`
+	}
+	out := response{Errors: r.Errors}
+	if r.executed {
+		out.Data = &r.Data
+	}
+	return json.Marshal(out)
+}
+
+func rejected(code string, errs ...*Error) *Response {
+	return &Response{Errors: withCode(errs, code)}
+}
+
+// #endregion
+
+// #region Execution
+
+type schemaKey struct{}
+
+// Execute parses, validates and executes a request. Fields at the same depth
+// are resolved together and their thunks forced together, so loaders can
+// fetch what a whole level needs in one query.
+func Execute(ctx context.Context, schema *Schema, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			return rejected(CodeParseFailed, &Error{Message: err.Error()})
+		}
+		return rejected(CodeParseFailed, newError(syntaxErr.Pos, "Syntax error: %s", syntaxErr.Message))
+	}
+
+	op, opErr := selectOperation(doc, req.OperationName)
+	if opErr != nil {
+		return rejected(CodeUnknownOperation, opErr)
+	}
+	if op.Kind == "mutation" && schema.Mutation == nil {
+		return rejected(CodeValidationFailed, newError(op.Pos, "The schema has no mutations"))
+	}
+	if op.Kind == "mutation" && req.ReadOnly {
+		return rejected(CodeMutationNotAllowed, newError(op.Pos, "Mutations must be sent with POST"))
+	}
+
+	if errs := validate(schema, doc, op); len(errs) > 0 {
+		return rejected(CodeValidationFailed, errs...)
+	}
+
+	vars, errs := schema.coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return rejected(CodeBadUserInput, errs...)
+	}
+
+	if errs := checkLimits(schema, doc, op, vars, req.Limits); len(errs) > 0 {
+		return rejected(CodeQueryTooComplex, errs...)
+	}
+
+	e := &executor{
+		ctx:    context.WithValue(ctx, schemaKey{}, schema),
+		schema: schema,
+		doc:    doc,
+		vars:   vars,
+	}
+	return &Response{Data: e.execute(op), Errors: e.errors, executed: true}
+}
+
+// selectOperation picks the operation a request runs
+func selectOperation(doc *Document, name string) (*Operation, *Error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "The document has several operations, so an operationName is required"}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation %q", name)}
+}
+
+type executor struct {
+	ctx    context.Context
+	schema *Schema
+	doc    *Document
+	vars   map[string]any
+	errors []*Error
+}
+
+// slot is a position in the response a value is written to
+type slot struct {
+	set func(any)
+	// bubble nulls the nearest nullable position at or above this one, for
+	// a null or error where a value is required
+	bubble func()
+	// alive reports whether the nearest nullable position at or above this
+	// one still holds a value, as work under a nulled position is skipped
+	alive func() bool
+}
+
+// objectTask is an object whose fields are yet to be resolved
+type objectTask struct {
+	typ        *Object
+	source     any
+	selections []Selection
+	result     *orderedMap
+	path       []any
+	slot       slot
+}
+
+// fieldTask is a field being resolved
+type fieldTask struct {
+	object *objectTask
+	key    string
+	nodes  []*FieldSelection
+	field  *Field
+	path   []any
+	value  any
+	err    error
+}
+
+func (e *executor) execute(op *Operation) any {
+	var data any
+	root := &orderedMap{}
+	data = root
+	dead := false
+	rootSlot := slot{
+		set:    func(v any) { data = v },
+		bubble: func() { data, dead = nil, true },
+		alive:  func() bool { return !dead },
+	}
+
+	if op.Kind == "query" {
+		e.run([]*objectTask{{typ: e.schema.Query, selections: op.Selections, result: root, slot: rootSlot}})
+		return data
+	}
+
+	// Mutation fields run one after the other, each with everything it selects
+	for _, group := range e.collectFields(e.schema.Mutation, op.Selections) {
+		if dead {
+			break
+		}
+		selections := make([]Selection, len(group.nodes))
+		for i, node := range group.nodes {
+			selections[i] = node
+		}
+		e.run([]*objectTask{{typ: e.schema.Mutation, selections: selections, result: root, slot: rootSlot}})
+	}
+	return data
+}
+
+// run resolves objects level by level: the fields of every object at a
+// depth, then their thunks, then the objects they hold
+func (e *executor) run(level []*objectTask) {
+	for len(level) > 0 {
+		var tasks []*fieldTask
+		for _, object := range level {
+			if !object.slot.alive() {
+				continue
+			}
+			for _, group := range e.collectFields(object.typ, object.selections) {
+				object.result.set(group.key, nil)
+				tasks = append(tasks, e.resolve(object, group))
+			}
+		}
+
+		for _, task := range tasks {
+			for task.err == nil {
+				thunk, ok := task.value.(Thunk)
+				if !ok {
+					break
+				}
+				task.value, task.err = e.force(thunk)
+			}
+		}
+
+		var next []*objectTask
+		for _, task := range tasks {
+			if !task.object.slot.alive() {
+				continue
+			}
+			object, key := task.object, task.key
+			s := slot{
+				set:    func(v any) { object.result.set(key, v) },
+				bubble: object.slot.bubble,
+				alive:  object.slot.alive,
+			}
+			if task.err != nil {
+				e.fieldError(task.nodes[0].Pos, task.path, task.err)
+				if _, required := task.field.Type.(*NonNull); required {
+					s.bubble()
+				} else {
+					s.set(nil)
+				}
+				continue
+			}
+			e.complete(task.field.Type, task.value, task, task.path, s, &next)
+		}
+		level = next
+	}
+}
+
+type fieldGroup struct {
+	key   string
+	nodes []*FieldSelection
+}
+
+// collectFields groups the fields selected on an object by response key,
+// following fragments and applying @skip and @include
+func (e *executor) collectFields(typ *Object, selections []Selection) []*fieldGroup {
+	var groups []*fieldGroup
+	byKey := map[string]*fieldGroup{}
+	visited := map[string]bool{}
+
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, s := range selections {
+			switch s := s.(type) {
+			case *FieldSelection:
+				if !e.included(s.Directives) {
+					continue
+				}
+				key := s.ResponseKey()
+				group, ok := byKey[key]
+				if !ok {
+					group = &fieldGroup{key: key}
+					byKey[key] = group
+					groups = append(groups, group)
+				}
+				group.nodes = append(group.nodes, s)
+			case *InlineFragment:
+				if e.included(s.Directives) && (s.TypeCondition == "" || s.TypeCondition == typ.Name) {
+					collect(s.Selections)
+				}
+			case *FragmentSpread:
+				if !e.included(s.Directives) || visited[s.Name] {
+					continue
+				}
+				visited[s.Name] = true
+				if f := e.doc.Fragments[s.Name]; f != nil && f.TypeCondition == typ.Name {
+					collect(f.Selections)
+				}
+			}
+		}
+	}
+	collect(selections)
+	return groups
+}
+
+// included applies @skip(if:) and @include(if:)
+func (e *executor) included(directives []*Directive) bool {
+	return directivesInclude(directives, e.vars)
+}
+
+func directivesInclude(directives []*Directive, vars map[string]any) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		args, err := coerceArguments("@"+d.Name, ifArgument, d.Arguments, vars)
+		if err != nil {
+			continue
+		}
+		if cond, _ := args["if"].(bool); cond == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve runs the resolver of a field
+func (e *executor) resolve(object *objectTask, group *fieldGroup) *fieldTask {
+	node := group.nodes[0]
+	task := &fieldTask{
+		object: object,
+		key:    group.key,
+		nodes:  group.nodes,
+		path:   append(append([]any{}, object.path...), group.key),
+	}
+
+	task.field = fieldOf(e.schema, object.typ, node.Name)
+	args, err := coerceArguments(object.typ.Name+"."+node.Name, task.field.Args, node.Arguments, e.vars)
+	if err != nil {
+		task.err = fmt.Errorf("invalid argument %w", err)
+		return task
+	}
+
+	resolve := task.field.Resolve
+	if resolve == nil {
+		resolve = resolveFromMap
+	}
+	if node.Name == "__typename" {
+		task.value = object.typ.Name
+		return task
+	}
+	task.value, task.err = e.call(resolve, ResolveParams{
+		Context: e.ctx,
+		Source:  object.source,
+		Args:    args,
+		Field:   task.field,
+		Path:    task.path,
+	})
+	return task
+}
+
+// call runs a resolver, turning a panic into a field error
+func (e *executor) call(resolve ResolveFunc, p ResolveParams) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Interface("path", p.Path).Msg("GraphQL resolver panicked")
+			value, err = nil, fmt.Errorf("internal error")
+		}
+	}()
+	return resolve(p)
+}
+
+func (e *executor) force(thunk Thunk) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Msg("GraphQL loader panicked")
+			value, err = nil, fmt.Errorf("internal error")
+		}
+	}()
+	return thunk()
+}
+
+// fieldOf finds the definition of a field selected on an object, including
+// __typename and the introspection fields of the query type
+func fieldOf(schema *Schema, typ *Object, name string) *Field {
+	switch {
+	case name == "__typename":
+		return typenameField
+	case typ == schema.Query && name == "__schema":
+		return schemaField
+	case typ == schema.Query && name == "__type":
+		return typeField
+	}
+	return typ.Field(name)
+}
+
+func resolveFromMap(p ResolveParams) (any, error) {
+	if m, ok := p.Source.(map[string]any); ok {
+		return m[p.Field.Name], nil
+	}
+	return nil, nil
+}
+
+// complete writes a resolved value into its slot, queueing the objects it
+// holds for the next level
+func (e *executor) complete(t Type, value any, task *fieldTask, path []any, s slot, next *[]*objectTask) {
+	if n, ok := t.(*NonNull); ok {
+		if isNil(value) {
+			e.fieldError(task.nodes[0].Pos, path, fmt.Errorf("Cannot return null for non-nullable field %s.%s", task.object.typ.Name, task.field.Name))
+			s.bubble()
+			return
+		}
+		e.completeValue(n.Of, value, task, path, s, next)
+		return
+	}
+
+	// A nullable position absorbs nulls from below
+	dead := false
+	parent := s
+	s = slot{
+		set:    parent.set,
+		bubble: func() { parent.set(nil); dead = true },
+		alive:  func() bool { return !dead && parent.alive() },
+	}
+	if isNil(value) {
+		s.set(nil)
+		return
+	}
+	e.completeValue(t, value, task, path, s, next)
+}
+
+// completeValue writes a non-null value of a type that is not NonNull
+func (e *executor) completeValue(t Type, value any, task *fieldTask, path []any, s slot, next *[]*objectTask) {
+	// Leaves and lists are read through pointers; objects are passed to
+	// their resolvers as they are
+	rv := reflect.ValueOf(value)
+	if _, ok := t.(*Object); !ok {
+		for rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+			value = rv.Interface()
+		}
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		out, err := t.Serialize(value)
+		if err != nil {
+			e.fieldError(task.nodes[0].Pos, path, err)
+			s.bubble()
+			return
+		}
+		s.set(out)
+	case *Enum:
+		name := fmt.Sprint(value)
+		if !t.has(name) {
+			e.fieldError(task.nodes[0].Pos, path, fmt.Errorf("%s is not a value of %s", name, t.Name))
+			s.bubble()
+			return
+		}
+		s.set(name)
+	case *List:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fieldError(task.nodes[0].Pos, path, fmt.Errorf("expected a list, got %T", value))
+			s.bubble()
+			return
+		}
+		items := make([]any, rv.Len())
+		s.set(items)
+		for i := range items {
+			itemPath := append(append([]any{}, path...), i)
+			itemSlot := slot{set: func(v any) { items[i] = v }, bubble: s.bubble, alive: s.alive}
+			e.complete(t.Of, rv.Index(i).Interface(), task, itemPath, itemSlot, next)
+		}
+	case *Object:
+		result := &orderedMap{}
+		s.set(result)
+		var selections []Selection
+		for _, node := range task.nodes {
+			selections = append(selections, node.Selections...)
+		}
+		*next = append(*next, &objectTask{typ: t, source: value, selections: selections, result: result, path: path, slot: s})
+	}
+}
+
+func (e *executor) fieldError(pos Pos, path []any, err error) {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	gqlErr.Locations = []Pos{pos}
+	gqlErr.Path = path
+	e.errors = append(e.errors, gqlErr)
+}
+
+// isNil reports whether a resolved value is null. Nil slices are empty lists.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap is a response object, which keeps its fields in the order they were selected
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if m.values == nil {
+		m.values = map[string]any{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// #endregion
@@ -0,0 +1,367 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// shop is a schema of orders, their lines, products and categories held in
+// maps, with loaders recording the keys each of their fetches was given
+type shop struct {
+	schema     *Schema
+	lines      *Loader[int, []map[string]any]
+	products   *Loader[int, map[string]any]
+	categories *Loader[int, map[string]any]
+	fetches    map[string][][]int
+	touched    int
+}
+
+var (
+	shopOrders = []map[string]any{{"id": 10248}, {"id": 10249}, {"id": 10250}}
+	shopLines  = map[int][]map[string]any{
+		10248: {{"product_id": 11, "quantity": 12}, {"product_id": 42, "quantity": 10}},
+		10249: {{"product_id": 14, "quantity": 9}, {"product_id": 42, "quantity": 40}},
+	}
+	shopProducts = map[int]map[string]any{
+		11: {"id": 11, "name": "Queso Cabrales", "category_id": 4},
+		14: {"id": 14, "name": "Tofu", "category_id": 7},
+		42: {"id": 42, "name": "Singaporean Hokkien Fried Mee", "category_id": 5},
+	}
+	shopCategories = map[int]map[string]any{
+		4: {"id": 4, "name": "Dairy Products"},
+		5: {"id": 5, "name": "Grains/Cereals"},
+		7: {"id": 7, "name": "Produce"},
+	}
+)
+
+// fetchFrom returns a fetch looking keys up in rows
+func fetchFrom[V any](s *shop, name string, rows map[int]V) func(keys []int) (map[int]V, error) {
+	return func(keys []int) (map[int]V, error) {
+		s.fetches[name] = append(s.fetches[name], keys)
+		found := map[int]V{}
+		for _, key := range keys {
+			if row, ok := rows[key]; ok {
+				found[key] = row
+			}
+		}
+		return found, nil
+	}
+}
+
+func newShop(t *testing.T) *shop {
+	s := &shop{fetches: map[string][][]int{}}
+	s.lines = NewLoader(fetchFrom(s, "lines", shopLines))
+	s.products = NewLoader(fetchFrom(s, "products", shopProducts))
+	s.categories = NewLoader(fetchFrom(s, "categories", shopCategories))
+
+	category := &Object{Name: "Category", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(Int)},
+		{Name: "name", Type: String},
+	}}
+	product := &Object{Name: "Product", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(Int)},
+		{Name: "name", Type: NewNonNull(String)},
+		{Name: "category", Type: category, Resolve: func(p ResolveParams) (any, error) {
+			return s.categories.Load(p.Source.(map[string]any)["category_id"].(int)), nil
+		}},
+	}}
+	line := &Object{Name: "Line", Fields: []*Field{
+		{Name: "quantity", Type: NewNonNull(Int)},
+		{Name: "product", Type: product, Resolve: func(p ResolveParams) (any, error) {
+			return s.products.Load(p.Source.(map[string]any)["product_id"].(int)), nil
+		}},
+	}}
+	order := &Object{Name: "Order", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(Int)},
+		{Name: "lines", Type: NewNonNull(NewList(NewNonNull(line))), Resolve: func(p ResolveParams) (any, error) {
+			return s.lines.Load(p.Source.(map[string]any)["id"].(int)), nil
+		}},
+		{Name: "shipVia", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return nil, nil
+		}},
+	}}
+
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "order", Type: order, Args: []*Argument{{Name: "id", Type: NewNonNull(Int)}}, Resolve: func(p ResolveParams) (any, error) {
+			for _, o := range shopOrders {
+				if o["id"] == p.Args["id"] {
+					return o, nil
+				}
+			}
+			return nil, nil
+		}},
+		{Name: "orders", Type: NewNonNull(NewList(NewNonNull(order))), Args: []*Argument{{Name: "limit", Type: Int}}, Resolve: func(p ResolveParams) (any, error) {
+			if limit, ok := p.Args["limit"].(int); ok && limit < len(shopOrders) {
+				return shopOrders[:limit], nil
+			}
+			return shopOrders, nil
+		}},
+		{Name: "fail", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return nil, errors.New("order not found")
+		}},
+		{Name: "panic", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			panic("nil map")
+		}},
+	}}
+	mutation := &Object{Name: "Mutation", Fields: []*Field{
+		{Name: "touch", Type: NewNonNull(Int), Resolve: func(p ResolveParams) (any, error) {
+			s.touched++
+			return s.touched, nil
+		}},
+	}}
+
+	schema, err := NewSchema(query, mutation)
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+	s.schema = schema
+	return s
+}
+
+func (s *shop) execute(req Request) *Response {
+	return Execute(context.Background(), s.schema, req)
+}
+
+// dataJSON encodes the data of a response, as it is written to clients
+func dataJSON(t *testing.T, resp *Response) string {
+	out, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatalf("encode data: %v", err)
+	}
+	return string(out)
+}
+
+const orderLinesQuery = `{
+  orders(limit: 2) {
+    id
+    lines {
+      quantity
+      product { name category { name } }
+    }
+  }
+}`
+
+func TestExecuteNestedQueryBatchesLoads(t *testing.T) {
+	s := newShop(t)
+	resp := s.execute(Request{Query: orderLinesQuery})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %v", resp.Errors)
+	}
+
+	want := `{"orders":[` +
+		`{"id":10248,"lines":[` +
+		`{"quantity":12,"product":{"name":"Queso Cabrales","category":{"name":"Dairy Products"}}},` +
+		`{"quantity":10,"product":{"name":"Singaporean Hokkien Fried Mee","category":{"name":"Grains/Cereals"}}}]},` +
+		`{"id":10249,"lines":[` +
+		`{"quantity":9,"product":{"name":"Tofu","category":{"name":"Produce"}}},` +
+		`{"quantity":40,"product":{"name":"Singaporean Hokkien Fried Mee","category":{"name":"Grains/Cereals"}}}]}]}`
+	if got := dataJSON(t, resp); got != want {
+		t.Errorf("data = %s\nwant %s", got, want)
+	}
+
+	// Each level is fetched once, with every key it needs and no repeats
+	wantFetches := map[string][][]int{
+		"lines":      {{10248, 10249}},
+		"products":   {{11, 42, 14}},
+		"categories": {{4, 5, 7}},
+	}
+	if !reflect.DeepEqual(s.fetches, wantFetches) {
+		t.Errorf("fetches = %v, want %v", s.fetches, wantFetches)
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	// orderLinesQuery is 5 fields deep and costs 1 + 2 × (1 + 1 + 1 × (1 + 1 + 1 + 1 + 1)) = 15
+	tests := []struct {
+		name     string
+		query    string
+		limits   Limits
+		wantCode string
+	}{
+		{"no limits", orderLinesQuery, Limits{}, ""},
+		{"at the depth limit", orderLinesQuery, Limits{MaxDepth: 5}, ""},
+		{"too deep", orderLinesQuery, Limits{MaxDepth: 4}, CodeQueryTooComplex},
+		{"at the complexity limit", orderLinesQuery, Limits{MaxComplexity: 15}, ""},
+		{"too complex", orderLinesQuery, Limits{MaxComplexity: 14}, CodeQueryTooComplex},
+		{"lists without a limit count as ListSize", `{ orders { id } }`, Limits{MaxComplexity: 30, ListSize: 30}, CodeQueryTooComplex},
+		{"lists count as their limit", `{ orders(limit: 2) { id } }`, Limits{MaxComplexity: 3, ListSize: 30}, ""},
+		{"fragments count", `{ ...f } fragment f on Query { orders(limit: 100) { id } }`, Limits{MaxComplexity: 100}, CodeQueryTooComplex},
+		{"skipped fields do not count", `{ orders(limit: 100) @skip(if: true) { id } }`, Limits{MaxComplexity: 1}, ""},
+		{"introspection is free", `{ __typename __schema { queryType { name } } }`, Limits{MaxDepth: 1, MaxComplexity: 1}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newShop(t).execute(Request{Query: tt.query, Limits: tt.limits})
+			if got := resp.Code(); got != tt.wantCode {
+				t.Errorf("code = %q, want %q: %v", got, tt.wantCode, resp.Errors)
+			}
+			if resp.Executed() != (tt.wantCode == "") {
+				t.Errorf("executed = %v, want %v", resp.Executed(), tt.wantCode == "")
+			}
+		})
+	}
+}
+
+func TestExecuteVariables(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		wantCode  string
+		wantData  string
+	}{
+		{"given", `query ($id: Int!) { order(id: $id) { id } }`, map[string]any{"id": json.Number("10249")}, "", `{"order":{"id":10249}}`},
+		{"default", `query ($id: Int = 10250) { order(id: $id) { id } }`, nil, "", `{"order":{"id":10250}}`},
+		{"given over the default", `query ($id: Int = 10250) { order(id: $id) { id } }`, map[string]any{"id": json.Number("10248")}, "", `{"order":{"id":10248}}`},
+		{"required but missing", `query ($id: Int!) { order(id: $id) { id } }`, nil, CodeBadUserInput, ""},
+		{"string for an Int", `query ($id: Int!) { order(id: $id) { id } }`, map[string]any{"id": "10248"}, CodeBadUserInput, ""},
+		{"float for an Int", `query ($id: Int!) { order(id: $id) { id } }`, map[string]any{"id": json.Number("10248.5")}, CodeBadUserInput, ""},
+		{"out of range", `query ($id: Int!) { order(id: $id) { id } }`, map[string]any{"id": json.Number("3000000000")}, CodeBadUserInput, ""},
+		{"null for a non-null", `query ($id: Int!) { order(id: $id) { id } }`, map[string]any{"id": nil}, CodeBadUserInput, ""},
+		{"directive", `query ($lines: Boolean!) { order(id: 10248) { id lines @include(if: $lines) { quantity } } }`, map[string]any{"lines": false}, "", `{"order":{"id":10248}}`},
+		{"limit", `query ($n: Int) { orders(limit: $n) { id } }`, map[string]any{"n": json.Number("1")}, "", `{"orders":[{"id":10248}]}`},
+		{"undeclared", `{ order(id: $id) { id } }`, nil, CodeValidationFailed, ""},
+		{"unused", `query ($id: Int) { orders { id } }`, nil, CodeValidationFailed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newShop(t).execute(Request{Query: tt.query, Variables: tt.variables})
+			if got := resp.Code(); got != tt.wantCode {
+				t.Fatalf("code = %q, want %q: %v", got, tt.wantCode, resp.Errors)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			if len(resp.Errors) > 0 {
+				t.Fatalf("errors = %v", resp.Errors)
+			}
+			if got := dataJSON(t, resp); got != tt.wantData {
+				t.Errorf("data = %s, want %s", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestExecuteRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		wantCode string
+		wantMsg  string
+	}{
+		{"syntax error", Request{Query: `{ orders { id }`}, CodeParseFailed, "Syntax error"},
+		{"unknown field", Request{Query: `{ orders { total } }`}, CodeValidationFailed, "total"},
+		{"unknown argument", Request{Query: `{ orders(first: 2) { id } }`}, CodeValidationFailed, "first"},
+		{"leaf with selections", Request{Query: `{ orders { id { value } } }`}, CodeValidationFailed, "id"},
+		{"object without selections", Request{Query: `{ orders }`}, CodeValidationFailed, "orders"},
+		{"unknown fragment", Request{Query: `{ ...missing }`}, CodeValidationFailed, "missing"},
+		{"several operations without a name", Request{Query: `query A { orders { id } } query B { orders { id } }`}, CodeUnknownOperation, "operationName"},
+		{"unknown operation", Request{Query: `query A { orders { id } }`, OperationName: "B"}, CodeUnknownOperation, `"B"`},
+		{"mutation sent with GET", Request{Query: `mutation { touch }`, ReadOnly: true}, CodeMutationNotAllowed, "POST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newShop(t).execute(tt.req)
+			if resp.Executed() {
+				t.Fatalf("request was executed: data = %s", dataJSON(t, resp))
+			}
+			if got := resp.Code(); got != tt.wantCode {
+				t.Errorf("code = %q, want %q", got, tt.wantCode)
+			}
+			if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, tt.wantMsg) {
+				t.Errorf("errors = %v, want one mentioning %s", resp.Errors, tt.wantMsg)
+			}
+
+			// Rejected requests have no data, not even null
+			out, _ := json.Marshal(resp)
+			if strings.Contains(string(out), `"data"`) {
+				t.Errorf("response = %s, want no data", out)
+			}
+		})
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantData  string
+		wantPaths [][]any
+		wantMsg   string
+	}{
+		{
+			name:      "resolver error",
+			query:     `{ fail kind: __typename }`,
+			wantData:  `{"fail":null,"kind":"Query"}`,
+			wantPaths: [][]any{{"fail"}},
+			wantMsg:   "order not found",
+		},
+		{
+			name:      "resolver panic",
+			query:     `{ panic order(id: 10248) { id } }`,
+			wantData:  `{"panic":null,"order":{"id":10248}}`,
+			wantPaths: [][]any{{"panic"}},
+			wantMsg:   "internal error",
+		},
+		{
+			name:      "null for a non-null field bubbles to the nullable parent",
+			query:     `{ order(id: 10248) { id shipVia } }`,
+			wantData:  `{"order":null}`,
+			wantPaths: [][]any{{"order", "shipVia"}},
+			wantMsg:   "Cannot return null for non-nullable field Order.shipVia",
+		},
+		{
+			name:      "null bubbles through non-null lists to the root",
+			query:     `{ orders(limit: 1) { shipVia } }`,
+			wantData:  `null`,
+			wantPaths: [][]any{{"orders", 0, "shipVia"}},
+			wantMsg:   "Cannot return null",
+		},
+		{
+			name:      "missing object",
+			query:     `{ order(id: 1) { id } }`,
+			wantData:  `{"order":null}`,
+			wantPaths: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newShop(t).execute(Request{Query: tt.query})
+			if !resp.Executed() {
+				t.Fatalf("request was rejected: %v", resp.Errors)
+			}
+			if got := dataJSON(t, resp); got != tt.wantData {
+				t.Errorf("data = %s, want %s", got, tt.wantData)
+			}
+
+			var paths [][]any
+			for _, err := range resp.Errors {
+				paths = append(paths, err.Path)
+				if !strings.Contains(err.Message, tt.wantMsg) {
+					t.Errorf("error = %q, want one mentioning %q", err.Message, tt.wantMsg)
+				}
+				if len(err.Locations) != 1 {
+					t.Errorf("error locations = %v, want the field's", err.Locations)
+				}
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("error paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestExecuteMutationsRunInOrder(t *testing.T) {
+	s := newShop(t)
+	resp := s.execute(Request{Query: `mutation { first: touch second: touch third: touch }`})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %v", resp.Errors)
+	}
+	if got, want := dataJSON(t, resp), `{"first":1,"second":2,"third":3}`; got != want {
+		t.Errorf("data = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,339 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// #region Directives
+
+type directive struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*Argument
+}
+
+var ifArgument = []*Argument{{Name: "if", Type: NewNonNull(Boolean)}}
+
+var directives = []*directive{
+	{
+		Name:        "include",
+		Description: "Includes the field or fragment only when the argument is true",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        ifArgument,
+	},
+	{
+		Name:        "skip",
+		Description: "Skips the field or fragment when the argument is true",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        ifArgument,
+	},
+}
+
+// #endregion
+
+// #region Introspection types
+
+var (
+	introspectionSchema = &Object{Name: "__Schema", Description: "The types and directives of the API"}
+	introspectionType   = &Object{Name: "__Type", Description: "A type of the API, or a list or non-null wrapper of one"}
+	introspectionField  = &Object{Name: "__Field", Description: "A field of an object type"}
+	introspectionInput  = &Object{Name: "__InputValue", Description: "An argument, or a field of an input object"}
+	introspectionEnum   = &Object{Name: "__EnumValue", Description: "A value of an enum"}
+	introspectionDir    = &Object{Name: "__Directive", Description: "A directive the API supports"}
+
+	typeKind = &Enum{Name: "__TypeKind", Description: "The kind of a type", Values: enumValues(
+		"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL",
+	)}
+	directiveLocation = &Enum{Name: "__DirectiveLocation", Description: "Where a directive may be used", Values: enumValues(
+		"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT", "VARIABLE_DEFINITION",
+	)}
+)
+
+var (
+	typenameField = &Field{
+		Name:        "__typename",
+		Description: "The name of the object's type",
+		Type:        NewNonNull(String),
+	}
+	schemaField = &Field{
+		Name:        "__schema",
+		Description: "The schema of the API",
+		Type:        NewNonNull(introspectionSchema),
+		Resolve: func(p ResolveParams) (any, error) {
+			return p.Context.Value(schemaKey{}), nil
+		},
+	}
+	typeField = &Field{
+		Name:        "__type",
+		Description: "A type of the API by name",
+		Type:        introspectionType,
+		Args:        []*Argument{{Name: "name", Type: NewNonNull(String)}},
+		Resolve: func(p ResolveParams) (any, error) {
+			t := p.Context.Value(schemaKey{}).(*Schema).Type(p.Args["name"].(string))
+			if t == nil {
+				return nil, nil
+			}
+			return t, nil
+		},
+	}
+)
+
+func enumValues(names ...string) []EnumValue {
+	values := make([]EnumValue, len(names))
+	for i, name := range names {
+		values[i] = EnumValue{Name: name}
+	}
+	return values
+}
+
+var includeDeprecated = []*Argument{{Name: "includeDeprecated", Type: Boolean, Default: false}}
+
+// deprecation fields; nothing in the API is deprecated
+var deprecationFields = []*Field{
+	{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: constant(false)},
+	{Name: "deprecationReason", Type: String, Resolve: constant(nil)},
+}
+
+func constant(v any) ResolveFunc {
+	return func(ResolveParams) (any, error) { return v, nil }
+}
+
+func init() {
+	introspectionSchema.AddFields(
+		&Field{Name: "description", Type: String, Resolve: constant(nil)},
+		&Field{Name: "types", Type: NewNonNull(NewList(NewNonNull(introspectionType))), Resolve: func(p ResolveParams) (any, error) {
+			s := p.Source.(*Schema)
+			var types []Type
+			for _, name := range s.typeNames() {
+				types = append(types, s.types[name])
+			}
+			return types, nil
+		}},
+		&Field{Name: "queryType", Type: NewNonNull(introspectionType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Schema).Query, nil
+		}},
+		&Field{Name: "mutationType", Type: introspectionType, Resolve: func(p ResolveParams) (any, error) {
+			if m := p.Source.(*Schema).Mutation; m != nil {
+				return m, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "subscriptionType", Type: introspectionType, Resolve: constant(nil)},
+		&Field{Name: "directives", Type: NewNonNull(NewList(NewNonNull(introspectionDir))), Resolve: constant(directives)},
+	)
+
+	introspectionType.AddFields(
+		&Field{Name: "kind", Type: NewNonNull(typeKind), Resolve: func(p ResolveParams) (any, error) {
+			switch p.Source.(type) {
+			case *Scalar:
+				return "SCALAR", nil
+			case *Object:
+				return "OBJECT", nil
+			case *Enum:
+				return "ENUM", nil
+			case *InputObject:
+				return "INPUT_OBJECT", nil
+			case *List:
+				return "LIST", nil
+			case *NonNull:
+				return "NON_NULL", nil
+			}
+			return nil, fmt.Errorf("unknown kind of type %T", p.Source)
+		}},
+		&Field{Name: "name", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			switch t := p.Source.(type) {
+			case *List, *NonNull:
+				return nil, nil
+			default:
+				return t.(Type).String(), nil
+			}
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			var description string
+			switch t := p.Source.(type) {
+			case *Scalar:
+				description = t.Description
+			case *Object:
+				description = t.Description
+			case *Enum:
+				description = t.Description
+			case *InputObject:
+				description = t.Description
+			}
+			return optional(description), nil
+		}},
+		&Field{Name: "specifiedByURL", Type: String, Resolve: constant(nil)},
+		&Field{Name: "fields", Type: NewList(NewNonNull(introspectionField)), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			if o, ok := p.Source.(*Object); ok {
+				return o.Fields, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "interfaces", Type: NewList(NewNonNull(introspectionType)), Resolve: func(p ResolveParams) (any, error) {
+			if _, ok := p.Source.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "possibleTypes", Type: NewList(NewNonNull(introspectionType)), Resolve: constant(nil)},
+		&Field{Name: "enumValues", Type: NewList(NewNonNull(introspectionEnum)), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			if e, ok := p.Source.(*Enum); ok {
+				return e.Values, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "inputFields", Type: NewList(NewNonNull(introspectionInput)), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			if o, ok := p.Source.(*InputObject); ok {
+				return o.Fields, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "ofType", Type: introspectionType, Resolve: func(p ResolveParams) (any, error) {
+			switch t := p.Source.(type) {
+			case *List:
+				return t.Of, nil
+			case *NonNull:
+				return t.Of, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "isOneOf", Type: Boolean, Resolve: func(p ResolveParams) (any, error) {
+			if _, ok := p.Source.(*InputObject); ok {
+				return false, nil
+			}
+			return nil, nil
+		}},
+	)
+
+	introspectionField.AddFields(
+		&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).Name, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Field).Description), nil
+		}},
+		&Field{Name: "args", Type: NewNonNull(NewList(NewNonNull(introspectionInput))), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).Args, nil
+		}},
+		&Field{Name: "type", Type: NewNonNull(introspectionType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Field).Type, nil
+		}},
+	)
+	introspectionField.AddFields(deprecationFields...)
+
+	introspectionInput.AddFields(
+		&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Argument).Name, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*Argument).Description), nil
+		}},
+		&Field{Name: "type", Type: NewNonNull(introspectionType), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*Argument).Type, nil
+		}},
+		&Field{Name: "defaultValue", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			a := p.Source.(*Argument)
+			if a.Default == nil {
+				return nil, nil
+			}
+			return formatValue(a.Type, a.Default), nil
+		}},
+	)
+	introspectionInput.AddFields(deprecationFields...)
+
+	introspectionEnum.AddFields(
+		&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(EnumValue).Name, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(EnumValue).Description), nil
+		}},
+	)
+	introspectionEnum.AddFields(deprecationFields...)
+
+	introspectionDir.AddFields(
+		&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*directive).Name, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return optional(p.Source.(*directive).Description), nil
+		}},
+		&Field{Name: "locations", Type: NewNonNull(NewList(NewNonNull(directiveLocation))), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*directive).Locations, nil
+		}},
+		&Field{Name: "args", Type: NewNonNull(NewList(NewNonNull(introspectionInput))), Args: includeDeprecated, Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(*directive).Args, nil
+		}},
+		&Field{Name: "isRepeatable", Type: NewNonNull(Boolean), Resolve: constant(false)},
+	)
+}
+
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// formatValue writes a default value as a GraphQL literal
+func formatValue(t Type, v any) string {
+	if n, ok := t.(*NonNull); ok {
+		t = n.Of
+	}
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if _, ok := t.(*Enum); ok {
+			return v
+		}
+		return strconv.Quote(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			var of Type = t
+			if l, ok := t.(*List); ok {
+				of = l.Of
+			}
+			items[i] = formatValue(of, item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields := make([]string, len(names))
+		for i, name := range names {
+			var of Type = String
+			if o, ok := t.(*InputObject); ok {
+				if def := argumentNamed(o.Fields, name); def != nil {
+					of = def.Type
+				}
+			}
+			fields[i] = name + ": " + formatValue(of, v[name])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return formatValue(t, items)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
+
+// #endregion
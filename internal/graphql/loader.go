@@ -0,0 +1,79 @@
+package graphql
+
+// Loader batches the lookups of a request by key, in the manner of DataLoader.
+// Load queues a key and returns a thunk; forcing any of the thunks fetches
+// every queued key in one call, and results are cached for the rest of the
+// request. Loaders are per request and, like the executor, not safe for
+// concurrent use.
+type Loader[K comparable, V any] struct {
+	fetch   func(keys []K) (map[K]V, error)
+	queued  []K
+	pending map[K]bool
+	fetched map[K]bool
+	values  map[K]V
+	errors  map[K]error
+}
+
+// NewLoader returns a loader fetching values with fetch, which returns the
+// values found for the keys. Keys it leaves out resolve to null.
+func NewLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	l := &Loader[K, V]{fetch: fetch}
+	l.Clear()
+	return l
+}
+
+// Load queues a key and returns a thunk resolving to its value
+func (l *Loader[K, V]) Load(key K) Thunk {
+	if !l.fetched[key] && !l.pending[key] {
+		l.queued = append(l.queued, key)
+		l.pending[key] = true
+	}
+
+	return func() (any, error) {
+		l.dispatch()
+		if err, ok := l.errors[key]; ok {
+			return nil, err
+		}
+		if v, ok := l.values[key]; ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+}
+
+// Prime caches a value, such as one a mutation just wrote
+func (l *Loader[K, V]) Prime(key K, v V) {
+	l.fetched[key] = true
+	l.values[key] = v
+	delete(l.errors, key)
+}
+
+// Clear forgets every cached value, as after a mutation
+func (l *Loader[K, V]) Clear() {
+	l.queued = nil
+	l.pending = map[K]bool{}
+	l.fetched = map[K]bool{}
+	l.values = map[K]V{}
+	l.errors = map[K]error{}
+}
+
+// dispatch fetches the queued keys
+func (l *Loader[K, V]) dispatch() {
+	if len(l.queued) == 0 {
+		return
+	}
+	keys := l.queued
+	l.queued = nil
+	l.pending = map[K]bool{}
+
+	found, err := l.fetch(keys)
+	for _, key := range keys {
+		l.fetched[key] = true
+		switch v, ok := found[key]; {
+		case err != nil:
+			l.errors[key] = err
+		case ok:
+			l.values[key] = v
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// countingFetch returns a fetch recording the keys of each call and finding
+// every even key
+func countingFetch(calls *[][]int) func(keys []int) (map[int]string, error) {
+	return func(keys []int) (map[int]string, error) {
+		*calls = append(*calls, keys)
+		found := map[int]string{}
+		for _, key := range keys {
+			if key%2 == 0 {
+				found[key] = fmt.Sprintf("v%d", key)
+			}
+		}
+		return found, nil
+	}
+}
+
+func TestLoaderBatches(t *testing.T) {
+	var calls [][]int
+	l := NewLoader(countingFetch(&calls))
+
+	thunks := []Thunk{l.Load(2), l.Load(3), l.Load(2), l.Load(4)}
+	if len(calls) != 0 {
+		t.Fatalf("Load() fetched %v before any thunk was forced", calls)
+	}
+
+	var got []any
+	for _, thunk := range thunks {
+		v, err := thunk()
+		if err != nil {
+			t.Fatalf("thunk() error = %v", err)
+		}
+		got = append(got, v)
+	}
+	if want := []any{"v2", nil, "v2", "v4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+	if want := [][]int{{2, 3, 4}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("fetches = %v, want %v", calls, want)
+	}
+
+	// Fetched keys, found or not, are cached for the rest of the request
+	if _, err := l.Load(3)(); err != nil || len(calls) != 1 {
+		t.Errorf("Load(3) after fetching = %v, %d fetches, want cached", err, len(calls))
+	}
+	if v, _ := l.Load(6)(); v != "v6" || len(calls) != 2 {
+		t.Errorf("Load(6) = %v after %d fetches, want v6 after 2", v, len(calls))
+	}
+}
+
+func TestLoaderPrimeAndClear(t *testing.T) {
+	var calls [][]int
+	l := NewLoader(countingFetch(&calls))
+
+	l.Prime(2, "primed")
+	if v, _ := l.Load(2)(); v != "primed" || len(calls) != 0 {
+		t.Errorf("Load(2) = %v after %d fetches, want the primed value without fetching", v, len(calls))
+	}
+
+	l.Clear()
+	if v, _ := l.Load(2)(); v != "v2" || len(calls) != 1 {
+		t.Errorf("Load(2) after Clear() = %v after %d fetches, want v2 fetched again", v, len(calls))
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	fetchErr := errors.New("connection refused")
+	fetches := 0
+	l := NewLoader(func(keys []int) (map[int]string, error) {
+		fetches++
+		return nil, fetchErr
+	})
+
+	first, second := l.Load(1), l.Load(2)
+	for i, thunk := range []Thunk{first, second, l.Load(1)} {
+		if _, err := thunk(); !errors.Is(err, fetchErr) {
+			t.Errorf("thunk %d error = %v, want %v", i, err, fetchErr)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d times, want once", fetches)
+	}
+}
@@ -0,0 +1,860 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// #region Documents
+
+// Document is a parsed GraphQL request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query or mutation of a document
+type Operation struct {
+	Kind       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Pos        Pos
+}
+
+// VariableDefinition declares a variable of an operation
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Pos     Pos
+}
+
+// TypeRef is a type as written in a variable definition, such as [Int!]!
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a *FieldSelection, *FragmentSpread or *InlineFragment
+type Selection interface {
+	position() Pos
+}
+
+// FieldSelection selects a field, under its alias if it has one
+type FieldSelection struct {
+	Alias      string
+	Name       string
+	Arguments  []*ArgumentNode
+	Directives []*Directive
+	Selections []Selection
+	Pos        Pos
+}
+
+// ResponseKey is the key the field is returned under
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread includes a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Pos        Pos
+}
+
+// InlineFragment selects fields when the object has the given type
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Pos           Pos
+}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Pos           Pos
+}
+
+func (f *FieldSelection) position() Pos { return f.Pos }
+func (f *FragmentSpread) position() Pos { return f.Pos }
+func (f *InlineFragment) position() Pos { return f.Pos }
+
+// ArgumentNode is an argument given to a field or directive
+type ArgumentNode struct {
+	Name  string
+	Value *Value
+	Pos   Pos
+}
+
+// Directive is a directive such as @include(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments []*ArgumentNode
+	Pos       Pos
+}
+
+// ValueKind is the kind of a literal value
+type ValueKind int
+
+const (
+	VariableKind ValueKind = iota
+	IntKind
+	FloatKind
+	StringKind
+	BooleanKind
+	NullKind
+	EnumKind
+	ListKind
+	ObjectKind
+)
+
+// Value is a literal value, or a variable. Raw holds the variable name or the
+// scalar's text; lists hold their items and objects their fields.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+	Pos    Pos
+}
+
+// ObjectField is a field of an input object literal
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// Pos is a position in a document, counted from 1
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// #endregion
+
+// #region Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Pos
+}
+
+type lexer struct {
+	src       string
+	offset    int
+	line      int
+	lineStart int
+}
+
+// SyntaxError is a document that cannot be parsed
+type SyntaxError struct {
+	Message string
+	Pos     Pos
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Line: l.line, Column: utf8.RuneCountInString(l.src[l.lineStart:l.offset]) + 1}
+}
+
+func (l *lexer) errorf(pos Pos, format string, args ...any) error {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Pos: pos}
+}
+
+// skipIgnored skips whitespace, commas, comments and byte order marks
+func (l *lexer) skipIgnored() {
+	for l.offset < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.offset:])
+		switch r {
+		case '\n':
+			l.offset++
+			l.line++
+			l.lineStart = l.offset
+		case '\r':
+			l.offset++
+			if l.offset < len(l.src) && l.src[l.offset] == '\n' {
+				l.offset++
+			}
+			l.line++
+			l.lineStart = l.offset
+		case ' ', '\t', ',', '\ufeff':
+			l.offset += size
+		case '#':
+			for l.offset < len(l.src) && l.src[l.offset] != '\n' && l.src[l.offset] != '\r' {
+				l.offset++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := l.pos()
+	if l.offset >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.offset]
+	switch {
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.offset++
+		return token{kind: tokenPunctuator, value: string(c), pos: pos}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.offset:], "...") {
+			l.offset += 3
+			return token{kind: tokenPunctuator, value: "...", pos: pos}, nil
+		}
+		return token{}, l.errorf(pos, "unexpected .")
+	case c == '_' || isLetter(c):
+		start := l.offset
+		for l.offset < len(l.src) && (l.src[l.offset] == '_' || isLetter(l.src[l.offset]) || isDigit(l.src[l.offset])) {
+			l.offset++
+		}
+		return token{kind: tokenName, value: l.src[start:l.offset], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.offset:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.offset:])
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) number(pos Pos) (token, error) {
+	start := l.offset
+	if l.src[l.offset] == '-' {
+		l.offset++
+	}
+	digits := func() int {
+		n := 0
+		for l.offset < len(l.src) && isDigit(l.src[l.offset]) {
+			l.offset++
+			n++
+		}
+		return n
+	}
+
+	intStart := l.offset
+	if digits() == 0 {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	if l.src[intStart] == '0' && l.offset-intStart > 1 {
+		return token{}, l.errorf(pos, "invalid number: leading zero")
+	}
+
+	kind := tokenInt
+	if l.offset < len(l.src) && l.src[l.offset] == '.' {
+		l.offset++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	if l.offset < len(l.src) && (l.src[l.offset] == 'e' || l.src[l.offset] == 'E') {
+		l.offset++
+		kind = tokenFloat
+		if l.offset < len(l.src) && (l.src[l.offset] == '+' || l.src[l.offset] == '-') {
+			l.offset++
+		}
+		if digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	if l.offset < len(l.src) && (l.src[l.offset] == '_' || l.src[l.offset] == '.' || isLetter(l.src[l.offset])) {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	return token{kind: kind, value: l.src[start:l.offset], pos: pos}, nil
+}
+
+func (l *lexer) string(pos Pos) (token, error) {
+	l.offset++
+	var b strings.Builder
+	for {
+		if l.offset >= len(l.src) || l.src[l.offset] == '\n' || l.src[l.offset] == '\r' {
+			return token{}, l.errorf(pos, "unterminated string")
+		}
+		c := l.src[l.offset]
+		switch {
+		case c == '"':
+			l.offset++
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case c == '\\':
+			if l.offset+1 >= len(l.src) {
+				return token{}, l.errorf(pos, "unterminated string")
+			}
+			escape := l.src[l.offset+1]
+			l.offset += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.offset+4 > len(l.src) {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				n, err := strconv.ParseUint(l.src[l.offset:l.offset+4], 16, 32)
+				if err != nil {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				l.offset += 4
+				b.WriteRune(rune(n))
+			default:
+				return token{}, l.errorf(pos, "invalid escape \\%c", escape)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.offset:])
+			b.WriteRune(r)
+			l.offset += size
+		}
+	}
+}
+
+// blockString reads a """block string""", removing its common indentation
+func (l *lexer) blockString(pos Pos) (token, error) {
+	l.offset += 3
+	var raw strings.Builder
+	for {
+		if l.offset >= len(l.src) {
+			return token{}, l.errorf(pos, "unterminated block string")
+		}
+		switch {
+		case strings.HasPrefix(l.src[l.offset:], `"""`):
+			l.offset += 3
+			return token{kind: tokenString, value: dedentBlock(raw.String()), pos: pos}, nil
+		case strings.HasPrefix(l.src[l.offset:], `\"""`):
+			raw.WriteString(`"""`)
+			l.offset += 4
+		default:
+			c := l.src[l.offset]
+			raw.WriteByte(c)
+			l.offset++
+			if c == '\n' {
+				l.line++
+				l.lineStart = l.offset
+			}
+		}
+	}
+}
+
+func dedentBlock(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// #endregion
+
+// #region Parser
+
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// Parse parses an executable GraphQL document of operations and fragments
+func Parse(src string) (*Document, error) {
+	p := &parser{lexer: &lexer{src: src, line: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"), p.peekName("query"), p.peekName("mutation"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekName("fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[f.Name]; ok {
+				return nil, p.lexer.errorf(f.Pos, "fragment %s is defined more than once", f.Name)
+			}
+			doc.Fragments[f.Name] = f
+		case p.peekName("subscription"):
+			return nil, p.lexer.errorf(p.tok.pos, "subscriptions are not supported")
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, &SyntaxError{Message: "the document has no operations", Pos: Pos{Line: 1, Column: 1}}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == punctuator
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.lexer.errorf(p.tok.pos, "unexpected end of document")
+	}
+	return p.lexer.errorf(p.tok.pos, "unexpected %q", p.tok.value)
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Kind: "query", Pos: p.tok.pos}
+	if p.peek("{") {
+		selections, err := p.selectionSet()
+		op.Selections = selections
+		return op, err
+	}
+
+	op.Kind = p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peek(")") {
+			v, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, v)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	op.Directives = directives
+
+	op.Selections, err = p.selectionSet()
+	return op, err
+}
+
+func (p *parser) variableDefinition() (*VariableDefinition, error) {
+	v := &VariableDefinition{Pos: p.tok.pos}
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	v.Name = name
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if v.Type, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if p.peek("=") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if v.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	t := &TypeRef{}
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		t.Elem = elem
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.Name = name
+	}
+
+	if p.peek("!") {
+		t.NonNull = true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek("}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, p.lexer.errorf(p.tok.pos, "selection sets must not be empty")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	pos := p.tok.pos
+	if !p.peek("...") {
+		return p.field()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Pos: pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		directives, err := p.directives()
+		spread.Directives = directives
+		return spread, err
+	}
+
+	inline := &InlineFragment{Pos: pos}
+	if p.peekName("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		inline.TypeCondition = name
+	}
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	inline.Directives = directives
+	inline.Selections, err = p.selectionSet()
+	return inline, err
+}
+
+func (p *parser) field() (*FieldSelection, error) {
+	f := &FieldSelection{Pos: p.tok.pos}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	f.Name = name
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		f.Alias = name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*ArgumentNode, error) {
+	if !p.peek("(") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var args []*ArgumentNode
+	for !p.peek(")") {
+		arg := &ArgumentNode{Pos: p.tok.pos}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arg.Name = name
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, p.unexpected()
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		d := &Directive{Pos: p.tok.pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d.Name = name
+		if d.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	f := &Fragment{Pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.lexer.errorf(f.Pos, "a fragment cannot be named on")
+	}
+	f.Name = name
+	if !p.peekName("on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	f.Selections, err = p.selectionSet()
+	return f, err
+}
+
+// value parses a value; variables are not allowed in constant values such as defaults
+func (p *parser) value(constant bool) (*Value, error) {
+	v := &Value{Pos: p.tok.pos}
+	switch p.tok.kind {
+	case tokenInt:
+		v.Kind, v.Raw = IntKind, p.tok.value
+	case tokenFloat:
+		v.Kind, v.Raw = FloatKind, p.tok.value
+	case tokenString:
+		v.Kind, v.Raw = StringKind, p.tok.value
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = BooleanKind
+		case "null":
+			v.Kind = NullKind
+		default:
+			v.Kind = EnumKind
+		}
+		v.Raw = p.tok.value
+	case tokenPunctuator:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return nil, p.lexer.errorf(v.Pos, "variables are not allowed here")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			v.Kind, v.Raw = VariableKind, name
+			return v, err
+		case "[":
+			v.Kind = ListKind
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.peek("]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.List = append(v.List, item)
+			}
+		case "{":
+			v.Kind = ObjectKind
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.peek("}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.Fields = append(v.Fields, &ObjectField{Name: name, Value: item})
+			}
+		default:
+			return nil, p.unexpected()
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	return v, p.advance()
+}
+
+// #endregion
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		operations int
+		fragments  int
+	}{
+		{"shorthand query", `{ orders { id } }`, 1, 0},
+		{"named query with variables", `query Order($id: Int!, $lines: [Int!] = [1, 2]) { order(id: $id) { id } }`, 1, 0},
+		{"mutation", `mutation { touch }`, 1, 0},
+		{"aliases and directives", `{ first: order(id: 1) @include(if: true) { id } }`, 1, 0},
+		{"fragments", `query { ...orderFields } fragment orderFields on Query { orders { id } }`, 1, 1},
+		{"inline fragment", `{ ... on Query { orders { id } } }`, 1, 0},
+		{"several operations", `query A { orders { id } } query B { orders { id } }`, 2, 0},
+		{"literals", `{ f(a: -1.5e3, b: "aé\n", c: """ block """, d: null, e: RED, f: {g: [1]}) }`, 1, 0},
+		{"comments and commas", "# orders\n{ orders, { id, } }", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(doc.Operations) != tt.operations || len(doc.Fragments) != tt.fragments {
+				t.Errorf("Parse() = %d operations and %d fragments, want %d and %d",
+					len(doc.Operations), len(doc.Fragments), tt.operations, tt.fragments)
+			}
+		})
+	}
+}
+
+func TestParseSelections(t *testing.T) {
+	doc, err := Parse(`query Lines($id: Int!) {
+  first: order(id: $id) @skip(if: false) {
+    lines { quantity }
+  }
+}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	op := doc.Operations[0]
+	if op.Kind != "query" || op.Name != "Lines" {
+		t.Errorf("operation = %s %s, want query Lines", op.Kind, op.Name)
+	}
+	if len(op.Variables) != 1 || op.Variables[0].Name != "id" || op.Variables[0].Type.String() != "Int!" {
+		t.Fatalf("variables = %+v, want $id: Int!", op.Variables)
+	}
+
+	field, ok := op.Selections[0].(*FieldSelection)
+	if !ok {
+		t.Fatalf("selection = %T, want a field", op.Selections[0])
+	}
+	if field.Name != "order" || field.ResponseKey() != "first" {
+		t.Errorf("field = %s as %s, want order as first", field.Name, field.ResponseKey())
+	}
+	if want := (Pos{Line: 2, Column: 3}); field.Pos != want {
+		t.Errorf("field position = %+v, want %+v", field.Pos, want)
+	}
+	if len(field.Arguments) != 1 || field.Arguments[0].Value.Kind != VariableKind || field.Arguments[0].Value.Raw != "id" {
+		t.Errorf("arguments = %+v, want id: $id", field.Arguments)
+	}
+	if len(field.Directives) != 1 || field.Directives[0].Name != "skip" {
+		t.Errorf("directives = %+v, want @skip", field.Directives)
+	}
+	if lines, ok := field.Selections[0].(*FieldSelection); !ok || lines.Name != "lines" || len(lines.Selections) != 1 {
+		t.Errorf("nested selection = %+v, want lines { quantity }", field.Selections[0])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		pos  Pos
+	}{
+		{"empty", ``, Pos{Line: 1, Column: 1}},
+		{"only fragments", `fragment f on Query { id }`, Pos{Line: 1, Column: 1}},
+		{"unclosed selection", `{ orders { id }`, Pos{Line: 1, Column: 16}},
+		{"missing argument value", `{ order(id: ) { id } }`, Pos{Line: 1, Column: 13}},
+		{"unterminated string", "{\n  order(name: \"abc) { id } }", Pos{Line: 2, Column: 15}},
+		{"subscription", `subscription { orders { id } }`, Pos{Line: 1, Column: 1}},
+		{"duplicate fragment", "{ ...f }\nfragment f on Query { id }\nfragment f on Query { id }", Pos{Line: 3, Column: 1}},
+		{"variable in a default", `query ($a: Int = $b) { id }`, Pos{Line: 1, Column: 18}},
+		{"unexpected character", `{ orders { id ^ } }`, Pos{Line: 1, Column: 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error = %v, want a syntax error", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Parse() error at %+v, want %+v: %v", syntaxErr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"northwind-api/internal/money"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Input values reach Parse as decoded JSON, with numbers as json.Number, or
+// as literals converted to the same form. Enum literals are an enumLiteral so
+// scalars can tell them apart from strings.
+type enumLiteral string
+
+// Built-in scalars, and the Decimal and DateTime scalars of this API
+var (
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "A signed 32-bit integer",
+		Serialize:   serializeInt,
+		Parse: func(v any) (any, error) {
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("expected an integer, got %s", describe(v))
+			}
+			i, err := strconv.ParseInt(string(n), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("expected a 32-bit integer, got %s", n)
+			}
+			return int(i), nil
+		},
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "A double-precision floating point number",
+		Serialize: func(v any) (any, error) {
+			rv := reflect.ValueOf(v)
+			switch {
+			case rv.CanFloat():
+				return rv.Float(), nil
+			case rv.CanInt():
+				return float64(rv.Int()), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Float", v)
+		},
+		Parse: func(v any) (any, error) {
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("expected a number, got %s", describe(v))
+			}
+			return n.Float64()
+		},
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "A UTF-8 string",
+		Serialize: func(v any) (any, error) {
+			if s, ok := v.(fmt.Stringer); ok {
+				return s.String(), nil
+			}
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+				return rv.String(), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as String", v)
+		},
+		Parse: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %s", describe(v))
+			}
+			return s, nil
+		},
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false",
+		Serialize: func(v any) (any, error) {
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Boolean", v)
+		},
+		Parse: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected a boolean, got %s", describe(v))
+			}
+			return b, nil
+		},
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "A unique identifier, serialized as a string",
+		Serialize: func(v any) (any, error) {
+			if rv := reflect.ValueOf(v); rv.CanInt() {
+				return strconv.FormatInt(rv.Int(), 10), nil
+			}
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as ID", v)
+		},
+		Parse: func(v any) (any, error) {
+			switch v := v.(type) {
+			case string:
+				return v, nil
+			case json.Number:
+				if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+					return string(v), nil
+				}
+			}
+			return nil, fmt.Errorf("expected a string or integer, got %s", describe(v))
+		},
+	}
+	// Decimal is an exact decimal such as a price, passed to resolvers as a
+	// json.Number so it decodes into money.Money without rounding
+	Decimal = &Scalar{
+		Name:        "Decimal",
+		Description: "An exact decimal number such as an amount of money, serialized as a string",
+		Serialize: func(v any) (any, error) {
+			switch v := v.(type) {
+			case money.Money:
+				return v.String(), nil
+			case money.Rate:
+				return v.String(), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Decimal", v)
+		},
+		Parse: func(v any) (any, error) {
+			var s string
+			switch v := v.(type) {
+			case string:
+				s = v
+			case json.Number:
+				s = string(v)
+			default:
+				return nil, fmt.Errorf("expected a decimal, got %s", describe(v))
+			}
+			if !decimalPattern.MatchString(s) {
+				return nil, fmt.Errorf("expected a decimal, got %q", s)
+			}
+			return json.Number(s), nil
+		},
+	}
+	DateTime = &Scalar{
+		Name:        "DateTime",
+		Description: "An RFC 3339 date and time",
+		Serialize: func(v any) (any, error) {
+			if t, ok := v.(time.Time); ok {
+				return t.Format(time.RFC3339Nano), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as DateTime", v)
+		},
+		Parse: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected an RFC 3339 date and time, got %s", describe(v))
+			}
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, fmt.Errorf("expected an RFC 3339 date and time, got %q", s)
+			}
+			return t, nil
+		},
+	}
+	// JSON is any JSON value, for maps whose keys vary
+	JSON = &Scalar{
+		Name:        "JSON",
+		Description: "Any JSON value",
+		Serialize:   func(v any) (any, error) { return v, nil },
+		Parse:       func(v any) (any, error) { return v, nil },
+	}
+)
+
+var builtinScalars = []*Scalar{Int, Float, String, Boolean, ID}
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+func serializeInt(v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return rv.Int(), nil
+	case rv.CanUint() && rv.Uint() <= math.MaxInt64:
+		return int64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("cannot serialize %T as Int", v)
+}
+
+// describe names the kind of an input value for error messages
+func describe(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case json.Number:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case enumLiteral:
+		return string(v)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
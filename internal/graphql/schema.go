@@ -0,0 +1,284 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// #region Types
+
+// Type is a *Scalar, *Object, *Enum, *InputObject, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Serialize turns a resolved value into its JSON
+// output, and Parse turns an input value (a JSON-decoded variable or a
+// literal converted to Go) into the value resolvers receive.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(v any) (any, error)
+	Parse       func(v any) (any, error)
+}
+
+// Object is an output type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// Field is a field of an object. A nil Resolve reads the field from a map
+// source by name.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+	// Cost is the complexity of resolving the field once, 1 if zero
+	Cost int
+}
+
+// Argument is an argument of a field or directive, or a field of an input object
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	// Default is the value used when the argument is not given, nil for none
+	Default any
+}
+
+// Enum is a leaf type with a fixed set of string values
+type Enum struct {
+	Name        string
+	Description string
+	Values      []EnumValue
+}
+
+// EnumValue is a value of an enum
+type EnumValue struct {
+	Name        string
+	Description string
+}
+
+// InputObject is an input type with fields, received by resolvers as a map
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*Argument
+}
+
+// List is a list of values of a type
+type List struct {
+	Of Type
+}
+
+// NonNull is a type whose values are never null
+type NonNull struct {
+	Of Type
+}
+
+func (s *Scalar) String() string      { return s.Name }
+func (o *Object) String() string      { return o.Name }
+func (e *Enum) String() string        { return e.Name }
+func (o *InputObject) String() string { return o.Name }
+func (l *List) String() string        { return "[" + l.Of.String() + "]" }
+func (n *NonNull) String() string     { return n.Of.String() + "!" }
+
+// Field returns the field of an object with the given name, or nil
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// AddFields adds fields to an object, replacing any with the same name
+func (o *Object) AddFields(fields ...*Field) {
+	for _, f := range fields {
+		replaced := false
+		for i, existing := range o.Fields {
+			if existing.Name == f.Name {
+				o.Fields[i] = f
+				replaced = true
+			}
+		}
+		if !replaced {
+			o.Fields = append(o.Fields, f)
+		}
+	}
+}
+
+func (e *Enum) has(value string) bool {
+	for _, v := range e.Values {
+		if v.Name == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NewList returns a list of t
+func NewList(t Type) *List {
+	return &List{Of: t}
+}
+
+// NewNonNull returns t made non-null
+func NewNonNull(t Type) *NonNull {
+	return &NonNull{Of: t}
+}
+
+// namedType strips lists and non-null wrappers off a type
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.Of
+		case *NonNull:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+func isLeaf(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+func isInput(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		return true
+	}
+	return false
+}
+
+func isList(t Type) bool {
+	if n, ok := t.(*NonNull); ok {
+		t = n.Of
+	}
+	_, ok := t.(*List)
+	return ok
+}
+
+// #endregion
+
+// #region Resolvers
+
+// ResolveFunc resolves a field of its source object. It may return a Thunk to
+// defer the work, so fields at the same depth can be loaded together.
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// Thunk is a deferred value. The thunks of a level of the query are forced
+// together after all of its fields have been resolved.
+type Thunk func() (any, error)
+
+// ResolveParams are passed to a resolver
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value the parent field resolved to, nil for root fields
+	Source any
+	Args   map[string]any
+	Field  *Field
+	// Path is the response path of the field
+	Path []any
+}
+
+// #endregion
+
+// #region Schema
+
+// Schema is the root types of an API and the types reachable from them
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+	types    map[string]Type
+}
+
+// NewSchema collects the types reachable from the roots, checking that no two
+// types share a name. Mutation may be nil.
+func NewSchema(query, mutation *Object) (*Schema, error) {
+	s := &Schema{Query: query, Mutation: mutation, types: map[string]Type{}}
+	roots := []Type{query, introspectionSchema, introspectionType}
+	for _, scalar := range builtinScalars {
+		roots = append(roots, scalar)
+	}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, t := range roots {
+		if err := s.collect(t); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) collect(t Type) error {
+	t = namedType(t)
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types are named %s", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	switch t := t.(type) {
+	case *Object:
+		for _, f := range t.Fields {
+			if f.Type == nil {
+				return fmt.Errorf("graphql: field %s.%s has no type", t.Name, f.Name)
+			}
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, a := range f.Args {
+				if !isInput(a.Type) {
+					return fmt.Errorf("graphql: argument %s of %s.%s is not an input type", a.Name, t.Name, f.Name)
+				}
+				if err := s.collect(a.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *InputObject:
+		for _, f := range t.Fields {
+			if !isInput(f.Type) {
+				return fmt.Errorf("graphql: field %s.%s is not an input type", t.Name, f.Name)
+			}
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Type returns the named type, or nil
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+// typeNames returns the names of the schema's types in order
+func (s *Schema) typeNames() []string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// #endregion
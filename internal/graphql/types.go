@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"fmt"
+	"northwind-api/internal/money"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType  = reflect.TypeFor[time.Time]()
+	moneyType = reflect.TypeFor[money.Money]()
+	rateType  = reflect.TypeFor[money.Rate]()
+)
+
+// Builder builds object and input types from Go structs, naming fields after
+// their JSON names. Each struct is built once, so types shared by several
+// structs are the same type in the schema.
+type Builder struct {
+	objects map[reflect.Type]*Object
+	inputs  map[reflect.Type]*InputObject
+	err     error
+}
+
+// NewBuilder returns a builder with no types built yet
+func NewBuilder() *Builder {
+	return &Builder{objects: map[reflect.Type]*Object{}, inputs: map[reflect.Type]*InputObject{}}
+}
+
+// Err returns the first field that could not be mapped to a GraphQL type
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Object returns the object type of a struct, named name or, if name is
+// empty, after the struct. Nullable fields, pointers and Null values, are
+// nullable and all others non-null.
+func (b *Builder) Object(t reflect.Type, name string) *Object {
+	if o, ok := b.objects[t]; ok {
+		return o
+	}
+	if name == "" {
+		name = upperFirst(t.Name())
+	}
+	o := &Object{Name: name}
+	b.objects[t] = o
+
+	for _, f := range jsonFields(t) {
+		typ, nullable := b.output(f.field.Type)
+		if typ == nil {
+			b.fail(t, f)
+			continue
+		}
+		if !nullable {
+			typ = NewNonNull(typ)
+		}
+		o.Fields = append(o.Fields, &Field{Name: f.name, Type: typ, Resolve: structField(f.field.Index)})
+	}
+	return o
+}
+
+// output maps a Go type to an output type, reporting whether it is nullable
+func (b *Builder) output(t reflect.Type) (Type, bool) {
+	if scalar := scalarOf(t); scalar != nil {
+		return scalar, false
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		typ, _ := b.output(t.Elem())
+		return typ, true
+	case reflect.Slice:
+		elem, nullable := b.output(t.Elem())
+		if elem == nil {
+			return nil, false
+		}
+		if !nullable {
+			elem = NewNonNull(elem)
+		}
+		return NewList(elem), false
+	case reflect.Map, reflect.Interface:
+		return JSON, true
+	case reflect.Struct:
+		if isNull(t) {
+			typ, _ := b.output(nullValue(t))
+			return typ, true
+		}
+		return b.Object(t, ""), false
+	}
+	return nil, false
+}
+
+// Input returns the input type of a request struct, named name or, if name
+// is empty, after the struct with Request replaced by Input. Fields the
+// validate tag requires are non-null; others may be left out as in JSON.
+func (b *Builder) Input(t reflect.Type, name string) *InputObject {
+	return b.input(t, name)
+}
+
+// Patch returns an input type for a JSON merge patch of a struct. Every field
+// is nullable and the omitted ones, such as the key, are left out.
+func (b *Builder) Patch(t reflect.Type, name string, omit ...string) *InputObject {
+	o := &InputObject{Name: name, Description: "Fields to change. Fields left out keep their value and null clears a nullable field."}
+	for _, f := range jsonFields(t) {
+		if contains(omit, f.name) {
+			continue
+		}
+		typ := b.inputType(f.field.Type)
+		if typ == nil {
+			b.fail(t, f)
+			continue
+		}
+		o.Fields = append(o.Fields, &Argument{Name: f.name, Type: typ})
+	}
+	return o
+}
+
+func (b *Builder) input(t reflect.Type, name string) *InputObject {
+	if o, ok := b.inputs[t]; ok {
+		return o
+	}
+	if name == "" {
+		name = strings.TrimSuffix(upperFirst(t.Name()), "Request") + "Input"
+	}
+	o := &InputObject{Name: name}
+	b.inputs[t] = o
+
+	for _, f := range jsonFields(t) {
+		typ := b.inputType(f.field.Type)
+		if typ == nil {
+			b.fail(t, f)
+			continue
+		}
+		if tag := f.field.Tag.Get("validate"); tag == "required" || strings.HasPrefix(tag, "required,") {
+			typ = NewNonNull(typ)
+		}
+		o.Fields = append(o.Fields, &Argument{Name: f.name, Type: typ})
+	}
+	return o
+}
+
+// inputType maps a Go type to a nullable input type
+func (b *Builder) inputType(t reflect.Type) Type {
+	if scalar := scalarOf(t); scalar != nil {
+		return scalar
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.inputType(t.Elem())
+	case reflect.Slice:
+		elem := b.inputType(t.Elem())
+		if elem == nil {
+			return nil
+		}
+		return NewList(NewNonNull(elem))
+	case reflect.Map, reflect.Interface:
+		return JSON
+	case reflect.Struct:
+		if isNull(t) {
+			return b.inputType(nullValue(t))
+		}
+		return b.input(t, "")
+	}
+	return nil
+}
+
+func (b *Builder) fail(t reflect.Type, f jsonField) {
+	if b.err == nil {
+		b.err = fmt.Errorf("graphql: field %s of %s has no GraphQL type", f.field.Name, t)
+	}
+}
+
+// scalarOf returns the scalar a Go type maps to, or nil
+func scalarOf(t reflect.Type) *Scalar {
+	switch t {
+	case timeType:
+		return DateTime
+	case moneyType, rateType:
+		return Decimal
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Int
+	case reflect.Float32, reflect.Float64:
+		return Float
+	case reflect.String:
+		return String
+	}
+	return nil
+}
+
+type jsonField struct {
+	name  string
+	field reflect.StructField
+}
+
+// jsonFields lists the fields of a struct's JSON encoding
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Tag.Get("json") == "") {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, field: f})
+	}
+	return fields
+}
+
+// structField resolves a field of a struct source, reading through pointers
+// and turning invalid Null values into null
+func structField(index []int) ResolveFunc {
+	return func(p ResolveParams) (any, error) {
+		v := reflect.Indirect(reflect.ValueOf(p.Source))
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot read %s from %T", p.Field.Name, p.Source)
+		}
+		f := v.FieldByIndex(index)
+		if isNull(f.Type()) {
+			if !f.FieldByName("Valid").Bool() {
+				return nil, nil
+			}
+			f = f.FieldByName("V")
+		}
+		if f.Kind() == reflect.Pointer && f.IsNil() {
+			return nil, nil
+		}
+		return f.Interface(), nil
+	}
+}
+
+// isNull reports whether a type is a nullable wrapper with V and Valid fields
+func isNull(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	_, hasV := t.FieldByName("V")
+	return ok && hasV && valid.Type.Kind() == reflect.Bool
+}
+
+func nullValue(t reflect.Type) reflect.Type {
+	v, _ := t.FieldByName("V")
+	return v.Type
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
@@ -0,0 +1,342 @@
+package graphql
+
+// #region Validation
+
+// validator checks an operation and the fragments it uses against the schema
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	errors    []*Error
+	used      map[string]bool
+	spreading map[string]bool
+	checked   map[string]bool
+}
+
+// validate reports what makes an operation invalid: unknown fields,
+// arguments, fragments and variables, missing arguments and sub-selections,
+// selections on leaf fields, fragment cycles and unused variables
+func validate(schema *Schema, doc *Document, op *Operation) []*Error {
+	v := &validator{
+		schema:    schema,
+		doc:       doc,
+		used:      map[string]bool{},
+		spreading: map[string]bool{},
+		checked:   map[string]bool{},
+	}
+
+	defined := map[string]bool{}
+	for _, def := range op.Variables {
+		if defined[def.Name] {
+			v.errorf(def.Pos, "Variable $%s is defined more than once", def.Name)
+		}
+		defined[def.Name] = true
+		if _, err := schema.resolveTypeRef(def.Type); err != nil {
+			v.errorf(def.Pos, "Variable $%s: %s", def.Name, err)
+		}
+	}
+
+	root := schema.Query
+	if op.Kind == "mutation" {
+		root = schema.Mutation
+	}
+	v.directives(op.Directives)
+	v.selections(root, op.Selections)
+
+	for name := range v.used {
+		if !defined[name] {
+			v.errorf(op.Pos, "Variable $%s is not defined by operation %s", name, operationName(op))
+		}
+	}
+	for _, def := range op.Variables {
+		if !v.used[def.Name] {
+			v.errorf(def.Pos, "Variable $%s is never used in operation %s", def.Name, operationName(op))
+		}
+	}
+	return v.errors
+}
+
+func operationName(op *Operation) string {
+	if op.Name == "" {
+		return "(anonymous)"
+	}
+	return op.Name
+}
+
+func (v *validator) errorf(pos Pos, format string, args ...any) {
+	v.errors = append(v.errors, newError(pos, format, args...))
+}
+
+func (v *validator) selections(typ *Object, selections []Selection) {
+	names := map[string]string{}
+	v.collectNames(typ, selections, names, map[string]bool{})
+
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *FieldSelection:
+			v.field(typ, s)
+		case *InlineFragment:
+			v.directives(s.Directives)
+			if target := v.typeCondition(typ, s.TypeCondition, s.Pos); target != nil {
+				v.selections(target, s.Selections)
+			}
+		case *FragmentSpread:
+			v.directives(s.Directives)
+			f, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.errorf(s.Pos, "Unknown fragment %s", s.Name)
+				continue
+			}
+			if v.spreading[s.Name] {
+				v.errorf(s.Pos, "Fragment %s spreads itself", s.Name)
+				continue
+			}
+			target := v.typeCondition(typ, f.TypeCondition, f.Pos)
+			if target == nil || v.checked[s.Name+"@"+typ.Name] {
+				continue
+			}
+			v.checked[s.Name+"@"+typ.Name] = true
+			v.spreading[s.Name] = true
+			v.directives(f.Directives)
+			v.selections(target, f.Selections)
+			delete(v.spreading, s.Name)
+		}
+	}
+}
+
+// collectNames checks that fields sharing a response key select the same field
+func (v *validator) collectNames(typ *Object, selections []Selection, names map[string]string, visited map[string]bool) {
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *FieldSelection:
+			key := s.ResponseKey()
+			if name, ok := names[key]; ok && name != s.Name {
+				v.errorf(s.Pos, "Fields %s and %s conflict, as both are returned as %s", name, s.Name, key)
+			}
+			names[key] = s.Name
+		case *InlineFragment:
+			if s.TypeCondition == "" || s.TypeCondition == typ.Name {
+				v.collectNames(typ, s.Selections, names, visited)
+			}
+		case *FragmentSpread:
+			if f, ok := v.doc.Fragments[s.Name]; ok && !visited[s.Name] && f.TypeCondition == typ.Name {
+				visited[s.Name] = true
+				v.collectNames(typ, f.Selections, names, visited)
+			}
+		}
+	}
+}
+
+// typeCondition checks that a fragment can apply to objects of typ. With
+// object types only, that means naming typ itself.
+func (v *validator) typeCondition(typ *Object, condition string, pos Pos) *Object {
+	if condition == "" {
+		return typ
+	}
+	target, ok := v.schema.Type(condition).(*Object)
+	if !ok {
+		v.errorf(pos, "Fragments cannot be on %s, which is not an object type", condition)
+		return nil
+	}
+	if target != typ {
+		v.errorf(pos, "A fragment on %s cannot be spread within %s", condition, typ.Name)
+		return nil
+	}
+	return target
+}
+
+func (v *validator) field(typ *Object, s *FieldSelection) {
+	v.directives(s.Directives)
+	field := fieldOf(v.schema, typ, s.Name)
+	if field == nil {
+		v.errorf(s.Pos, "Cannot query field %s on type %s", s.Name, typ.Name)
+		return
+	}
+	v.arguments(typ.Name+"."+s.Name, field.Args, s.Arguments, s.Pos)
+
+	switch named := namedType(field.Type).(type) {
+	case *Object:
+		if len(s.Selections) == 0 {
+			v.errorf(s.Pos, "Field %s of type %s must have a selection of subfields", s.Name, field.Type)
+			return
+		}
+		v.selections(named, s.Selections)
+	default:
+		if len(s.Selections) > 0 {
+			v.errorf(s.Pos, "Field %s must not have a selection since type %s has no subfields", s.Name, field.Type)
+		}
+	}
+}
+
+// arguments checks that arguments are known, given once and, where
+// required, given; literals without variables are checked against their types
+func (v *validator) arguments(owner string, defs []*Argument, args []*ArgumentNode, pos Pos) {
+	given := map[string]bool{}
+	for _, arg := range args {
+		def := argumentNamed(defs, arg.Name)
+		switch {
+		case def == nil:
+			v.errorf(arg.Pos, "Unknown argument %s on %s", arg.Name, owner)
+			continue
+		case given[arg.Name]:
+			v.errorf(arg.Pos, "Argument %s of %s is given more than once", arg.Name, owner)
+			continue
+		}
+		given[arg.Name] = true
+
+		if !v.variables(arg.Value) {
+			value, _ := literal(arg.Value, nil)
+			if _, err := coerceInput(def.Type, value); err != nil {
+				v.errorf(arg.Pos, "Argument %s of %s: %s", arg.Name, owner, err)
+			}
+		}
+	}
+
+	for _, def := range defs {
+		if _, required := def.Type.(*NonNull); required && def.Default == nil && !given[def.Name] {
+			v.errorf(pos, "Argument %s of %s, of type %s, is required", def.Name, owner, def.Type)
+		}
+	}
+}
+
+// variables records the variables a value uses, reporting whether it uses any
+func (v *validator) variables(value *Value) bool {
+	switch value.Kind {
+	case VariableKind:
+		v.used[value.Raw] = true
+		return true
+	case ListKind:
+		found := false
+		for _, item := range value.List {
+			found = v.variables(item) || found
+		}
+		return found
+	case ObjectKind:
+		found := false
+		for _, f := range value.Fields {
+			found = v.variables(f.Value) || found
+		}
+		return found
+	}
+	return false
+}
+
+func (v *validator) directives(directives []*Directive) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			v.errorf(d.Pos, "Unknown directive @%s", d.Name)
+			continue
+		}
+		v.arguments("@"+d.Name, ifArgument, d.Arguments, d.Pos)
+	}
+}
+
+// #endregion
+
+// #region Limits
+
+// checkLimits measures the depth and estimated complexity of the fields an
+// operation selects. Introspection is not counted, so tools can always
+// fetch the schema.
+func checkLimits(schema *Schema, doc *Document, op *Operation, vars map[string]any, limits Limits) []*Error {
+	if limits.MaxDepth <= 0 && limits.MaxComplexity <= 0 {
+		return nil
+	}
+	listSize := limits.ListSize
+	if listSize <= 0 {
+		listSize = 1
+	}
+
+	m := &measure{schema: schema, doc: doc, vars: vars, listSize: listSize}
+	root := schema.Query
+	if op.Kind == "mutation" {
+		root = schema.Mutation
+	}
+	depth, cost := m.selections(root, op.Selections, 1)
+
+	var errs []*Error
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		errs = append(errs, newError(op.Pos, "Query depth %d exceeds the limit of %d", depth, limits.MaxDepth))
+	}
+	if limits.MaxComplexity > 0 && cost > limits.MaxComplexity {
+		errs = append(errs, newError(op.Pos, "Query complexity %d exceeds the limit of %d", cost, limits.MaxComplexity))
+	}
+	return errs
+}
+
+type measure struct {
+	schema   *Schema
+	doc      *Document
+	vars     map[string]any
+	listSize int
+}
+
+// maxCost caps costs, so deeply nested lists cannot overflow
+const maxCost = 1 << 40
+
+// selections returns the depth and cost of a selection set at the given depth
+func (m *measure) selections(typ *Object, selections []Selection, depth int) (maxDepth, cost int) {
+	for _, s := range selections {
+		var d, c int
+		switch s := s.(type) {
+		case *FieldSelection:
+			if !directivesInclude(s.Directives, m.vars) {
+				continue
+			}
+			d, c = m.field(typ, s, depth)
+		case *InlineFragment:
+			if !directivesInclude(s.Directives, m.vars) {
+				continue
+			}
+			d, c = m.selections(typ, s.Selections, depth)
+		case *FragmentSpread:
+			if !directivesInclude(s.Directives, m.vars) {
+				continue
+			}
+			d, c = m.selections(typ, m.doc.Fragments[s.Name].Selections, depth)
+		}
+		maxDepth = max(maxDepth, d)
+		cost = min(cost+c, maxCost)
+	}
+	return maxDepth, cost
+}
+
+func (m *measure) field(typ *Object, s *FieldSelection, depth int) (int, int) {
+	if s.Name == "__schema" || s.Name == "__type" || s.Name == "__typename" {
+		return 0, 0
+	}
+	field := fieldOf(m.schema, typ, s.Name)
+	cost := max(field.Cost, 1)
+
+	object, ok := namedType(field.Type).(*Object)
+	if !ok {
+		return depth, cost
+	}
+	childDepth, childCost := m.selections(object, s.Selections, depth+1)
+	if isList(field.Type) {
+		childCost = multiplyCost(childCost, m.items(field, s))
+	}
+	return max(depth, childDepth), min(cost+childCost, maxCost)
+}
+
+func multiplyCost(cost, n int) int {
+	if n > 0 && cost > maxCost/n {
+		return maxCost
+	}
+	return cost * n
+}
+
+// items is how many items a list field may return: its limit or first
+// argument if it has one, else the configured list size
+func (m *measure) items(field *Field, s *FieldSelection) int {
+	args, err := coerceArguments(s.Name, field.Args, s.Arguments, m.vars)
+	if err == nil {
+		for _, name := range []string{"limit", "first"} {
+			if n, ok := args[name].(int); ok && n >= 0 {
+				return min(n, maxCost)
+			}
+		}
+	}
+	return m.listSize
+}
+
+// #endregion
@@ -0,0 +1,217 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// coerceInput checks an input value against its type, returning the value
+// resolvers receive. Input objects keep only the fields that were given, so
+// resolvers can tell a field set to null from one left out.
+func coerceInput(t Type, v any) (any, error) {
+	if f, ok := v.(float64); ok {
+		v = json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+
+	if n, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a non-null %s", n.Of)
+		}
+		return coerceInput(n.Of, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		return t.Parse(v)
+	case *Enum:
+		var name string
+		switch v := v.(type) {
+		case enumLiteral:
+			name = string(v)
+		case string:
+			name = v
+		default:
+			return nil, fmt.Errorf("expected a value of %s, got %s", t.Name, describe(v))
+		}
+		if !t.has(name) {
+			return nil, fmt.Errorf("%s is not a value of %s", name, t.Name)
+		}
+		return name, nil
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			// A single value is accepted as a list of one
+			item, err := coerceInput(t.Of, v)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			coerced, err := coerceInput(t.Of, item)
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			out[i] = coerced
+		}
+		return out, nil
+	case *InputObject:
+		fields, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object of %s, got %s", t.Name, describe(v))
+		}
+		return coerceFields(t.Name, t.Fields, fields)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// coerceFields coerces the fields of an input object or the arguments of a
+// field, filling in defaults and rejecting unknown and missing required ones
+func coerceFields(owner string, defs []*Argument, given map[string]any) (map[string]any, error) {
+	for name := range given {
+		if argumentNamed(defs, name) == nil {
+			return nil, fmt.Errorf("%s has no field %s", owner, name)
+		}
+	}
+
+	out := make(map[string]any, len(defs))
+	for _, def := range defs {
+		v, ok := given[def.Name]
+		if !ok {
+			if def.Default != nil {
+				out[def.Name] = def.Default
+			} else if _, required := def.Type.(*NonNull); required {
+				return nil, fmt.Errorf("%s of %s is required", def.Name, owner)
+			}
+			continue
+		}
+		coerced, err := coerceInput(def.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", def.Name, err)
+		}
+		out[def.Name] = coerced
+	}
+	return out, nil
+}
+
+func argumentNamed(defs []*Argument, name string) *Argument {
+	for _, def := range defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// literal converts a literal to the form of decoded JSON, substituting
+// variables. It reports false for a variable that was not given, which leaves
+// an argument or object field out rather than setting it to null.
+func literal(v *Value, vars map[string]any) (any, bool) {
+	switch v.Kind {
+	case VariableKind:
+		value, ok := vars[v.Raw]
+		return value, ok
+	case IntKind, FloatKind:
+		return json.Number(v.Raw), true
+	case StringKind:
+		return v.Raw, true
+	case BooleanKind:
+		return v.Raw == "true", true
+	case NullKind:
+		return nil, true
+	case EnumKind:
+		return enumLiteral(v.Raw), true
+	case ListKind:
+		items := make([]any, len(v.List))
+		for i, item := range v.List {
+			items[i], _ = literal(item, vars)
+		}
+		return items, true
+	case ObjectKind:
+		fields := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			if value, ok := literal(f.Value, vars); ok {
+				fields[f.Name] = value
+			}
+		}
+		return fields, true
+	}
+	return nil, false
+}
+
+// coerceArguments coerces the arguments given to a field or directive
+func coerceArguments(owner string, defs []*Argument, args []*ArgumentNode, vars map[string]any) (map[string]any, error) {
+	given := make(map[string]any, len(args))
+	for _, arg := range args {
+		if v, ok := literal(arg.Value, vars); ok {
+			given[arg.Name] = v
+		}
+	}
+	return coerceFields(owner, defs, given)
+}
+
+// #region Variables
+
+// resolveTypeRef looks up the type a variable is declared with
+func (s *Schema) resolveTypeRef(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.resolveTypeRef(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		t = s.Type(ref.Name)
+		if t == nil {
+			return nil, fmt.Errorf("unknown type %s", ref.Name)
+		}
+		if !isInput(t) {
+			return nil, fmt.Errorf("%s is not an input type", ref.Name)
+		}
+	}
+	if ref.NonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+// coerceVariables checks the variables given with a request against the
+// operation's definitions, applying defaults. The values are kept in their
+// input form, as they are coerced again with the arguments they are used in.
+func (s *Schema) coerceVariables(op *Operation, given map[string]any) (map[string]any, []*Error) {
+	out := map[string]any{}
+	var errs []*Error
+	for _, def := range op.Variables {
+		t, err := s.resolveTypeRef(def.Type)
+		if err != nil {
+			errs = append(errs, newError(def.Pos, "Variable $%s: %s", def.Name, err))
+			continue
+		}
+
+		v, ok := given[def.Name]
+		if !ok && def.Default != nil {
+			v, ok = literal(def.Default, nil)
+		}
+		if !ok {
+			if _, required := t.(*NonNull); required {
+				errs = append(errs, newError(def.Pos, "Variable $%s of type %s is required", def.Name, def.Type))
+			}
+			continue
+		}
+
+		if _, err := coerceInput(t, v); err != nil {
+			errs = append(errs, newError(def.Pos, "Variable $%s: %s", def.Name, err))
+			continue
+		}
+		out[def.Name] = v
+	}
+	return out, errs
+}
+
+// #endregion
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"northwind-api/internal/patch"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Bulk
+
+// Bulk modes: atomic applies every item or none, best_effort keeps the items
+// that succeed
+const (
+	bulkAtomic     = "atomic"
+	bulkBestEffort = "best_effort"
+)
+
+// NDJSONType is the media type of newline delimited JSON bodies
+const NDJSONType = "application/x-ndjson"
+
+// A line of a bulk request. Creates carry the new resource in data, updates a
+// merge patch and deletes nothing.
+type bulkRequestItem struct {
+	Op   string          `json:"op"`
+	Id   int             `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Represents the outcome of one item of a bulk request
+type BulkItemResult struct {
+	Index  int             `json:"index"`
+	Op     string          `json:"op"`
+	Id     int             `json:"id,omitempty"`
+	Status string          `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Fields validate.Errors `json:"fields,omitempty"`
+}
+
+// Represents the response to a bulk request
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// Statuses of bulk items that succeeded, by operation
+var bulkDoneStatus = map[string]string{
+	repository.BulkCreate: "created",
+	repository.BulkUpdate: "updated",
+	repository.BulkDelete: "deleted",
+}
+
+// errNotArray is returned for JSON bulk bodies that are not an array
+var errNotArray = errors.New("the body must be a JSON array of items")
+
+// bulkReader reads the items of a bulk request one at a time, from a JSON
+// array or an NDJSON stream
+type bulkReader struct {
+	dec   *json.Decoder
+	array bool
+	done  bool
+}
+
+// Starts reading a bulk request body
+func newBulkReader(body io.Reader, mediaType string) (*bulkReader, error) {
+	br := &bulkReader{dec: json.NewDecoder(body), array: mediaType != NDJSONType}
+	if br.array {
+		token, err := br.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != json.Delim('[') {
+			return nil, errNotArray
+		}
+	}
+	return br, nil
+}
+
+// Returns the next item's JSON, or io.EOF after the last
+func (br *bulkReader) next() (json.RawMessage, error) {
+	if br.done {
+		return nil, io.EOF
+	}
+
+	if br.array && !br.dec.More() {
+		br.done = true
+		if _, err := br.dec.Token(); err != nil {
+			return nil, err
+		}
+		if _, err := br.dec.Token(); err != io.EOF {
+			return nil, errTrailingData
+		}
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := br.dec.Decode(&raw); err != nil {
+		if err == io.EOF && br.array {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return raw, nil
+}
+
+// bulkResource runs a bulk request for one resource. Items are read in chunks
+// of BULK_CHUNK_SIZE, turned into repository operations and run by the given
+// func. Items that cannot be decoded or fail validation are reported without
+// reaching the database; updates are merge patches validated like PATCH.
+func bulkResource[T any](h *Handler, w http.ResponseWriter, r *http.Request, noun string,
+	check func(v *T, fields map[string]bool) validate.Errors,
+	run func(b *repository.Bulk, items []repository.BulkItem[T]) ([]repository.BulkResult, error)) {
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = bulkAtomic
+	}
+	if mode != bulkAtomic && mode != bulkBestEffort {
+		writeErrorResponse(w, http.StatusBadRequest, "mode must be atomic or best_effort")
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" && mediaType != NDJSONType {
+		writeErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json or "+NDJSONType)
+		return
+	}
+
+	response := BulkResponse{Mode: mode, Results: []BulkItemResult{}}
+	status := http.StatusOK
+
+	bulk := h.db.NewBulk(mode == bulkAtomic)
+	defer bulk.Close()
+
+	// Runs the items of a chunk that passed validation
+	var items []repository.BulkItem[T]
+	var indexes []int
+	flush := func() error {
+		if len(items) == 0 {
+			return nil
+		}
+		results, err := run(bulk, items)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			item := &response.Results[indexes[i]]
+			item.Id = result.Id
+			if result.Err != nil {
+				item.Status = "failed"
+				item.Error, item.Fields = bulkItemError(result.Err, noun)
+			}
+		}
+		items, indexes = items[:0], indexes[:0]
+		return nil
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.config.BulkMaxBodyBytes)
+	reader, err := newBulkReader(body, mediaType)
+	for index := 0; err == nil; index++ {
+		var raw json.RawMessage
+		if raw, err = reader.next(); err != nil {
+			break
+		}
+
+		result, item := parseBulkItem(raw, check)
+		result.Index = index
+		response.Results = append(response.Results, result)
+		if item != nil {
+			items = append(items, *item)
+			indexes = append(indexes, index)
+		}
+		if len(items) == h.config.BulkChunkSize {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+	}
+	if err == io.EOF {
+		err = flush()
+	}
+	for _, result := range response.Results {
+		if result.Status == "failed" {
+			response.Failed++
+		}
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil && (mode == bulkBestEffort || response.Failed == 0):
+		if err := bulk.Finish(); err != nil {
+			log.Error().Err(err).Msg("Error finishing bulk " + noun + " request")
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to save the "+noun+" changes")
+			return
+		}
+		response.Committed = true
+	case err == nil:
+		// An atomic bulk with a failed item is rolled back by Close
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+		response.Error = fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit)
+	case isJSONError(err):
+		status = http.StatusBadRequest
+		response.Error = fmt.Sprintf("Invalid JSON in item %d: %s", len(response.Results), err.Error())
+	default:
+		log.Error().Err(err).Msg("Error running bulk " + noun + " request")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to save the "+noun+" changes")
+		return
+	}
+
+	// A best effort bulk keeps the chunks that ran before a body error, but
+	// not the items still waiting in the unfinished chunk
+	if response.Error != "" && mode == bulkBestEffort {
+		response.Committed = true
+		for _, index := range indexes {
+			response.Results[index].Status = "not_applied"
+		}
+	}
+	for i := range response.Results {
+		item := &response.Results[i]
+		switch {
+		case item.Status != "":
+		case response.Committed:
+			item.Status = bulkDoneStatus[item.Op]
+			response.Succeeded++
+		default:
+			item.Status = "not_applied"
+		}
+	}
+
+	if status == http.StatusOK && response.Failed > 0 {
+		status = http.StatusMultiStatus
+		if !response.Committed {
+			status = http.StatusUnprocessableEntity
+		}
+	}
+
+	log.Info().Str("mode", mode).Int("succeeded", response.Succeeded).Int("failed", response.Failed).
+		Bool("committed", response.Committed).Msg("Finished bulk " + noun + " request")
+	writeJSONResponse(w, status, response)
+}
+
+// Decodes and validates one bulk item. The result is marked failed, and no
+// item returned, if it is not valid.
+func parseBulkItem[T any](raw json.RawMessage, check func(v *T, fields map[string]bool) validate.Errors) (BulkItemResult, *repository.BulkItem[T]) {
+	var req bulkRequestItem
+	if err := decodeStrict(raw, &req); err != nil {
+		return BulkItemResult{Status: "failed", Error: describeJSONError(raw, err)}, nil
+	}
+	if req.Op == "" {
+		req.Op = repository.BulkCreate
+	}
+
+	result := BulkItemResult{Op: req.Op, Id: req.Id}
+	fail := func(message string, fields validate.Errors) (BulkItemResult, *repository.BulkItem[T]) {
+		result.Status, result.Error, result.Fields = "failed", message, fields
+		return result, nil
+	}
+
+	hasData := len(bytes.TrimSpace(req.Data)) > 0 && !bytes.Equal(bytes.TrimSpace(req.Data), []byte("null"))
+	switch req.Op {
+	case repository.BulkCreate:
+		if req.Id != 0 {
+			return fail("id must not be given when creating", nil)
+		}
+		if !hasData {
+			return fail("data is required when creating", nil)
+		}
+		value := new(T)
+		if err := decodeStrict(req.Data, value); err != nil {
+			return fail(describeJSONError(req.Data, err), nil)
+		}
+		errs := validate.Struct(value)
+		if check != nil {
+			errs = append(errs, check(value, nil)...)
+		}
+		if len(errs) > 0 {
+			return fail("Validation failed", errs)
+		}
+		return result, &repository.BulkItem[T]{Op: req.Op, Value: value}
+
+	case repository.BulkUpdate:
+		if req.Id < 1 {
+			return fail("id is required when updating", nil)
+		}
+		if !hasData {
+			return fail("data is required when updating", nil)
+		}
+		doc, err := patch.Parse(patch.MergePatchType, req.Data)
+		if err != nil {
+			return fail(err.Error(), nil)
+		}
+		return result, &repository.BulkItem[T]{Op: req.Op, Id: req.Id, Apply: applyPatch(doc, check)}
+
+	case repository.BulkDelete:
+		if req.Id < 1 {
+			return fail("id is required when deleting", nil)
+		}
+		if hasData {
+			return fail("data must not be given when deleting", nil)
+		}
+		return result, &repository.BulkItem[T]{Op: req.Op, Id: req.Id}
+	}
+
+	return fail("op must be create, update or delete", nil)
+}
+
+// Turns the error a bulk item failed with into a message for the client
+func bulkItemError(err error, noun string) (string, validate.Errors) {
+	var errs validate.Errors
+	switch {
+	case errors.As(err, &errs):
+		return "Validation failed", errs
+	case err.Error() == noun+" not found":
+		return strings.ToUpper(noun[:1]) + noun[1:] + " not found", nil
+	case strings.HasPrefix(err.Error(), "invalid patch: "):
+		return strings.TrimPrefix(err.Error(), "invalid patch: "), nil
+	case strings.HasPrefix(err.Error(), "invalid "+noun+": "):
+		return strings.TrimPrefix(err.Error(), "invalid "+noun+": "), nil
+	}
+
+	log.Error().Err(err).Msg("Error running bulk " + noun + " item")
+	return "Failed to save the " + noun, nil
+}
+
+// Reports whether an error came from reading malformed JSON
+func isJSONError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, errTrailingData) || errors.Is(err, errNotArray)
+}
+
+// Handler to create, update and delete categories in bulk
+func (h *Handler) BulkCategories(w http.ResponseWriter, r *http.Request) {
+	log.Info().Str("mode", r.URL.Query().Get("mode")).Msg("POST /api/categories/bulk - Running bulk category request")
+
+	bulkResource(h, w, r, "category", nil, (*repository.Bulk).Categories)
+}
+
+// Handler to create, update and delete products in bulk
+func (h *Handler) BulkProducts(w http.ResponseWriter, r *http.Request) {
+	log.Info().Str("mode", r.URL.Query().Get("mode")).Msg("POST /api/products/bulk - Running bulk product request")
+
+	bulkResource(h, w, r, "product", nil, (*repository.Bulk).Products)
+}
+
+// #endregion
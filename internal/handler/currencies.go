@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"northwind-api/internal/currency"
+	"northwind-api/internal/model"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Exchange rates
+
+// Handler to get the current exchange rate of every currency
+func (h *Handler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/exchange-rates - Getting exchange rates")
+
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		t, err := currency.ParseDate(v)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "at must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+			return
+		}
+		at = t
+	}
+
+	if exportList(w, r, "exchange-rates", func() (*repository.Cursor[model.ExchangeRate], error) {
+		return h.db.StreamExchangeRates(at)
+	}) {
+		return
+	}
+
+	rates, err := h.db.GetExchangeRates(at)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting exchange rates")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get exchange rates")
+		return
+	}
+
+	log.Info().Int("count", len(rates)).Msg("Successfully retrieved exchange rates")
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"base":  h.db.BaseCurrency(),
+		"rates": rates,
+	})
+}
+
+// Handler to load exchange rates from a JSON array or a CSV body
+func (h *Handler) SaveExchangeRates(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("POST /api/exchange-rates - Saving exchange rates")
+
+	var rates []model.ExchangeRate
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/csv":
+		body, ok := h.readBody(w, r)
+		if !ok {
+			return
+		}
+		parsed, err := currency.ParseRatesCSV(bytes.NewReader(body))
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		rates = parsed
+	case isJSONContentType(r.Header.Get("Content-Type")):
+		if !h.decodeJSON(w, r, &rates) {
+			return
+		}
+	default:
+		writeErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json or text/csv")
+		return
+	}
+
+	if len(rates) == 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "at least one exchange rate is required")
+		return
+	}
+	if !validRequest(w, h.checkExchangeRates(rates)) {
+		return
+	}
+
+	if err := h.db.SaveExchangeRates(rates); err != nil {
+		log.Error().Err(err).Msg("Error saving exchange rates")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to save exchange rates")
+		return
+	}
+
+	log.Info().Int("count", len(rates)).Msg("Successfully saved exchange rates")
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"count":   len(rates),
+		"message": "Exchange rates saved successfully",
+	})
+}
+
+// checkExchangeRates upper-cases the currencies of rates to save, dates the
+// undated ones now and validates them
+func (h *Handler) checkExchangeRates(rates []model.ExchangeRate) validate.Errors {
+	for i := range rates {
+		rates[i].Currency = strings.ToUpper(rates[i].Currency)
+		if rates[i].EffectiveFrom.IsZero() {
+			rates[i].EffectiveFrom = time.Now()
+		}
+	}
+
+	errs := validate.Struct(rates)
+	for i, rate := range rates {
+		if rate.Currency == h.db.BaseCurrency() {
+			errs = append(errs, validate.FieldError{
+				Field:   fmt.Sprintf("[%d].currency", i),
+				Message: "is the base currency, which always has a rate of 1",
+			})
+		}
+	}
+	return errs
+}
+
+// #endregion
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"northwind-api/internal/currency"
+	"northwind-api/internal/model"
+	"northwind-api/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// Number of top products included in a statement unless ?top= says otherwise
+const defaultStatementTopProducts = 5
+
+// #region Customers
+
+// Handler to get a page of a customer's order history
+func (h *Handler) GetCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerId := vars["customerId"]
+
+	log.Info().Str("customer_id", customerId).Msg("GET /api/customers/{ID}/orders - Getting customer orders")
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Make sure the customer exists so an unknown ID is a 404 rather than an empty page
+	if _, err := h.db.GetCustomerById(customerId); err != nil {
+		if err.Error() == "customer not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Customer not found")
+			return
+		}
+		log.Error().Err(err).Str("customer_id", customerId).Msg("Error getting customer")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the customer")
+		return
+	}
+
+	if exportList(w, r, "customer-"+customerId+"-orders", func() (*repository.Cursor[model.Orders], error) {
+		return h.db.StreamOrdersByCustomer(customerId)
+	}) {
+		return
+	}
+
+	projection, err := parseProjection(r, orderEmbeddings)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	orders, total, err := h.db.GetOrdersByCustomer(customerId, pageSize, (page-1)*pageSize, projection.columns()...)
+	if err != nil {
+		log.Error().Err(err).Str("customer_id", customerId).Msg("Error getting customer orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the customer's orders")
+		return
+	}
+
+	data, err := projection.apply(h.db, orders)
+	if err != nil {
+		log.Error().Err(err).Str("customer_id", customerId).Msg("Error getting the relations of customer orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the customer's orders")
+		return
+	}
+
+	log.Info().Str("customer_id", customerId).Int("count", len(orders)).Int("total", total).Msg("Successfully retrieved customer orders")
+	writeJSONResponse(w, http.StatusOK, PagedResponse{
+		Data:     data,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Handler to get a customer's account statement
+func (h *Handler) GetCustomerStatement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerId := vars["customerId"]
+
+	log.Info().Str("customer_id", customerId).Msg("GET /api/customers/{ID}/statement - Getting customer statement")
+
+	top := defaultStatementTopProducts
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxPageSize {
+			writeErrorResponse(w, http.StatusBadRequest, "top must be between 0 and 100")
+			return
+		}
+		top = n
+	}
+
+	code := strings.ToUpper(r.URL.Query().Get("currency"))
+	if code != "" && !currency.ValidCode(code) {
+		writeErrorResponse(w, http.StatusBadRequest, "currency must be a three letter ISO 4217 code")
+		return
+	}
+
+	statement, err := h.db.GetCustomerStatement(customerId, top)
+	if err != nil {
+		if err.Error() == "customer not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Customer not found")
+			return
+		}
+		log.Error().Err(err).Str("customer_id", customerId).Msg("Error getting customer statement")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the customer statement")
+		return
+	}
+
+	// Statements default to the base currency; ?currency= converts at today's rate
+	if code != "" && code != statement.Currency {
+		rate, err := h.db.GetExchangeRate(code, time.Now())
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "no exchange rate") {
+				writeErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			log.Error().Err(err).Str("currency", code).Msg("Error getting exchange rate")
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the exchange rate")
+			return
+		}
+		currency.ConvertStatement(statement, code, rate)
+	}
+
+	log.Info().Str("customer_id", customerId).Int("order_count", statement.OrderCount).Msg("Successfully built customer statement")
+	writeJSONResponse(w, http.StatusOK, statement)
+}
+
+// #endregion
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Decoding
+
+// Decodes a JSON request body into v, writing an error response and returning
+// false if it cannot. The body must be sent as application/json (or a +json
+// type), fit within MAX_BODY_BYTES, hold exactly one JSON value and name only
+// fields v has. Decoding errors report where in the body they occurred.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		writeErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return false
+	}
+
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return false
+	}
+
+	if err := decodeStrict(body, v); err != nil {
+		log.Warn().Err(err).Str("path", r.URL.Path).Msg("Invalid JSON in request body")
+		writeErrorResponse(w, http.StatusBadRequest, describeJSONError(body, err))
+		return false
+	}
+	return true
+}
+
+// Reads the request body up to the configured limit, writing a 413 response
+// and returning false if it is larger
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.config.MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErrorResponse(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit))
+			return nil, false
+		}
+		writeErrorResponse(w, http.StatusBadRequest, "Failed to read the request body")
+		return nil, false
+	}
+	return body, true
+}
+
+// Reports whether a Content-Type header names JSON
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// errTrailingData is returned when a body has more after its JSON value
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// Decodes exactly one JSON value, rejecting unknown fields and trailing data
+func decodeStrict(body []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	end := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		return &trailingDataError{offset: end + int64(len(body[end:])-len(bytes.TrimLeft(body[end:], " \t\r\n")))}
+	}
+	return nil
+}
+
+// trailingDataError records where the data after the JSON value starts
+type trailingDataError struct {
+	offset int64
+}
+
+func (e *trailingDataError) Error() string {
+	return errTrailingData.Error()
+}
+
+func (e *trailingDataError) Unwrap() error {
+	return errTrailingData
+}
+
+// Turns a JSON decoding error into a message for the client, with the line
+// and column of the body it occurred at where that is known
+func describeJSONError(body []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var trailingErr *trailingDataError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return "Request body must not be empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "Invalid JSON at " + position(body, int64(len(body))) + ": unexpected end of the body"
+	case errors.As(err, &syntaxErr):
+		return "Invalid JSON at " + position(body, syntaxErr.Offset-1) + ": " + syntaxErr.Error()
+	case errors.As(err, &typeErr):
+		// Name array elements the way validation errors do: lines[1].quantity
+		field := arrayIndexPattern.ReplaceAllString(typeErr.Field, "[$1]")
+		if field == "" {
+			field = "body"
+		}
+		return fmt.Sprintf("Invalid JSON at %s: %s must be %s, not %s",
+			position(body, typeErr.Offset), field, jsonTypeName(typeErr.Type.String()), typeErr.Value)
+	case errors.As(err, &trailingErr):
+		return "Invalid JSON at " + position(body, trailingErr.offset) + ": " + trailingErr.Error()
+	}
+
+	// encoding/json reports unknown fields without an offset, so find the key
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if offset, found := keyOffset(body, strings.Trim(name, `"`)); found {
+			return "Invalid JSON at " + position(body, offset) + ": unknown field " + name
+		}
+		return "Invalid JSON: unknown field " + name
+	}
+
+	return "Invalid JSON: " + err.Error()
+}
+
+// arrayIndexPattern matches the array indexes in encoding/json field paths
+var arrayIndexPattern = regexp.MustCompile(`\.(\d+)`)
+
+// Describes a Go type in the terms of JSON
+func jsonTypeName(goType string) string {
+	switch {
+	case goType == "string" || goType == "time.Time":
+		return "a string"
+	case goType == "bool":
+		return "a boolean"
+	case strings.HasPrefix(goType, "int") || strings.HasPrefix(goType, "uint") || strings.HasPrefix(goType, "float"):
+		return "a number"
+	case strings.HasPrefix(goType, "[]"):
+		return "an array"
+	}
+	return "an object"
+}
+
+// Returns the offset of the first object key with the given name
+func keyOffset(body []byte, name string) (int64, bool) {
+	// Each open object or array, and for objects whether a key comes next
+	type container struct {
+		object  bool
+		wantKey bool
+	}
+	var stack []container
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		start := dec.InputOffset()
+		token, err := dec.Token()
+		if err != nil {
+			return 0, false
+		}
+
+		delim, isDelim := token.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		var top *container
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+		}
+		if top != nil && top.wantKey {
+			if token == name {
+				// start is where the previous token ended, before any comma or space
+				return start + int64(bytes.IndexByte(body[start:], '"')), true
+			}
+			top.wantKey = false
+			continue
+		}
+
+		// token is a value; in an object the next token is a key again
+		if top != nil && top.object {
+			top.wantKey = true
+		}
+		if isDelim {
+			stack = append(stack, container{object: delim == '{', wantKey: delim == '{'})
+		}
+	}
+}
+
+// Formats the position of the byte at a body offset as a line and column,
+// both counted from one
+func position(body []byte, offset int64) string {
+	offset = min(max(offset, 0), int64(len(body)))
+	before := body[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// #endregion
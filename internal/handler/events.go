@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"northwind-api/internal/events"
+	"northwind-api/internal/stream"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Event stream
+
+// Media type of server-sent events
+const eventStreamType = "text/event-stream"
+
+// How long a write to an event stream may take before the client is given up
+// on. The server's WriteTimeout covers the whole response, so it is lifted for
+// streams and each write is given this deadline instead.
+const streamWriteTimeout = 15 * time.Second
+
+// Handler to stream the domain's change events as server-sent events, such as
+// for a dashboard to update as changes are made. ?resource= and ?id= pick the
+// events of some resources or resource ids. A client reconnecting with the
+// Last-Event-ID header is first sent the events it missed, provided they are
+// still buffered; when they are not it is sent a resync event and should
+// reload what it shows.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := eventFilter(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lastEventId := r.Header.Get("Last-Event-ID")
+
+	log.Info().Strs("resources", filter.Resources).Strs("ids", filter.ResourceIds).Str("last_event_id", lastEventId).
+		Msg("GET /api/events - Streaming events")
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Error().Err(err).Msg("Failed to lift the write deadline of the event stream")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to stream events")
+		return
+	}
+
+	sub, replay, resumed := h.eventHub.Subscribe(filter, lastEventId)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", eventStreamType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep proxies such as nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// send writes to the stream and flushes it, reporting whether the client is still there
+	send := func(format string, args ...any) bool {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendEvent := func(e events.Event) bool {
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Error().Err(err).Str("event_id", e.Id).Msg("Failed to encode event")
+			return true
+		}
+		return send("id: %s\nevent: %s\ndata: %s\n\n", e.Id, e.Type, data)
+	}
+
+	if !resumed {
+		data, _ := json.Marshal(map[string]string{"last_event_id": lastEventId})
+		if !send("event: resync\ndata: %s\n\n", data) {
+			return
+		}
+	}
+	for _, e := range replay {
+		if !sendEvent(e) {
+			return
+		}
+	}
+	// Sent on connecting as well so the response starts straight away
+	if !send(": connected\n\n") {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.config.EventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				log.Warn().Str("remote_addr", r.RemoteAddr).Msg("Event stream fell behind and was closed")
+				return
+			}
+			if !sendEvent(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// Reads the ?resource= and ?id= filters of an event stream, each a comma separated list
+func eventFilter(r *http.Request) (stream.Filter, error) {
+	var filter stream.Filter
+	resources := events.Resources()
+	for _, resource := range splitParam(r.URL.Query().Get("resource")) {
+		if !slices.Contains(resources, resource) {
+			return filter, fmt.Errorf("unknown resource %q, resources are: %s", resource, strings.Join(resources, ", "))
+		}
+		filter.Resources = append(filter.Resources, resource)
+	}
+	filter.ResourceIds = splitParam(r.URL.Query().Get("id"))
+	return filter, nil
+}
+
+// Splits a comma separated query parameter, dropping empty items
+func splitParam(param string) []string {
+	var items []string
+	for _, item := range strings.Split(param, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// #endregion
@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/spreadsheet"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region Export
+
+// Formats a list can be written in
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatXLSX   = "xlsx"
+	formatNDJSON = "ndjson"
+)
+
+// Media types of the list formats, in the order they are preferred
+var formatTypes = []struct{ format, mediaType string }{
+	{formatJSON, "application/json"},
+	{formatCSV, spreadsheet.CSVType},
+	{formatXLSX, spreadsheet.XLSXType},
+	{formatNDJSON, NDJSONType},
+}
+
+const (
+	// Exports are flushed to the client every exportFlushRows rows
+	exportFlushRows = 500
+	// Each flush gives the export this long to write the next rows, as the
+	// server's WriteTimeout would otherwise cut off a long export
+	exportWriteWindow = 15 * time.Second
+)
+
+// exportColumn is a field of a model written as a column of an export
+type exportColumn struct {
+	name  string
+	index []int
+}
+
+// Picks the format of a list response from ?format=, or else from the Accept
+// header, returning the status to respond with if neither can be met
+func exportFormat(r *http.Request) (string, int, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		for _, t := range formatTypes {
+			if t.format == format {
+				return format, 0, nil
+			}
+		}
+		return "", http.StatusBadRequest, fmt.Errorf("format must be json, csv, xlsx or ndjson")
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return formatJSON, 0, nil
+	}
+
+	// The acceptable media types, most preferred first
+	type acceptable struct {
+		mediaType string
+		q         float64
+	}
+	var types []acceptable
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			types = append(types, acceptable{mediaType, q})
+		}
+	}
+	slices.SortStableFunc(types, func(a, b acceptable) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	for _, accepted := range types {
+		for _, t := range formatTypes {
+			prefix, _, _ := strings.Cut(t.mediaType, "/")
+			if accepted.mediaType == t.mediaType || accepted.mediaType == "*/*" || accepted.mediaType == prefix+"/*" {
+				return t.format, 0, nil
+			}
+		}
+	}
+	return "", http.StatusNotAcceptable, fmt.Errorf("Accept must allow application/json, %s, %s or %s",
+		spreadsheet.CSVType, spreadsheet.XLSXType, NDJSONType)
+}
+
+// Returns the columns named by ?columns=, in the order given, or every column
+// of the model when there is no ?columns=
+func exportColumns[T any](param string) ([]exportColumn, error) {
+	return modelColumns[T](param, "column")
+}
+
+// Returns the fields of a model named in a comma separated list, in the order
+// given, or every field when the list is empty. noun names them in errors.
+func modelColumns[T any](param, noun string) ([]exportColumn, error) {
+	var all []exportColumn
+	for _, field := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || field.Anonymous || name == "" || name == "-" {
+			continue
+		}
+		all = append(all, exportColumn{name, field.Index})
+	}
+	if param == "" {
+		return all, nil
+	}
+
+	var columns []exportColumn
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(all, func(c exportColumn) bool { return c.name == name })
+		if i < 0 {
+			names := make([]string, len(all))
+			for j, c := range all {
+				names[j] = c.name
+			}
+			return nil, fmt.Errorf("unknown %s %q, %ss are: %s", noun, name, noun, strings.Join(names, ", "))
+		}
+		if slices.ContainsFunc(columns, func(c exportColumn) bool { return c.name == name }) {
+			return nil, fmt.Errorf("%s %q is selected more than once", noun, name)
+		}
+		columns = append(columns, all[i])
+	}
+	return columns, nil
+}
+
+// exportList writes a list as CSV, XLSX or NDJSON when the request asks for one
+// of them through ?format= or Accept, returning false without writing anything
+// when it asks for JSON. Rows are streamed from the cursor open returns rather
+// than loaded first, and lists that are paged as JSON export every row.
+// ?columns= picks the columns and their order.
+func exportList[T any](w http.ResponseWriter, r *http.Request, name string, open func() (*repository.Cursor[T], error)) bool {
+	format, status, err := exportFormat(r)
+	if err != nil {
+		writeErrorResponse(w, status, err.Error())
+		return true
+	}
+	if format == formatJSON {
+		return false
+	}
+
+	columns, err := exportColumns[T](r.URL.Query().Get("columns"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return true
+	}
+
+	cursor, err := open()
+	if err != nil {
+		if noun, ok := strings.CutSuffix(err.Error(), " not found"); ok {
+			writeErrorResponse(w, http.StatusNotFound, strings.ToUpper(noun[:1])+noun[1:]+" not found")
+			return true
+		}
+		log.Error().Err(err).Str("export", name).Msg("Error opening export")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to export the "+strings.ReplaceAll(name, "-", " "))
+		return true
+	}
+	defer cursor.Close()
+
+	for _, t := range formatTypes {
+		if t.format == format {
+			w.Header().Set("Content-Type", t.mediaType)
+		}
+	}
+	if format != formatNDJSON {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	rows, err := writeExport(w, format, name, columns, cursor)
+	if err != nil {
+		// The status has been sent, so abort the response to show it is incomplete
+		log.Error().Err(err).Str("export", name).Int("rows", rows).Msg("Error writing export")
+		panic(http.ErrAbortHandler)
+	}
+
+	log.Info().Str("export", name).Str("format", format).Int("rows", rows).Msg("Successfully exported list")
+	return true
+}
+
+// Writes the rows of a cursor in a format, returning how many were written
+func writeExport[T any](w http.ResponseWriter, format, name string, columns []exportColumn, cursor *repository.Cursor[T]) (int, error) {
+	rc := http.NewResponseController(w)
+
+	var writeRow func(v reflect.Value) error
+	var flush, finish func() error
+	switch format {
+	case formatNDJSON:
+		writeRow = func(v reflect.Value) error {
+			return writeNDJSONRow(w, v, columns)
+		}
+		flush = func() error { return nil }
+		finish = flush
+
+	default:
+		var sheet spreadsheet.Writer
+		if format == formatCSV {
+			sheet = spreadsheet.NewCSVWriter(w)
+		} else {
+			var err error
+			if sheet, err = spreadsheet.NewXLSXWriter(w, name); err != nil {
+				return 0, err
+			}
+		}
+
+		header := make([]spreadsheet.Cell, len(columns))
+		for i, c := range columns {
+			header[i] = spreadsheet.Cell{Text: c.name}
+		}
+		if err := sheet.WriteRow(header); err != nil {
+			return 0, err
+		}
+
+		cells := make([]spreadsheet.Cell, len(columns))
+		writeRow = func(v reflect.Value) error {
+			for i, c := range columns {
+				cells[i] = exportCell(v.FieldByIndex(c.index))
+			}
+			return sheet.WriteRow(cells)
+		}
+		flush, finish = sheet.Flush, sheet.Close
+	}
+
+	rows := 0
+	for cursor.Next() {
+		if err := writeRow(reflect.ValueOf(cursor.Value()).Elem()); err != nil {
+			return rows, err
+		}
+		rows++
+
+		if rows%exportFlushRows == 0 {
+			if err := flush(); err != nil {
+				return rows, err
+			}
+			if err := rc.Flush(); err != nil {
+				return rows, err
+			}
+			// Not every ResponseWriter supports deadlines; those that do not have none to extend
+			_ = rc.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return rows, err
+	}
+	return rows, finish()
+}
+
+// Writes a row as a JSON object holding the selected columns, in order
+func writeNDJSONRow(w io.Writer, v reflect.Value, columns []exportColumn) error {
+	var b bytes.Buffer
+	if err := appendJSONObject(&b, v, columns, nil); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Appends a JSON object holding the selected columns of a row, in order,
+// followed by the extra members, which may be nil
+func appendJSONObject(b *bytes.Buffer, v reflect.Value, columns []exportColumn, extra []jsonMember) error {
+	members := make([]jsonMember, 0, len(columns)+len(extra))
+	for _, c := range columns {
+		members = append(members, jsonMember{c.name, v.FieldByIndex(c.index).Interface()})
+	}
+	members = append(members, extra...)
+
+	b.WriteByte('{')
+	for i, m := range members {
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Quote(m.name))
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// A named member of a JSON object
+type jsonMember struct {
+	name  string
+	value any
+}
+
+// Formats a field as a spreadsheet cell. Amounts and rates are written as
+// decimals, dates as YYYY-MM-DD, times as RFC 3339 and NULL as an empty cell.
+func exportCell(v reflect.Value) spreadsheet.Cell {
+	// Null[T] holds its value in V
+	if v.Kind() == reflect.Struct {
+		if valid := v.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool {
+			if !valid.Bool() {
+				return spreadsheet.Cell{}
+			}
+			v = v.FieldByName("V")
+		}
+	}
+
+	switch value := v.Interface().(type) {
+	case money.Money:
+		return spreadsheet.Cell{Text: value.Format(), Number: true}
+	case money.Rate:
+		return spreadsheet.Cell{Text: value.String(), Number: true}
+	case time.Time:
+		return spreadsheet.Cell{Text: formatExportTime(value)}
+	case string:
+		return spreadsheet.Cell{Text: value}
+	case bool:
+		return spreadsheet.Cell{Text: strconv.FormatBool(value)}
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return spreadsheet.Cell{Text: strconv.FormatInt(v.Int(), 10), Number: true}
+	case reflect.Float32, reflect.Float64:
+		return spreadsheet.Cell{Text: strconv.FormatFloat(v.Float(), 'f', -1, 64), Number: true}
+	}
+
+	// Anything else, such as a nested list, is written as its JSON
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return spreadsheet.Cell{}
+	}
+	return spreadsheet.Cell{Text: string(b)}
+}
+
+// Formats a time as a date when it falls on midnight UTC, as date columns do,
+// and as an RFC 3339 timestamp otherwise
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.UTC().Equal(t.UTC().Truncate(24 * time.Hour)) {
+		return t.UTC().Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// #endregion
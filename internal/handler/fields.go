@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/repository"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// #region Sparse fieldsets
+
+// embedding is a resource that ?include= embeds in the response of another
+type embedding[T any] struct {
+	name string
+	// The column of T the relation is found from, which is selected even when
+	// ?fields= leaves it out
+	column string
+	// Fetches the relation of every row with one batched query, returning the
+	// value embedded in each row
+	load func(db *repository.DB, rows []T) ([]any, error)
+}
+
+// belongsTo is the row a nullable foreign key of T refers to, embedded as
+// null when the key is NULL or refers to nothing
+func belongsTo[T, R any, K comparable](name, column string, keyOf func(*T) model.Null[K],
+	lookup func(*repository.DB, []K) ([]R, error), idOf func(*R) K) embedding[T] {
+
+	return embedding[T]{name: name, column: column, load: func(db *repository.DB, rows []T) ([]any, error) {
+		var keys []K
+		for i := range rows {
+			if key := keyOf(&rows[i]); key.Valid && !slices.Contains(keys, key.V) {
+				keys = append(keys, key.V)
+			}
+		}
+		related, err := lookup(db, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		byId := make(map[K]*R, len(related))
+		for i := range related {
+			byId[idOf(&related[i])] = &related[i]
+		}
+		values := make([]any, len(rows))
+		for i := range rows {
+			values[i] = (*R)(nil)
+			if key := keyOf(&rows[i]); key.Valid && byId[key.V] != nil {
+				values[i] = byId[key.V]
+			}
+		}
+		return values, nil
+	}}
+}
+
+// hasMany is the rows referring to each row of T, embedded as a list
+func hasMany[T, R any, K comparable](name, column string, keyOf func(*T) K,
+	lookup func(*repository.DB, []K) ([]R, error), parentOf func(*R) K) embedding[T] {
+
+	return embedding[T]{name: name, column: column, load: func(db *repository.DB, rows []T) ([]any, error) {
+		keys := make([]K, 0, len(rows))
+		for i := range rows {
+			if key := keyOf(&rows[i]); !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		related, err := lookup(db, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		children := make(map[K][]R, len(keys))
+		for _, r := range related {
+			children[parentOf(&r)] = append(children[parentOf(&r)], r)
+		}
+		values := make([]any, len(rows))
+		for i := range rows {
+			list := children[keyOf(&rows[i])]
+			if list == nil {
+				list = []R{}
+			}
+			values[i] = list
+		}
+		return values, nil
+	}}
+}
+
+// The relations ?include= can embed in product and order responses
+var (
+	productEmbeddings = []embedding[model.Products]{
+		belongsTo("category", "category_id", func(p *model.Products) model.NullInt { return p.CategoryId },
+			(*repository.DB).CategoriesByIds, func(c *model.Category) int { return c.CategoryId }),
+		belongsTo("supplier", "supplier_id", func(p *model.Products) model.NullInt { return p.SupplierId },
+			(*repository.DB).SuppliersByIds, func(s *model.Suppliers) int { return s.SupplierId }),
+	}
+	orderEmbeddings = []embedding[model.Orders]{
+		belongsTo("customer", "customer_id", func(o *model.Orders) model.NullString { return o.CustomerId },
+			(*repository.DB).CustomersByIds, func(c *model.Customer) string { return c.CustomerId }),
+		belongsTo("employee", "employee_id", func(o *model.Orders) model.NullInt { return o.EmployeeId },
+			(*repository.DB).EmployeesByIds, func(e *model.Employees) int { return e.EmployeeId }),
+		belongsTo("shipper", "ship_via", func(o *model.Orders) model.NullInt { return o.ShipVia },
+			(*repository.DB).ShippersByIds, func(s *model.Shippers) int { return s.ShipperId }),
+		hasMany("lines", "order_id", func(o *model.Orders) int { return o.OrderId },
+			(*repository.DB).OrderLinesByOrders, func(l *model.OrderLine) int { return l.OrderId }),
+	}
+)
+
+// projection is the shape of a response asked for with ?fields= and ?include=
+type projection[T any] struct {
+	// The fields to write, every field of T when ?fields= is not given
+	fields []exportColumn
+	sparse bool
+	// The relations to embed after the fields
+	include []embedding[T]
+}
+
+// Reads ?fields=, the comma separated fields of T to write, and ?include=, the
+// comma separated relations to embed
+func parseProjection[T any](r *http.Request, relations []embedding[T]) (*projection[T], error) {
+	param := r.URL.Query().Get("fields")
+	fields, err := modelColumns[T](param, "field")
+	if err != nil {
+		return nil, err
+	}
+	p := &projection[T]{fields: fields, sparse: param != ""}
+
+	param = r.URL.Query().Get("include")
+	if param == "" {
+		return p, nil
+	}
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(relations, func(rel embedding[T]) bool { return rel.name == name })
+		if i < 0 {
+			names := make([]string, len(relations))
+			for j, rel := range relations {
+				names[j] = rel.name
+			}
+			return nil, fmt.Errorf("unknown relation %q, relations are: %s", name, strings.Join(names, ", "))
+		}
+		if slices.ContainsFunc(p.include, func(rel embedding[T]) bool { return rel.name == name }) {
+			return nil, fmt.Errorf("relation %q is included more than once", name)
+		}
+		p.include = append(p.include, relations[i])
+	}
+	return p, nil
+}
+
+// Returns the columns to select: those of the fields asked for and those
+// the included relations are found from, or nil for every column
+func (p *projection[T]) columns() []string {
+	if !p.sparse {
+		return nil
+	}
+
+	t := reflect.TypeFor[T]()
+	var columns []string
+	for _, f := range p.fields {
+		column, _, _ := strings.Cut(t.FieldByIndex(f.index).Tag.Get("db"), ",")
+		if column != "" && column != "-" {
+			columns = append(columns, column)
+		}
+	}
+	for _, rel := range p.include {
+		if !slices.Contains(columns, rel.column) {
+			columns = append(columns, rel.column)
+		}
+	}
+	return columns
+}
+
+// Returns the rows as they are written in the response, loading the included
+// relations of every row with one query each. Rows are returned as they are
+// when neither ?fields= nor ?include= was given.
+func (p *projection[T]) apply(db *repository.DB, rows []T) (any, error) {
+	if !p.sparse && p.include == nil {
+		return rows, nil
+	}
+
+	embedded := make([][]any, len(p.include))
+	for i, rel := range p.include {
+		values, err := rel.load(db, rows)
+		if err != nil {
+			return nil, err
+		}
+		embedded[i] = values
+	}
+
+	projected := make([]projectedRow, len(rows))
+	for i := range rows {
+		projected[i] = projectedRow{value: reflect.ValueOf(&rows[i]).Elem(), fields: p.fields}
+		for j, rel := range p.include {
+			projected[i].include = append(projected[i].include, jsonMember{rel.name, embedded[j][i]})
+		}
+	}
+	return projected, nil
+}
+
+// Returns a single row as it is written in the response
+func (p *projection[T]) applyOne(db *repository.DB, row *T) (any, error) {
+	rows, err := p.apply(db, []T{*row})
+	if err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(rows).Index(0).Interface(), nil
+}
+
+// projectedRow is a row written as the fields of a projection followed by its
+// embedded relations
+type projectedRow struct {
+	value   reflect.Value
+	fields  []exportColumn
+	include []jsonMember
+}
+
+func (r projectedRow) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := appendJSONObject(&b, r.value, r.fields, r.include); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// #endregion
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Freight
+
+// Handler to get a shipper's freight rate table
+func (h *Handler) GetFreightRates(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["shipperId"]
+
+	log.Info().Str("shipper_id", idStr).Msg("GET /api/shippers/{ID}/rates - Getting freight rates")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid shipper ID")
+		return
+	}
+
+	if exportList(w, r, fmt.Sprintf("shipper-%d-rates", id), func() (*repository.Cursor[model.FreightRate], error) {
+		return h.db.StreamFreightRates(id)
+	}) {
+		return
+	}
+
+	rates, err := h.db.GetFreightRates(id)
+	if err != nil {
+		if err.Error() == "shipper not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Shipper not found")
+			return
+		}
+		log.Error().Err(err).Int("shipper_id", id).Msg("Error getting freight rates")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the freight rates")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rates)
+}
+
+// Request body for a band of a shipper's freight rate table
+type freightRateRequest struct {
+	ShipCountry   string      `json:"ship_country" validate:"max=15,country"`
+	ShipRegion    string      `json:"ship_region" validate:"max=60"`
+	MinQuantity   int         `json:"min_quantity" validate:"min=0"`
+	MaxQuantity   *int        `json:"max_quantity" validate:"gtefield=min_quantity"`
+	BaseCharge    money.Money `json:"base_charge" validate:"min=0"`
+	PerUnitCharge money.Money `json:"per_unit_charge" validate:"min=0"`
+}
+
+// Converts the request into a band of the shipper's rate table
+func (req *freightRateRequest) toModel(shipperId int) model.FreightRate {
+	return model.FreightRate{
+		ShipperId:     shipperId,
+		ShipCountry:   model.NullIfZero(req.ShipCountry),
+		ShipRegion:    model.NullIfZero(req.ShipRegion),
+		MinQuantity:   req.MinQuantity,
+		MaxQuantity:   model.NullFromPtr(req.MaxQuantity),
+		BaseCharge:    req.BaseCharge,
+		PerUnitCharge: req.PerUnitCharge,
+	}
+}
+
+// Handler to add a band to a shipper's freight rate table
+func (h *Handler) CreateFreightRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["shipperId"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid shipper ID")
+		return
+	}
+
+	var req freightRateRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, validate.Struct(&req)) {
+		return
+	}
+
+	rateId, err := h.db.CreateFreightRate(req.toModel(id))
+	if err != nil {
+		if err.Error() == "shipper not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Shipper not found")
+			return
+		}
+		log.Error().Err(err).Int("shipper_id", id).Msg("Error creating freight rate")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create the freight rate")
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":      rateId,
+		"message": "Freight rate created successfully",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// Handler to remove a band from a shipper's freight rate table
+func (h *Handler) DeleteFreightRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	shipperId, err := strconv.Atoi(vars["shipperId"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid shipper ID")
+		return
+	}
+	rateId, err := strconv.Atoi(vars["rateId"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid rate ID")
+		return
+	}
+
+	if err := h.db.DeleteFreightRate(shipperId, rateId); err != nil {
+		if err.Error() == "freight rate not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Freight rate not found")
+			return
+		}
+		log.Error().Err(err).Int("rate_id", rateId).Msg("Error deleting freight rate")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete the freight rate")
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Freight rate was successfully deleted",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// Request body for a freight quote. A ship_via of zero quotes every shipper.
+type freightQuoteRequest struct {
+	ShipVia     int         `json:"ship_via" validate:"min=0"`
+	ShipCountry string      `json:"ship_country" validate:"required,max=15,country"`
+	Region      string      `json:"region" validate:"max=60"`
+	Lines       []orderLine `json:"lines" validate:"required"`
+}
+
+// Handler to quote freight for a basket with one or all shippers
+func (h *Handler) QuoteFreight(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("POST /api/freight/quote - Quoting freight")
+
+	var req freightQuoteRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, validate.Struct(&req)) {
+		return
+	}
+	quantity := 0
+	for _, line := range req.Lines {
+		quantity += line.Quantity
+	}
+
+	quotes, err := h.db.QuoteFreight(req.ShipVia, req.ShipCountry, req.Region, quantity)
+	if err != nil {
+		if err.Error() == "shipper not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Shipper not found")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "no freight rate") {
+			writeErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		log.Error().Err(err).Msg("Error quoting freight")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to quote freight")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, quotes)
+}
+
+// #endregion
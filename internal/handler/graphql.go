@@ -0,0 +1,901 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"northwind-api/internal/graphql"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/patch"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region GraphQL
+
+// Request body of a GraphQL request sent with POST
+type graphqlRequest struct {
+	Query         string          `json:"query" validate:"required"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+	Extensions    json.RawMessage `json:"extensions"`
+}
+
+// Handler to run a GraphQL request. Queries may also be sent with GET, with
+// query, operationName and variables in the query string; mutations may not.
+func (h *Handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			req.Variables = json.RawMessage(v)
+		}
+	} else if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	log.Info().Str("operation", req.OperationName).Msg(r.Method + " /graphql - Running GraphQL request")
+
+	variables, err := decodeVariables(req.Variables)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "variables must be a JSON object")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlLoadersKey{}, h.newGraphQLLoaders())
+	resp := graphql.Execute(ctx, h.graphqlSchema, graphql.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     variables,
+		ReadOnly:      r.Method == http.MethodGet,
+		Limits: graphql.Limits{
+			MaxDepth:      h.config.GraphQLMaxDepth,
+			MaxComplexity: h.config.GraphQLMaxComplexity,
+			ListSize:      defaultPageSize,
+		},
+	})
+
+	// The response is application/json, so requests that fail validation
+	// are answered with 200 too, except for a mutation sent with GET
+	status := http.StatusOK
+	if resp.Code() == graphql.CodeMutationNotAllowed {
+		w.Header().Set("Allow", http.MethodPost)
+		status = http.StatusMethodNotAllowed
+	}
+	if !resp.Executed() {
+		log.Warn().Str("code", resp.Code()).Msg("GraphQL request was rejected")
+	} else {
+		log.Info().Int("errors", len(resp.Errors)).Msg("Successfully ran GraphQL request")
+	}
+	writeJSONResponse(w, status, resp)
+}
+
+// decodeVariables decodes the variables of a request, keeping numbers as
+// json.Number so decimals are not rounded
+func decodeVariables(raw json.RawMessage) (map[string]any, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var variables map[string]any
+	if err := dec.Decode(&variables); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errTrailingData
+	}
+	return variables, nil
+}
+
+// #endregion
+
+// #region GraphQL loaders
+
+type graphqlLoadersKey struct{}
+
+// graphqlLoaders batch the lookups of one GraphQL request, so a relation
+// selected on every row of a list is fetched in one query
+type graphqlLoaders struct {
+	categories *graphql.Loader[int, model.Category]
+	suppliers  *graphql.Loader[int, model.Suppliers]
+	products   *graphql.Loader[int, model.Products]
+	customers  *graphql.Loader[string, model.Customer]
+	employees  *graphql.Loader[int, model.Employees]
+	shippers   *graphql.Loader[int, model.Shippers]
+	orders     *graphql.Loader[int, model.Orders]
+
+	categoryProducts *graphql.Loader[int, []model.Products]
+	supplierProducts *graphql.Loader[int, []model.Products]
+	productPrices    *graphql.Loader[int, []model.ProductPrice]
+	shipperRates     *graphql.Loader[int, []model.FreightRate]
+	customerOrders   *graphql.Loader[string, []model.Orders]
+	orderLines       *graphql.Loader[int, []model.OrderLine]
+}
+
+func (h *Handler) newGraphQLLoaders() *graphqlLoaders {
+	return &graphqlLoaders{
+		categories: graphql.NewLoader(byKey(h.db.CategoriesByIds, func(c model.Category) int { return c.CategoryId })),
+		suppliers:  graphql.NewLoader(byKey(h.db.SuppliersByIds, func(s model.Suppliers) int { return s.SupplierId })),
+		products:   graphql.NewLoader(byKey(h.db.ProductsByIds, func(p model.Products) int { return p.ProductId })),
+		customers:  graphql.NewLoader(byKey(h.db.CustomersByIds, func(c model.Customer) string { return c.CustomerId })),
+		employees:  graphql.NewLoader(byKey(h.db.EmployeesByIds, func(e model.Employees) int { return e.EmployeeId })),
+		shippers:   graphql.NewLoader(byKey(h.db.ShippersByIds, func(s model.Shippers) int { return s.ShipperId })),
+		orders:     graphql.NewLoader(byKey(h.db.OrdersByIds, func(o model.Orders) int { return o.OrderId })),
+
+		categoryProducts: graphql.NewLoader(groupBy(h.db.ProductsByCategories, func(p model.Products) int { return p.CategoryId.V })),
+		supplierProducts: graphql.NewLoader(groupBy(h.db.ProductsBySuppliers, func(p model.Products) int { return p.SupplierId.V })),
+		productPrices:    graphql.NewLoader(groupBy(h.db.PricesByProducts, func(p model.ProductPrice) int { return p.ProductId })),
+		shipperRates:     graphql.NewLoader(groupBy(h.db.FreightRatesByShippers, func(r model.FreightRate) int { return r.ShipperId })),
+		customerOrders:   graphql.NewLoader(groupBy(h.db.OrdersByCustomers, func(o model.Orders) string { return o.CustomerId.V })),
+		orderLines:       graphql.NewLoader(groupBy(h.db.OrderLinesByOrders, func(l model.OrderLine) int { return l.OrderId })),
+	}
+}
+
+// clear forgets everything loaded, so the fields of a mutation see what it wrote
+func (l *graphqlLoaders) clear() {
+	l.categories.Clear()
+	l.suppliers.Clear()
+	l.products.Clear()
+	l.customers.Clear()
+	l.employees.Clear()
+	l.shippers.Clear()
+	l.orders.Clear()
+	l.categoryProducts.Clear()
+	l.supplierProducts.Clear()
+	l.productPrices.Clear()
+	l.shipperRates.Clear()
+	l.customerOrders.Clear()
+	l.orderLines.Clear()
+}
+
+func loadersOf(ctx context.Context) *graphqlLoaders {
+	return ctx.Value(graphqlLoadersKey{}).(*graphqlLoaders)
+}
+
+// byKey adapts a batch lookup to a loader, finding each row by its key
+func byKey[K comparable, V any](fetch func([]K) ([]V, error), key func(V) K) func([]K) (map[K]V, error) {
+	return func(keys []K) (map[K]V, error) {
+		rows, err := fetch(keys)
+		if err != nil {
+			return nil, loaderError(err)
+		}
+		found := make(map[K]V, len(rows))
+		for _, row := range rows {
+			found[key(row)] = row
+		}
+		return found, nil
+	}
+}
+
+// groupBy adapts a batch lookup of related rows to a loader, grouping the
+// rows by key in the order they were fetched. Keys without rows get none.
+func groupBy[K comparable, V any](fetch func([]K) ([]V, error), key func(V) K) func([]K) (map[K][]V, error) {
+	return func(keys []K) (map[K][]V, error) {
+		rows, err := fetch(keys)
+		if err != nil {
+			return nil, loaderError(err)
+		}
+		groups := make(map[K][]V, len(keys))
+		for _, k := range keys {
+			groups[k] = []V{}
+		}
+		for _, row := range rows {
+			groups[key(row)] = append(groups[key(row)], row)
+		}
+		return groups, nil
+	}
+}
+
+func loaderError(err error) error {
+	log.Error().Err(err).Msg("Error loading GraphQL data")
+	return graphqlError("INTERNAL_SERVER_ERROR", "Failed to load data")
+}
+
+// loadRef loads the row a nullable reference points to, or returns nil when it is NULL
+func loadRef[K comparable, V any](l *graphql.Loader[K, V], ref model.Null[K]) graphql.Thunk {
+	if !ref.Valid {
+		return nil
+	}
+	return l.Load(ref.V)
+}
+
+// #endregion
+
+// #region GraphQL schema
+
+// sourceOf returns the model a field is resolved on
+func sourceOf[T any](p graphql.ResolveParams) T {
+	switch v := p.Source.(type) {
+	case T:
+		return v
+	case *T:
+		return *v
+	}
+	var zero T
+	return zero
+}
+
+// listOf is a non-null list of non-null items of a type
+func listOf(t graphql.Type) graphql.Type {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// relation is a field of a T resolved through a loader. load returns nil for
+// a NULL reference.
+func relation[T any](name, description string, typ graphql.Type, load func(l *graphqlLoaders, v T) graphql.Thunk) *graphql.Field {
+	return &graphql.Field{Name: name, Description: description, Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		if thunk := load(loadersOf(p.Context), sourceOf[T](p)); thunk != nil {
+			return thunk, nil
+		}
+		return nil, nil
+	}}
+}
+
+// lookup is a root field finding one row by an integer ID, null when there is none
+func lookup(name, description string, typ *graphql.Object, load func(l *graphqlLoaders, id int) graphql.Thunk) *graphql.Field {
+	return &graphql.Field{
+		Name:        name,
+		Description: description,
+		Type:        typ,
+		Args:        []*graphql.Argument{idArgument("id")},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return load(loadersOf(p.Context), p.Args["id"].(int)), nil
+		},
+	}
+}
+
+func idArgument(name string) *graphql.Argument {
+	return &graphql.Argument{Name: name, Type: graphql.NewNonNull(graphql.Int)}
+}
+
+var pageArguments = []*graphql.Argument{
+	{Name: "limit", Description: fmt.Sprintf("Rows to return, at most %d", maxPageSize), Type: graphql.NewNonNull(graphql.Int), Default: defaultPageSize},
+	{Name: "offset", Description: "Rows to skip", Type: graphql.NewNonNull(graphql.Int), Default: 0},
+}
+
+// paged is a root field listing a page of rows in key order
+func paged[T any](name, description string, typ *graphql.Object, list func(limit, offset int) ([]T, error)) *graphql.Field {
+	return &graphql.Field{
+		Name:        name,
+		Description: description,
+		Type:        listOf(typ),
+		Args:        pageArguments,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			limit, offset := p.Args["limit"].(int), p.Args["offset"].(int)
+			if limit < 1 || limit > maxPageSize {
+				return nil, graphqlError(graphql.CodeBadUserInput, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+			}
+			if offset < 0 {
+				return nil, graphqlError(graphql.CodeBadUserInput, "offset must not be negative")
+			}
+			rows, err := list(limit, offset)
+			if err != nil {
+				return nil, resolverError(err, "get "+name)
+			}
+			return rows, nil
+		},
+	}
+}
+
+// mutation is a root field of the mutation type. Mutations run one at a
+// time, and each starts with empty loaders so it reads what earlier ones wrote.
+func mutation(name, description string, typ graphql.Type, args []*graphql.Argument, resolve graphql.ResolveFunc) *graphql.Field {
+	return &graphql.Field{Name: name, Description: description, Type: typ, Args: args, Resolve: func(p graphql.ResolveParams) (any, error) {
+		loadersOf(p.Context).clear()
+		return resolve(p)
+	}}
+}
+
+// patchMutation patches a row with a merge patch given as an input object.
+// Patches are validated and applied as PATCH requests are.
+func patchMutation[T any](name, noun string, typ *graphql.Object, patchType *graphql.InputObject, ids []*graphql.Argument,
+	check func(v *T, fields map[string]bool) validate.Errors,
+	update func(args map[string]any, apply func(*T) (*T, error)) (*T, error)) *graphql.Field {
+
+	args := append(append([]*graphql.Argument{}, ids...), &graphql.Argument{Name: "patch", Type: graphql.NewNonNull(patchType)})
+	return mutation(name, "Patch a "+noun, graphql.NewNonNull(typ), args, func(p graphql.ResolveParams) (any, error) {
+		body, err := json.Marshal(p.Args["patch"])
+		if err != nil {
+			return nil, resolverError(err, "update the "+noun)
+		}
+		doc, err := patch.Parse(patch.MergePatchType, body)
+		if err != nil {
+			return nil, graphqlError(graphql.CodeBadUserInput, err.Error())
+		}
+		updated, err := update(p.Args, applyPatch(doc, check))
+		if err != nil {
+			return nil, resolverError(err, "update the "+noun)
+		}
+		return updated, nil
+	})
+}
+
+// decodeArgument decodes an input argument into the type the matching REST
+// route decodes its body into
+func decodeArgument(arg any, v any) error {
+	body, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	if err := decodeStrict(body, v); err != nil {
+		return graphqlError(graphql.CodeBadUserInput, describeJSONError(body, err))
+	}
+	return nil
+}
+
+// decodeInput decodes an input object argument into the request struct of
+// the matching REST route and validates it as that route does
+func decodeInput(arg any, v any) error {
+	if err := decodeArgument(arg, v); err != nil {
+		return err
+	}
+
+	var errs validate.Errors
+	if c, ok := v.(interface{ check() validate.Errors }); ok {
+		errs = c.check()
+	} else {
+		errs = validate.Struct(v)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// graphqlError is an error with a code extension
+func graphqlError(code, message string) *graphql.Error {
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": code}}
+}
+
+// resolverError maps an error from validation or the repository to a
+// GraphQL error, as the REST handlers map them to status codes. Unexpected
+// errors are logged and reported as failing to do action.
+func resolverError(err error, action string) error {
+	var gqlErr *graphql.Error
+	var errs validate.Errors
+	msg := err.Error()
+	switch {
+	case errors.As(err, &gqlErr):
+		return gqlErr
+	case errors.As(err, &errs):
+		e := graphqlError(graphql.CodeBadUserInput, "Validation failed")
+		e.Extensions["fields"] = errs
+		return e
+	case strings.HasSuffix(msg, " not found") && !strings.Contains(msg, ":"):
+		return graphqlError("NOT_FOUND", strings.ToUpper(msg[:1])+msg[1:])
+	case strings.HasPrefix(msg, "invalid patch: "):
+		return graphqlError(graphql.CodeBadUserInput, strings.TrimPrefix(msg, "invalid patch: "))
+	case strings.HasPrefix(msg, "invalid order"), strings.HasPrefix(msg, "invalid price change"), strings.HasPrefix(msg, "no freight rate"):
+		return graphqlError(graphql.CodeBadUserInput, msg)
+	case strings.Contains(msg, "already exists"):
+		return graphqlError("CONFLICT", msg)
+	}
+	log.Error().Err(err).Msg("Error resolving GraphQL field, failed to " + action)
+	return graphqlError("INTERNAL_SERVER_ERROR", "Failed to "+action)
+}
+
+// newGraphQLSchema builds the schema served at /graphql. Object types are
+// built from the models, so their fields have the models' JSON names, with
+// relations added as fields resolved through the request's loaders. Mutations
+// take the request bodies of the matching REST routes as input objects.
+func (h *Handler) newGraphQLSchema() (*graphql.Schema, error) {
+	b := graphql.NewBuilder()
+
+	var (
+		category     = b.Object(reflect.TypeFor[model.Category](), "Category")
+		customer     = b.Object(reflect.TypeFor[model.Customer](), "Customer")
+		employee     = b.Object(reflect.TypeFor[model.Employees](), "Employee")
+		order        = b.Object(reflect.TypeFor[model.Orders](), "Order")
+		orderLine    = b.Object(reflect.TypeFor[model.OrderLine](), "OrderLine")
+		product      = b.Object(reflect.TypeFor[model.Products](), "Product")
+		price        = b.Object(reflect.TypeFor[model.ProductPrice](), "ProductPrice")
+		priceChange  = b.Object(reflect.TypeFor[model.PriceChange](), "PriceChange")
+		shipper      = b.Object(reflect.TypeFor[model.Shippers](), "Shipper")
+		freightRate  = b.Object(reflect.TypeFor[model.FreightRate](), "FreightRate")
+		freightQuote = b.Object(reflect.TypeFor[model.FreightQuote](), "FreightQuote")
+		supplier     = b.Object(reflect.TypeFor[model.Suppliers](), "Supplier")
+		promotion    = b.Object(reflect.TypeFor[model.Promotion](), "Promotion")
+		exchangeRate = b.Object(reflect.TypeFor[model.ExchangeRate](), "ExchangeRate")
+		quote        = b.Object(reflect.TypeFor[model.Quote](), "Quote")
+		search       = b.Object(reflect.TypeFor[model.SearchResults](), "SearchResults")
+	)
+	for o, description := range map[*graphql.Object]string{
+		category:     "A category of products",
+		customer:     "A customer. A customer without a currency is billed in the base currency.",
+		employee:     "An employee",
+		order:        "An order placed by a customer",
+		orderLine:    "A line of an order",
+		product:      "A product in the catalogue",
+		price:        "A period of a product's price history; an open-ended period has no effective_to",
+		priceChange:  "A scheduled change to a product's price",
+		shipper:      "A shipper that delivers orders",
+		freightRate:  "A band of a shipper's freight rate table",
+		freightQuote: "The freight a shipper charges for a basket",
+		supplier:     "A supplier of products",
+		promotion:    "A discount rule applied to order lines when orders are priced",
+		exchangeRate: "How much of a currency one unit of the base currency buys from a point in time",
+		quote:        "A priced basket",
+		search:       "A page of catalogue search matches with facets over all matches",
+	} {
+		o.Description = description
+	}
+
+	category.AddFields(
+		relation("products", "The products in the category", listOf(product), func(l *graphqlLoaders, c model.Category) graphql.Thunk {
+			return l.categoryProducts.Load(c.CategoryId)
+		}),
+	)
+	customer.AddFields(
+		relation("orders", "The customer's orders, newest first", listOf(order), func(l *graphqlLoaders, c model.Customer) graphql.Thunk {
+			return l.customerOrders.Load(c.CustomerId)
+		}),
+	)
+	employee.AddFields(
+		relation("manager", "The employee the employee reports to", employee, func(l *graphqlLoaders, e model.Employees) graphql.Thunk {
+			return loadRef(l.employees, e.ReportsTo)
+		}),
+	)
+	order.AddFields(
+		relation("customer", "The customer who placed the order", customer, func(l *graphqlLoaders, o model.Orders) graphql.Thunk {
+			return loadRef(l.customers, o.CustomerId)
+		}),
+		relation("employee", "The employee who took the order", employee, func(l *graphqlLoaders, o model.Orders) graphql.Thunk {
+			return loadRef(l.employees, o.EmployeeId)
+		}),
+		relation("shipper", "The shipper the order ships with", shipper, func(l *graphqlLoaders, o model.Orders) graphql.Thunk {
+			return loadRef(l.shippers, o.ShipVia)
+		}),
+		relation("lines", "The lines of the order", listOf(orderLine), func(l *graphqlLoaders, o model.Orders) graphql.Thunk {
+			return l.orderLines.Load(o.OrderId)
+		}),
+	)
+	orderLine.AddFields(
+		relation("product", "The product ordered", product, func(l *graphqlLoaders, line model.OrderLine) graphql.Thunk {
+			return l.products.Load(line.ProductId)
+		}),
+	)
+	product.AddFields(
+		relation("category", "The category of the product", category, func(l *graphqlLoaders, p model.Products) graphql.Thunk {
+			return loadRef(l.categories, p.CategoryId)
+		}),
+		relation("supplier", "The supplier of the product", supplier, func(l *graphqlLoaders, p model.Products) graphql.Thunk {
+			return loadRef(l.suppliers, p.SupplierId)
+		}),
+		relation("prices", "The price history of the product, oldest first", listOf(price), func(l *graphqlLoaders, p model.Products) graphql.Thunk {
+			return l.productPrices.Load(p.ProductId)
+		}),
+	)
+	shipper.AddFields(
+		relation("rates", "The shipper's freight rate table", listOf(freightRate), func(l *graphqlLoaders, s model.Shippers) graphql.Thunk {
+			return l.shipperRates.Load(s.ShipperId)
+		}),
+	)
+	supplier.AddFields(
+		relation("products", "The products the supplier supplies", listOf(product), func(l *graphqlLoaders, s model.Suppliers) graphql.Thunk {
+			return l.supplierProducts.Load(s.SupplierId)
+		}),
+	)
+	promotion.AddFields(
+		relation("product", "The product the promotion is limited to", product, func(l *graphqlLoaders, p model.Promotion) graphql.Thunk {
+			return loadRef(l.products, p.ProductId)
+		}),
+		relation("category", "The category the promotion is limited to", category, func(l *graphqlLoaders, p model.Promotion) graphql.Thunk {
+			return loadRef(l.categories, p.CategoryId)
+		}),
+		relation("customer", "The customer the promotion is limited to", customer, func(l *graphqlLoaders, p model.Promotion) graphql.Thunk {
+			return loadRef(l.customers, p.CustomerId)
+		}),
+	)
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		lookup("category", "A category by ID", category, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.categories.Load(id)
+		}),
+		{Name: "categories", Description: "Every category", Type: listOf(category), Resolve: func(p graphql.ResolveParams) (any, error) {
+			categories, err := h.db.GetAllCategories()
+			if err != nil {
+				return nil, resolverError(err, "get categories")
+			}
+			return categories, nil
+		}},
+		{
+			Name:        "customer",
+			Description: "A customer by ID",
+			Type:        customer,
+			Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return loadersOf(p.Context).customers.Load(p.Args["id"].(string)), nil
+			},
+		},
+		paged("customers", "A page of customers", customer, h.db.GetCustomers),
+		lookup("employee", "An employee by ID", employee, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.employees.Load(id)
+		}),
+		paged("employees", "A page of employees", employee, h.db.GetEmployees),
+		lookup("order", "An order by ID", order, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.orders.Load(id)
+		}),
+		paged("orders", "A page of orders", order, func(limit, offset int) ([]model.Orders, error) {
+			orders, _, err := h.db.GetOrders(limit, offset)
+			return orders, err
+		}),
+		lookup("product", "A product by ID", product, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.products.Load(id)
+		}),
+		paged("products", "A page of products", product, h.db.GetProducts),
+		lookup("shipper", "A shipper by ID", shipper, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.shippers.Load(id)
+		}),
+		{Name: "shippers", Description: "Every shipper", Type: listOf(shipper), Resolve: func(p graphql.ResolveParams) (any, error) {
+			shippers, err := h.db.GetShippers()
+			if err != nil {
+				return nil, resolverError(err, "get shippers")
+			}
+			return shippers, nil
+		}},
+		lookup("supplier", "A supplier by ID", supplier, func(l *graphqlLoaders, id int) graphql.Thunk {
+			return l.suppliers.Load(id)
+		}),
+		paged("suppliers", "A page of suppliers", supplier, h.db.GetSuppliers),
+		{
+			Name:        "promotion",
+			Description: "A promotion by ID",
+			Type:        promotion,
+			Args:        []*graphql.Argument{idArgument("id")},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				promotion, err := h.db.GetPromotionById(p.Args["id"].(int))
+				if err != nil {
+					if err.Error() == "promotion not found" {
+						return nil, nil
+					}
+					return nil, resolverError(err, "get the promotion")
+				}
+				return promotion, nil
+			},
+		},
+		{Name: "promotions", Description: "Every promotion", Type: listOf(promotion), Resolve: func(p graphql.ResolveParams) (any, error) {
+			promotions, err := h.db.GetAllPromotions()
+			if err != nil {
+				return nil, resolverError(err, "get promotions")
+			}
+			return promotions, nil
+		}},
+		{
+			Name:        "exchange_rates",
+			Description: "The exchange rate of every currency at a time, by default now",
+			Type:        listOf(exchangeRate),
+			Args:        []*graphql.Argument{{Name: "at", Type: graphql.DateTime}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				at, ok := p.Args["at"].(time.Time)
+				if !ok {
+					at = time.Now()
+				}
+				rates, err := h.db.GetExchangeRates(at)
+				if err != nil {
+					return nil, resolverError(err, "get exchange rates")
+				}
+				return rates, nil
+			},
+		},
+		{
+			Name:        "search",
+			Description: "Search the catalogue by product, category and supplier names",
+			Type:        graphql.NewNonNull(search),
+			Args: append([]*graphql.Argument{
+				{Name: "q", Type: graphql.NewNonNull(graphql.String)},
+				{Name: "category_id", Type: graphql.Int},
+				{Name: "supplier_id", Type: graphql.Int},
+				{Name: "min_price", Type: graphql.Decimal},
+				{Name: "max_price", Type: graphql.Decimal},
+			}, pageArguments...),
+			Resolve: h.resolveSearch,
+		},
+		{
+			Name:        "quote_order",
+			Description: "Price a basket without placing the order",
+			Type:        graphql.NewNonNull(quote),
+			Args:        []*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[orderRequest](), ""))}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				var req orderRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "quote the order")
+				}
+				quote, err := h.db.QuoteOrder(req.toModel())
+				if err != nil {
+					return nil, resolverError(err, "quote the order")
+				}
+				return quote, nil
+			},
+		},
+		{
+			Name:        "quote_freight",
+			Description: "Quote freight for a basket with one or all shippers",
+			Type:        listOf(freightQuote),
+			Args:        []*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[freightQuoteRequest](), ""))}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				var req freightQuoteRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "quote freight")
+				}
+				quantity := 0
+				for _, line := range req.Lines {
+					quantity += line.Quantity
+				}
+				quotes, err := h.db.QuoteFreight(req.ShipVia, req.ShipCountry, req.Region, quantity)
+				if err != nil {
+					return nil, resolverError(err, "quote freight")
+				}
+				return quotes, nil
+			},
+		},
+	}}
+
+	categoryInput := graphql.NewNonNull(b.Input(reflect.TypeFor[categoryRequest](), ""))
+	shipperAndRate := []*graphql.Argument{idArgument("shipper_id"), idArgument("rate_id")}
+	intId := func(args map[string]any) int { return args["id"].(int) }
+
+	mutations := &graphql.Object{Name: "Mutation", Fields: []*graphql.Field{
+		// Categories
+		mutation("create_category", "Create a category", graphql.NewNonNull(category),
+			[]*graphql.Argument{{Name: "input", Type: categoryInput}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req categoryRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "create new category")
+				}
+				id, err := h.db.CreateNewCategory(req.Name, req.Description)
+				if err != nil {
+					return nil, resolverError(err, "create new category")
+				}
+				return loadersOf(p.Context).categories.Load(id), nil
+			}),
+		mutation("update_category", "Replace a category", graphql.NewNonNull(category),
+			[]*graphql.Argument{idArgument("id"), {Name: "input", Type: categoryInput}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req categoryRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "update the category")
+				}
+				id := intId(p.Args)
+				if err := h.db.UpdateCategory(strconv.Itoa(id), req.Name, req.Description); err != nil {
+					return nil, resolverError(err, "update the category")
+				}
+				return loadersOf(p.Context).categories.Load(id), nil
+			}),
+		patchMutation("patch_category", "category", category, b.Patch(reflect.TypeFor[model.Category](), "CategoryPatch", "category_id"),
+			[]*graphql.Argument{idArgument("id")}, nil,
+			func(args map[string]any, apply func(*model.Category) (*model.Category, error)) (*model.Category, error) {
+				return h.db.PatchCategory(intId(args), apply)
+			}),
+		mutation("delete_category", "Delete a category", graphql.NewNonNull(graphql.Boolean),
+			[]*graphql.Argument{idArgument("id")},
+			func(p graphql.ResolveParams) (any, error) {
+				if err := h.db.DeleteCategory(strconv.Itoa(intId(p.Args))); err != nil {
+					return nil, resolverError(err, "delete the category")
+				}
+				return true, nil
+			}),
+
+		// Customers, employees, suppliers and shippers
+		patchMutation("patch_customer", "customer", customer, b.Patch(reflect.TypeFor[model.Customer](), "CustomerPatch", "customer_id"),
+			[]*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.String)}}, nil,
+			func(args map[string]any, apply func(*model.Customer) (*model.Customer, error)) (*model.Customer, error) {
+				return h.db.PatchCustomer(args["id"].(string), apply)
+			}),
+		patchMutation("patch_employee", "employee", employee, b.Patch(reflect.TypeFor[model.Employees](), "EmployeePatch", "employee_id"),
+			[]*graphql.Argument{idArgument("id")}, checkEmployeePatch,
+			func(args map[string]any, apply func(*model.Employees) (*model.Employees, error)) (*model.Employees, error) {
+				return h.db.PatchEmployee(intId(args), apply)
+			}),
+		patchMutation("patch_supplier", "supplier", supplier, b.Patch(reflect.TypeFor[model.Suppliers](), "SupplierPatch", "supplier_id"),
+			[]*graphql.Argument{idArgument("id")}, nil,
+			func(args map[string]any, apply func(*model.Suppliers) (*model.Suppliers, error)) (*model.Suppliers, error) {
+				return h.db.PatchSupplier(intId(args), apply)
+			}),
+		patchMutation("patch_shipper", "shipper", shipper, b.Patch(reflect.TypeFor[model.Shippers](), "ShipperPatch", "shipper_id"),
+			[]*graphql.Argument{idArgument("id")}, nil,
+			func(args map[string]any, apply func(*model.Shippers) (*model.Shippers, error)) (*model.Shippers, error) {
+				return h.db.PatchShipper(intId(args), apply)
+			}),
+
+		// Products and prices
+		patchMutation("patch_product", "product", product,
+			b.Patch(reflect.TypeFor[model.Products](), "ProductPatch", "product_id", "unit_price"),
+			[]*graphql.Argument{idArgument("id")}, nil,
+			func(args map[string]any, apply func(*model.Products) (*model.Products, error)) (*model.Products, error) {
+				return h.db.PatchProduct(intId(args), apply)
+			}),
+		mutation("schedule_product_price", "Schedule a new price for a product", graphql.NewNonNull(priceChange),
+			[]*graphql.Argument{idArgument("product_id"), {Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[priceScheduleRequest](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req priceScheduleRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "schedule the product price")
+				}
+				change, err := h.db.ScheduleProductPrice(p.Args["product_id"].(int), req.UnitPrice, effectiveFrom(req.EffectiveFrom))
+				if err != nil {
+					return nil, resolverError(err, "schedule the product price")
+				}
+				return change, nil
+			}),
+		mutation("adjust_prices", "Schedule a percentage price change across a category and/or supplier", listOf(priceChange),
+			[]*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[priceAdjustmentRequest](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req priceAdjustmentRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "schedule the price adjustment")
+				}
+				changes, err := h.db.AdjustPrices(req.CategoryId, req.SupplierId, req.Percent, effectiveFrom(req.EffectiveFrom))
+				if err != nil {
+					return nil, resolverError(err, "schedule the price adjustment")
+				}
+				return changes, nil
+			}),
+
+		// Freight rates
+		mutation("create_freight_rate", "Add a band to a shipper's freight rate table", graphql.NewNonNull(freightRate),
+			[]*graphql.Argument{idArgument("shipper_id"), {Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[freightRateRequest](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req freightRateRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "create the freight rate")
+				}
+				rate := req.toModel(p.Args["shipper_id"].(int))
+				id, err := h.db.CreateFreightRate(rate)
+				if err != nil {
+					return nil, resolverError(err, "create the freight rate")
+				}
+				rate.RateId = id
+				return rate, nil
+			}),
+		patchMutation("patch_freight_rate", "freight rate", freightRate,
+			b.Patch(reflect.TypeFor[model.FreightRate](), "FreightRatePatch", "rate_id", "shipper_id"),
+			shipperAndRate, nil,
+			func(args map[string]any, apply func(*model.FreightRate) (*model.FreightRate, error)) (*model.FreightRate, error) {
+				return h.db.PatchFreightRate(args["shipper_id"].(int), args["rate_id"].(int), apply)
+			}),
+		mutation("delete_freight_rate", "Remove a band from a shipper's freight rate table", graphql.NewNonNull(graphql.Boolean),
+			shipperAndRate,
+			func(p graphql.ResolveParams) (any, error) {
+				if err := h.db.DeleteFreightRate(p.Args["shipper_id"].(int), p.Args["rate_id"].(int)); err != nil {
+					return nil, resolverError(err, "delete the freight rate")
+				}
+				return true, nil
+			}),
+
+		// Exchange rates
+		mutation("save_exchange_rates", "Save exchange rates; undated rates take effect now", listOf(exchangeRate),
+			[]*graphql.Argument{{Name: "rates", Type: listOf(b.Input(reflect.TypeFor[model.ExchangeRate](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var rates []model.ExchangeRate
+				if err := decodeArgument(p.Args["rates"], &rates); err != nil {
+					return nil, resolverError(err, "save exchange rates")
+				}
+				if len(rates) == 0 {
+					return nil, graphqlError(graphql.CodeBadUserInput, "at least one exchange rate is required")
+				}
+				if errs := h.checkExchangeRates(rates); len(errs) > 0 {
+					return nil, resolverError(errs, "save exchange rates")
+				}
+				if err := h.db.SaveExchangeRates(rates); err != nil {
+					return nil, resolverError(err, "save exchange rates")
+				}
+				return rates, nil
+			}),
+
+		// Promotions
+		mutation("create_promotion", "Create a promotion", graphql.NewNonNull(promotion),
+			[]*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[promotionRequest](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req promotionRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "create new promotion")
+				}
+				promotion := req.toModel()
+				id, err := h.db.CreatePromotion(promotion)
+				if err != nil {
+					return nil, resolverError(err, "create new promotion")
+				}
+				promotion.PromotionId = id
+				return promotion, nil
+			}),
+		patchMutation("patch_promotion", "promotion", promotion,
+			b.Patch(reflect.TypeFor[model.Promotion](), "PromotionPatch", "promotion_id"),
+			[]*graphql.Argument{idArgument("id")}, checkPromotionPatch,
+			func(args map[string]any, apply func(*model.Promotion) (*model.Promotion, error)) (*model.Promotion, error) {
+				return h.db.PatchPromotion(intId(args), apply)
+			}),
+		mutation("delete_promotion", "Delete a promotion", graphql.NewNonNull(graphql.Boolean),
+			[]*graphql.Argument{idArgument("id")},
+			func(p graphql.ResolveParams) (any, error) {
+				if err := h.db.DeletePromotion(intId(p.Args)); err != nil {
+					return nil, resolverError(err, "delete the promotion")
+				}
+				return true, nil
+			}),
+
+		// Orders
+		mutation("create_order", "Place an order", graphql.NewNonNull(order),
+			[]*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(b.Input(reflect.TypeFor[orderRequest](), ""))}},
+			func(p graphql.ResolveParams) (any, error) {
+				var req orderRequest
+				if err := decodeInput(p.Args["input"], &req); err != nil {
+					return nil, resolverError(err, "create the order")
+				}
+				id, err := h.db.CreateOrder(req.toModel())
+				if err != nil {
+					return nil, resolverError(err, "create the order")
+				}
+				return loadersOf(p.Context).orders.Load(id), nil
+			}),
+		// Pricing fields of an order are fixed when it is placed
+		patchMutation("patch_order", "order", order,
+			b.Patch(reflect.TypeFor[model.Orders](), "OrderPatch",
+				"order_id", "customer_id", "order_date", "ship_via", "freight", "currency", "exchange_rate"),
+			[]*graphql.Argument{idArgument("id")}, nil,
+			func(args map[string]any, apply func(*model.Orders) (*model.Orders, error)) (*model.Orders, error) {
+				return h.db.PatchOrder(intId(args), apply)
+			}),
+	}}
+
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return graphql.NewSchema(query, mutations)
+}
+
+// resolveSearch resolves the search field as GET /api/search does
+func (h *Handler) resolveSearch(p graphql.ResolveParams) (any, error) {
+	q := strings.TrimSpace(p.Args["q"].(string))
+	if q == "" {
+		return nil, graphqlError(graphql.CodeBadUserInput, "q is required")
+	}
+	limit, offset := p.Args["limit"].(int), p.Args["offset"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return nil, graphqlError(graphql.CodeBadUserInput, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+	if offset < 0 {
+		return nil, graphqlError(graphql.CodeBadUserInput, "offset must not be negative")
+	}
+
+	filter := repository.SearchFilter{Query: q, Limit: limit, Offset: offset}
+	if id, ok := p.Args["category_id"].(int); ok {
+		filter.CategoryId = id
+	}
+	if id, ok := p.Args["supplier_id"].(int); ok {
+		filter.SupplierId = id
+	}
+	for name, target := range map[string]**money.Money{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		n, ok := p.Args[name].(json.Number)
+		if !ok {
+			continue
+		}
+		price, err := money.Parse(string(n))
+		if err != nil || price.IsNegative() {
+			return nil, graphqlError(graphql.CodeBadUserInput, "Invalid "+name)
+		}
+		*target = &price
+	}
+
+	results, err := h.db.SearchProducts(filter)
+	if err != nil {
+		return nil, resolverError(err, "search the catalogue")
+	}
+	return results, nil
+}
+
+// #endregion
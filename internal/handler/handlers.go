@@ -2,9 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/graphql"
+	"northwind-api/internal/invoice"
+	"northwind-api/internal/openapi"
 	"northwind-api/internal/repository"
+	"northwind-api/internal/stream"
+	"northwind-api/internal/validate"
 	"strconv"
 	"strings"
 
@@ -13,16 +19,41 @@ import (
 )
 
 type Handler struct {
-	db     *repository.DB
-	config *appconfig.Config
+	db       *repository.DB
+	config   *appconfig.Config
+	invoices *invoice.Renderer
+	imports  *importJobs
+
+	// The latest events, streamed at /api/events
+	eventHub *stream.Hub
+
+	// The schema served at /graphql, whose resolvers call back into the handler
+	graphqlSchema *graphql.Schema
+
+	// The OpenAPI document, set by DocumentRoutes once the routes are registered
+	openapi     *openapi.Document
+	openapiJSON []byte
 }
 
 // Create a new instance of handler
-func New(db *repository.DB, cfg *appconfig.Config) *Handler {
-	return &Handler{
-		db:     db,
-		config: cfg,
+func New(db *repository.DB, cfg *appconfig.Config, eventHub *stream.Hub) (*Handler, error) {
+	invoices, err := invoice.NewRenderer(cfg.InvoiceTemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoice templates: %w", err)
 	}
+
+	h := &Handler{
+		db:       db,
+		config:   cfg,
+		invoices: invoices,
+		imports:  newImportJobs(cfg.ImportJobRetention),
+		eventHub: eventHub,
+	}
+	if h.graphqlSchema, err = h.newGraphQLSchema(); err != nil {
+		return nil, fmt.Errorf("failed to build the GraphQL schema: %w", err)
+	}
+
+	return h, nil
 }
 
 // Represents an error response
@@ -30,6 +61,46 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// Represents a request that failed validation, listing every invalid field
+type ValidationErrorResponse struct {
+	Error  string          `json:"error"`
+	Fields validate.Errors `json:"fields"`
+}
+
+// Represents one page of a list response
+type PagedResponse struct {
+	Data     interface{} `json:"data"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Total    int         `json:"total"`
+}
+
+// Paging defaults for list endpoints
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Reads ?page= and ?page_size= from the request, applying defaults and limits
+func parsePagination(r *http.Request) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultPageSize
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer")
+		}
+	}
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		pageSize, err = strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return 0, 0, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+	}
+
+	return page, pageSize, nil
+}
+
 // Writes a JSON response
 func writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -45,10 +116,28 @@ func writeErrorResponse(w http.ResponseWriter, status int, message string) {
 	writeJSONResponse(w, status, ErrorResponse{Error: message})
 }
 
+// Writes a 422 response listing the field errors and returns false if there
+// are any, so handlers can write `if !validRequest(w, errs) { return }`
+func validRequest(w http.ResponseWriter, errs validate.Errors) bool {
+	if len(errs) == 0 {
+		return true
+	}
+	log.Warn().Str("errors", errs.Error()).Msg("Request failed validation")
+	writeJSONResponse(w, http.StatusUnprocessableEntity, ValidationErrorResponse{
+		Error:  "Validation failed",
+		Fields: errs,
+	})
+	return false
+}
+
 // Handler to get all categories
 func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
 	log.Info().Msg("GET /categories - Getting all of the categories")
 
+	if exportList(w, r, "categories", h.db.StreamCategories) {
+		return
+	}
+
 	categories, err := h.db.GetAllCategories()
 	if err != nil {
 		log.Error().Err(err).Msg("Error getting categories")
@@ -95,18 +184,19 @@ func (h *Handler) GetCategoryById(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, category)
 }
 
+// Request body for creating or replacing a category
+type categoryRequest struct {
+	Name        string `json:"category_name" validate:"required,max=15"`
+	Description string `json:"description" validate:"required"`
+}
+
 // Handler to create a new category
 func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	// Struct for request category info
-	var req struct {
-		Name        string `json:"category_name"`
-		Description string `json:"description"`
-	}
+	var req categoryRequest
 
 	// Decode the json and put into struct
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Error().Err(err).Msg("Invalid JSON in create category request")
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -115,10 +205,7 @@ func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 		Str("description", req.Description).
 		Msg("Creating category with data")
 
-		// If name or description is empty, write error
-	if req.Name == "" || req.Description == "" {
-		log.Warn().Str("name", req.Name).Str("description", req.Description).Msg("Missing required fields")
-		writeErrorResponse(w, http.StatusBadRequest, "category_name and description are required")
+	if !validRequest(w, validate.Struct(&req)) {
 		return
 	}
 
@@ -157,14 +244,9 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Str("category_id", catId).Msg("PUT /api/categories/{ID} - Updating category")
 
-	var req struct {
-		Name        string `json:"category_name"`
-		Description string `json:"description"`
-	}
+	var req categoryRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Error().Err(err).Str("category_id", catId).Msg("Invalid JSON in update category request")
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -174,6 +256,10 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		Str("Description", req.Description).
 		Msg("Updating category with new data")
 
+	if !validRequest(w, validate.Struct(&req)) {
+		return
+	}
+
 	// Convert the ID to an int
 	// id, err := strconv.Atoi(idStr)
 	// if err != nil {
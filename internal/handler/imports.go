@@ -0,0 +1,726 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/patch"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/spreadsheet"
+	"northwind-api/internal/validate"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Imports
+
+// Statuses of an import job
+const (
+	importRunning   = "running"
+	importCompleted = "completed"
+	importFailed    = "failed"
+)
+
+// maxImportErrors caps the row errors a job keeps; failed still counts them all
+const maxImportErrors = 1000
+
+// Represents a row of an imported file that could not be imported. Rows are
+// numbered as in the file, the header being row 1.
+type ImportRowError struct {
+	Row    int             `json:"row"`
+	Error  string          `json:"error"`
+	Fields validate.Errors `json:"fields,omitempty"`
+}
+
+// Represents an import and its progress. Created and updated count the rows
+// that were written, or for a dry run or a rolled back atomic import the rows
+// that would have been; committed says whether they were kept.
+type ImportJob struct {
+	Id              string            `json:"id"`
+	Resource        string            `json:"resource"`
+	Status          string            `json:"status"`
+	Mode            string            `json:"mode"`
+	DryRun          bool              `json:"dry_run"`
+	Committed       bool              `json:"committed"`
+	TotalRows       int               `json:"total_rows"`
+	ProcessedRows   int               `json:"processed_rows"`
+	Created         int               `json:"created"`
+	Updated         int               `json:"updated"`
+	Failed          int               `json:"failed"`
+	Columns         map[string]string `json:"columns"`
+	Warnings        []string          `json:"warnings"`
+	Errors          []ImportRowError  `json:"errors"`
+	ErrorsTruncated bool              `json:"errors_truncated,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	FinishedAt      *time.Time        `json:"finished_at,omitempty"`
+}
+
+// importJobs keeps import jobs in memory for polling, dropping finished jobs
+// once they are older than the retention period
+type importJobs struct {
+	mu        sync.Mutex
+	jobs      map[string]*ImportJob
+	retention time.Duration
+}
+
+func newImportJobs(retention time.Duration) *importJobs {
+	return &importJobs{jobs: map[string]*ImportJob{}, retention: retention}
+}
+
+// Stores a copy of a job, replacing any earlier version of it
+func (s *importJobs) put(job *ImportJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, old := range s.jobs {
+		if old.FinishedAt != nil && time.Since(*old.FinishedAt) > s.retention {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[job.Id] = job.clone()
+}
+
+// Returns a copy of a job
+func (s *importJobs) get(id string) (*ImportJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return job.clone(), true
+}
+
+// Copies a job, so the copy can be read while the job runs on
+func (j *ImportJob) clone() *ImportJob {
+	c := *j
+	c.Warnings = slices.Clone(j.Warnings)
+	c.Errors = slices.Clone(j.Errors)
+	return &c
+}
+
+// Records a row that could not be imported
+func (j *ImportJob) fail(row int, message string, fields validate.Errors) {
+	j.Failed++
+	if len(j.Errors) == maxImportErrors {
+		j.ErrorsTruncated = true
+		return
+	}
+	j.Errors = append(j.Errors, ImportRowError{Row: row, Error: message, Fields: fields})
+}
+
+// importer describes how the rows of a file become items of a resource. Rows
+// are upserted by the resource's natural key, whose fields every file must
+// have a column for, directly or through a column naming the related row.
+type importer[T any] struct {
+	noun string
+	id   string
+	key  []string
+	refs map[string]importRef
+	run  func(b *repository.Bulk, items []repository.BulkItem[T]) ([]repository.BulkResult, error)
+}
+
+// importRef is a column naming a related row, imported as the id of that row
+type importRef struct {
+	field string
+	noun  string
+	ids   func(db *repository.DB) (map[string]int, error)
+}
+
+var (
+	categoryImport = importer[model.Category]{
+		noun: "category", id: "category_id", key: []string{"category_name"},
+		run: (*repository.Bulk).Categories,
+	}
+	// A product's natural key is its name and supplier, so files must say
+	// which supplier each product is from; a blank supplier cell matches a
+	// product without one
+	productImport = importer[model.Products]{
+		noun: "product", id: "product_id", key: []string{"product_name", "supplier_id"},
+		refs: map[string]importRef{
+			"supplier": {"supplier_id", "supplier", (*repository.DB).SupplierIdsByName},
+			"category": {"category_id", "category", (*repository.DB).CategoryIdsByName},
+		},
+		run: (*repository.Bulk).Products,
+	}
+	supplierImport = importer[model.Suppliers]{
+		noun: "supplier", id: "supplier_id", key: []string{"company_name"},
+		run: (*repository.Bulk).Suppliers,
+	}
+)
+
+// An import request: the file and the options sent with it
+type importRequest struct {
+	data      []byte
+	mediaType string
+	filename  string
+	mapping   string
+	mode      string
+	dryRun    string
+}
+
+// Reads an import request, writing an error response and returning false if
+// it cannot. The file is sent either as the body, with a CSV or XLSX
+// Content-Type, or as the "file" part of a multipart form whose other parts
+// may hold the mapping, mode and dry_run options, which otherwise come from
+// the query string.
+func (h *Handler) readImportRequest(w http.ResponseWriter, r *http.Request) (*importRequest, bool) {
+	query := r.URL.Query()
+	req := &importRequest{mapping: query.Get("mapping"), mode: query.Get("mode"), dryRun: query.Get("dry_run")}
+
+	tooLarge := func(err error) bool {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeErrorResponse(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Request body must be at most %d bytes", maxErr.Limit))
+			return true
+		}
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.BulkMaxBodyBytes)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case spreadsheet.CSVType, spreadsheet.XLSXType:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			if !tooLarge(err) {
+				writeErrorResponse(w, http.StatusBadRequest, "Failed to read the request body")
+			}
+			return nil, false
+		}
+		req.data, req.mediaType = data, mediaType
+		return req, true
+
+	case "multipart/form-data":
+		reader, err := r.MultipartReader()
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid multipart body")
+			return nil, false
+		}
+		found := false
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if !tooLarge(err) {
+					writeErrorResponse(w, http.StatusBadRequest, "Invalid multipart body")
+				}
+				return nil, false
+			}
+
+			value, err := io.ReadAll(part)
+			if err != nil {
+				if !tooLarge(err) {
+					writeErrorResponse(w, http.StatusBadRequest, "Invalid multipart body")
+				}
+				return nil, false
+			}
+			switch part.FormName() {
+			case "file":
+				req.data, req.filename, found = value, part.FileName(), true
+				req.mediaType, _, _ = mime.ParseMediaType(part.Header.Get("Content-Type"))
+			case "mapping":
+				req.mapping = string(value)
+			case "mode":
+				req.mode = string(value)
+			case "dry_run":
+				req.dryRun = string(value)
+			}
+		}
+		if !found {
+			writeErrorResponse(w, http.StatusBadRequest, "The form must have a file part")
+			return nil, false
+		}
+		return req, true
+	}
+
+	writeErrorResponse(w, http.StatusUnsupportedMediaType,
+		"Content-Type must be "+spreadsheet.CSVType+", "+spreadsheet.XLSXType+" or multipart/form-data")
+	return nil, false
+}
+
+// importResource imports a CSV or XLSX file into a resource. Header cells are
+// matched to fields by name, or by the mapping sent with the file, and each
+// row is upserted by natural key through the same repository operations as the
+// bulk API. Files with up to IMPORT_SYNC_ROWS rows are imported before the
+// response is sent; larger files are imported by a job polled for progress.
+func importResource[T any](h *Handler, w http.ResponseWriter, r *http.Request, resource string, imp importer[T]) {
+	req, ok := h.readImportRequest(w, r)
+	if !ok {
+		return
+	}
+
+	mode := req.mode
+	if mode == "" {
+		mode = bulkBestEffort
+	}
+	if mode != bulkAtomic && mode != bulkBestEffort {
+		writeErrorResponse(w, http.StatusBadRequest, "mode must be atomic or best_effort")
+		return
+	}
+	dryRun := false
+	if req.dryRun != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(req.dryRun); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+	mapping := map[string]string{}
+	if req.mapping != "" {
+		if err := decodeStrict([]byte(req.mapping), &mapping); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "mapping must be a JSON object of column names to field names")
+			return
+		}
+	}
+
+	rows, err := spreadsheet.Read(req.data, req.mediaType, req.filename)
+	if err != nil {
+		if errors.Is(err, spreadsheet.ErrUnsupportedFormat) {
+			writeErrorResponse(w, http.StatusUnsupportedMediaType, "The file must be CSV or XLSX")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "The file must start with a header row")
+		return
+	}
+
+	columns, warnings, err := imp.mapColumns(rows[0], mapping)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Related rows are named in the file, so load the names of those referred to
+	refIds := map[string]map[string]int{}
+	for _, field := range columns {
+		if ref, isRef := imp.refs[field]; isRef {
+			if refIds[field], err = ref.ids(h.db); err != nil {
+				log.Error().Err(err).Msg("Error loading " + ref.noun + " names")
+				writeErrorResponse(w, http.StatusInternalServerError, "Failed to import the "+resource)
+				return
+			}
+		}
+	}
+
+	id, err := newJobId()
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating import job id")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to import the "+resource)
+		return
+	}
+	job := &ImportJob{
+		Id:        id,
+		Resource:  resource,
+		Status:    importRunning,
+		Mode:      mode,
+		DryRun:    dryRun,
+		Columns:   map[string]string{},
+		Warnings:  warnings,
+		Errors:    []ImportRowError{},
+		StartedAt: time.Now(),
+	}
+	for i, field := range columns {
+		if field != "" {
+			job.Columns[rows[0][i]] = field
+		}
+	}
+	for _, row := range rows[1:] {
+		if !blankRow(row) {
+			job.TotalRows++
+		}
+	}
+	h.imports.put(job)
+
+	if job.TotalRows <= h.config.ImportSyncRows {
+		runImport(h, job, imp, rows, columns, refIds)
+		writeJSONResponse(w, http.StatusOK, job)
+		return
+	}
+
+	accepted := job.clone()
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().Any("panic", rec).Str("job_id", job.Id).Msg("Import job panicked")
+				finishImport(h, job, importFailed, "Failed to import the "+resource)
+			}
+		}()
+		runImport(h, job, imp, rows, columns, refIds)
+	}()
+
+	w.Header().Set("Location", "/api/import/jobs/"+job.Id)
+	writeJSONResponse(w, http.StatusAccepted, accepted)
+}
+
+// runImport imports the rows of a file chunk by chunk, publishing the job's
+// progress after each chunk. A dry run runs every row in one transaction and
+// rolls it back, so it reports what the import would do without doing it.
+func runImport[T any](h *Handler, job *ImportJob, imp importer[T], rows [][]string, columns []string, refIds map[string]map[string]int) {
+	bulk := h.db.NewBulk(job.Mode == bulkAtomic || job.DryRun)
+	defer bulk.Close()
+
+	fields := importFields[T]()
+	var items []repository.BulkItem[T]
+	var itemRows []int
+	committedChunk := false
+
+	flush := func() error {
+		if len(items) > 0 {
+			results, err := imp.run(bulk, items)
+			if err != nil {
+				return err
+			}
+			for i, result := range results {
+				switch {
+				case result.Err != nil:
+					message, errs := bulkItemError(result.Err, imp.noun)
+					job.fail(itemRows[i], message, errs)
+				case result.Op == repository.BulkCreate:
+					job.Created++
+				default:
+					job.Updated++
+				}
+			}
+			committedChunk = committedChunk || job.Mode == bulkBestEffort && !job.DryRun
+			items, itemRows = items[:0], itemRows[:0]
+		}
+		h.imports.put(job)
+		return nil
+	}
+
+	for i, row := range rows[1:] {
+		if blankRow(row) {
+			continue
+		}
+		job.ProcessedRows++
+
+		// Row numbers count the header as row 1
+		item, message, errs := imp.parseRow(row, columns, fields, refIds)
+		if item == nil {
+			job.fail(i+2, message, errs)
+		} else {
+			items = append(items, *item)
+			itemRows = append(itemRows, i+2)
+		}
+
+		if len(items) == h.config.BulkChunkSize {
+			if err := flush(); err != nil {
+				log.Error().Err(err).Str("job_id", job.Id).Msg("Error running import chunk")
+				job.Committed = committedChunk
+				finishImport(h, job, importFailed, "Failed to import the "+job.Resource)
+				return
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		log.Error().Err(err).Str("job_id", job.Id).Msg("Error running import chunk")
+		job.Committed = committedChunk
+		finishImport(h, job, importFailed, "Failed to import the "+job.Resource)
+		return
+	}
+
+	switch {
+	case job.DryRun:
+		// Rolled back by Close
+	case job.Mode == bulkAtomic && job.Failed > 0:
+		// An atomic import with a failed row is rolled back by Close
+	default:
+		if err := bulk.Finish(); err != nil {
+			log.Error().Err(err).Str("job_id", job.Id).Msg("Error finishing import")
+			job.Committed = committedChunk
+			finishImport(h, job, importFailed, "Failed to import the "+job.Resource)
+			return
+		}
+		job.Committed = true
+	}
+
+	finishImport(h, job, importCompleted, "")
+}
+
+// Marks a job finished and publishes it
+func finishImport(h *Handler, job *ImportJob, status, message string) {
+	now := time.Now()
+	job.Status, job.Error, job.FinishedAt = status, message, &now
+	h.imports.put(job)
+
+	log.Info().Str("job_id", job.Id).Str("resource", job.Resource).Str("status", status).
+		Bool("dry_run", job.DryRun).Int("created", job.Created).Int("updated", job.Updated).
+		Int("failed", job.Failed).Bool("committed", job.Committed).Msg("Finished import")
+}
+
+// mapColumns returns the field each column of the header row is imported
+// into, "" for columns that are not imported. The mapping names fields by
+// header cell, mapping a column to "" to leave it out; other columns are
+// matched to the field whose name they normalize to.
+func (imp importer[T]) mapColumns(header []string, mapping map[string]string) ([]string, []string, error) {
+	fields := importFields[T]()
+	known := func(field string) bool {
+		_, isField := fields[field]
+		_, isRef := imp.refs[field]
+		return (isField && field != imp.id) || isRef
+	}
+
+	for column, field := range mapping {
+		if !slices.Contains(header, column) {
+			return nil, nil, fmt.Errorf("mapping names column %q, which the file does not have", column)
+		}
+		if field != "" && !known(field) {
+			return nil, nil, fmt.Errorf("mapping maps column %q to %q, which is not a field of %s", column, field, imp.noun)
+		}
+	}
+
+	columns := make([]string, len(header))
+	warnings := []string{}
+	mappedBy := map[string]string{}
+	for i, cell := range header {
+		field, mapped := mapping[cell]
+		if !mapped {
+			field = normalizeHeader(cell)
+			if !known(field) {
+				if cell != "" {
+					warnings = append(warnings, fmt.Sprintf("column %q is not imported: it matches no field", cell))
+				}
+				continue
+			}
+		}
+		if field == "" {
+			continue
+		}
+		if other, taken := mappedBy[field]; taken {
+			return nil, nil, fmt.Errorf("columns %q and %q are both imported into %s", other, cell, field)
+		}
+		mappedBy[field] = cell
+		columns[i] = field
+	}
+
+	for _, field := range imp.key {
+		names := []string{field}
+		_, found := mappedBy[field]
+		for name, ref := range imp.refs {
+			if ref.field == field {
+				names = append([]string{name}, names...)
+				_, byName := mappedBy[name]
+				found = found || byName
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("the file must have a %s column", strings.Join(names, " or "))
+		}
+	}
+	for name, ref := range imp.refs {
+		if _, ok := mappedBy[name]; ok {
+			if _, ok := mappedBy[ref.field]; ok {
+				return nil, nil, fmt.Errorf("columns %q and %q are both imported into %s",
+					mappedBy[ref.field], mappedBy[name], ref.field)
+			}
+		}
+	}
+
+	return columns, warnings, nil
+}
+
+// parseRow turns a row into an upsert. Empty cells are left out, so they never
+// clear what is stored. The row is returned with a message and field errors,
+// and no item, if it is not valid.
+func (imp importer[T]) parseRow(row []string, columns []string, fields map[string]reflect.Type, refIds map[string]map[string]int) (*repository.BulkItem[T], string, validate.Errors) {
+	values := map[string]any{}
+	var errs validate.Errors
+	for i, field := range columns {
+		cell := strings.TrimSpace(row[i])
+		if field == "" || cell == "" {
+			continue
+		}
+
+		if ref, isRef := imp.refs[field]; isRef {
+			id, found := refIds[field][strings.ToLower(cell)]
+			switch {
+			case !found:
+				errs = append(errs, validate.FieldError{Field: field, Message: "does not name a " + ref.noun})
+			case id == 0:
+				errs = append(errs, validate.FieldError{Field: field, Message: "names more than one " + ref.noun})
+			default:
+				values[ref.field] = id
+			}
+			continue
+		}
+
+		value, message := cellValue(cell, fields[field])
+		if message != "" {
+			errs = append(errs, validate.FieldError{Field: field, Message: message})
+			continue
+		}
+		values[field] = value
+	}
+	if len(errs) > 0 {
+		return nil, "Validation failed", errs
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, "Failed to read the row", nil
+	}
+	value := new(T)
+	if err := decodeStrict(data, value); err != nil {
+		return nil, describeJSONError(data, err), nil
+	}
+	if errs := validate.Struct(value); len(errs) > 0 {
+		return nil, "Validation failed", errs
+	}
+	doc, err := patch.Parse(patch.MergePatchType, data)
+	if err != nil {
+		return nil, err.Error(), nil
+	}
+
+	return &repository.BulkItem[T]{Op: repository.BulkUpsert, Value: value, Apply: applyPatch[T](doc, nil)}, "", nil
+}
+
+// Types of the cells a spreadsheet can hold for each field
+var (
+	moneyType = reflect.TypeFor[money.Money]()
+	timeType  = reflect.TypeFor[time.Time]()
+)
+
+// cellValue converts a cell into the JSON value of a field of type t,
+// returning a validation message instead if the cell does not hold one
+func cellValue(cell string, t reflect.Type) (any, string) {
+	// Null[T] holds its value in V
+	if t.Kind() == reflect.Struct {
+		if v, ok := t.FieldByName("V"); ok {
+			if _, ok := t.FieldByName("Valid"); ok {
+				t = v.Type
+			}
+		}
+	}
+
+	switch {
+	case t == moneyType:
+		if _, err := money.Parse(cell); err != nil {
+			return nil, "must be an amount"
+		}
+		return cell, ""
+	case t == timeType:
+		if d, err := time.Parse(time.DateOnly, cell); err == nil {
+			return d.Format(time.RFC3339), ""
+		}
+		if _, err := time.Parse(time.RFC3339, cell); err != nil {
+			return nil, "must be a date such as 2006-01-02"
+		}
+		return cell, ""
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Spreadsheets may store whole numbers as 12.0
+		n, err := strconv.ParseFloat(cell, 64)
+		if err != nil || n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+			return nil, "must be a whole number"
+		}
+		return int64(n), ""
+	case reflect.Bool:
+		switch strings.ToLower(cell) {
+		case "true", "yes", "y", "1":
+			return true, ""
+		case "false", "no", "n", "0":
+			return false, ""
+		}
+		return nil, "must be true or false"
+	}
+	return cell, ""
+}
+
+// importFields returns the JSON fields of a model with their types
+func importFields[T any]() map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	t := reflect.TypeFor[T]()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+// normalizeHeader turns a header cell such as "Product Name" into the field
+// name it most likely means
+func normalizeHeader(cell string) string {
+	cell = strings.ToLower(strings.TrimSpace(cell))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(cell)
+}
+
+// Reports whether every cell of a row is empty
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a random id for an import job
+func newJobId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Handler to import a CSV or XLSX file of categories, products or suppliers
+func (h *Handler) ImportResource(w http.ResponseWriter, r *http.Request) {
+	resource := mux.Vars(r)["resource"]
+	log.Info().Str("resource", resource).Msg("POST /api/import/{resource} - Importing file")
+
+	switch resource {
+	case "categories":
+		importResource(h, w, r, resource, categoryImport)
+	case "products":
+		importResource(h, w, r, resource, productImport)
+	case "suppliers":
+		importResource(h, w, r, resource, supplierImport)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Only categories, products and suppliers can be imported")
+	}
+}
+
+// Handler to get the progress of an import job
+func (h *Handler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["jobId"]
+	log.Info().Str("job_id", id).Msg("GET /api/import/jobs/{ID} - Getting import job")
+
+	job, ok := h.imports.get(id)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotFound, "Import job not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, job)
+}
+
+// #endregion
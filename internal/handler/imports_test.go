@@ -0,0 +1,444 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/spreadsheet"
+	"northwind-api/internal/validate"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMapColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []string
+		mapping  map[string]string
+		want     []string
+		warnings int
+		wantErr  string
+	}{
+		{
+			name:   "headers normalized to fields",
+			header: []string{"Product Name", "Supplier", "unit-price", "Category"},
+			want:   []string{"product_name", "supplier", "unit_price", "category"},
+		},
+		{
+			name:   "supplier by id",
+			header: []string{"product_name", "supplier_id"},
+			want:   []string{"product_name", "supplier_id"},
+		},
+		{
+			name:     "unknown columns are warned about",
+			header:   []string{"product_name", "supplier", "Notes", ""},
+			want:     []string{"product_name", "supplier", "", ""},
+			warnings: 1,
+		},
+		{
+			name:    "mapping",
+			header:  []string{"Name", "Vendor", "Notes"},
+			mapping: map[string]string{"Name": "product_name", "Vendor": "supplier", "Notes": ""},
+			want:    []string{"product_name", "supplier", ""},
+		},
+		{
+			name:    "no supplier column",
+			header:  []string{"product_name", "unit_price"},
+			wantErr: "the file must have a supplier or supplier_id column",
+		},
+		{
+			name:    "no name column",
+			header:  []string{"supplier"},
+			wantErr: "the file must have a product_name column",
+		},
+		{
+			name:    "supplier by name and id",
+			header:  []string{"product_name", "supplier", "supplier_id"},
+			wantErr: `columns "supplier_id" and "supplier" are both imported into supplier_id`,
+		},
+		{
+			name:    "two columns into one field",
+			header:  []string{"product_name", "Product-Name", "supplier"},
+			wantErr: `columns "product_name" and "Product-Name" are both imported into product_name`,
+		},
+		{
+			name:    "mapping names a missing column",
+			header:  []string{"product_name", "supplier"},
+			mapping: map[string]string{"Price": "unit_price"},
+			wantErr: `mapping names column "Price", which the file does not have`,
+		},
+		{
+			name:    "mapping into an unknown field",
+			header:  []string{"product_name", "supplier", "Price"},
+			mapping: map[string]string{"Price": "price"},
+			wantErr: `mapping maps column "Price" to "price", which is not a field of product`,
+		},
+		{
+			name:    "mapping into the id",
+			header:  []string{"product_name", "supplier", "Id"},
+			mapping: map[string]string{"Id": "product_id"},
+			wantErr: `mapping maps column "Id" to "product_id", which is not a field of product`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, warnings, err := productImport.mapColumns(tt.header, tt.mapping)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("mapColumns() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mapColumns() error = %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.want) {
+				t.Errorf("columns = %q, want %q", columns, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseRow(t *testing.T) {
+	columns := []string{"product_name", "supplier", "unit_price", "units_in_stock", "discontinued", ""}
+	refIds := map[string]map[string]int{
+		// Names shared by several suppliers map to 0
+		"supplier": {"exotic liquids": 1, "tokyo traders": 0},
+	}
+	fields := importFields[model.Products]()
+
+	tests := []struct {
+		name       string
+		row        []string
+		wantFields validate.Errors
+		wantErr    bool
+		want       *model.Products
+	}{
+		{
+			name: "valid",
+			row:  []string{" Chai ", "Exotic Liquids", "18.00", "39.0", "no", "ignored"},
+			want: &model.Products{
+				ProductName:  "Chai",
+				SupplierId:   model.NewNull(1),
+				UnitPrice:    model.NewNull(money.MustParse("18")),
+				UnitsInStock: model.NewNull(39),
+			},
+		},
+		{
+			name: "blank cells are left out",
+			row:  []string{"Chai", "", "", "", "", ""},
+			want: &model.Products{ProductName: "Chai"},
+		},
+		{
+			name:       "unknown supplier",
+			row:        []string{"Chai", "Exotic Liquid", "18", "", "", ""},
+			wantFields: validate.Errors{{Field: "supplier", Message: "does not name a supplier"}},
+		},
+		{
+			name:       "ambiguous supplier",
+			row:        []string{"Chai", "TOKYO TRADERS", "18", "", "", ""},
+			wantFields: validate.Errors{{Field: "supplier", Message: "names more than one supplier"}},
+		},
+		{
+			name: "cells of the wrong type",
+			row:  []string{"Chai", "", "eighteen", "39.5", "maybe", ""},
+			wantFields: validate.Errors{
+				{Field: "unit_price", Message: "must be an amount"},
+				{Field: "units_in_stock", Message: "must be a whole number"},
+				{Field: "discontinued", Message: "must be true or false"},
+			},
+		},
+		{
+			name:    "invalid product",
+			row:     []string{"Chai", "", "-18", "", "", ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, message, errs := productImport.parseRow(tt.row, columns, fields, refIds)
+			if tt.want == nil {
+				if item != nil {
+					t.Fatalf("parseRow() = %+v, want no item", item.Value)
+				}
+				if tt.wantFields != nil && !reflect.DeepEqual(errs, tt.wantFields) {
+					t.Errorf("parseRow() errors = %v, want %v", errs, tt.wantFields)
+				}
+				if message == "" || (tt.wantErr && len(errs) == 0) {
+					t.Errorf("parseRow() = %q, %v, want a message and field errors", message, errs)
+				}
+				return
+			}
+
+			if item == nil {
+				t.Fatalf("parseRow() = %q, %v, want an item", message, errs)
+			}
+			if item.Op != repository.BulkUpsert {
+				t.Errorf("op = %s, want %s", item.Op, repository.BulkUpsert)
+			}
+			if !reflect.DeepEqual(item.Value, tt.want) {
+				t.Errorf("value = %+v, want %+v", item.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRowPatchLeavesBlankCellsAlone(t *testing.T) {
+	columns := []string{"product_name", "supplier_id", "unit_price", "quantity_per_unit"}
+	item, message, errs := productImport.parseRow([]string{"Chai", "1", "19", ""}, columns, importFields[model.Products](), nil)
+	if item == nil {
+		t.Fatalf("parseRow() = %q, %v, want an item", message, errs)
+	}
+
+	stored := &model.Products{
+		ProductId:       1,
+		ProductName:     "Chai",
+		SupplierId:      model.NewNull(1),
+		QuantityPerUnit: model.NewNull("10 boxes x 20 bags"),
+		UnitPrice:       model.NewNull(money.MustParse("18")),
+		Discontinued:    true,
+	}
+	got, err := item.Apply(stored)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := *stored
+	want.UnitPrice = model.NewNull(money.MustParse("19"))
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Apply() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestCellValue(t *testing.T) {
+	tests := []struct {
+		cell    string
+		typ     reflect.Type
+		want    any
+		wantErr string
+	}{
+		{"18.50", reflect.TypeFor[money.Money](), "18.50", ""},
+		{"18,50", reflect.TypeFor[model.NullMoney](), nil, "must be an amount"},
+		{"1996-07-04", reflect.TypeFor[model.NullTime](), "1996-07-04T00:00:00Z", ""},
+		{"1996-07-04T09:30:00+02:00", reflect.TypeFor[time.Time](), "1996-07-04T09:30:00+02:00", ""},
+		{"04/07/1996", reflect.TypeFor[model.NullTime](), nil, "must be a date such as 2006-01-02"},
+		{"12", reflect.TypeFor[int](), int64(12), ""},
+		{"12.0", reflect.TypeFor[model.NullInt](), int64(12), ""},
+		{"-3", reflect.TypeFor[model.NullInt](), int64(-3), ""},
+		{"12.5", reflect.TypeFor[model.NullInt](), nil, "must be a whole number"},
+		{"3000000000", reflect.TypeFor[int](), nil, "must be a whole number"},
+		{"twelve", reflect.TypeFor[int](), nil, "must be a whole number"},
+		{"Yes", reflect.TypeFor[bool](), true, ""},
+		{"0", reflect.TypeFor[bool](), false, ""},
+		{"maybe", reflect.TypeFor[bool](), nil, "must be true or false"},
+		{"Chai", reflect.TypeFor[string](), "Chai", ""},
+		{"12", reflect.TypeFor[model.NullString](), "12", ""},
+	}
+	for _, tt := range tests {
+		got, message := cellValue(tt.cell, tt.typ)
+		if got != tt.want || message != tt.wantErr {
+			t.Errorf("cellValue(%q, %s) = %v, %q, want %v, %q", tt.cell, tt.typ, got, message, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// #region fake database
+
+// importDriver is a database/sql driver standing in for Postgres under a
+// supplier import. Lookups find nothing, so every row is inserted, and
+// inserting a supplier named "Broken" fails. It records when transactions
+// begin and end and which suppliers are inserted.
+type importDriver struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *importDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *importDriver) Connect(context.Context) (driver.Conn, error) { return importConn{d}, nil }
+func (d *importDriver) Driver() driver.Driver                        { return nil }
+
+type importConn struct{ d *importDriver }
+
+func (c importConn) Prepare(query string) (driver.Stmt, error) {
+	return importStmt{c.d, query}, nil
+}
+func (c importConn) Close() error { return nil }
+func (c importConn) Begin() (driver.Tx, error) {
+	c.d.record("begin")
+	return importTx(c), nil
+}
+
+type importTx struct{ d *importDriver }
+
+func (tx importTx) Commit() error   { tx.d.record("commit"); return nil }
+func (tx importTx) Rollback() error { tx.d.record("rollback"); return nil }
+
+type importStmt struct {
+	d     *importDriver
+	query string
+}
+
+func (s importStmt) Close() error  { return nil }
+func (s importStmt) NumInput() int { return -1 }
+
+func (s importStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s importStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.Contains(s.query, "INSERT INTO suppliers") {
+		return &importRows{column: "supplier_id"}, nil
+	}
+	name := args[0].(string)
+	if name == "Broken" {
+		return nil, errors.New("connection reset")
+	}
+	s.d.record("insert " + name)
+	return &importRows{column: "supplier_id", ids: []int64{1}}, nil
+}
+
+type importRows struct {
+	column string
+	ids    []int64
+}
+
+func (r *importRows) Columns() []string { return []string{r.column} }
+func (r *importRows) Close() error      { return nil }
+func (r *importRows) Next(dest []driver.Value) error {
+	if len(r.ids) == 0 {
+		return io.EOF
+	}
+	dest[0], r.ids = r.ids[0], r.ids[1:]
+	return nil
+}
+
+// #endregion
+
+const supplierFile = `company_name,country,phone
+Exotic Liquids,UK,(171) 555-2222
+New Orleans Cajun Delights,USA,(100) 555-4822
+,,
+Grandma Kelly's Homestead,USA,(313) 555-5735
+`
+
+func TestImportModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		file          string
+		wantLog       []string
+		wantCommitted bool
+		wantCreated   int
+		wantFailed    int
+	}{
+		{
+			name:  "best effort commits each chunk",
+			query: "mode=best_effort",
+			file:  supplierFile + "Broken,USA,\n",
+			wantLog: []string{
+				"begin", "insert Exotic Liquids", "insert New Orleans Cajun Delights", "commit",
+				"begin", "insert Grandma Kelly's Homestead", "commit",
+			},
+			wantCommitted: true, wantCreated: 3, wantFailed: 1,
+		},
+		{
+			name:  "atomic commits once",
+			query: "mode=atomic",
+			file:  supplierFile,
+			wantLog: []string{
+				"begin", "insert Exotic Liquids", "insert New Orleans Cajun Delights",
+				"insert Grandma Kelly's Homestead", "commit",
+			},
+			wantCommitted: true, wantCreated: 3,
+		},
+		{
+			name:  "atomic rolls back when a row fails to save",
+			query: "mode=atomic",
+			file:  supplierFile + "Broken,USA,\n",
+			wantLog: []string{
+				"begin", "insert Exotic Liquids", "insert New Orleans Cajun Delights",
+				"insert Grandma Kelly's Homestead", "rollback",
+			},
+			wantCreated: 3, wantFailed: 1,
+		},
+		{
+			name:  "atomic rolls back when a row is invalid",
+			query: "mode=atomic",
+			file:  supplierFile + "Bigfoot Breweries,Atlantis,\n",
+			wantLog: []string{
+				"begin", "insert Exotic Liquids", "insert New Orleans Cajun Delights",
+				"insert Grandma Kelly's Homestead", "rollback",
+			},
+			wantCreated: 3, wantFailed: 1,
+		},
+		{
+			name:  "dry run rolls back",
+			query: "dry_run=true",
+			file:  supplierFile,
+			wantLog: []string{
+				"begin", "insert Exotic Liquids", "insert New Orleans Cajun Delights",
+				"insert Grandma Kelly's Homestead", "rollback",
+			},
+			wantCreated: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &importDriver{}
+			sqlDB := sql.OpenDB(db)
+			defer sqlDB.Close()
+			h := &Handler{
+				db:      &repository.DB{DB: sqlDB},
+				config:  &appconfig.Config{BulkChunkSize: 2, BulkMaxBodyBytes: 1 << 20, ImportSyncRows: 100},
+				imports: newImportJobs(time.Hour),
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/import/suppliers?"+tt.query, strings.NewReader(tt.file))
+			r.Header.Set("Content-Type", spreadsheet.CSVType)
+			w := httptest.NewRecorder()
+			importResource(h, w, r, "suppliers", supplierImport)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			var job ImportJob
+			if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+				t.Fatalf("decode job: %v", err)
+			}
+
+			if !slices.Equal(db.log, tt.wantLog) {
+				t.Errorf("database saw %q\nwant %q", db.log, tt.wantLog)
+			}
+			if job.Status != importCompleted || job.Committed != tt.wantCommitted {
+				t.Errorf("job %s, committed %v, want completed, committed %v", job.Status, job.Committed, tt.wantCommitted)
+			}
+			if job.Created != tt.wantCreated || job.Failed != tt.wantFailed || job.TotalRows != 3+tt.wantFailed {
+				t.Errorf("job created %d and failed %d of %d rows, want %d and %d of %d",
+					job.Created, job.Failed, job.TotalRows, tt.wantCreated, tt.wantFailed, 3+tt.wantFailed)
+			}
+			if tt.wantFailed > 0 && (len(job.Errors) != 1 || job.Errors[0].Row != 6) {
+				t.Errorf("errors = %+v, want row 6", job.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"northwind-api/internal/invoice"
+	"northwind-api/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// Invoice formats that can be negotiated, in order of preference
+var invoiceFormats = []string{"text/html", "application/pdf", "application/json"}
+
+// negotiateInvoiceFormat picks the invoice media type from ?format= or the Accept header.
+// It returns an empty string when none of the supported formats is acceptable.
+func negotiateInvoiceFormat(r *http.Request) string {
+	switch r.URL.Query().Get("format") {
+	case "html":
+		return "text/html"
+	case "pdf":
+		return "application/pdf"
+	case "json":
+		return "application/json"
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return invoiceFormats[0]
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "*/*", "text/*":
+			return invoiceFormats[0]
+		case "application/*":
+			return "application/pdf"
+		}
+		for _, format := range invoiceFormats {
+			if mediaType == format {
+				return format
+			}
+		}
+	}
+
+	return ""
+}
+
+// Handler to render the invoice of an order as HTML, PDF or JSON
+func (h *Handler) GetOrderInvoice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["orderId"]
+
+	log.Info().Str("order_id", idStr).Msg("GET /api/orders/{ID}/invoice - Rendering invoice")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		log.Warn().Str("id", idStr).Msg("Invalid order ID format")
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	format := negotiateInvoiceFormat(r)
+	if format == "" {
+		writeErrorResponse(w, http.StatusNotAcceptable, "Invoices are available as text/html, application/pdf or application/json")
+		return
+	}
+
+	order, err := h.db.GetOrderById(id)
+	if err != nil {
+		if err.Error() == "order not found" {
+			log.Warn().Int("ID", id).Msg("Order not found")
+			writeErrorResponse(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		log.Error().Err(err).Int("order_id", id).Msg("Error getting order")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the order")
+		return
+	}
+
+	// Orders without a customer, or whose customer is gone, are billed to their ship-to
+	var customer *model.Customer
+	if order.CustomerId.Valid {
+		customer, err = h.db.GetCustomerById(order.CustomerId.V)
+		if err != nil && err.Error() == "customer not found" {
+			log.Warn().Int("order_id", id).Str("customer_id", order.CustomerId.V).Msg("Order customer not found, billing the ship-to")
+			customer = nil
+		} else if err != nil {
+			log.Error().Err(err).Int("order_id", id).Str("customer_id", order.CustomerId.V).Msg("Error getting order customer")
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the customer for the order")
+			return
+		}
+	}
+
+	lines, err := h.db.GetOrderLines(id)
+	if err != nil {
+		log.Error().Err(err).Int("order_id", id).Msg("Error getting order lines")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the order lines")
+		return
+	}
+
+	inv := invoice.Build(order, customer, lines)
+
+	if format == "application/json" {
+		writeJSONResponse(w, http.StatusOK, inv)
+		return
+	}
+
+	// Render into a buffer first so template errors can still produce an error response
+	var buf bytes.Buffer
+	if format == "application/pdf" {
+		err = h.invoices.PDF(&buf, inv)
+	} else {
+		err = h.invoices.HTML(&buf, inv)
+		format = "text/html; charset=utf-8"
+	}
+	if err != nil {
+		log.Error().Err(err).Int("order_id", id).Msg("Error rendering invoice")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to render the invoice")
+		return
+	}
+
+	log.Info().Int("order_id", id).Str("format", format).Msg("Successfully rendered invoice")
+
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("Vary", "Accept")
+	if strings.HasPrefix(format, "application/pdf") {
+		w.Header().Set("Content-Disposition", "inline; filename=\""+inv.InvoiceNumber+".pdf\"")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
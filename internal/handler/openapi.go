@@ -0,0 +1,456 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"northwind-api/internal/events"
+	"northwind-api/internal/graphql"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/openapi"
+	"northwind-api/internal/patch"
+	"northwind-api/internal/spreadsheet"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region OpenAPI
+
+// Response bodies that handlers write as maps, named so they can be documented
+type (
+	createdResponse struct {
+		Id      int    `json:"id"`
+		Message string `json:"message"`
+	}
+	messageResponse struct {
+		Message string `json:"message"`
+	}
+	exchangeRatesResponse struct {
+		Base  string               `json:"base"`
+		Rates []model.ExchangeRate `json:"rates"`
+	}
+	savedRatesResponse struct {
+		Count   int    `json:"count"`
+		Message string `json:"message"`
+	}
+	graphqlResponse struct {
+		Data   json.RawMessage `json:"data,omitempty"`
+		Errors []graphql.Error `json:"errors,omitempty"`
+	}
+
+	// A product or order with the fields picked with ?fields= and the
+	// relations embedded with ?include=
+	productResponse struct {
+		model.Products `openapi:"partial"`
+		Category       *model.Category  `json:"category,omitempty"`
+		Supplier       *model.Suppliers `json:"supplier,omitempty"`
+	}
+	orderResponse struct {
+		model.Orders `openapi:"partial"`
+		Customer     *model.Customer   `json:"customer,omitempty"`
+		Employee     *model.Employees  `json:"employee,omitempty"`
+		Shipper      *model.Shippers   `json:"shipper,omitempty"`
+		Lines        []model.OrderLine `json:"lines,omitempty"`
+	}
+)
+
+// page documents a PagedResponse whose data is a list of T
+type page[T any] struct {
+	Data     []T `json:"data"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+// Query parameters shared by several routes
+var (
+	pageParams = []openapi.Param{
+		{Name: "page", In: "query", Type: 0, Description: "Page number, starting at 1"},
+		{Name: "page_size", In: "query", Type: 0, Description: "Results per page, at most 100"},
+	}
+	exportParams = []openapi.Param{
+		{Name: "format", In: "query", Type: "", Enum: []string{formatJSON, formatCSV, formatXLSX, formatNDJSON},
+			Description: "Response format; the Accept header is used when it is not given"},
+		{Name: "columns", In: "query", Type: "", Description: "Comma separated columns to export, in order"},
+	}
+	bulkParams = []openapi.Param{
+		{Name: "mode", In: "query", Type: "", Enum: []string{bulkAtomic, bulkBestEffort}},
+	}
+	importParams = []openapi.Param{
+		{Name: "resource", In: "path", Type: "", Enum: []string{"categories", "products", "suppliers"}},
+		{Name: "mode", In: "query", Type: "", Enum: []string{bulkAtomic, bulkBestEffort}},
+		{Name: "dry_run", In: "query", Type: false},
+		{Name: "mapping", In: "query", Type: "", Description: "JSON object mapping file headers to fields"},
+	}
+	customerParam = openapi.Param{Name: "customerId", In: "path", Type: ""}
+	fieldsParam   = openapi.Param{Name: "fields", In: "query", Type: "",
+		Description: "Comma separated fields to return, in order; every field when not given"}
+	productIncludeParam = openapi.Param{Name: "include", In: "query", Type: "",
+		Description: "Comma separated relations to embed: category, supplier"}
+	orderIncludeParam = openapi.Param{Name: "include", In: "query", Type: "",
+		Description: "Comma separated relations to embed: customer, employee, shipper, lines"}
+)
+
+// The multipart form an import file may be sent in
+var importForm = &openapi.Schema{
+	Type: "object",
+	Properties: map[string]*openapi.Schema{
+		"file":    openapi.Binary,
+		"mapping": {Type: "string", Description: "JSON object mapping file headers to fields"},
+		"mode":    {Type: "string", Enum: []any{bulkAtomic, bulkBestEffort}},
+		"dry_run": {Type: "boolean"},
+	},
+	Required: []string{"file"},
+}
+
+func jsonContent(v any) openapi.Content {
+	return openapi.Content{"application/json": v}
+}
+
+func jsonResponse(v any) map[int]openapi.Content {
+	return map[int]openapi.Content{http.StatusOK: jsonContent(v)}
+}
+
+// exportResponse documents a list response that exportList can also write as
+// CSV, XLSX or NDJSON. list is the JSON response and item a single row.
+func exportResponse(list, item any) map[int]openapi.Content {
+	return map[int]openapi.Content{http.StatusOK: {
+		"application/json":   list,
+		spreadsheet.CSVType:  openapi.Text,
+		spreadsheet.XLSXType: openapi.Binary,
+		NDJSONType:           item,
+	}}
+}
+
+// patchContent documents a PATCH body: a merge patch of v or a JSON Patch
+func patchContent(v any) openapi.Content {
+	return openapi.Content{patch.MergePatchType: v, patch.JSONPatchType: []patch.Operation(nil)}
+}
+
+// bulkContent documents a bulk request, a JSON array or NDJSON stream of items
+func bulkContent() openapi.Content {
+	return openapi.Content{"application/json": []bulkRequestItem(nil), NDJSONType: bulkRequestItem{}}
+}
+
+func bulkResponses() map[int]openapi.Content {
+	body := jsonContent(BulkResponse{})
+	return map[int]openapi.Content{
+		http.StatusOK:                  body,
+		http.StatusMultiStatus:         body,
+		http.StatusUnprocessableEntity: body,
+	}
+}
+
+// apiRoutes documents every route setupRouter registers
+func apiRoutes() []openapi.Route {
+	var (
+		categories = []model.Category(nil)
+		orders     = []model.Orders(nil)
+	)
+
+	return []openapi.Route{
+		// Documentation
+		{Method: "GET", Path: "/api/openapi.json", Id: "GetOpenAPI", Tag: "Documentation",
+			Summary:   "Get this OpenAPI document",
+			Responses: map[int]openapi.Content{http.StatusOK: {"application/json": &openapi.Schema{Type: "object"}}}},
+		{Method: "GET", Path: "/api/docs", Id: "GetAPIDocs", Tag: "Documentation",
+			Summary:   "Browse this document",
+			Responses: map[int]openapi.Content{http.StatusOK: {"text/html": openapi.Text}}},
+
+		// GraphQL
+		{Method: "GET", Path: "/graphql", Id: "GraphQLQuery", Tag: "GraphQL",
+			Summary: "Run a GraphQL query",
+			Params: []openapi.Param{
+				{Name: "query", In: "query", Type: "", Required: true},
+				{Name: "operationName", In: "query", Type: ""},
+				{Name: "variables", In: "query", Type: "", Description: "JSON object of variable values"},
+			},
+			Responses: map[int]openapi.Content{
+				http.StatusOK:               jsonContent(graphqlResponse{}),
+				http.StatusMethodNotAllowed: jsonContent(graphqlResponse{}),
+			}},
+		{Method: "POST", Path: "/graphql", Id: "GraphQL", Tag: "GraphQL",
+			Summary: "Run a GraphQL query or mutation", Body: jsonContent(graphqlRequest{}),
+			Responses: jsonResponse(graphqlResponse{})},
+
+		// Search
+		{Method: "GET", Path: "/api/search", Id: "SearchProducts", Tag: "Search",
+			Summary: "Search the catalogue by product, category and supplier names",
+			Params: append([]openapi.Param{
+				{Name: "q", In: "query", Type: "", Required: true},
+				{Name: "category_id", In: "query", Type: 0},
+				{Name: "supplier_id", In: "query", Type: 0},
+				{Name: "min_price", In: "query", Type: money.Money(0)},
+				{Name: "max_price", In: "query", Type: money.Money(0)},
+			}, pageParams...),
+			Responses: jsonResponse(model.SearchResults{})},
+
+		// Categories
+		{Method: "GET", Path: "/api/categories", Id: "GetCategories", Tag: "Categories",
+			Summary: "List the categories", Params: exportParams,
+			Responses: exportResponse(categories, model.Category{})},
+		{Method: "GET", Path: "/api/categories/{categoryId}", Id: "GetCategoryById", Tag: "Categories",
+			Summary: "Get a category", Responses: jsonResponse(model.Category{})},
+		{Method: "POST", Path: "/api/categories", Id: "CreateCategory", Tag: "Categories",
+			Summary: "Create a category", Body: jsonContent(categoryRequest{}),
+			Responses: jsonResponse(createdResponse{})},
+		{Method: "POST", Path: "/api/categories/bulk", Id: "BulkCategories", Tag: "Categories",
+			Summary: "Create, update and delete categories in bulk", Params: bulkParams,
+			Body: bulkContent(), Responses: bulkResponses()},
+		{Method: "PUT", Path: "/api/categories/{categoryId}", Id: "UpdateCategory", Tag: "Categories",
+			Summary: "Replace a category", Body: jsonContent(categoryRequest{}),
+			Responses: jsonResponse(messageResponse{})},
+		{Method: "PATCH", Path: "/api/categories/{categoryId}", Id: "PatchCategory", Tag: "Categories",
+			Summary: "Patch a category", Body: patchContent(model.Category{}),
+			Responses: jsonResponse(model.Category{})},
+		{Method: "DELETE", Path: "/api/categories/{categoryId}", Id: "DeleteCategory", Tag: "Categories",
+			Summary: "Delete a category", Responses: jsonResponse(messageResponse{})},
+
+		// Customers
+		{Method: "PATCH", Path: "/api/customers/{customerId}", Id: "PatchCustomer", Tag: "Customers",
+			Summary: "Patch a customer", Params: []openapi.Param{customerParam},
+			Body: patchContent(model.Customer{}), Responses: jsonResponse(model.Customer{})},
+		{Method: "GET", Path: "/api/customers/{customerId}/orders", Id: "GetCustomerOrders", Tag: "Customers",
+			Summary: "List a customer's orders",
+			Params: append(append([]openapi.Param{customerParam, fieldsParam, orderIncludeParam}, pageParams...),
+				exportParams...),
+			Responses: exportResponse(page[orderResponse]{}, model.Orders{})},
+		{Method: "GET", Path: "/api/customers/{customerId}/statement", Id: "GetCustomerStatement", Tag: "Customers",
+			Summary: "Get a customer's account statement",
+			Params: []openapi.Param{
+				customerParam,
+				{Name: "top", In: "query", Type: 0, Description: "Number of top products, at most 100"},
+				{Name: "currency", In: "query", Type: "", Description: "ISO 4217 code to convert the statement to"},
+			},
+			Responses: jsonResponse(model.CustomerStatement{})},
+
+		// Employees and suppliers
+		{Method: "PATCH", Path: "/api/employees/{employeeId}", Id: "PatchEmployee", Tag: "Employees",
+			Summary: "Patch an employee", Body: patchContent(model.Employees{}),
+			Responses: jsonResponse(model.Employees{})},
+		{Method: "PATCH", Path: "/api/suppliers/{supplierId}", Id: "PatchSupplier", Tag: "Suppliers",
+			Summary: "Patch a supplier", Body: patchContent(model.Suppliers{}),
+			Responses: jsonResponse(model.Suppliers{})},
+
+		// Products
+		{Method: "GET", Path: "/api/products", Id: "GetProducts", Tag: "Products",
+			Summary: "List every product",
+			Params: append(append([]openapi.Param{fieldsParam, productIncludeParam}, pageParams...),
+				exportParams...),
+			Responses: exportResponse(page[productResponse]{}, model.Products{})},
+		{Method: "GET", Path: "/api/products/{productId}", Id: "GetProductById", Tag: "Products",
+			Summary: "Get a product", Params: []openapi.Param{fieldsParam, productIncludeParam},
+			Responses: jsonResponse(productResponse{})},
+		{Method: "POST", Path: "/api/products/bulk", Id: "BulkProducts", Tag: "Products",
+			Summary: "Create, update and delete products in bulk", Params: bulkParams,
+			Body: bulkContent(), Responses: bulkResponses()},
+		{Method: "PATCH", Path: "/api/products/{productId}", Id: "PatchProduct", Tag: "Products",
+			Summary: "Patch a product", Body: patchContent(model.Products{}),
+			Responses: jsonResponse(model.Products{})},
+		{Method: "GET", Path: "/api/products/{productId}/prices", Id: "GetProductPrices", Tag: "Prices",
+			Summary: "List a product's price history", Params: exportParams,
+			Responses: exportResponse([]model.ProductPrice(nil), model.ProductPrice{})},
+		{Method: "POST", Path: "/api/products/{productId}/prices", Id: "ScheduleProductPrice", Tag: "Prices",
+			Summary: "Schedule a new price for a product", Body: jsonContent(priceScheduleRequest{}),
+			Responses: jsonResponse(model.PriceChange{})},
+		{Method: "POST", Path: "/api/prices/adjustments", Id: "AdjustPrices", Tag: "Prices",
+			Summary: "Schedule a percentage price change across a category and/or supplier",
+			Body:    jsonContent(priceAdjustmentRequest{}), Responses: jsonResponse([]model.PriceChange(nil))},
+
+		// Shippers and freight
+		{Method: "PATCH", Path: "/api/shippers/{shipperId}", Id: "PatchShipper", Tag: "Shippers",
+			Summary: "Patch a shipper", Body: patchContent(model.Shippers{}),
+			Responses: jsonResponse(model.Shippers{})},
+		{Method: "GET", Path: "/api/shippers/{shipperId}/rates", Id: "GetFreightRates", Tag: "Freight",
+			Summary: "List a shipper's freight rates", Params: exportParams,
+			Responses: exportResponse([]model.FreightRate(nil), model.FreightRate{})},
+		{Method: "POST", Path: "/api/shippers/{shipperId}/rates", Id: "CreateFreightRate", Tag: "Freight",
+			Summary: "Add a band to a shipper's freight rate table", Body: jsonContent(freightRateRequest{}),
+			Responses: jsonResponse(createdResponse{})},
+		{Method: "PATCH", Path: "/api/shippers/{shipperId}/rates/{rateId}", Id: "PatchFreightRate", Tag: "Freight",
+			Summary: "Patch a freight rate", Body: patchContent(model.FreightRate{}),
+			Responses: jsonResponse(model.FreightRate{})},
+		{Method: "DELETE", Path: "/api/shippers/{shipperId}/rates/{rateId}", Id: "DeleteFreightRate", Tag: "Freight",
+			Summary: "Remove a band from a shipper's freight rate table", Responses: jsonResponse(messageResponse{})},
+		{Method: "POST", Path: "/api/freight/quote", Id: "QuoteFreight", Tag: "Freight",
+			Summary: "Quote freight for a basket with one or all shippers", Body: jsonContent(freightQuoteRequest{}),
+			Responses: jsonResponse([]model.FreightQuote(nil))},
+
+		// Exchange rates
+		{Method: "GET", Path: "/api/exchange-rates", Id: "GetExchangeRates", Tag: "Exchange rates",
+			Summary: "List the exchange rates in effect",
+			Params: append([]openapi.Param{
+				{Name: "at", In: "query", Type: "", Description: "Date (YYYY-MM-DD) or RFC 3339 time the rates are in effect at"},
+			}, exportParams...),
+			Responses: exportResponse(exchangeRatesResponse{}, model.ExchangeRate{})},
+		{Method: "POST", Path: "/api/exchange-rates", Id: "SaveExchangeRates", Tag: "Exchange rates",
+			Summary: "Load exchange rates",
+			Body: openapi.Content{
+				"application/json":  []model.ExchangeRate(nil),
+				spreadsheet.CSVType: openapi.Text,
+			},
+			Responses: jsonResponse(savedRatesResponse{})},
+
+		// Imports
+		{Method: "GET", Path: "/api/import/jobs/{jobId}", Id: "GetImportJob", Tag: "Imports",
+			Summary:   "Get the progress of an import",
+			Params:    []openapi.Param{{Name: "jobId", In: "path", Type: ""}},
+			Responses: jsonResponse(ImportJob{})},
+		{Method: "POST", Path: "/api/import/{resource}", Id: "ImportResource", Tag: "Imports",
+			Summary: "Import categories, products or suppliers from a CSV or XLSX file", Params: importParams,
+			Body: openapi.Content{
+				spreadsheet.CSVType:   openapi.Text,
+				spreadsheet.XLSXType:  openapi.Binary,
+				"multipart/form-data": importForm,
+			},
+			Responses: map[int]openapi.Content{
+				http.StatusOK:       jsonContent(ImportJob{}),
+				http.StatusAccepted: jsonContent(ImportJob{}),
+			}},
+
+		// Promotions
+		{Method: "GET", Path: "/api/promotions", Id: "GetPromotions", Tag: "Promotions",
+			Summary: "List the promotions", Params: exportParams,
+			Responses: exportResponse([]model.Promotion(nil), model.Promotion{})},
+		{Method: "GET", Path: "/api/promotions/{promotionId}", Id: "GetPromotionById", Tag: "Promotions",
+			Summary: "Get a promotion", Responses: jsonResponse(model.Promotion{})},
+		{Method: "POST", Path: "/api/promotions", Id: "CreatePromotion", Tag: "Promotions",
+			Summary: "Create a promotion", Body: jsonContent(promotionRequest{}),
+			Responses: jsonResponse(createdResponse{})},
+		{Method: "PATCH", Path: "/api/promotions/{promotionId}", Id: "PatchPromotion", Tag: "Promotions",
+			Summary: "Patch a promotion", Body: patchContent(model.Promotion{}),
+			Responses: jsonResponse(model.Promotion{})},
+		{Method: "DELETE", Path: "/api/promotions/{promotionId}", Id: "DeletePromotion", Tag: "Promotions",
+			Summary: "Delete a promotion", Responses: jsonResponse(messageResponse{})},
+
+		// Orders
+		{Method: "GET", Path: "/api/orders", Id: "GetOrders", Tag: "Orders",
+			Summary: "List every order, oldest first",
+			Params: append(append([]openapi.Param{fieldsParam, orderIncludeParam}, pageParams...),
+				exportParams...),
+			Responses: exportResponse(page[orderResponse]{}, model.Orders{})},
+		{Method: "POST", Path: "/api/orders", Id: "CreateOrder", Tag: "Orders",
+			Summary: "Place an order", Body: jsonContent(orderRequest{}),
+			Responses: jsonResponse(createdResponse{})},
+		{Method: "POST", Path: "/api/orders/quote", Id: "QuoteOrder", Tag: "Orders",
+			Summary: "Price a basket without placing the order", Body: jsonContent(orderRequest{}),
+			Responses: jsonResponse(model.Quote{})},
+		{Method: "GET", Path: "/api/orders/late", Id: "GetLateOrders", Tag: "Orders",
+			Summary: "List orders past their required date that have not shipped", Params: exportParams,
+			Responses: exportResponse(orders, model.Orders{})},
+		{Method: "GET", Path: "/api/orders/at-risk", Id: "GetAtRiskOrders", Tag: "Orders",
+			Summary: "List unshipped orders required within the next days",
+			Params: append([]openapi.Param{
+				{Name: "days", In: "query", Type: 0, Description: "Days ahead to look, by default MONITOR_AT_RISK_DAYS"},
+			}, exportParams...),
+			Responses: exportResponse(orders, model.Orders{})},
+		{Method: "GET", Path: "/api/orders/{orderId}", Id: "GetOrderById", Tag: "Orders",
+			Summary: "Get an order", Params: []openapi.Param{fieldsParam, orderIncludeParam},
+			Responses: jsonResponse(orderResponse{})},
+		{Method: "PATCH", Path: "/api/orders/{orderId}", Id: "PatchOrder", Tag: "Orders",
+			Summary: "Patch an order", Body: patchContent(model.Orders{}),
+			Responses: jsonResponse(model.Orders{})},
+		{Method: "GET", Path: "/api/orders/{orderId}/invoice", Id: "GetOrderInvoice", Tag: "Orders",
+			Summary: "Render the invoice of an order",
+			Params: []openapi.Param{
+				{Name: "format", In: "query", Type: "", Enum: []string{"html", "pdf", "json"},
+					Description: "Invoice format; the Accept header is used when it is not given"},
+			},
+			Responses: map[int]openapi.Content{http.StatusOK: {
+				"text/html":        openapi.Text,
+				"application/pdf":  openapi.Binary,
+				"application/json": model.Invoice{},
+			}}},
+
+		// Webhooks
+		{Method: "GET", Path: "/api/webhooks", Id: "GetWebhooks", Tag: "Webhooks",
+			Summary: "List the registered webhooks", Responses: jsonResponse([]model.Webhook(nil))},
+		{Method: "POST", Path: "/api/webhooks", Id: "CreateWebhook", Tag: "Webhooks",
+			Summary: "Register a URL to be sent the events its filters match; the response holds the signing secret",
+			Body:    jsonContent(webhookRequest{}), Responses: jsonResponse(createdWebhook{})},
+		{Method: "GET", Path: "/api/webhooks/dead-letters", Id: "GetDeadDeliveries", Tag: "Webhooks",
+			Summary: "List the deliveries that ran out of attempts, newest first", Params: pageParams,
+			Responses: jsonResponse(page[model.WebhookDelivery]{})},
+		{Method: "POST", Path: "/api/webhooks/deliveries/{deliveryId}/redeliver", Id: "RedeliverWebhookDelivery", Tag: "Webhooks",
+			Summary:   "Queue a dead or delivered delivery to be sent again with a fresh set of attempts",
+			Responses: map[int]openapi.Content{http.StatusAccepted: jsonContent(model.WebhookDelivery{})}},
+		{Method: "GET", Path: "/api/webhooks/{webhookId}", Id: "GetWebhookById", Tag: "Webhooks",
+			Summary: "Get a webhook", Responses: jsonResponse(model.Webhook{})},
+		{Method: "DELETE", Path: "/api/webhooks/{webhookId}", Id: "DeleteWebhook", Tag: "Webhooks",
+			Summary: "Delete a webhook and its deliveries", Responses: jsonResponse(messageResponse{})},
+		{Method: "GET", Path: "/api/webhooks/{webhookId}/deliveries", Id: "GetWebhookDeliveries", Tag: "Webhooks",
+			Summary: "List a webhook's deliveries, newest first", Params: pageParams,
+			Responses: jsonResponse(page[model.WebhookDelivery]{})},
+
+		// Events
+		{Method: "GET", Path: "/api/events", Id: "StreamEvents", Tag: "Events",
+			Summary: "Stream change events as server-sent events, resuming after the Last-Event-ID header",
+			Params: []openapi.Param{
+				{Name: "resource", In: "query", Type: "", Description: "Comma separated resources to stream the events of: " + strings.Join(events.Resources(), ", ")},
+				{Name: "id", In: "query", Type: "", Description: "Comma separated resource ids to stream the events of"},
+				{Name: "Last-Event-ID", In: "header", Type: "", Description: "Id of the last event received, to be sent the events since"},
+			},
+			Responses: map[int]openapi.Content{http.StatusOK: {eventStreamType: openapi.Text}}},
+	}
+}
+
+// DocumentRoutes builds the OpenAPI document of the routes registered on a
+// router, returning the routes that are registered without being documented
+// in apiRoutes, or documented without being registered. Those left out of the
+// document are still served, just not validated.
+func (h *Handler) DocumentRoutes(router *mux.Router) ([]string, error) {
+	routes := apiRoutes()
+	// Handlers that decode JSON answer invalid fields with a 422
+	for i, route := range routes {
+		_, isJSON := route.Body["application/json"]
+		_, isPatch := route.Body[patch.MergePatchType]
+		if _, listed := route.Responses[http.StatusUnprocessableEntity]; (isJSON || isPatch) && !listed {
+			routes[i].Responses[http.StatusUnprocessableEntity] = jsonContent(ValidationErrorResponse{})
+		}
+	}
+
+	doc, problems, err := openapi.Build(openapi.Spec{
+		Info: openapi.Info{
+			Title:       "Northwind API",
+			Version:     "1.0.0",
+			Description: "REST API for the Northwind grocery store database",
+		},
+		Routes:  routes,
+		Default: jsonContent(ErrorResponse{}),
+	}, router)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	h.openapi, h.openapiJSON = doc, spec
+	return problems, nil
+}
+
+// OpenAPI returns the document built by DocumentRoutes
+func (h *Handler) OpenAPI() *openapi.Document {
+	return h.openapi
+}
+
+// Handler to get the OpenAPI document of the API
+func (h *Handler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/openapi.json - Getting the OpenAPI document")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.openapiJSON)
+}
+
+// Handler to browse the OpenAPI document
+func (h *Handler) GetAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openapi.DocsPage)
+}
+
+// #endregion
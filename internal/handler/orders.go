@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Orders
+
+// Handler to get a page of every order, oldest first
+func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/orders - Getting orders")
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if exportList(w, r, "orders", h.db.StreamOrders) {
+		return
+	}
+
+	projection, err := parseProjection(r, orderEmbeddings)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	orders, total, err := h.db.GetOrders(pageSize, (page-1)*pageSize, projection.columns()...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get orders")
+		return
+	}
+
+	data, err := projection.apply(h.db, orders)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting the relations of orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get orders")
+		return
+	}
+
+	log.Info().Int("count", len(orders)).Int("total", total).Msg("Successfully retrieved orders")
+	writeJSONResponse(w, http.StatusOK, PagedResponse{
+		Data:     data,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Handler to get an order by its ID
+func (h *Handler) GetOrderById(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["orderId"]
+
+	log.Info().Str("order_id", idStr).Msg("GET /api/orders/{ID} - Getting order by ID")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	projection, err := parseProjection(r, orderEmbeddings)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := h.db.GetOrderById(id, projection.columns()...)
+	if err != nil {
+		if err.Error() == "order not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		log.Error().Err(err).Int("order_id", id).Msg("Error getting order")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the order")
+		return
+	}
+
+	data, err := projection.applyOne(h.db, order)
+	if err != nil {
+		log.Error().Err(err).Int("order_id", id).Msg("Error getting the relations of an order")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the order")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, data)
+}
+
+// Handler to get orders past their required date that have not shipped
+func (h *Handler) GetLateOrders(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/orders/late - Getting late orders")
+
+	now := time.Now()
+	if exportList(w, r, "late-orders", func() (*repository.Cursor[model.Orders], error) {
+		return h.db.StreamLateOrders(now)
+	}) {
+		return
+	}
+
+	orders, err := h.db.GetLateOrders(now)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting late orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get late orders")
+		return
+	}
+
+	log.Info().Int("count", len(orders)).Msg("Successfully retrieved late orders")
+	writeJSONResponse(w, http.StatusOK, orders)
+}
+
+// Handler to get unshipped orders required within the next ?days= days
+func (h *Handler) GetAtRiskOrders(w http.ResponseWriter, r *http.Request) {
+	days := h.config.MonitorAtRiskDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeErrorResponse(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = n
+	}
+
+	log.Info().Int("days", days).Msg("GET /api/orders/at-risk - Getting at-risk orders")
+
+	now := time.Now()
+	if exportList(w, r, "at-risk-orders", func() (*repository.Cursor[model.Orders], error) {
+		return h.db.StreamAtRiskOrders(now, days)
+	}) {
+		return
+	}
+
+	orders, err := h.db.GetAtRiskOrders(now, days)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting at-risk orders")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get at-risk orders")
+		return
+	}
+
+	log.Info().Int("count", len(orders)).Msg("Successfully retrieved at-risk orders")
+	writeJSONResponse(w, http.StatusOK, orders)
+}
+
+// Request body for placing or quoting an order
+type orderRequest struct {
+	CustomerId     string       `json:"customer_id" validate:"required,max=5"`
+	EmployeeId     int          `json:"employee_id" validate:"min=0"`
+	OrderDate      time.Time    `json:"order_date"`
+	RequiredDate   time.Time    `json:"required_date" validate:"gtefield=order_date"`
+	ShipVia        int          `json:"ship_via" validate:"min=0"`
+	Freight        *money.Money `json:"freight" validate:"min=0"`
+	ShipName       string       `json:"ship_name" validate:"max=40"`
+	ShipAddress    string       `json:"ship_address" validate:"max=60"`
+	Region         string       `json:"region" validate:"max=60"`
+	ShipCity       string       `json:"ship_city" validate:"max=15"`
+	ShipPostalCode string       `json:"ship_postal_code" validate:"max=10"`
+	ShipCountry    string       `json:"ship_country" validate:"max=15,country"`
+	Lines          []orderLine  `json:"lines" validate:"required"`
+}
+
+// A line of an order request
+type orderLine struct {
+	ProductId int `json:"product_id" validate:"gt=0"`
+	Quantity  int `json:"quantity" validate:"min=1,max=32767"`
+}
+
+// Converts the request into the order header and lines the repository expects.
+// autoFreight is true when the request leaves freight to be rated.
+func (req *orderRequest) toModel() (order model.Orders, lines []model.OrderDetails, autoFreight bool) {
+	order = model.Orders{
+		CustomerId:     model.NullIfZero(req.CustomerId),
+		EmployeeId:     model.NullIfZero(req.EmployeeId),
+		OrderDate:      model.NullIfZero(req.OrderDate),
+		RequiredDate:   model.NullIfZero(req.RequiredDate),
+		ShipVia:        model.NullIfZero(req.ShipVia),
+		Freight:        model.NullFromPtr(req.Freight),
+		ShipName:       model.NullIfZero(req.ShipName),
+		ShipAddress:    model.NullIfZero(req.ShipAddress),
+		Region:         model.NullIfZero(req.Region),
+		ShipCity:       model.NullIfZero(req.ShipCity),
+		ShipPostalCode: model.NullIfZero(req.ShipPostalCode),
+		ShipCountry:    model.NullIfZero(req.ShipCountry),
+	}
+
+	lines = make([]model.OrderDetails, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, model.OrderDetails{ProductId: line.ProductId, Quantity: line.Quantity})
+	}
+
+	return order, lines, req.Freight == nil
+}
+
+// Decodes an order request, writing an error response and returning false if it is invalid
+func (h *Handler) decodeOrderRequest(w http.ResponseWriter, r *http.Request) (*orderRequest, bool) {
+	var req orderRequest
+	if !h.decodeJSON(w, r, &req) {
+		return nil, false
+	}
+
+	if !validRequest(w, validate.Struct(&req)) {
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// Handler to place a new order
+func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("POST /api/orders - Placing order")
+
+	req, ok := h.decodeOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, lines, autoFreight := req.toModel()
+	orderId, err := h.db.CreateOrder(order, lines, autoFreight)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid order") {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().Err(err).Str("customer_id", req.CustomerId).Msg("Error creating order")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create the order")
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":      orderId,
+		"message": "Order created successfully",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// Handler to price a basket without placing the order
+func (h *Handler) QuoteOrder(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("POST /api/orders/quote - Quoting order")
+
+	req, ok := h.decodeOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, lines, autoFreight := req.toModel()
+	quote, err := h.db.QuoteOrder(order, lines, autoFreight)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid order") {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().Err(err).Str("customer_id", req.CustomerId).Msg("Error quoting order")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to quote the order")
+		return
+	}
+
+	log.Info().Str("customer_id", req.CustomerId).Stringer("total", quote.Total).Msg("Successfully quoted order")
+	writeJSONResponse(w, http.StatusOK, quote)
+}
+
+// #endregion
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/patch"
+	"northwind-api/internal/validate"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Patch
+
+// patchResource handles a PATCH request for one resource. The body is an RFC
+// 7396 merge patch or, with Content-Type application/json-patch+json, an RFC
+// 6902 JSON Patch. The repository loads the current row and calls back to
+// apply the patch; only the fields the patch touches are validated, by their
+// `validate:` tags and then by check, which may be nil.
+func patchResource[T any](h *Handler, w http.ResponseWriter, r *http.Request, noun string,
+	check func(v *T, fields map[string]bool) validate.Errors,
+	update func(apply func(*T) (*T, error)) (*T, error)) {
+
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := patch.Parse(r.Header.Get("Content-Type"), body)
+	if errors.Is(err, patch.ErrUnsupportedMediaType) {
+		writeErrorResponse(w, http.StatusUnsupportedMediaType,
+			"Content-Type must be "+patch.MergePatchType+" or "+patch.JSONPatchType)
+		return
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		writeErrorResponse(w, http.StatusBadRequest, describeJSONError(body, err))
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := update(applyPatch(doc, check))
+	if err != nil {
+		var errs validate.Errors
+		switch {
+		case errors.As(err, &errs):
+			validRequest(w, errs)
+		case err.Error() == noun+" not found":
+			writeErrorResponse(w, http.StatusNotFound, strings.ToUpper(noun[:1])+noun[1:]+" not found")
+		case strings.HasPrefix(err.Error(), "invalid patch: "):
+			writeErrorResponse(w, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "invalid patch: "))
+		default:
+			log.Error().Err(err).Msg("Error patching " + noun)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to update the "+noun)
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, updated)
+}
+
+// applyPatch returns the callback the repository patches a row through: it
+// applies the patch and validates the fields it touched
+func applyPatch[T any](doc *patch.Document, check func(v *T, fields map[string]bool) validate.Errors) func(*T) (*T, error) {
+	return func(current *T) (*T, error) {
+		next, err := patch.Apply(doc, current)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: %w", err)
+		}
+		fields := doc.Fields()
+		errs := validate.Partial(next, fields)
+		if check != nil {
+			errs = append(errs, check(next, fields)...)
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		return next, nil
+	}
+}
+
+// patchIntId parses an integer route variable, writing an error response and returning false if it is invalid
+func patchIntId(w http.ResponseWriter, r *http.Request, name, noun string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid "+noun+" ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// checkEmployeePatch keeps an employee from reporting to themselves
+func checkEmployeePatch(e *model.Employees, fields map[string]bool) validate.Errors {
+	if fields["reports_to"] && e.ReportsTo.Valid && e.ReportsTo.V == e.EmployeeId {
+		return validate.Errors{{Field: "reports_to", Message: "must not be the employee themselves"}}
+	}
+	return nil
+}
+
+// checkPromotionPatch keeps a percent discount at most 100
+func checkPromotionPatch(p *model.Promotion, fields map[string]bool) validate.Errors {
+	if fields["discount_type"] || fields["discount_value"] {
+		return percentAtMost100(p.DiscountType, p.DiscountValue)
+	}
+	return nil
+}
+
+// Handler to patch a category
+func (h *Handler) PatchCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "categoryId", "category")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("category_id", id).Msg("PATCH /api/categories/{ID} - Patching category")
+
+	patchResource(h, w, r, "category", nil, func(apply func(*model.Category) (*model.Category, error)) (*model.Category, error) {
+		return h.db.PatchCategory(id, apply)
+	})
+}
+
+// Handler to patch a customer
+func (h *Handler) PatchCustomer(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["customerId"]
+
+	log.Info().Str("customer_id", id).Msg("PATCH /api/customers/{ID} - Patching customer")
+
+	patchResource(h, w, r, "customer", nil, func(apply func(*model.Customer) (*model.Customer, error)) (*model.Customer, error) {
+		return h.db.PatchCustomer(id, apply)
+	})
+}
+
+// Handler to patch an employee
+func (h *Handler) PatchEmployee(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "employeeId", "employee")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("employee_id", id).Msg("PATCH /api/employees/{ID} - Patching employee")
+
+	patchResource(h, w, r, "employee", checkEmployeePatch, func(apply func(*model.Employees) (*model.Employees, error)) (*model.Employees, error) {
+		return h.db.PatchEmployee(id, apply)
+	})
+}
+
+// Handler to patch an order's fulfilment details
+func (h *Handler) PatchOrder(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "orderId", "order")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("order_id", id).Msg("PATCH /api/orders/{ID} - Patching order")
+
+	patchResource(h, w, r, "order", nil, func(apply func(*model.Orders) (*model.Orders, error)) (*model.Orders, error) {
+		return h.db.PatchOrder(id, apply)
+	})
+}
+
+// Handler to patch a product
+func (h *Handler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "productId", "product")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("product_id", id).Msg("PATCH /api/products/{ID} - Patching product")
+
+	patchResource(h, w, r, "product", nil, func(apply func(*model.Products) (*model.Products, error)) (*model.Products, error) {
+		return h.db.PatchProduct(id, apply)
+	})
+}
+
+// Handler to patch a shipper
+func (h *Handler) PatchShipper(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "shipperId", "shipper")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("shipper_id", id).Msg("PATCH /api/shippers/{ID} - Patching shipper")
+
+	patchResource(h, w, r, "shipper", nil, func(apply func(*model.Shippers) (*model.Shippers, error)) (*model.Shippers, error) {
+		return h.db.PatchShipper(id, apply)
+	})
+}
+
+// Handler to patch a supplier
+func (h *Handler) PatchSupplier(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "supplierId", "supplier")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("supplier_id", id).Msg("PATCH /api/suppliers/{ID} - Patching supplier")
+
+	patchResource(h, w, r, "supplier", nil, func(apply func(*model.Suppliers) (*model.Suppliers, error)) (*model.Suppliers, error) {
+		return h.db.PatchSupplier(id, apply)
+	})
+}
+
+// Handler to patch a promotion
+func (h *Handler) PatchPromotion(w http.ResponseWriter, r *http.Request) {
+	id, ok := patchIntId(w, r, "promotionId", "promotion")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("promotion_id", id).Msg("PATCH /api/promotions/{ID} - Patching promotion")
+
+	patchResource(h, w, r, "promotion", checkPromotionPatch, func(apply func(*model.Promotion) (*model.Promotion, error)) (*model.Promotion, error) {
+		return h.db.PatchPromotion(id, apply)
+	})
+}
+
+// Handler to patch a band of a shipper's freight rate table
+func (h *Handler) PatchFreightRate(w http.ResponseWriter, r *http.Request) {
+	shipperId, ok := patchIntId(w, r, "shipperId", "shipper")
+	if !ok {
+		return
+	}
+	rateId, ok := patchIntId(w, r, "rateId", "rate")
+	if !ok {
+		return
+	}
+
+	log.Info().Int("shipper_id", shipperId).Int("rate_id", rateId).Msg("PATCH /api/shippers/{ID}/rates/{ID} - Patching freight rate")
+
+	patchResource(h, w, r, "freight rate", nil, func(apply func(*model.FreightRate) (*model.FreightRate, error)) (*model.FreightRate, error) {
+		return h.db.PatchFreightRate(shipperId, rateId, apply)
+	})
+}
+
+// #endregion
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"northwind-api/internal/validate"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Prices
+
+// Handler to get the price history of a product
+func (h *Handler) GetProductPrices(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["productId"]
+
+	log.Info().Str("product_id", idStr).Msg("GET /api/products/{ID}/prices - Getting product price history")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	if exportList(w, r, fmt.Sprintf("product-%d-prices", id), func() (*repository.Cursor[model.ProductPrice], error) {
+		return h.db.StreamProductPrices(id)
+	}) {
+		return
+	}
+
+	prices, err := h.db.GetProductPrices(id)
+	if err != nil {
+		if err.Error() == "product not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		log.Error().Err(err).Int("product_id", id).Msg("Error getting product prices")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the product prices")
+		return
+	}
+
+	log.Info().Int("product_id", id).Int("count", len(prices)).Msg("Successfully retrieved product prices")
+	writeJSONResponse(w, http.StatusOK, prices)
+}
+
+// Request body for scheduling a product price. The price takes effect now
+// unless effective_from is given, which must not be in the past.
+type priceScheduleRequest struct {
+	UnitPrice     money.Money `json:"unit_price" validate:"min=0"`
+	EffectiveFrom *time.Time  `json:"effective_from"`
+}
+
+// Validates the request, including the rules its tags cannot express
+func (req *priceScheduleRequest) check() validate.Errors {
+	return append(validate.Struct(req), checkEffectiveFrom(req.EffectiveFrom)...)
+}
+
+// Handler to schedule a new price for a product
+func (h *Handler) ScheduleProductPrice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["productId"]
+
+	log.Info().Str("product_id", idStr).Msg("POST /api/products/{ID}/prices - Scheduling product price")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var req priceScheduleRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, req.check()) {
+		return
+	}
+
+	change, err := h.db.ScheduleProductPrice(id, req.UnitPrice, effectiveFrom(req.EffectiveFrom))
+	if err != nil {
+		if err.Error() == "product not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid price change") {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().Err(err).Int("product_id", id).Msg("Error scheduling product price")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to schedule the product price")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, change)
+}
+
+// Request body for a percentage price change across a category and/or supplier
+type priceAdjustmentRequest struct {
+	CategoryId    int         `json:"category_id" validate:"min=0"`
+	SupplierId    int         `json:"supplier_id" validate:"min=0"`
+	Percent       money.Money `json:"percent" validate:"gt=-100"`
+	EffectiveFrom *time.Time  `json:"effective_from"`
+}
+
+// Validates the request, including the rules its tags cannot express
+func (req *priceAdjustmentRequest) check() validate.Errors {
+	errs := validate.Struct(req)
+	if req.CategoryId == 0 && req.SupplierId == 0 {
+		errs = append(errs, validate.FieldError{Field: "category_id", Message: "is required when supplier_id is not given"})
+	}
+	if req.Percent.IsZero() {
+		errs = append(errs, validate.FieldError{Field: "percent", Message: "must not be zero"})
+	}
+	return append(errs, checkEffectiveFrom(req.EffectiveFrom)...)
+}
+
+// checkEffectiveFrom refuses a price change taking effect in the past, which
+// would rewrite the prices past orders were placed at
+func checkEffectiveFrom(t *time.Time) validate.Errors {
+	if t != nil && t.Before(time.Now().Add(-repository.BackdateTolerance)) {
+		return validate.Errors{{Field: "effective_from", Message: "must not be in the past"}}
+	}
+	return nil
+}
+
+// effectiveFrom returns when a price change takes effect, now unless the request says otherwise
+func effectiveFrom(t *time.Time) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return *t
+}
+
+// Handler to schedule a percentage price change across a category and/or supplier
+func (h *Handler) AdjustPrices(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("POST /api/prices/adjustments - Scheduling price adjustment")
+
+	var req priceAdjustmentRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, req.check()) {
+		return
+	}
+
+	changes, err := h.db.AdjustPrices(req.CategoryId, req.SupplierId, req.Percent, effectiveFrom(req.EffectiveFrom))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid price change") {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().Err(err).Msg("Error scheduling price adjustment")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to schedule the price adjustment")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, changes)
+}
+
+// #endregion
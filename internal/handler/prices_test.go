@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"northwind-api/internal/money"
+	"northwind-api/internal/validate"
+	"testing"
+	"time"
+)
+
+func TestPriceRequestsRefuseThePast(t *testing.T) {
+	at := func(d time.Duration) *time.Time {
+		from := time.Now().Add(d)
+		return &from
+	}
+
+	tests := []struct {
+		name string
+		from *time.Time
+		want bool
+	}{
+		{"now", nil, false},
+		{"tomorrow", at(24 * time.Hour), false},
+		{"seconds ago", at(-5 * time.Second), false},
+		{"yesterday", at(-24 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := priceScheduleRequest{UnitPrice: money.MustParse("18"), EffectiveFrom: tt.from}
+			adjustment := priceAdjustmentRequest{CategoryId: 1, Percent: money.MustParse("5"), EffectiveFrom: tt.from}
+
+			for name, errs := range map[string]validate.Errors{"schedule": schedule.check(), "adjustment": adjustment.check()} {
+				if got := refuses(errs, "effective_from"); got != tt.want {
+					t.Errorf("%s: effective_from refused = %v, want %v (%v)", name, got, tt.want, errs)
+				}
+				if len(errs) > 0 && !tt.want {
+					t.Errorf("%s: unexpected errors %v", name, errs)
+				}
+			}
+		})
+	}
+}
+
+// refuses reports whether errs include an error about a field
+func refuses(errs validate.Errors, field string) bool {
+	for _, fe := range errs {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Products
+
+// Handler to get a page of every product
+func (h *Handler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/products - Getting products")
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if exportList(w, r, "products", h.db.StreamProducts) {
+		return
+	}
+
+	projection, err := parseProjection(r, productEmbeddings)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	products, total, err := h.db.GetProductPage(pageSize, (page-1)*pageSize, projection.columns()...)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting products")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get products")
+		return
+	}
+
+	data, err := projection.apply(h.db, products)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting the relations of products")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get products")
+		return
+	}
+
+	log.Info().Int("count", len(products)).Int("total", total).Msg("Successfully retrieved products")
+	writeJSONResponse(w, http.StatusOK, PagedResponse{
+		Data:     data,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Handler to get a product by its ID
+func (h *Handler) GetProductById(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["productId"]
+
+	log.Info().Str("product_id", idStr).Msg("GET /api/products/{ID} - Getting product by ID")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	projection, err := parseProjection(r, productEmbeddings)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := h.db.GetProductById(id, projection.columns()...)
+	if err != nil {
+		if err.Error() == "product not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		log.Error().Err(err).Int("product_id", id).Msg("Error getting product")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the product")
+		return
+	}
+
+	data, err := projection.applyOne(h.db, product)
+	if err != nil {
+		log.Error().Err(err).Int("product_id", id).Msg("Error getting the relations of a product")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the product")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, data)
+}
+
+// #endregion
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"net/http"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/validate"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Promotions
+
+// Handler to get all promotions
+func (h *Handler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/promotions - Getting all of the promotions")
+
+	if exportList(w, r, "promotions", h.db.StreamPromotions) {
+		return
+	}
+
+	promotions, err := h.db.GetAllPromotions()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting promotions")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get promotions")
+		return
+	}
+
+	log.Info().Int("count", len(promotions)).Msg("Successfully retrieved promotions")
+	writeJSONResponse(w, http.StatusOK, promotions)
+}
+
+// Handler to get a promotion by its ID
+func (h *Handler) GetPromotionById(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["promotionId"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.db.GetPromotionById(id)
+	if err != nil {
+		if err.Error() == "promotion not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Promotion not found")
+			return
+		}
+		log.Error().Err(err).Int("promotion_id", id).Msg("Error getting promotion")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get that promotion")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, promotion)
+}
+
+// Request body for creating a promotion. Zero scope fields are unrestricted
+// and a promotion is active unless active is false.
+type promotionRequest struct {
+	Name          string      `json:"name" validate:"required,max=60"`
+	DiscountType  string      `json:"discount_type" validate:"required,oneof=percent fixed"`
+	DiscountValue money.Money `json:"discount_value" validate:"gt=0"`
+	MinQuantity   int         `json:"min_quantity" validate:"min=0,max=32767"`
+	ProductId     int         `json:"product_id" validate:"min=0"`
+	CategoryId    int         `json:"category_id" validate:"min=0"`
+	CustomerId    string      `json:"customer_id" validate:"max=5"`
+	ValidFrom     *time.Time  `json:"valid_from"`
+	ValidTo       *time.Time  `json:"valid_to" validate:"gtfield=valid_from"`
+	Active        *bool       `json:"active"`
+}
+
+// Validates the request, including the rules its tags cannot express
+func (req *promotionRequest) check() validate.Errors {
+	return append(validate.Struct(req), percentAtMost100(req.DiscountType, req.DiscountValue)...)
+}
+
+// Converts the request into the promotion the repository stores
+func (req *promotionRequest) toModel() model.Promotion {
+	return model.Promotion{
+		Name:          req.Name,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MinQuantity:   max(req.MinQuantity, 1),
+		ProductId:     model.NullIfZero(req.ProductId),
+		CategoryId:    model.NullIfZero(req.CategoryId),
+		CustomerId:    model.NullIfZero(req.CustomerId),
+		ValidFrom:     model.NullFromPtr(req.ValidFrom),
+		ValidTo:       model.NullFromPtr(req.ValidTo),
+		Active:        req.Active == nil || *req.Active,
+	}
+}
+
+// Handler to create a new promotion
+func (h *Handler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var req promotionRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, req.check()) {
+		return
+	}
+
+	id, err := h.db.CreatePromotion(req.toModel())
+	if err != nil {
+		log.Error().Err(err).Str("name", req.Name).Msg("Error creating promotion")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create new promotion")
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":      id,
+		"message": "Promotion created successfully",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// percentAtMost100 checks a percent discount is not more than the whole amount
+func percentAtMost100(discountType string, value money.Money) validate.Errors {
+	if discountType == model.DiscountPercent && value > money.FromInt(100) {
+		return validate.Errors{{Field: "discount_value", Message: "must be at most 100 for a percent discount"}}
+	}
+	return nil
+}
+
+// Handler to delete a promotion
+func (h *Handler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["promotionId"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	if err := h.db.DeletePromotion(id); err != nil {
+		if err.Error() == "promotion not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Promotion not found")
+			return
+		}
+		log.Error().Err(err).Int("promotion_id", id).Msg("Error deleting promotion")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete the promotion")
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Promotion was successfully deleted",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// #endregion
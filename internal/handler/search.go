@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handler to search the product catalogue by product, category and supplier names
+func (h *Handler) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+
+	log.Info().Str("q", q).Msg("GET /api/search - Searching the catalogue")
+
+	if q == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "q is required")
+		return
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := repository.SearchFilter{
+		Query:  q,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	for name, target := range map[string]*int{"category_id": &filter.CategoryId, "supplier_id": &filter.SupplierId} {
+		if v := query.Get(name); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	for name, target := range map[string]**money.Money{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		if v := query.Get(name); v != "" {
+			price, err := money.Parse(v)
+			if err != nil || price.IsNegative() {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			*target = &price
+		}
+	}
+
+	results, err := h.db.SearchProducts(filter)
+	if err != nil {
+		log.Error().Err(err).Str("q", q).Msg("Error searching the catalogue")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to search the catalogue")
+		return
+	}
+
+	log.Info().Str("q", q).Int("total", results.Total).Msg("Successfully searched the catalogue")
+	writeJSONResponse(w, http.StatusOK, results)
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+	"northwind-api/internal/validate"
+	"northwind-api/internal/webhook"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// #region Webhooks
+
+// Handler to get every registered webhook
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/webhooks - Getting webhooks")
+
+	webhooks, err := h.db.GetWebhooks()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting webhooks")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get webhooks")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, webhooks)
+}
+
+// Handler to get a webhook by its ID
+func (h *Handler) GetWebhookById(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookId(w, r)
+	if !ok {
+		return
+	}
+
+	hook, err := h.db.GetWebhookById(id)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		log.Error().Err(err).Int("webhook_id", id).Msg("Error getting webhook")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the webhook")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, hook)
+}
+
+// Request body for registering a webhook. Events are the filters deliveries
+// are made for: event types such as order.shipped, every event of a resource
+// such as order.*, or * for every event. A webhook is active unless active is
+// false.
+type webhookRequest struct {
+	URL         string   `json:"url" validate:"required,max=2048"`
+	Events      []string `json:"events" validate:"required,max=20"`
+	Description string   `json:"description" validate:"max=200"`
+	Active      *bool    `json:"active"`
+}
+
+// Validates the request, including the rules its tags cannot express
+func (req *webhookRequest) check(allowPrivate bool) validate.Errors {
+	errs := validate.Struct(req)
+	if req.URL != "" {
+		if err := webhook.CheckURL(req.URL, allowPrivate); err != nil {
+			errs = append(errs, validate.FieldError{Field: "url", Message: err.Error()})
+		}
+	}
+	for i, filter := range req.Events {
+		if !events.ValidFilter(filter) {
+			errs = append(errs, validate.FieldError{Field: fmt.Sprintf("events[%d]", i),
+				Message: "must be an event type, <resource>.* or *; event types are: " + strings.Join(events.Types, ", ")})
+		}
+	}
+	return errs
+}
+
+// Converts the request into the webhook the repository stores
+func (req *webhookRequest) toModel(secret string) model.Webhook {
+	filters := []string{}
+	for _, filter := range req.Events {
+		if !slices.Contains(filters, filter) {
+			filters = append(filters, filter)
+		}
+	}
+
+	return model.Webhook{
+		URL:         req.URL,
+		Events:      filters,
+		Description: model.NullIfZero(req.Description),
+		Secret:      secret,
+		Active:      req.Active == nil || *req.Active,
+	}
+}
+
+// A webhook as it is returned when it is registered, the only time its
+// secret is shown
+type createdWebhook struct {
+	model.Webhook
+	Secret string `json:"secret"`
+}
+
+// Handler to register a webhook
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validRequest(w, req.check(h.config.WebhookAllowPrivateURLs)) {
+		return
+	}
+
+	hook, err := h.db.CreateWebhook(req.toModel(webhook.NewSecret()))
+	if err != nil {
+		log.Error().Err(err).Str("url", req.URL).Msg("Error creating webhook")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create the webhook")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, createdWebhook{Webhook: *hook, Secret: hook.Secret})
+}
+
+// Handler to delete a webhook along with its deliveries
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookId(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteWebhook(id); err != nil {
+		if err.Error() == "webhook not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		log.Error().Err(err).Int("webhook_id", id).Msg("Error deleting webhook")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete the webhook")
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Webhook was successfully deleted",
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// Handler to get a page of a webhook's deliveries, newest first
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookId(w, r)
+	if !ok {
+		return
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Make sure the webhook exists so an unknown ID is a 404 rather than an empty page
+	if _, err := h.db.GetWebhookById(id); err != nil {
+		if err.Error() == "webhook not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		log.Error().Err(err).Int("webhook_id", id).Msg("Error getting webhook")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the webhook")
+		return
+	}
+
+	deliveries, total, err := h.db.GetWebhookDeliveries(id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Error().Err(err).Int("webhook_id", id).Msg("Error getting webhook deliveries")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get the webhook's deliveries")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, PagedResponse{
+		Data:     deliveries,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Handler to get a page of the deliveries that ran out of attempts, newest first
+func (h *Handler) GetDeadDeliveries(w http.ResponseWriter, r *http.Request) {
+	log.Info().Msg("GET /api/webhooks/dead-letters - Getting dead webhook deliveries")
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deliveries, total, err := h.db.GetDeadDeliveries(pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting dead webhook deliveries")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get dead webhook deliveries")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, PagedResponse{
+		Data:     deliveries,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// Handler to queue a dead or delivered delivery to be sent again, such as a
+// dead one once its receiver is fixed. It is sent by the dispatcher's next
+// poll; a delivery that is still pending is refused with a 409.
+func (h *Handler) RedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["deliveryId"]
+
+	log.Info().Str("delivery_id", idStr).Msg("POST /api/webhooks/deliveries/{ID}/redeliver - Redelivering webhook delivery")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.db.RedeliverWebhookDelivery(id)
+	if err != nil {
+		if err.Error() == "webhook delivery not found" {
+			writeErrorResponse(w, http.StatusNotFound, "Webhook delivery not found")
+			return
+		}
+		if err.Error() == "webhook delivery is already pending" {
+			writeErrorResponse(w, http.StatusConflict, "Webhook delivery is already pending")
+			return
+		}
+		log.Error().Err(err).Int("delivery_id", id).Msg("Error redelivering webhook delivery")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to redeliver the webhook delivery")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusAccepted, delivery)
+}
+
+// Reads the webhook ID from the path, writing a 400 if it is not a number
+func webhookId(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["webhookId"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid webhook ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// #endregion
@@ -0,0 +1,188 @@
+package invoice
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	htmlTemplateName = "invoice.html"
+	textTemplateName = "invoice.txt"
+)
+
+//go:embed templates/*
+var defaultTemplates embed.FS
+
+// Renderer renders invoices as HTML or PDF from Go templates
+type Renderer struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// NewRenderer loads the invoice templates. Templates found in overrideDir
+// replace the built-in ones, anything missing falls back to the defaults.
+func NewRenderer(overrideDir string) (*Renderer, error) {
+	htmlSrc, err := loadTemplate(overrideDir, htmlTemplateName)
+	if err != nil {
+		return nil, err
+	}
+	textSrc, err := loadTemplate(overrideDir, textTemplateName)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := htmltemplate.New(htmlTemplateName).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(htmlSrc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", htmlTemplateName, err)
+	}
+	text, err := texttemplate.New(textTemplateName).Funcs(templateFuncs).Parse(textSrc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", textTemplateName, err)
+	}
+
+	return &Renderer{html: html, text: text}, nil
+}
+
+// loadTemplate reads a template from overrideDir if present, otherwise from the embedded defaults
+func loadTemplate(overrideDir, name string) (string, error) {
+	if overrideDir != "" {
+		path := filepath.Join(overrideDir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			log.Info().Str("template", path).Msg("Using invoice template override")
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read invoice template %s: %w", path, err)
+		}
+	}
+
+	data, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read default invoice template %s: %w", name, err)
+	}
+
+	return string(data), nil
+}
+
+var templateFuncs = texttemplate.FuncMap{
+	"money": func(m money.Money) string {
+		return m.Format()
+	},
+	// date formats a time or a nullable order date, showing NULL as "-"
+	"date": func(v any) string {
+		var t model.NullTime
+		switch v := v.(type) {
+		case time.Time:
+			t = model.NullIfZero(v)
+		case model.NullTime:
+			t = v
+		}
+		if !t.Valid {
+			return "-"
+		}
+		return t.V.Format("2006-01-02")
+	},
+	"pad": func(width int, s string) string {
+		runes := []rune(s)
+		if len(runes) >= width {
+			return string(runes[:width])
+		}
+		return s + strings.Repeat(" ", width-len(runes))
+	},
+	"lpad": func(width int, s string) string {
+		runes := []rune(s)
+		if len(runes) >= width {
+			return s
+		}
+		return strings.Repeat(" ", width-len(runes)) + s
+	},
+}
+
+// Build assembles an invoice from an order, its customer and its lines. An
+// order without a customer, whose customer is nil, is billed to its ship-to.
+func Build(order *model.Orders, customer *model.Customer, lines []model.OrderLine) *model.Invoice {
+	// Amounts are stored in the base currency; orders placed in another
+	// currency are invoiced at the rate captured when they were placed.
+	rate := order.ExchangeRate
+	if !rate.IsPositive() {
+		rate = money.OneRate
+	}
+	convert := func(m money.Money) money.Money {
+		return m.Convert(rate).Rounded()
+	}
+
+	inv := &model.Invoice{
+		InvoiceNumber: fmt.Sprintf("INV-%d", order.OrderId),
+		Currency:      order.Currency,
+		IssuedAt:      time.Now(),
+		Order:         *order,
+		Customer:      billTo(order, customer),
+		Lines:         make([]model.InvoiceLine, 0, len(lines)),
+		Freight:       convert(order.Freight.V),
+	}
+
+	for _, line := range lines {
+		unitPrice := convert(line.UnitPrice)
+		discount := convert(line.Discount)
+		amount := unitPrice.MulInt(line.Quantity)
+		inv.Lines = append(inv.Lines, model.InvoiceLine{
+			ProductId:   line.ProductId,
+			ProductName: line.ProductName,
+			UnitPrice:   unitPrice,
+			Quantity:    line.Quantity,
+			Discount:    discount,
+			LineTotal:   amount.Sub(discount),
+		})
+		inv.Subtotal = inv.Subtotal.Add(amount)
+		inv.Discount = inv.Discount.Add(discount)
+	}
+	inv.Total = inv.Subtotal.Sub(inv.Discount).Add(inv.Freight)
+
+	return inv
+}
+
+// billTo returns who an order is billed to: its customer, or a party made up
+// of its ship_* fields when it has none
+func billTo(order *model.Orders, customer *model.Customer) model.Customer {
+	if customer != nil {
+		return *customer
+	}
+	return model.Customer{
+		CompanyName: order.ShipName.V,
+		Address:     order.ShipAddress,
+		City:        order.ShipCity,
+		Region:      order.Region,
+		PostalCode:  order.ShipPostalCode,
+		Country:     order.ShipCountry,
+		Currency:    order.Currency,
+	}
+}
+
+// HTML writes the invoice as an HTML document
+func (r *Renderer) HTML(w io.Writer, inv *model.Invoice) error {
+	return r.html.Execute(w, inv)
+}
+
+// PDF writes the invoice as a PDF document built from the text template
+func (r *Renderer) PDF(w io.Writer, inv *model.Invoice) error {
+	var buf bytes.Buffer
+	if err := r.text.Execute(&buf, inv); err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	return writePDF(w, lines)
+}
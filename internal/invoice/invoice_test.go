@@ -0,0 +1,51 @@
+package invoice
+
+import (
+	"bytes"
+	"northwind-api/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestBuildBillsShipToWithoutCustomer(t *testing.T) {
+	order := &model.Orders{
+		OrderId:        10248,
+		Currency:       "USD",
+		ShipName:       model.NewNull("Vins et alcools Chevalier"),
+		ShipAddress:    model.NewNull("59 rue de l'Abbaye"),
+		ShipCity:       model.NewNull("Reims"),
+		ShipPostalCode: model.NewNull("51100"),
+		ShipCountry:    model.NewNull("France"),
+	}
+
+	inv := Build(order, nil, nil)
+	if inv.Customer.CompanyName != "Vins et alcools Chevalier" || inv.Customer.City.V != "Reims" {
+		t.Fatalf("bill-to = %+v, want the order's ship-to", inv.Customer)
+	}
+
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := r.HTML(&buf, inv); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	billTo, _, _ := strings.Cut(buf.String(), "Ship to")
+	if !strings.Contains(billTo, "Vins et alcools Chevalier") {
+		t.Errorf("bill-to block does not name the ship-to:\n%s", billTo)
+	}
+	if err := r.PDF(&bytes.Buffer{}, inv); err != nil {
+		t.Errorf("PDF: %v", err)
+	}
+}
+
+func TestBuildBillsCustomer(t *testing.T) {
+	order := &model.Orders{OrderId: 10249, ShipName: model.NewNull("Toms Spezialitäten")}
+	customer := &model.Customer{CustomerId: "TOMSP", CompanyName: "Toms Spezialitäten GmbH"}
+
+	inv := Build(order, customer, nil)
+	if inv.Customer.CustomerId != "TOMSP" || inv.Customer.CompanyName != "Toms Spezialitäten GmbH" {
+		t.Fatalf("bill-to = %+v, want the customer", inv.Customer)
+	}
+}
@@ -0,0 +1,112 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page layout for the generated PDF (US Letter, 10pt Courier)
+const (
+	pageWidth    = 612
+	pageHeight   = 792
+	pageMargin   = 50
+	fontSize     = 10
+	lineHeight   = 12
+	linesPerPage = (pageHeight - 2*pageMargin) / lineHeight
+	maxLineChars = (pageWidth - 2*pageMargin) / 6
+)
+
+// writePDF writes a minimal PDF containing the given lines of monospaced text
+func writePDF(w io.Writer, lines []string) error {
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Objects 1-3 are the catalog, page tree and font, then a page and content stream per page
+	beginObject := func() int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n", id)
+		return id
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	beginObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	beginObject()
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+
+	beginObject()
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for _, page := range pages {
+		pageId := beginObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pageWidth, pageHeight, pageId+1)
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, pageMargin, pageHeight-pageMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDFText(line))
+		}
+		content.WriteString("ET\n")
+
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", content.Len())
+		buf.Write(content.Bytes())
+		buf.WriteString("endstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// escapePDFText escapes a line for use in a PDF string literal, encoding
+// Latin-1 characters as octal escapes and replacing anything else with '?'
+func escapePDFText(s string) string {
+	var b strings.Builder
+	count := 0
+	for _, r := range s {
+		if count == maxLineChars {
+			break
+		}
+		count++
+
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
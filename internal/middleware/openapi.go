@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"northwind-api/internal/openapi"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// Response checks of OpenAPIValidation
+const (
+	// ResponsesOff does not check responses
+	ResponsesOff = ""
+	// ResponsesLog logs responses that do not match the document
+	ResponsesLog = "log"
+	// ResponsesFail replaces JSON responses that do not match the document
+	// with a 500, so tests catch handlers drifting from the document
+	ResponsesFail = "fail"
+)
+
+// OpenAPIConfig holds the configuration of OpenAPIValidation
+type OpenAPIConfig struct {
+	Document *openapi.Document
+	// Responses is ResponsesOff, ResponsesLog or ResponsesFail
+	Responses string
+	// Bodies larger than this are passed on without being checked
+	MaxBodyBytes int64
+}
+
+// SchemaErrorResponse is written for a request, or with ResponsesFail a
+// response, that does not match the OpenAPI document
+type SchemaErrorResponse struct {
+	Error      string              `json:"error"`
+	Violations []openapi.Violation `json:"violations"`
+}
+
+// OpenAPIValidation checks the parameters and JSON bodies of requests against
+// the OpenAPI document, answering those that do not match with a 400 listing
+// where they differ, and optionally checks responses too. It must be added to
+// the router with Use, as it looks operations up by the matched route.
+func OpenAPIValidation(config OpenAPIConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			path, err := route.GetPathTemplate()
+			if err != nil || config.Document.Operation(r.Method, path) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			var body []byte
+			if openapi.IsJSON(mediaType) && r.Body != nil {
+				body = peekBody(r, config.MaxBodyBytes)
+			}
+
+			violations := config.Document.ValidateRequest(r.Method, path, mux.Vars(r), r.URL.Query(), mediaType, body)
+			if len(violations) > 0 {
+				log.Warn().Str("method", r.Method).Str("path", r.URL.Path).Interface("violations", violations).
+					Msg("Request does not match the OpenAPI document")
+				writeSchemaError(w, http.StatusBadRequest, "Request does not match the API schema", violations)
+				return
+			}
+
+			if config.Responses == ResponsesOff {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			checked := &checkedResponseWriter{ResponseWriter: w, config: config, method: r.Method, path: path, status: http.StatusOK}
+			next.ServeHTTP(checked, r)
+			checked.finish()
+		})
+	}
+}
+
+// peekBody reads a request body so it can be checked, leaving it to be read
+// again by the handler. Bodies over the limit are not read in full and nil is returned.
+func peekBody(r *http.Request, limit int64) []byte {
+	body := r.Body
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(data), body), body}
+	if err != nil || int64(len(data)) > limit {
+		return nil
+	}
+	return data
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// checkedResponseWriter holds back JSON responses until they are checked.
+// Other responses, such as exports, stream as usual and only their status and
+// media type are checked.
+type checkedResponseWriter struct {
+	http.ResponseWriter
+	config       OpenAPIConfig
+	method, path string
+
+	status      int
+	mediaType   string
+	wroteHeader bool
+	held        bool
+	body        bytes.Buffer
+}
+
+func (cw *checkedResponseWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+	cw.mediaType, _, _ = mime.ParseMediaType(cw.Header().Get("Content-Type"))
+
+	if openapi.IsJSON(cw.mediaType) {
+		cw.held = true
+		return
+	}
+	cw.report(cw.config.Document.ValidateResponse(cw.method, cw.path, code, cw.mediaType, nil))
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *checkedResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.held {
+		return cw.body.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer to flush
+// streamed responses
+func (cw *checkedResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// finish checks and writes a held back response
+func (cw *checkedResponseWriter) finish() {
+	if !cw.held {
+		return
+	}
+
+	violations := cw.config.Document.ValidateResponse(cw.method, cw.path, cw.status, cw.mediaType, cw.body.Bytes())
+	if cw.report(violations) && cw.config.Responses == ResponsesFail {
+		cw.Header().Del("Content-Length")
+		writeSchemaError(cw.ResponseWriter, http.StatusInternalServerError, "Response does not match the API schema", violations)
+		return
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(cw.body.Bytes())
+}
+
+// report logs a response that does not match the document, returning whether it does not
+func (cw *checkedResponseWriter) report(violations []openapi.Violation) bool {
+	if len(violations) == 0 {
+		return false
+	}
+	log.Error().Str("method", cw.method).Str("route", cw.path).Int("status", cw.status).
+		Interface("violations", violations).
+		Msg("Response does not match the OpenAPI document")
+	return true
+}
+
+func writeSchemaError(w http.ResponseWriter, status int, message string, violations []openapi.Violation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(SchemaErrorResponse{Error: message, Violations: violations})
+}
@@ -13,6 +13,12 @@ func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// Handlers abort responses they cannot finish, such as a failed
+				// export, and the server closes the connection without logging
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				// Log the panic with stack trace
 				log.Error().
 					Str("method", r.Method).
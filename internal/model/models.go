@@ -1,95 +1,342 @@
 package model
 
-import "time"
+import (
+	"northwind-api/internal/events"
+	"northwind-api/internal/money"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 // Category model for the categories of products
 type Category struct {
-	CategoryId  int    `json:"category_id" db:"category_id"`
-	Name        string `json:"category_name" db:"category_name"`
-	Description string `json:"description" db:"description"`
+	CategoryId  int        `json:"category_id" db:"category_id"`
+	Name        string     `json:"category_name" db:"category_name" validate:"required,max=15"`
+	Description NullString `json:"description" db:"description"`
 }
 
+// Customer model. A NULL currency is read as the base currency.
 type Customer struct {
-	CustomerId  string `json:"customer_id" db:"customer_id"`
-	CompanyName string `json:"company_name" db:"customer_name"`
-	ContactName string `json:"contact_name" db:"contact_name"`
-	Address     string `json:"address" db:"address"`
-	City        string `json:"city" db:"city"`
-	Region      string `json:"region" db:"region"`
-	PostalCode  string `json:"postal_code" db:"postal_code"`
-	Country     string `json:"country" db:"country"`
-	Phone       string `json:"phone" db:"phone"`
+	CustomerId  string     `json:"customer_id" db:"customer_id" validate:"max=5"`
+	CompanyName string     `json:"company_name" db:"company_name" validate:"required,max=40"`
+	ContactName NullString `json:"contact_name" db:"contact_name" validate:"max=30"`
+	Address     NullString `json:"address" db:"address" validate:"max=60"`
+	City        NullString `json:"city" db:"city" validate:"max=15"`
+	Region      NullString `json:"region" db:"region" validate:"max=15"`
+	PostalCode  NullString `json:"postal_code" db:"postal_code" validate:"max=10"`
+	Country     NullString `json:"country" db:"country" validate:"max=15,country"`
+	Phone       NullString `json:"phone" db:"phone" validate:"max=24"`
+	Currency    string     `json:"currency" db:"currency,nullzero" validate:"currency"`
 }
 
 type Employees struct {
-	EmployeeId int     `json:"employee_id" db:"employee_id"`
-	LastName   string  `json:"last_name" db:"last_name"`
-	FirstName  string  `json:"first_name" db:"first_name"`
-	Title      string  `json:"title" db:"title"`
-	BirthDate  string  `json:"birth_date" db:"birth_date"`
-	HireDate   string  `json:"hire_date" db:"hire_date"`
-	Address    string  `json:"address" db:"address"`
-	State      string  `json:"state" db:"state"`
-	City       string  `json:"city" db:"city"`
-	PostalCode string  `json:"postal_code" db:"postal_code"`
-	Country    string  `json:"country" db:"country"`
-	ReportsTo  int     `json:"reports_to" db:"reports_to"`
-	Salary     float64 `json:"salary" db:"salary"`
+	EmployeeId int        `json:"employee_id" db:"employee_id"`
+	LastName   string     `json:"last_name" db:"last_name" validate:"required,max=20"`
+	FirstName  string     `json:"first_name" db:"first_name" validate:"required,max=10"`
+	Title      NullString `json:"title" db:"title" validate:"max=30"`
+	BirthDate  NullTime   `json:"birth_date" db:"birth_date"`
+	HireDate   NullTime   `json:"hire_date" db:"hire_date"`
+	Address    NullString `json:"address" db:"address" validate:"max=60"`
+	State      NullString `json:"state" db:"state" validate:"max=60"`
+	City       NullString `json:"city" db:"city" validate:"max=15"`
+	PostalCode NullString `json:"postal_code" db:"postal_code" validate:"max=10"`
+	Country    NullString `json:"country" db:"country" validate:"max=15,country"`
+	ReportsTo  NullInt    `json:"reports_to" db:"reports_to"`
+	Salary     NullMoney  `json:"salary" db:"salary" validate:"min=0"`
 }
 
 type OrderDetails struct {
-	OrderId   int     `json:"order_id" db:"order_id"`
-	ProductId int     `json:"product_id" db:"product_id"`
-	UnitPrice float64 `json:"unit_price" db:"unit_price"`
-	Quantity  int     `json:"quantity" db:"quantity"`
+	OrderId   int         `json:"order_id" db:"order_id"`
+	ProductId int         `json:"product_id" db:"product_id"`
+	UnitPrice money.Money `json:"unit_price" db:"unit_price"`
+	Quantity  int         `json:"quantity" db:"quantity"`
+	Discount  money.Money `json:"discount" db:"discount"`
 }
 
+// Orders model. A NULL currency is read as the base currency and a NULL
+// exchange rate as one.
 type Orders struct {
-	OrderId        int       `json:"order_id" db:"order_id"`
-	CustomerId     string    `json:"customer_id" db:"customer_id"`
-	EmployeeId     int       `json:"employee_id" db:"employee_id"`
-	OrderDate      time.Time `json:"order_date" db:"order_date"`
-	RequiredDate   time.Time `json:"required_date" db:"required_date"`
-	ShippedDate    time.Time `json:"shipped_date" db:"shipped_date"`
-	ShipVia        int       `json:"ship_via" db:"ship_via"`
-	Freight        int       `json:"freight" db:"freight"`
-	ShipName       string    `json:"ship_name" db:"ship_name"`
-	ShipAddress    string    `json:"ship_address" db:"ship_address"`
-	Region         string    `json:"region" db:"region"`
-	ShipCity       string    `json:"ship_city" db:"ship_city"`
-	ShipPostalCode string    `json:"ship_postal_code" db:"ship_postal_code"`
-	ShipCountry    string    `json:"ship_country" db:"ship_country"`
+	OrderId        int        `json:"order_id" db:"order_id"`
+	CustomerId     NullString `json:"customer_id" db:"customer_id" validate:"max=5"`
+	EmployeeId     NullInt    `json:"employee_id" db:"employee_id"`
+	OrderDate      NullTime   `json:"order_date" db:"order_date"`
+	RequiredDate   NullTime   `json:"required_date" db:"required_date" validate:"gtefield=order_date"`
+	ShippedDate    NullTime   `json:"shipped_date" db:"shipped_date" validate:"gtefield=order_date"`
+	ShipVia        NullInt    `json:"ship_via" db:"ship_via"`
+	Freight        NullMoney  `json:"freight" db:"freight" validate:"min=0"`
+	ShipName       NullString `json:"ship_name" db:"ship_name" validate:"max=40"`
+	ShipAddress    NullString `json:"ship_address" db:"ship_address" validate:"max=60"`
+	Region         NullString `json:"region" db:"region" validate:"max=60"`
+	ShipCity       NullString `json:"ship_city" db:"ship_city" validate:"max=15"`
+	ShipPostalCode NullString `json:"ship_postal_code" db:"ship_postal_code" validate:"max=10"`
+	ShipCountry    NullString `json:"ship_country" db:"ship_country" validate:"max=15,country"`
+	Currency       string     `json:"currency" db:"currency,nullzero"`
+	ExchangeRate   money.Rate `json:"exchange_rate" db:"exchange_rate"`
 }
 
 type Products struct {
-	ProductId       int     `json:"product_id" db:"product_id"`
-	ProductName     string  `json:"product_name" db:"product_name"`
-	SupplierId      int     `json:"supplier_id" db:"supplier_id"`
-	CategoryId      int     `json:"category_id" db:"category_id"`
-	QuantityPerUnit string  `json:"quantity_per_unit" db:"quantity_per_unit"`
-	UnitPrice       float64 `json:"unit_price" db:"unit_price"`
-	UnitsInStock    int     `json:"units_in_stock" db:"units_in_stock"`
-	UnitsOnOrder    int     `json:"units_on_order" db:"units_on_order"`
-	ReorderLevel    int     `json:"reorder_level" db:"reorder_level"`
-	Discontinued    bool    `json:"discontinued" db:"discontinued"`
+	ProductId       int        `json:"product_id" db:"product_id"`
+	ProductName     string     `json:"product_name" db:"product_name" validate:"required,max=40"`
+	SupplierId      NullInt    `json:"supplier_id" db:"supplier_id"`
+	CategoryId      NullInt    `json:"category_id" db:"category_id"`
+	QuantityPerUnit NullString `json:"quantity_per_unit" db:"quantity_per_unit" validate:"max=20"`
+	UnitPrice       NullMoney  `json:"unit_price" db:"unit_price" validate:"min=0"`
+	UnitsInStock    NullInt    `json:"units_in_stock" db:"units_in_stock" validate:"min=0,max=32767"`
+	UnitsOnOrder    NullInt    `json:"units_on_order" db:"units_on_order" validate:"min=0,max=32767"`
+	ReorderLevel    NullInt    `json:"reorder_level" db:"reorder_level" validate:"min=0,max=32767"`
+	Discontinued    bool       `json:"discontinued" db:"discontinued"`
 }
 
 type Shippers struct {
-	ShipperId   int    `json:"shipper_id" db:"shipper_id"`
-	CompanyName string `json:"company_name" db:"company_name"`
-	Phone       string `json:"phone" db:"phone"`
+	ShipperId   int        `json:"shipper_id" db:"shipper_id"`
+	CompanyName string     `json:"company_name" db:"company_name" validate:"required,max=40"`
+	Phone       NullString `json:"phone" db:"phone" validate:"max=24"`
 }
 
 type Suppliers struct {
-	SupplierId   int    `json:"supplier_id" db:"supplier_id"`
-	CompanyName  string `json:"company_name" db:"company_name"`
-	ContactName  string `json:"contact_name" db:"contact_name"`
-	ContactTitle string `json:"contact_title" db:"contact_title"`
-	Address      string `json:"address" db:"address"`
-	City         string `json:"city" db:"city"`
-	Region       string `json:"region" db:"region"`
-	PostalCode   string `json:"postal_code" db:"postal_code"`
-	Country      string `json:"country" db:"country"`
-	Phone        string `json:"phone" db:"phone"`
-	Fax          string `json:"fax" db:"fax"`
+	SupplierId   int        `json:"supplier_id" db:"supplier_id"`
+	CompanyName  string     `json:"company_name" db:"company_name" validate:"required,max=40"`
+	ContactName  NullString `json:"contact_name" db:"contact_name" validate:"max=30"`
+	ContactTitle NullString `json:"contact_title" db:"contact_title" validate:"max=30"`
+	Address      NullString `json:"address" db:"address" validate:"max=60"`
+	City         NullString `json:"city" db:"city" validate:"max=15"`
+	Region       NullString `json:"region" db:"region" validate:"max=15"`
+	PostalCode   NullString `json:"postal_code" db:"postal_code" validate:"max=10"`
+	Country      NullString `json:"country" db:"country" validate:"max=15,country"`
+	Phone        NullString `json:"phone" db:"phone" validate:"max=24"`
+	Fax          NullString `json:"fax" db:"fax" validate:"max=24"`
+}
+
+// OrderLine is an order_details row joined with the product it refers to
+type OrderLine struct {
+	OrderId     int         `json:"order_id" db:"order_id"`
+	ProductId   int         `json:"product_id" db:"product_id"`
+	ProductName string      `json:"product_name" db:"product_name"`
+	UnitPrice   money.Money `json:"unit_price" db:"unit_price"`
+	Quantity    int         `json:"quantity" db:"quantity"`
+	Discount    money.Money `json:"discount" db:"discount"`
+}
+
+// InvoiceLine is a single priced line on an invoice
+type InvoiceLine struct {
+	ProductId   int         `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Quantity    int         `json:"quantity"`
+	Discount    money.Money `json:"discount"`
+	LineTotal   money.Money `json:"line_total"`
+}
+
+// Invoice is the document rendered for an order. Customer is who it is billed
+// to, made up of the ship-to of an order without a customer.
+type Invoice struct {
+	InvoiceNumber string        `json:"invoice_number"`
+	Currency      string        `json:"currency"`
+	IssuedAt      time.Time     `json:"issued_at"`
+	Order         Orders        `json:"order"`
+	Customer      Customer      `json:"customer"`
+	Lines         []InvoiceLine `json:"lines"`
+	Subtotal      money.Money   `json:"subtotal"`
+	Discount      money.Money   `json:"discount"`
+	Freight       money.Money   `json:"freight"`
+	Total         money.Money   `json:"total"`
+}
+
+// ProductSales summarises how much of a product was bought
+type ProductSales struct {
+	ProductId   int         `json:"product_id" db:"product_id"`
+	ProductName string      `json:"product_name" db:"product_name"`
+	Quantity    int         `json:"quantity" db:"quantity"`
+	Revenue     money.Money `json:"revenue" db:"revenue"`
+}
+
+// CustomerStatement summarises a customer's account from their orders
+type CustomerStatement struct {
+	CustomerId        string         `json:"customer_id"`
+	CompanyName       string         `json:"company_name"`
+	Currency          string         `json:"currency"`
+	OrderCount        int            `json:"order_count"`
+	LifetimeRevenue   money.Money    `json:"lifetime_revenue"`
+	FirstOrderDate    NullTime       `json:"first_order_date"`
+	LastOrderDate     NullTime       `json:"last_order_date"`
+	OutstandingOrders int            `json:"outstanding_orders"`
+	OutstandingValue  money.Money    `json:"outstanding_value"`
+	TopProducts       []ProductSales `json:"top_products"`
+}
+
+// SearchResult is a product matched by a catalogue search. Highlights are the
+// names as escaped HTML, with the words matched in <mark> tags.
+type SearchResult struct {
+	ProductId    int               `json:"product_id"`
+	ProductName  string            `json:"product_name"`
+	CategoryId   NullInt           `json:"category_id"`
+	CategoryName NullString        `json:"category_name"`
+	SupplierId   NullInt           `json:"supplier_id"`
+	SupplierName NullString        `json:"supplier_name"`
+	UnitPrice    NullMoney         `json:"unit_price"`
+	Rank         float64           `json:"rank"`
+	Fuzzy        bool              `json:"fuzzy"`
+	Highlights   map[string]string `json:"highlights"`
+}
+
+// SearchFacet is the number of search matches sharing a value
+type SearchFacet struct {
+	Id    int    `json:"id,omitempty"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// SearchFacets groups the facets returned with search results
+type SearchFacets struct {
+	Categories  []SearchFacet `json:"categories"`
+	Suppliers   []SearchFacet `json:"suppliers"`
+	PriceRanges []SearchFacet `json:"price_ranges"`
+}
+
+// SearchResults is a page of catalogue search matches with facets over all matches
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+	Facets  SearchFacets   `json:"facets"`
+}
+
+// ProductPrice is one period of a product's price history. A NULL
+// EffectiveTo means the price applies until further notice.
+type ProductPrice struct {
+	PriceId       int         `json:"price_id" db:"price_id"`
+	ProductId     int         `json:"product_id" db:"product_id"`
+	UnitPrice     money.Money `json:"unit_price" db:"unit_price"`
+	EffectiveFrom time.Time   `json:"effective_from" db:"effective_from"`
+	EffectiveTo   NullTime    `json:"effective_to" db:"effective_to"`
+}
+
+// PriceChange describes a scheduled change to a product's price
+type PriceChange struct {
+	ProductId     int         `json:"product_id"`
+	OldPrice      money.Money `json:"old_price"`
+	NewPrice      money.Money `json:"new_price"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+}
+
+// Discount types a promotion can have
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Promotion is a discount rule applied to order lines when orders are priced.
+// NULL scope fields and validity bounds are unrestricted.
+type Promotion struct {
+	PromotionId   int         `json:"promotion_id" db:"promotion_id"`
+	Name          string      `json:"name" db:"name" validate:"required,max=60"`
+	DiscountType  string      `json:"discount_type" db:"discount_type" validate:"required,oneof=percent fixed"`
+	DiscountValue money.Money `json:"discount_value" db:"discount_value" validate:"gt=0"`
+	MinQuantity   int         `json:"min_quantity" db:"min_quantity" validate:"min=1,max=32767"`
+	ProductId     NullInt     `json:"product_id" db:"product_id"`
+	CategoryId    NullInt     `json:"category_id" db:"category_id"`
+	CustomerId    NullString  `json:"customer_id" db:"customer_id" validate:"max=5"`
+	ValidFrom     NullTime    `json:"valid_from" db:"valid_from"`
+	ValidTo       NullTime    `json:"valid_to" db:"valid_to" validate:"gtfield=valid_from"`
+	Active        bool        `json:"active" db:"active"`
+}
+
+// QuoteLine is a priced order line
+type QuoteLine struct {
+	ProductId   int         `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	CategoryId  int         `json:"category_id"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Quantity    int         `json:"quantity"`
+	Discount    money.Money `json:"discount"`
+	PromotionId int         `json:"promotion_id,omitempty"`
+	LineTotal   money.Money `json:"line_total"`
+}
+
+// Quote is a priced basket
+type Quote struct {
+	CustomerId    string      `json:"customer_id"`
+	Currency      string      `json:"currency"`
+	ExchangeRate  money.Rate  `json:"exchange_rate"`
+	OrderDate     time.Time   `json:"order_date"`
+	Lines         []QuoteLine `json:"lines"`
+	Subtotal      money.Money `json:"subtotal"`
+	Discount      money.Money `json:"discount"`
+	ShipVia       int         `json:"ship_via,omitempty"`
+	FreightRateId int         `json:"freight_rate_id,omitempty"`
+	Freight       money.Money `json:"freight"`
+	Total         money.Money `json:"total"`
+}
+
+// FreightRate is one band of a shipper's rate table. NULL destination fields
+// match any destination and a NULL MaxQuantity is unbounded.
+type FreightRate struct {
+	RateId        int         `json:"rate_id" db:"rate_id"`
+	ShipperId     int         `json:"shipper_id" db:"shipper_id"`
+	ShipCountry   NullString  `json:"ship_country" db:"ship_country" validate:"max=15,country"`
+	ShipRegion    NullString  `json:"ship_region" db:"ship_region" validate:"max=60"`
+	MinQuantity   int         `json:"min_quantity" db:"min_quantity" validate:"min=0"`
+	MaxQuantity   NullInt     `json:"max_quantity" db:"max_quantity" validate:"gtefield=min_quantity"`
+	BaseCharge    money.Money `json:"base_charge" db:"base_charge" validate:"min=0"`
+	PerUnitCharge money.Money `json:"per_unit_charge" db:"per_unit_charge" validate:"min=0"`
+}
+
+// FreightQuote is the freight a shipper charges for a basket
+type FreightQuote struct {
+	ShipperId   int         `json:"shipper_id"`
+	ShipperName string      `json:"shipper_name"`
+	RateId      int         `json:"rate_id"`
+	Quantity    int         `json:"quantity"`
+	Freight     money.Money `json:"freight"`
+}
+
+// ExchangeRate is how much of a currency one unit of the base currency buys from a point in time
+type ExchangeRate struct {
+	Currency      string     `json:"currency" db:"currency" validate:"required,currency"`
+	Rate          money.Rate `json:"rate" db:"rate" validate:"gt=0"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+}
+
+// Webhook is a partner URL told about the events its filters match: an event
+// type, every event of a resource such as "order.*", or "*". The secret that
+// signs deliveries is only shown when the webhook is created.
+type Webhook struct {
+	WebhookId   int            `json:"webhook_id" db:"webhook_id"`
+	URL         string         `json:"url" db:"url" validate:"required,max=2048"`
+	Events      pq.StringArray `json:"events" db:"events" validate:"required,max=20"`
+	Description NullString     `json:"description" db:"description" validate:"max=200"`
+	Secret      string         `json:"-" db:"secret"`
+	Active      bool           `json:"active" db:"active"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Statuses a webhook delivery can have
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is one event being delivered to one webhook. LastStatus is
+// the HTTP status of the last attempt, NULL when it got no response.
+type WebhookDelivery struct {
+	DeliveryId    int        `json:"delivery_id" db:"delivery_id"`
+	WebhookId     int        `json:"webhook_id" db:"webhook_id"`
+	EventId       string     `json:"event_id" db:"event_id"`
+	EventType     string     `json:"event_type" db:"event_type"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt NullTime   `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt NullTime   `json:"last_attempt_at" db:"last_attempt_at"`
+	LastStatus    NullInt    `json:"last_status" db:"last_status"`
+	LastError     NullString `json:"last_error" db:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt   NullTime   `json:"delivered_at" db:"delivered_at"`
+}
+
+// OutboxEvent is an event recorded in the outbox, published once PublishedAt is set
+type OutboxEvent struct {
+	OutboxId int64 `json:"outbox_id" db:"outbox_id"`
+	events.Event
+	PublishedAt NullTime `json:"published_at" db:"published_at"`
 }
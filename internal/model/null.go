@@ -0,0 +1,118 @@
+package model
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"northwind-api/internal/money"
+	"time"
+)
+
+// Null is a value of a nullable column. It scans NULL as invalid, stores an
+// invalid value as NULL and encodes as JSON null when invalid.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// Nullable column types used by the models
+type (
+	NullString = Null[string]
+	NullInt    = Null[int]
+	NullTime   = Null[time.Time]
+	NullMoney  = Null[money.Money]
+)
+
+// NewNull returns a valid value
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// NullIfZero returns a value that is NULL when v is the zero value of its
+// type, or reports IsZero (time.Time, money.Money)
+func NullIfZero[T comparable](v T) Null[T] {
+	if z, ok := any(v).(interface{ IsZero() bool }); ok {
+		return Null[T]{V: v, Valid: !z.IsZero()}
+	}
+	var zero T
+	return Null[T]{V: v, Valid: v != zero}
+}
+
+// NullFromPtr returns a value that is NULL when p is nil
+func NullFromPtr[T any](p *T) Null[T] {
+	if p == nil {
+		return Null[T]{}
+	}
+	return NewNull(*p)
+}
+
+// Ptr returns a pointer to the value, or nil when it is NULL
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V
+	return &v
+}
+
+// OrZero returns the value, or the zero value of its type when it is NULL
+func (n Null[T]) OrZero() T {
+	if !n.Valid {
+		var zero T
+		return zero
+	}
+	return n.V
+}
+
+// String formats the value, or returns "" when it is NULL
+func (n Null[T]) String() string {
+	if !n.Valid {
+		return ""
+	}
+	return fmt.Sprint(n.V)
+}
+
+// Scan implements sql.Scanner
+func (n *Null[T]) Scan(value any) error {
+	var s sql.Null[T]
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	n.V, n.Valid = s.V, s.Valid
+	return nil
+}
+
+// Value implements driver.Valuer, deferring to the value's own Valuer
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	if v, ok := any(n.V).(driver.Valuer); ok {
+		return v.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// MarshalJSON encodes NULL as null
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON decodes null as NULL
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		n.V, n.Valid = zero, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.V); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,325 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places Money keeps, matching the DECIMAL(10,4) columns
+const Scale = 4
+
+// unit is the number of Money units in 1
+const unit = 10000
+
+// Money is an exact decimal amount stored as a count of ten-thousandths.
+// It scans from and writes to NUMERIC columns without going through float64
+// and is encoded in JSON as a string with all Scale decimal places.
+type Money int64
+
+// RoundingMode controls how amounts are rounded to fewer decimal places
+type RoundingMode int
+
+const (
+	// HalfUp rounds halves away from zero
+	HalfUp RoundingMode = iota
+	// HalfEven rounds halves to the nearest even digit (banker's rounding)
+	HalfEven
+	// Down truncates towards zero
+	Down
+)
+
+// Rounding applied by Rounded and Format, set with Configure
+var (
+	places = 2
+	mode   = HalfEven
+)
+
+// ParseRoundingMode parses half_up, half_even or down
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch strings.ToLower(s) {
+	case "half_up":
+		return HalfUp, nil
+	case "half_even":
+		return HalfEven, nil
+	case "down":
+		return Down, nil
+	}
+
+	return 0, fmt.Errorf("unknown rounding mode %q", s)
+}
+
+// Configure sets the decimal places and rounding mode used by Rounded and Format.
+// It should be called once at startup.
+func Configure(decimalPlaces int, rounding RoundingMode) error {
+	if decimalPlaces < 0 || decimalPlaces > Scale {
+		return fmt.Errorf("decimal places must be between 0 and %d", Scale)
+	}
+
+	places = decimalPlaces
+	mode = rounding
+	return nil
+}
+
+// FromInt returns a whole amount
+func FromInt(n int64) Money {
+	return Money(n * unit)
+}
+
+// FromFloat converts a float, rounding half away from zero to Scale places.
+// It is only meant for values that are floats at the source, such as DOUBLE PRECISION columns.
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * unit))
+}
+
+// Parse parses a plain decimal such as "12", "-0.5" or "18.0000"
+func Parse(s string) (Money, error) {
+	n, err := parseDecimal(s, Scale)
+	return Money(n), err
+}
+
+// parseDecimal parses a plain decimal into an integer count of 10^-scale units
+func parseDecimal(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	negative := false
+	digits := s
+	switch digits[0] {
+	case '-':
+		negative = true
+		digits = digits[1:]
+	case '+':
+		digits = digits[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fraction) > scale {
+		// NUMERIC columns never have more, so extra digits may only be trailing zeros
+		if strings.Trim(fraction[scale:], "0") != "" {
+			return 0, fmt.Errorf("amount %q has more than %d decimal places", s, scale)
+		}
+		fraction = fraction[:scale]
+	}
+	for _, part := range []string{whole, fraction} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("invalid amount %q", s)
+			}
+		}
+	}
+
+	fraction += strings.Repeat("0", scale-len(fraction))
+	n, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is out of range", s)
+	}
+	if negative {
+		n = -n
+	}
+
+	return n, nil
+}
+
+// MustParse is like Parse but panics on error, for constants
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) Money {
+	return m + o
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	return m - o
+}
+
+// MulInt returns m multiplied by a whole number, such as a quantity
+func (m Money) MulInt(n int) Money {
+	return m * Money(n)
+}
+
+// Percent returns p percent of m, rounded to Scale places with the configured rounding mode
+func (m Money) Percent(p Money) Money {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(p)))
+	return Money(divRound(n, big.NewInt(100*unit), mode))
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if o < m {
+		return o
+	}
+	return m
+}
+
+// IsZero reports whether m is zero
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// IsNegative reports whether m is below zero
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// Round rounds m to the given number of decimal places
+func (m Money) Round(decimalPlaces int, rounding RoundingMode) Money {
+	if decimalPlaces >= Scale {
+		return m
+	}
+
+	step := int64(math.Pow10(Scale - decimalPlaces))
+	n := divRound(big.NewInt(int64(m)), big.NewInt(step), rounding)
+	return Money(n * step)
+}
+
+// Rounded rounds m with the configured places and rounding mode
+func (m Money) Rounded() Money {
+	return m.Round(places, mode)
+}
+
+// Float64 returns m as a float, for logging and other approximate uses
+func (m Money) Float64() float64 {
+	return float64(m) / unit
+}
+
+// String formats m with all Scale decimal places
+func (m Money) String() string {
+	return m.format(Scale)
+}
+
+// Format rounds m with the configured rounding and formats it with the
+// configured places, for display such as invoices and spreadsheets
+func (m Money) Format() string {
+	return m.Rounded().format(places)
+}
+
+// format formats m with the given number of decimal places, which must not lose digits
+func (m Money) format(decimalPlaces int) string {
+	return formatDecimal(int64(m), Scale, decimalPlaces)
+}
+
+// formatDecimal formats a count of 10^-scale units with the given number of decimal places
+func formatDecimal(n int64, scale, decimalPlaces int) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	div := int64(math.Pow10(scale))
+	whole := strconv.FormatInt(n/div, 10)
+	if decimalPlaces == 0 {
+		return sign + whole
+	}
+
+	fraction := fmt.Sprintf("%0*d", scale, n%div)[:decimalPlaces]
+	return sign + whole + "." + fraction
+}
+
+// divRound divides n by d, rounding the quotient with the given mode
+func divRound(n, d *big.Int, rounding RoundingMode) int64 {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 || rounding == Down {
+		return q.Int64()
+	}
+
+	// Compare twice the remainder with the divisor to find which side of half we are on
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	cmp := twice.Cmp(new(big.Int).Abs(d))
+
+	away := cmp > 0 || (cmp == 0 && (rounding == HalfUp || q.Bit(0) == 1))
+	if away {
+		if n.Sign()*d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q.Int64()
+}
+
+// Scan implements sql.Scanner. NUMERIC columns arrive as text and are parsed
+// exactly; floating point columns are rounded to Scale places.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = FromInt(v)
+		return nil
+	case float64:
+		*m = FromFloat(v)
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+// Value implements driver.Valuer, writing the exact decimal as text
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// MarshalJSON encodes m as a string with full precision, so amounts read back
+// from JSON are the amounts stored
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON accepts both "12.34" and 12.34, parsing either without going through float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", s)
+		}
+		s = unquoted
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,242 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+// configure sets the rounding for a test, restoring the previous one after it
+func configure(t *testing.T, decimalPlaces int, rounding RoundingMode) {
+	oldPlaces, oldMode := places, mode
+	t.Cleanup(func() { places, mode = oldPlaces, oldMode })
+	if err := Configure(decimalPlaces, rounding); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"12", 120000, false},
+		{"-0.5", -5000, false},
+		{"+3.25", 32500, false},
+		{" 7 ", 70000, false},
+		{".5", 5000, false},
+		{"5.", 50000, false},
+		{"18.0000", 180000, false},
+		{"1.23450", 12345, false},
+		{"-0.0001", -1, false},
+		{"922337203685477.5807", Money(1<<63 - 1), false},
+		{"-922337203685477.5807", -Money(1<<63 - 1), false},
+		{"1.23456", 0, true},
+		{"0.00001", 0, true},
+		{"922337203685477.5808", 0, true},
+		{"99999999999999999999", 0, true},
+		{"", 0, true},
+		{"-", 0, true},
+		{".", 0, true},
+		{"--1", 0, true},
+		{"+-1", 0, true},
+		{"1.2.3", 0, true},
+		{"1e3", 0, true},
+		{"12,50", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		n, d                   int64
+		halfUp, halfEven, down int64
+	}{
+		{20, 10, 2, 2, 2},
+		{24, 10, 2, 2, 2},
+		{25, 10, 3, 2, 2},
+		{26, 10, 3, 3, 2},
+		{35, 10, 4, 4, 3},
+		{-24, 10, -2, -2, -2},
+		{-25, 10, -3, -2, -2},
+		{-26, 10, -3, -3, -2},
+		{-35, 10, -4, -4, -3},
+		{25, -10, -3, -2, -2},
+		{-25, -10, 3, 2, 2},
+		{1, 3, 0, 0, 0},
+		{2, 3, 1, 1, 0},
+		{-2, 3, -1, -1, 0},
+	}
+	for _, tt := range tests {
+		for rounding, want := range map[RoundingMode]int64{HalfUp: tt.halfUp, HalfEven: tt.halfEven, Down: tt.down} {
+			if got := divRound(big.NewInt(tt.n), big.NewInt(tt.d), rounding); got != want {
+				t.Errorf("divRound(%d, %d, %d) = %d, want %d", tt.n, tt.d, rounding, got, want)
+			}
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in                     string
+		places                 int
+		halfUp, halfEven, down string
+	}{
+		{"2.345", 2, "2.3500", "2.3400", "2.3400"},
+		{"2.355", 2, "2.3600", "2.3600", "2.3500"},
+		{"-2.345", 2, "-2.3500", "-2.3400", "-2.3400"},
+		{"-2.355", 2, "-2.3600", "-2.3600", "-2.3500"},
+		{"2.5", 0, "3.0000", "2.0000", "2.0000"},
+		{"-0.5", 0, "-1.0000", "0.0000", "0.0000"},
+		{"1.2345", 4, "1.2345", "1.2345", "1.2345"},
+	}
+	for _, tt := range tests {
+		for rounding, want := range map[RoundingMode]string{HalfUp: tt.halfUp, HalfEven: tt.halfEven, Down: tt.down} {
+			if got := MustParse(tt.in).Round(tt.places, rounding).String(); got != want {
+				t.Errorf("%s.Round(%d, %d) = %s, want %s", tt.in, tt.places, rounding, got, want)
+			}
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount, percent string
+		rounding        RoundingMode
+		want            string
+	}{
+		{"10.00", "12.5", HalfEven, "1.2500"},
+		{"18.00", "-10", HalfEven, "-1.8000"},
+		{"0.0001", "50", HalfUp, "0.0001"},
+		{"0.0001", "50", HalfEven, "0.0000"},
+		{"0.0003", "50", HalfEven, "0.0002"},
+		{"0.0003", "50", Down, "0.0001"},
+		{"-0.0001", "50", HalfUp, "-0.0001"},
+		{"-0.0003", "50", HalfEven, "-0.0002"},
+		{"-0.0003", "50", Down, "-0.0001"},
+		{"-0.0003", "-50", HalfUp, "0.0002"},
+	}
+	for _, tt := range tests {
+		configure(t, 2, tt.rounding)
+		if got := MustParse(tt.amount).Percent(MustParse(tt.percent)).String(); got != tt.want {
+			t.Errorf("%s.Percent(%s) with rounding %d = %s, want %s", tt.amount, tt.percent, tt.rounding, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in       string
+		places   int
+		rounding RoundingMode
+		want     string
+	}{
+		{"12.345", 2, HalfEven, "12.34"},
+		{"12.345", 2, HalfUp, "12.35"},
+		{"-12.345", 2, HalfUp, "-12.35"},
+		{"-0.004", 2, HalfEven, "0.00"},
+		{"12.345", 0, Down, "12"},
+		{"12.345", 4, Down, "12.3450"},
+	}
+	for _, tt := range tests {
+		configure(t, tt.places, tt.rounding)
+		if got := MustParse(tt.in).Format(); got != tt.want {
+			t.Errorf("%s.Format() with %d places and rounding %d = %s, want %s", tt.in, tt.places, tt.rounding, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    Money
+		wantErr bool
+	}{
+		{"NUMERIC", []byte("12.3450"), 123450, false},
+		{"negative NUMERIC", []byte("-0.5000"), -5000, false},
+		{"text", "18", 180000, false},
+		{"integer", int64(3), 30000, false},
+		{"float", 1.23456, 12346, false},
+		{"NULL", nil, 0, false},
+		{"not a number", []byte("abc"), 0, true},
+		{"too many places", "1.23456", 0, true},
+		{"boolean", true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money(99)
+			err := m.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan(%v) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+			}
+			if !tt.wantErr && m != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONKeepsFullPrecision(t *testing.T) {
+	configure(t, 2, HalfEven)
+
+	for in, want := range map[string]string{
+		"12.345":  `"12.3450"`,
+		"-0.0001": `"-0.0001"`,
+		"18":      `"18.0000"`,
+	} {
+		out, err := json.Marshal(MustParse(in))
+		if err != nil {
+			t.Fatalf("Marshal(%s) error = %v", in, err)
+		}
+		if string(out) != want {
+			t.Errorf("Marshal(%s) = %s, want %s", in, out, want)
+		}
+
+		var back Money
+		if err := json.Unmarshal(out, &back); err != nil || back != MustParse(in) {
+			t.Errorf("Unmarshal(%s) = %s, %v, want %s", out, back, err, in)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{`"12.34"`, 123400, false},
+		{`12.34`, 123400, false},
+		{`-0.0001`, -1, false},
+		{` "7" `, 70000, false},
+		{`null`, 99, false},
+		{`"1.23456"`, 0, true},
+		{`1e3`, 0, true},
+		{`"12.34`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tt := range tests {
+		m := Money(99)
+		err := json.Unmarshal([]byte(tt.in), &m)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && m != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, m, tt.want)
+		}
+	}
+}
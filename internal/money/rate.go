@@ -0,0 +1,113 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// RateScale is the number of decimal places kept for exchange rates
+const RateScale = 8
+
+// rateUnit is the number of Rate units in 1
+const rateUnit = 100000000
+
+// Rate is an exact exchange rate stored as a count of 10^-8 units. A rate
+// for a currency is how much of that currency one unit of the base currency buys.
+type Rate int64
+
+// OneRate is the rate of the base currency against itself
+const OneRate = Rate(rateUnit)
+
+// ParseRate parses a plain decimal rate such as "0.92" or "149.5"
+func ParseRate(s string) (Rate, error) {
+	n, err := parseDecimal(s, RateScale)
+	return Rate(n), err
+}
+
+// IsPositive reports whether r is above zero
+func (r Rate) IsPositive() bool {
+	return r > 0
+}
+
+// String formats r with all RateScale decimal places
+func (r Rate) String() string {
+	return formatDecimal(int64(r), RateScale, RateScale)
+}
+
+// Convert converts m from the base currency using r, rounding half up to Scale places
+func (m Money) Convert(r Rate) Money {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(r)))
+	return Money(divRound(n, big.NewInt(rateUnit), HalfUp))
+}
+
+// ConvertBack converts m into the base currency from a currency with rate r,
+// rounding half up to Scale places
+func (m Money) ConvertBack(r Rate) Money {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(rateUnit))
+	return Money(divRound(n, big.NewInt(int64(r)), HalfUp))
+}
+
+// Scan implements sql.Scanner for NUMERIC columns; NULL scans as OneRate
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = OneRate
+		return nil
+	case []byte:
+		parsed, err := ParseRate(string(v))
+		if err != nil {
+			return err
+		}
+		*r = parsed
+		return nil
+	case string:
+		parsed, err := ParseRate(v)
+		if err != nil {
+			return err
+		}
+		*r = parsed
+		return nil
+	case int64:
+		*r = Rate(v * rateUnit)
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into Rate", src)
+}
+
+// Value implements driver.Valuer, writing the exact decimal as text
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// MarshalJSON encodes r as a string with full precision
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+// UnmarshalJSON accepts both "0.92" and 0.92
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid rate %s", s)
+		}
+		s = unquoted
+	}
+
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,103 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount, rate string
+		want         string
+	}{
+		{"18.00", "0.92", "16.5600"},
+		{"18.00", "149.5", "2691.0000"},
+		{"0.0001", "0.5", "0.0001"},
+		{"-0.0001", "0.5", "-0.0001"},
+		{"0.0001", "0.4", "0.0000"},
+		{"10.00", "1", "10.0000"},
+	}
+	for _, tt := range tests {
+		rate, err := ParseRate(tt.rate)
+		if err != nil {
+			t.Fatalf("ParseRate(%s) error = %v", tt.rate, err)
+		}
+		if got := MustParse(tt.amount).Convert(rate).String(); got != tt.want {
+			t.Errorf("%s.Convert(%s) = %s, want %s", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	amounts := []string{"0.0001", "0.0049", "18.00", "-19.4567", "12345.6789"}
+
+	// Converting into a currency worth less than the base and back is exact
+	for _, r := range []string{"1", "1.0837", "149.5", "7.8123456"} {
+		rate, _ := ParseRate(r)
+		for _, a := range amounts {
+			m := MustParse(a)
+			if back := m.Convert(rate).ConvertBack(rate); back != m {
+				t.Errorf("%s converted at %s and back = %s", a, r, back)
+			}
+		}
+	}
+
+	// Otherwise it is off by no more than the rounding of the converted amount
+	for _, r := range []string{"0.92", "0.0067", "0.5"} {
+		rate, _ := ParseRate(r)
+		for _, a := range amounts {
+			m := MustParse(a)
+			converted := m.Convert(rate)
+			back := converted.ConvertBack(rate)
+			if back.Convert(rate) != converted {
+				t.Errorf("%s converted at %s and back = %s, which converts to %s rather than %s",
+					a, r, back, back.Convert(rate), converted)
+			}
+		}
+	}
+
+	// ConvertBack undoes Convert for the amounts a currency can hold
+	rate, _ := ParseRate("0.92")
+	if got := MustParse("16.56").ConvertBack(rate).String(); got != "18.0000" {
+		t.Errorf("16.56.ConvertBack(0.92) = %s, want 18.0000", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"0.92", 92000000, false},
+		{"149.5", 14950000000, false},
+		{"0.00000001", 1, false},
+		{"0.000000001", 0, true},
+		{"x", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, %v, want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRateScanAndJSON(t *testing.T) {
+	var r Rate
+	if err := r.Scan(nil); err != nil || r != OneRate {
+		t.Errorf("Scan(nil) = %s, %v, want %s", r, err, OneRate)
+	}
+	if err := r.Scan([]byte("0.92000000")); err != nil || r.String() != "0.92000000" {
+		t.Errorf("Scan(0.92000000) = %s, %v", r, err)
+	}
+
+	out, err := json.Marshal(r)
+	if err != nil || string(out) != `"0.92000000"` {
+		t.Errorf("Marshal() = %s, %v, want \"0.92000000\"", out, err)
+	}
+	var back Rate
+	if err := json.Unmarshal([]byte(`0.92`), &back); err != nil || back != r {
+		t.Errorf("Unmarshal(0.92) = %s, %v, want %s", back, err, r)
+	}
+}
@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"northwind-api/internal/model"
+	"northwind-api/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// store is where the monitor finds late and at-risk orders, the database for repository.DB
+type store interface {
+	GetLateOrders(asOf time.Time) ([]model.Orders, error)
+	GetAtRiskOrders(asOf time.Time, days int) ([]model.Orders, error)
+}
+
+// Monitor periodically checks for late and at-risk orders and notifies about new ones
+type Monitor struct {
+	db         store
+	notifiers  []Notifier
+	interval   time.Duration
+	atRiskDays int
+
+	mu sync.Mutex
+	// notified holds, for each notifier, the orders of each kind it was told about
+	notified []map[string]map[int]bool
+}
+
+// New creates a monitor that checks every interval. The notifiers of a
+// MultiNotifier are kept track of separately, so one failing does not make
+// the others repeat notifications that reached them.
+func New(db *repository.DB, notifier Notifier, interval time.Duration, atRiskDays int) *Monitor {
+	return newMonitor(db, notifier, interval, atRiskDays)
+}
+
+func newMonitor(db store, notifier Notifier, interval time.Duration, atRiskDays int) *Monitor {
+	notifiers, ok := notifier.(MultiNotifier)
+	if !ok {
+		notifiers = MultiNotifier{notifier}
+	}
+
+	m := &Monitor{
+		db:         db,
+		notifiers:  notifiers,
+		interval:   interval,
+		atRiskDays: atRiskDays,
+		notified:   make([]map[string]map[int]bool, len(notifiers)),
+	}
+	for i := range m.notified {
+		m.notified[i] = map[string]map[int]bool{
+			KindLate:   {},
+			KindAtRisk: {},
+		}
+	}
+	return m
+}
+
+// Start runs the monitor in the background until ctx is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	go func() {
+		log.Info().Dur("interval", m.interval).Int("at_risk_days", m.atRiskDays).Msg("Order monitor started")
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			m.Check(ctx)
+
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Order monitor stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Check evaluates late and at-risk orders once and notifies about orders not reported before
+func (m *Monitor) Check(ctx context.Context) {
+	now := time.Now()
+
+	late, err := m.db.GetLateOrders(now)
+	if err != nil {
+		log.Error().Err(err).Msg("Order monitor failed to get late orders")
+	} else {
+		m.notify(ctx, KindLate, now, late)
+	}
+
+	atRisk, err := m.db.GetAtRiskOrders(now, m.atRiskDays)
+	if err != nil {
+		log.Error().Err(err).Msg("Order monitor failed to get at-risk orders")
+	} else {
+		m.notify(ctx, KindAtRisk, now, atRisk)
+	}
+}
+
+// notify sends each notifier the orders of a kind it has not been told about.
+// Orders that dropped out of the result (shipped, or moved from at risk to
+// late) are forgotten on every check, so an order that comes back is notified
+// again.
+func (m *Monitor) notify(ctx context.Context, kind string, checkedAt time.Time, orders []model.Orders) {
+	for i, notifier := range m.notifiers {
+		m.mu.Lock()
+		previous := m.notified[i][kind]
+		current := make(map[int]bool, len(orders))
+		var fresh []model.Orders
+		for _, order := range orders {
+			if previous[order.OrderId] {
+				current[order.OrderId] = true
+			} else {
+				fresh = append(fresh, order)
+			}
+		}
+		m.mu.Unlock()
+
+		if len(fresh) > 0 {
+			err := notifier.Notify(ctx, Notification{Kind: kind, CheckedAt: checkedAt, Orders: fresh})
+			if err != nil {
+				// Leave the fresh orders out so they are retried on the next check
+				log.Error().Err(err).Str("kind", kind).Int("count", len(fresh)).Type("notifier", notifier).Msg("Order monitor failed to send notification")
+			} else {
+				log.Info().Str("kind", kind).Int("count", len(fresh)).Type("notifier", notifier).Msg("Order monitor sent notification")
+				for _, order := range fresh {
+					current[order.OrderId] = true
+				}
+			}
+		}
+
+		m.mu.Lock()
+		m.notified[i][kind] = current
+		m.mu.Unlock()
+	}
+}
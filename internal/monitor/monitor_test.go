@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"northwind-api/internal/model"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOrders answers the monitor's queries with the orders it is given
+type fakeOrders struct {
+	mu        sync.Mutex
+	late      []model.Orders
+	atRisk    []model.Orders
+	lateErr   error
+	checks    int
+	asOf      time.Time
+	riskyDays int
+}
+
+func (s *fakeOrders) GetLateOrders(asOf time.Time) ([]model.Orders, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks++
+	s.asOf = asOf
+	return s.late, s.lateErr
+}
+
+func (s *fakeOrders) GetAtRiskOrders(asOf time.Time, days int) ([]model.Orders, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.riskyDays = days
+	return s.atRisk, nil
+}
+
+func (s *fakeOrders) set(late, atRisk []model.Orders, lateErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.late, s.atRisk, s.lateErr = late, atRisk, lateErr
+}
+
+func (s *fakeOrders) checked() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checks
+}
+
+// recorder is a notifier remembering the orders of each kind it was told
+// about, failing while it is told to
+type recorder struct {
+	mu       sync.Mutex
+	err      error
+	notified map[string][][]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{notified: map[string][][]int{}}
+}
+
+func (r *recorder) Notify(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	var ids []int
+	for _, order := range n.Orders {
+		ids = append(ids, order.OrderId)
+	}
+	r.notified[n.Kind] = append(r.notified[n.Kind], ids)
+	return nil
+}
+
+func (r *recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// take returns the orders notified of a kind since the last call
+func (r *recorder) take(kind string) [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	got := r.notified[kind]
+	delete(r.notified, kind)
+	return got
+}
+
+func orders(ids ...int) []model.Orders {
+	out := make([]model.Orders, len(ids))
+	for i, id := range ids {
+		out[i] = model.Orders{OrderId: id}
+	}
+	return out
+}
+
+func TestCheckNotifiesEachOrderOnce(t *testing.T) {
+	db := &fakeOrders{}
+	notifier := newRecorder()
+	m := newMonitor(db, notifier, time.Hour, 3)
+
+	steps := []struct {
+		name         string
+		late, atRisk []model.Orders
+		wantLate     [][]int
+		wantAtRisk   [][]int
+	}{
+		{"first check", orders(10248, 10249), orders(10250), [][]int{{10248, 10249}}, [][]int{{10250}}},
+		{"nothing new", orders(10248, 10249), orders(10250), nil, nil},
+		{"one shipped, one new", orders(10249, 10251), orders(10250), [][]int{{10251}}, nil},
+		{"at risk became late", orders(10249, 10250, 10251), nil, [][]int{{10250}}, nil},
+		{"shipped order came back", orders(10248, 10249, 10250, 10251), nil, [][]int{{10248}}, nil},
+		{"late order moved back to at risk", orders(10249, 10251), orders(10250), nil, [][]int{{10250}}},
+	}
+	for _, step := range steps {
+		db.set(step.late, step.atRisk, nil)
+		m.Check(context.Background())
+
+		if got := notifier.take(KindLate); !reflect.DeepEqual(got, step.wantLate) {
+			t.Errorf("%s: late notifications = %v, want %v", step.name, got, step.wantLate)
+		}
+		if got := notifier.take(KindAtRisk); !reflect.DeepEqual(got, step.wantAtRisk) {
+			t.Errorf("%s: at-risk notifications = %v, want %v", step.name, got, step.wantAtRisk)
+		}
+	}
+}
+
+func TestCheckAsksForTheConfiguredWindow(t *testing.T) {
+	db := &fakeOrders{}
+	m := newMonitor(db, newRecorder(), time.Hour, 5)
+
+	before := time.Now()
+	m.Check(context.Background())
+
+	if db.asOf.Before(before) || db.asOf.After(time.Now()) {
+		t.Errorf("checked as of %v, want the time of the check", db.asOf)
+	}
+	if db.riskyDays != 5 {
+		t.Errorf("at-risk window = %d days, want 5", db.riskyDays)
+	}
+}
+
+func TestCheckRetriesFailedNotifications(t *testing.T) {
+	db := &fakeOrders{late: orders(10248)}
+	notifier := newRecorder()
+	m := newMonitor(db, notifier, time.Hour, 3)
+
+	notifier.fail(errors.New("connection refused"))
+	m.Check(context.Background())
+
+	notifier.fail(nil)
+	m.Check(context.Background())
+	m.Check(context.Background())
+
+	if got, want := notifier.take(KindLate), [][]int{{10248}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("late notifications = %v, want %v once the notifier recovered", got, want)
+	}
+}
+
+func TestCheckRetriesOnlyTheNotifiersThatFailed(t *testing.T) {
+	db := &fakeOrders{late: orders(10248)}
+	working, failing := newRecorder(), newRecorder()
+	m := newMonitor(db, MultiNotifier{working, failing}, time.Hour, 3)
+
+	failing.fail(errors.New("connection refused"))
+	m.Check(context.Background())
+	failing.fail(nil)
+	db.set(orders(10248, 10249), nil, nil)
+	m.Check(context.Background())
+
+	if got, want := working.take(KindLate), [][]int{{10248}, {10249}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("working notifier got %v, want %v", got, want)
+	}
+	if got, want := failing.take(KindLate), [][]int{{10248, 10249}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failing notifier got %v, want %v", got, want)
+	}
+}
+
+func TestCheckCarriesOnAfterAQueryFails(t *testing.T) {
+	db := &fakeOrders{late: orders(10248), atRisk: orders(10250), lateErr: errors.New("timeout")}
+	notifier := newRecorder()
+	m := newMonitor(db, notifier, time.Hour, 3)
+
+	m.Check(context.Background())
+	if got := notifier.take(KindLate); got != nil {
+		t.Errorf("late notifications = %v after the query failed, want none", got)
+	}
+	if got, want := notifier.take(KindAtRisk), [][]int{{10250}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("at-risk notifications = %v, want %v", got, want)
+	}
+
+	db.set(orders(10248), orders(10250), nil)
+	m.Check(context.Background())
+	if got, want := notifier.take(KindLate), [][]int{{10248}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("late notifications = %v, want %v on the next check", got, want)
+	}
+}
+
+// waitFor polls until cond holds, failing the test after a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStartChecksRightAwayAndEveryInterval(t *testing.T) {
+	db := &fakeOrders{late: orders(10248)}
+	notifier := newRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newMonitor(db, notifier, time.Hour, 3).Start(ctx)
+	waitFor(t, "the first check", func() bool { return db.checked() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	if db.checked() != 1 {
+		t.Errorf("checked %d times within the first interval, want once", db.checked())
+	}
+	if got, want := notifier.take(KindLate), [][]int{{10248}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("late notifications = %v, want %v", got, want)
+	}
+
+	newMonitor(db, notifier, 5*time.Millisecond, 3).Start(ctx)
+	waitFor(t, "checks every interval", func() bool { return db.checked() >= 4 })
+}
+
+func TestStartStopsWhenCancelled(t *testing.T) {
+	db := &fakeOrders{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newMonitor(db, newRecorder(), time.Millisecond, 3).Start(ctx)
+	waitFor(t, "a check", func() bool { return db.checked() > 0 })
+	cancel()
+
+	// A check under way when cancelled may still finish
+	time.Sleep(10 * time.Millisecond)
+	stopped := db.checked()
+	time.Sleep(20 * time.Millisecond)
+	if db.checked() != stopped {
+		t.Errorf("checked %d more times after being cancelled", db.checked()-stopped)
+	}
+}
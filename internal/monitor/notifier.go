@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/model"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Kinds of order problems the monitor reports
+const (
+	KindLate   = "late"
+	KindAtRisk = "at_risk"
+)
+
+// Notification describes orders that newly became late or at risk
+type Notification struct {
+	Kind      string         `json:"kind"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Orders    []model.Orders `json:"orders"`
+}
+
+// Notifier delivers monitor notifications somewhere
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NewNotifier builds the notifier configured in MONITOR_NOTIFIERS
+func NewNotifier(cfg *appconfig.Config) (Notifier, error) {
+	var notifiers MultiNotifier
+	for _, name := range cfg.GetMonitorNotifiers() {
+		switch name {
+		case "log":
+			notifiers = append(notifiers, LogNotifier{})
+		case "webhook":
+			notifiers = append(notifiers, &WebhookNotifier{
+				URL:    cfg.MonitorWebhookURL,
+				Client: &http.Client{Timeout: 10 * time.Second},
+			})
+		case "smtp":
+			notifiers = append(notifiers, &SMTPNotifier{
+				Addr: cfg.SMTPHost + ":" + cfg.SMTPPort,
+				From: cfg.SMTPFrom,
+				To:   cfg.GetSMTPRecipients(),
+			})
+		default:
+			return nil, fmt.Errorf("unknown notifier %q", name)
+		}
+	}
+
+	return notifiers, nil
+}
+
+// MultiNotifier fans a notification out to several notifiers. Notify tries
+// every one and joins their errors, so retrying after an error repeats the
+// notification for those that succeeded; Monitor avoids that by keeping track
+// of each of them separately.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogNotifier writes notifications to the application log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	for _, order := range n.Orders {
+		log.Warn().
+			Str("kind", n.Kind).
+			Int("order_id", order.OrderId).
+			Str("customer_id", order.CustomerId.V).
+			Time("required_date", order.RequiredDate.V).
+			Msg("Order needs attention")
+	}
+
+	return nil
+}
+
+// WebhookNotifier POSTs notifications as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (wn *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wn.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := wn.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SMTPNotifier emails notifications through an SMTP server
+type SMTPNotifier struct {
+	Addr string
+	From string
+	To   []string
+}
+
+func (sn *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d order(s) flagged as %s at %s:\r\n\r\n", len(n.Orders), n.Kind, n.CheckedAt.Format(time.RFC1123))
+	for _, order := range n.Orders {
+		fmt.Fprintf(&body, "Order %d for %s, required %s, ship to %s\r\n",
+			order.OrderId, order.CustomerId.V, order.RequiredDate.V.Format("2006-01-02"), order.ShipName.V)
+	}
+
+	subject := fmt.Sprintf("[Northwind] %d %s order(s)", len(n.Orders), strings.ReplaceAll(n.Kind, "_", "-"))
+	msg := "From: " + sn.From + "\r\n" +
+		"To: " + strings.Join(sn.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + body.String()
+
+	// The local stand-in servers this targets do not require authentication
+	if err := smtp.SendMail(sn.Addr, nil, sn.From, sn.To, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+
+	return nil
+}
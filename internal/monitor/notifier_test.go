@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	appconfig "northwind-api/internal/config"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewNotifier(t *testing.T) {
+	tests := []struct {
+		notifiers string
+		want      []string
+		wantErr   bool
+	}{
+		{"log", []string{"monitor.LogNotifier"}, false},
+		{"log, webhook,smtp", []string{"monitor.LogNotifier", "*monitor.WebhookNotifier", "*monitor.SMTPNotifier"}, false},
+		{"", nil, false},
+		{"log,pager", nil, true},
+	}
+	for _, tt := range tests {
+		notifier, err := NewNotifier(&appconfig.Config{MonitorNotifiers: tt.notifiers})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewNotifier(%q) error = %v, wantErr %v", tt.notifiers, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+
+		var got []string
+		for _, n := range notifier.(MultiNotifier) {
+			got = append(got, reflect.TypeOf(n).String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NewNotifier(%q) = %v, want %v", tt.notifiers, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Notification
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s with %s, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode notification: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{URL: server.URL, Client: server.Client()}
+	n := Notification{Kind: KindLate, CheckedAt: time.Date(1996, 7, 4, 9, 30, 0, 0, time.UTC), Orders: orders(10248, 10249)}
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if received.Kind != KindLate || !received.CheckedAt.Equal(n.CheckedAt) || len(received.Orders) != 2 || received.Orders[1].OrderId != 10249 {
+		t.Errorf("received %+v, want %+v", received, n)
+	}
+
+	status = http.StatusBadGateway
+	if err := notifier.Notify(context.Background(), n); err == nil {
+		t.Error("Notify() succeeded when the webhook returned 502")
+	}
+}
+
+// notifierFunc adapts a function to the Notifier interface
+type notifierFunc func(ctx context.Context, n Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+func TestMultiNotifierTriesEveryNotifier(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	calls := 0
+	notifier := MultiNotifier{
+		notifierFunc(func(context.Context, Notification) error { calls++; return first }),
+		notifierFunc(func(context.Context, Notification) error { calls++; return nil }),
+		notifierFunc(func(context.Context, Notification) error { calls++; return second }),
+	}
+
+	err := notifier.Notify(context.Background(), Notification{Kind: KindLate})
+	if calls != 3 {
+		t.Errorf("called %d notifiers, want 3", calls)
+	}
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Errorf("Notify() error = %v, want both failures", err)
+	}
+}
@@ -0,0 +1,277 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.1.0"
+
+// DocsPage is an HTML page rendering the document served at /api/openapi.json with Redoc
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// #region Document
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower case method
+type PathItem map[string]*Operation
+
+// Operation documents one method of a path
+type Operation struct {
+	OperationId string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody lists the media types an operation accepts
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response lists the media types of one response status
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one media type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the named schemas referenced from the paths
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Operation returns the operation of a method and path template, or nil
+func (d *Document) Operation(method, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// #endregion
+
+// #region Routes
+
+// Route documents one route of a router
+type Route struct {
+	Method string
+	// Path is the template the route was registered with, such as /api/categories/{categoryId}
+	Path string
+	// Id is the operationId, by convention the name of the handler
+	Id      string
+	Summary string
+	Tag     string
+	// Params are the query parameters and any path parameters that are not
+	// integers; other path parameters are found in Path
+	Params    []Param
+	Body      Content
+	Responses map[int]Content
+}
+
+// Param documents a parameter. Type is a value of the Go type it is parsed
+// as, such as 0 for an integer or "" for a string.
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Type        any
+	Enum        []string
+}
+
+// Content maps media types to a body. A body is a value of the Go type it is
+// encoded from, a *Schema, or nil when the body has no schema.
+type Content map[string]any
+
+// Bodies without a JSON schema
+var (
+	Text   = &Schema{Type: "string"}
+	Binary = &Schema{Type: "string", Format: "binary"}
+)
+
+// Spec is the input to Build
+type Spec struct {
+	Info   Info
+	Routes []Route
+	// Default is the response documented for any status a route does not list
+	Default Content
+}
+
+// pathParam matches the variables of a mux path template
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+// Build documents the routes of a router that the spec documents. It also
+// returns the problems that make the document drift from the routes: every
+// route the router serves that the spec does not document, and every
+// documented route the router does not serve.
+func Build(spec Spec, router *mux.Router) (*Document, []string, error) {
+	registered := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		// Routes without methods, such as path prefixes, only group other routes
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registered[routeKey(method, path)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       spec.Info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	g := newGenerator(doc.Components.Schemas)
+
+	var problems []string
+	documented := map[string]bool{}
+	for _, route := range spec.Routes {
+		key := routeKey(route.Method, route.Path)
+		if documented[key] {
+			problems = append(problems, key+" is documented twice")
+			continue
+		}
+		documented[key] = true
+		if !registered[key] {
+			problems = append(problems, key+" is documented but not registered")
+			continue
+		}
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = g.operation(route, spec.Default)
+	}
+
+	var missing []string
+	for key := range registered {
+		if !documented[key] {
+			missing = append(missing, key+" is registered but not documented")
+		}
+	}
+	sort.Strings(missing)
+	problems = append(problems, missing...)
+
+	return doc, problems, nil
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// operation documents one route
+func (g *generator) operation(route Route, defaults Content) *Operation {
+	op := &Operation{
+		OperationId: route.Id,
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	params := map[string]Param{}
+	for _, p := range route.Params {
+		params[p.In+" "+p.Name] = p
+	}
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		p, ok := params["path "+match[1]]
+		if !ok {
+			p = Param{Name: match[1], In: "path", Type: 0}
+		}
+		p.Required = true
+		op.Parameters = append(op.Parameters, g.parameter(p))
+	}
+	for _, p := range route.Params {
+		if p.In != "path" {
+			op.Parameters = append(op.Parameters, g.parameter(p))
+		}
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: g.content(route.Body)}
+	}
+
+	for status, content := range route.Responses {
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     g.content(content),
+		}
+	}
+	if defaults != nil {
+		op.Responses["default"] = &Response{Description: "Error", Content: g.content(defaults)}
+	}
+	return op
+}
+
+func (g *generator) parameter(p Param) *Parameter {
+	schema := g.schemaOf(p.Type)
+	for _, option := range p.Enum {
+		schema.Enum = append(schema.Enum, option)
+	}
+	return &Parameter{
+		Name:        p.Name,
+		In:          p.In,
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      schema,
+	}
+}
+
+func (g *generator) content(content Content) map[string]*MediaType {
+	media := make(map[string]*MediaType, len(content))
+	for mediaType, body := range content {
+		media[mediaType] = &MediaType{Schema: g.schemaOf(body)}
+	}
+	return media
+}
+
+// #endregion
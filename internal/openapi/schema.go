@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"encoding/json"
+	"northwind-api/internal/money"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema, as used by OpenAPI 3.1. Type is a string, or a
+// list of strings for a value that may also be null.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Types reports the JSON types a schema allows, or nil for any type
+func (s *Schema) Types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+// RefName returns the component name of a $ref, or "" if s is not a reference
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	moneyType   = reflect.TypeFor[money.Money]()
+	rateType    = reflect.TypeFor[money.Rate]()
+	rawJSONType = reflect.TypeFor[json.RawMessage]()
+)
+
+// decimalPattern matches the decimal strings money amounts and rates are encoded as
+const decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// generator builds schemas from Go types, adding named structs to the components
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newGenerator(components map[string]*Schema) *generator {
+	return &generator{components: components, names: map[reflect.Type]string{}}
+}
+
+// schemaOf returns the schema of a body: a *Schema as is, nil as no schema,
+// and any other value as the schema of its type
+func (g *generator) schemaOf(v any) *Schema {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *Schema:
+		return v
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of a type. Named structs are referenced from the components.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case moneyType, rateType:
+		// Encoded as strings, but numbers are accepted too
+		return &Schema{Type: []string{"string", "number"}, Format: "decimal", Pattern: decimalPattern}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if isNull(t) {
+			v, _ := t.FieldByName("V")
+			return nullable(g.schema(v.Type))
+		}
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+	// Interfaces may hold anything
+	return &Schema{}
+}
+
+// ref returns a reference to the component of a named struct, adding it if needed
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		if _, taken := g.components[name]; taken {
+			name = upperFirst(path.Base(t.PkgPath())) + name
+		}
+		g.names[t] = name
+		// Reserve the name first, so recursive types reference it
+		g.components[name] = &Schema{}
+		*g.components[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object returns the schema of a struct's JSON encoding. Unknown properties
+// are not allowed, as request bodies are decoded strictly. The fields of an
+// embedded struct tagged `openapi:"partial"` are never required, for
+// responses that may leave any of them out.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	partial := map[int]bool{}
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous && f.Tag.Get("openapi") == "partial" {
+			partial[f.Index[0]] = true
+		}
+		if !f.IsExported() || (f.Anonymous && f.Tag.Get("json") == "") {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		field := g.schema(f.Type)
+		if required := constrain(field, f.Type, f.Tag.Get("validate")); required && !partial[f.Index[0]] {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = field
+	}
+	return s
+}
+
+// constrain adds the rules of a `validate:` tag to a field's schema, reporting
+// whether the field is required
+func constrain(s *Schema, t reflect.Type, tag string) (required bool) {
+	if tag == "" || s.Ref != "" {
+		return tag == "required" || strings.HasPrefix(tag, "required,")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isNull(t) {
+		v, _ := t.FieldByName("V")
+		t = v.Type
+	}
+
+	for _, entry := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(entry, "=")
+		switch name {
+		case "required":
+			required = true
+			if t.Kind() == reflect.Slice {
+				s.MinItems = intPtr(1)
+			} else if t.Kind() == reflect.String {
+				s.MinLength = intPtr(1)
+			}
+		case "min", "max":
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case t.Kind() == reflect.String && name == "min":
+				s.MinLength = intPtr(int(bound))
+			case t.Kind() == reflect.String:
+				s.MaxLength = intPtr(int(bound))
+			case t.Kind() == reflect.Slice && name == "min":
+				s.MinItems = intPtr(int(bound))
+			case t.Kind() == reflect.Slice:
+				s.MaxItems = intPtr(int(bound))
+			case name == "min":
+				s.Minimum = &bound
+			default:
+				s.Maximum = &bound
+			}
+		case "gt":
+			if bound, err := strconv.ParseFloat(param, 64); err == nil {
+				s.ExclusiveMinimum = &bound
+			}
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				s.Enum = append(s.Enum, option)
+			}
+			if s.Enum != nil && len(s.Types()) > 1 {
+				s.Enum = append(s.Enum, nil)
+			}
+		case "country":
+			s.Description = "ISO 3166 country code or country name"
+		case "currency":
+			s.Pattern = "^([A-Za-z]{3})?$"
+			s.Description = "ISO 4217 currency code"
+		case "gtfield":
+			s.Description = "Must be after " + param
+		case "gtefield":
+			s.Description = "Must not be before " + param
+		}
+	}
+	return required
+}
+
+// nullable allows null as well as the values of s
+func nullable(s *Schema) *Schema {
+	types := s.Types()
+	switch {
+	case s.Ref != "":
+		return &Schema{OneOf: []*Schema{s, {Type: "null"}}}
+	case types == nil:
+		return s
+	}
+	for _, t := range types {
+		if t == "null" {
+			return s
+		}
+	}
+	s.Type = append(append([]string{}, types...), "null")
+	return s
+}
+
+// isNull reports whether a type is a nullable wrapper with V and Valid fields
+func isNull(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	_, hasV := t.FieldByName("V")
+	return ok && hasV && valid.Type.Kind() == reflect.Bool
+}
+
+// componentName names the component of a struct after its type, so
+// orderRequest is OrderRequest and page[model.Orders] is PageOrders
+func componentName(t reflect.Type) string {
+	base, args, generic := strings.Cut(t.Name(), "[")
+	name := upperFirst(base)
+	if generic {
+		for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+			name += upperFirst(arg[strings.LastIndex(arg, ".")+1:])
+		}
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,474 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"northwind-api/internal/patch"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation is one way a request or response does not match the document.
+// Path is a JSON pointer into the body, or the name of a parameter, and
+// SchemaPath points at the keyword of the document that failed.
+type Violation struct {
+	In         string `json:"in"`
+	Path       string `json:"path"`
+	SchemaPath string `json:"schema_path"`
+	Message    string `json:"message"`
+}
+
+// ndjsonType is the media type of newline delimited JSON, checked line by line
+const ndjsonType = "application/x-ndjson"
+
+// IsJSON reports whether bodies of a media type are JSON, and so can be checked against a schema
+func IsJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == ndjsonType
+}
+
+// #region Requests and responses
+
+// ValidateRequest checks the parameters and body of a request against the
+// operation documented for its method and path template. vars are the path
+// variables. The body is only checked when it is in a documented JSON media
+// type and parses as JSON; other problems are left for the handler to report.
+func (d *Document) ValidateRequest(method, path string, vars map[string]string, query url.Values, mediaType string, body []byte) []Violation {
+	op := d.Operation(method, path)
+	if op == nil {
+		return nil
+	}
+	base := "#/paths/" + escapePointer(path) + "/" + strings.ToLower(method)
+	v := &validation{doc: d}
+
+	for i, p := range op.Parameters {
+		schemaPath := fmt.Sprintf("%s/parameters/%d", base, i)
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw, present = vars[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		default:
+			continue
+		}
+
+		v.in = p.In
+		if !present {
+			if p.Required {
+				v.fail(p.Name, schemaPath+"/required", "is required")
+			}
+			continue
+		}
+		value, ok := parseParam(p.Schema, raw)
+		if !ok {
+			v.fail(p.Name, schemaPath+"/schema/type", "must be "+describeTypes(p.Schema.Types()))
+			continue
+		}
+		v.check(p.Schema, value, p.Name, schemaPath+"/schema")
+	}
+
+	if op.RequestBody == nil || !IsJSON(mediaType) {
+		return v.violations
+	}
+	media, ok := op.RequestBody.Content[mediaType]
+	if !ok || media.Schema == nil {
+		return v.violations
+	}
+	v.in = "body"
+	// Merge patches may leave out required properties and null any property
+	v.partial = mediaType == patch.MergePatchType
+	v.checkBody(media.Schema, mediaType, body, base+"/requestBody/content/"+escapePointer(mediaType)+"/schema")
+	return v.violations
+}
+
+// ValidateResponse checks the status, media type and body of a response
+// against the operation documented for a method and path template. A nil body
+// is not checked, for responses that were streamed rather than held back.
+func (d *Document) ValidateResponse(method, path string, status int, mediaType string, body []byte) []Violation {
+	op := d.Operation(method, path)
+	if op == nil {
+		return nil
+	}
+	base := "#/paths/" + escapePointer(path) + "/" + strings.ToLower(method) + "/responses"
+	v := &validation{doc: d, in: "response"}
+
+	key := strconv.Itoa(status)
+	response, ok := op.Responses[key]
+	if !ok {
+		key = "default"
+		if response, ok = op.Responses[key]; !ok {
+			v.fail("", base, fmt.Sprintf("status %d is not documented", status))
+			return v.violations
+		}
+	}
+	base += "/" + key
+	if len(response.Content) == 0 {
+		return nil
+	}
+
+	media, ok := response.Content[mediaType]
+	if !ok {
+		v.fail("", base+"/content", fmt.Sprintf("Content-Type %q is not documented for status %d", mediaType, status))
+		return v.violations
+	}
+	if body != nil && media.Schema != nil && IsJSON(mediaType) {
+		v.checkBody(media.Schema, mediaType, body, base+"/content/"+escapePointer(mediaType)+"/schema")
+	}
+	return v.violations
+}
+
+// checkBody checks a JSON body, or each line of an NDJSON body
+func (v *validation) checkBody(s *Schema, mediaType string, body []byte, schemaPath string) {
+	if mediaType != ndjsonType {
+		if value, ok := parseJSON(body); ok {
+			v.check(s, value, "", schemaPath)
+		}
+		return
+	}
+
+	for i, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if value, ok := parseJSON(line); ok {
+			v.check(s, value, "/"+strconv.Itoa(i), schemaPath)
+		}
+	}
+}
+
+func parseJSON(data []byte) (any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// parseParam converts a parameter to the JSON value its schema describes
+func parseParam(s *Schema, raw string) (any, bool) {
+	types := s.Types()
+	for _, t := range types {
+		switch t {
+		case "string":
+			return raw, true
+		case "integer":
+			if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return json.Number(raw), true
+			}
+		case "number":
+			if _, err := strconv.ParseFloat(raw, 64); err == nil {
+				return json.Number(raw), true
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(raw); err == nil {
+				return b, true
+			}
+		}
+	}
+	return raw, types == nil
+}
+
+// #endregion
+
+// #region Schemas
+
+// validation collects the violations of a value against a schema
+type validation struct {
+	doc        *Document
+	in         string
+	partial    bool
+	violations []Violation
+}
+
+func (v *validation) fail(path, schemaPath, message string) {
+	v.violations = append(v.violations, Violation{In: v.in, Path: path, SchemaPath: schemaPath, Message: message})
+}
+
+// check checks a value decoded with UseNumber against a schema
+func (v *validation) check(s *Schema, value any, path, schemaPath string) {
+	if s.Ref != "" {
+		target, ok := v.doc.Components.Schemas[s.RefName()]
+		if !ok {
+			v.fail(path, schemaPath+"/$ref", "references an unknown schema")
+			return
+		}
+		v.check(target, value, path, "#/components/schemas/"+escapePointer(s.RefName()))
+		return
+	}
+
+	if s.OneOf != nil {
+		v.checkOneOf(s, value, path, schemaPath)
+		return
+	}
+
+	if types := s.Types(); types != nil && !hasType(types, value) {
+		v.fail(path, schemaPath+"/type", "must be "+describeTypes(types))
+		return
+	}
+	if value == nil {
+		return
+	}
+	if s.Enum != nil && !inEnum(s.Enum, value) {
+		options := make([]string, 0, len(s.Enum))
+		for _, option := range s.Enum {
+			if option != nil {
+				options = append(options, fmt.Sprint(option))
+			}
+		}
+		v.fail(path, schemaPath+"/enum", "must be one of: "+strings.Join(options, ", "))
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.checkString(s, value, path, schemaPath)
+	case json.Number:
+		if f, err := value.Float64(); err == nil {
+			v.checkBounds(s, f, path, schemaPath)
+		}
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			v.fail(path, schemaPath+"/minItems", fmt.Sprintf("must have at least %d items", *s.MinItems))
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			v.fail(path, schemaPath+"/maxItems", fmt.Sprintf("must have at most %d items", *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				v.check(s.Items, item, path+"/"+strconv.Itoa(i), schemaPath+"/items")
+			}
+		}
+	case map[string]any:
+		v.checkObject(s, value, path, schemaPath)
+	}
+}
+
+// checkOneOf checks a value matches exactly one of a schema's options. When
+// it matches none, the problems are reported against the first option that
+// allows the value's type, as that is the one the value was most likely meant for.
+func (v *validation) checkOneOf(s *Schema, value any, path, schemaPath string) {
+	var matched []int
+	var closest *validation
+	for i, option := range s.OneOf {
+		sub := &validation{doc: v.doc, in: v.in, partial: v.partial}
+		sub.check(option, value, path, fmt.Sprintf("%s/oneOf/%d", schemaPath, i))
+		if len(sub.violations) == 0 {
+			matched = append(matched, i)
+			continue
+		}
+		if closest == nil && v.allowsType(option, value) {
+			closest = sub
+		}
+	}
+
+	switch {
+	case len(matched) == 1:
+	case len(matched) > 1:
+		v.fail(path, schemaPath+"/oneOf", "must match exactly one schema")
+	case closest != nil:
+		v.violations = append(v.violations, closest.violations...)
+	default:
+		v.fail(path, schemaPath+"/oneOf", "does not match any of the allowed types")
+	}
+}
+
+// allowsType reports whether a schema, following references, allows the JSON type of a value
+func (v *validation) allowsType(s *Schema, value any) bool {
+	for s.Ref != "" {
+		target, ok := v.doc.Components.Schemas[s.RefName()]
+		if !ok {
+			return false
+		}
+		s = target
+	}
+	types := s.Types()
+	return types == nil || hasType(types, value)
+}
+
+func (v *validation) checkString(s *Schema, value, path, schemaPath string) {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(path, schemaPath+"/minLength", fmt.Sprintf("must be at least %d characters", *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, schemaPath+"/maxLength", fmt.Sprintf("must be at most %d characters", *s.MaxLength))
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err == nil && !re.MatchString(value) {
+			v.fail(path, schemaPath+"/pattern", "must match "+s.Pattern)
+			return
+		}
+	}
+
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			v.fail(path, schemaPath+"/format", "must be an RFC 3339 date-time")
+		}
+	case "decimal":
+		// Amounts encoded as strings are held to the numeric bounds as well
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			v.checkBounds(s, f, path, schemaPath)
+		}
+	}
+}
+
+func (v *validation) checkBounds(s *Schema, f float64, path, schemaPath string) {
+	if s.Minimum != nil && f < *s.Minimum {
+		v.fail(path, schemaPath+"/minimum", "must be at least "+formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		v.fail(path, schemaPath+"/maximum", "must be at most "+formatNumber(*s.Maximum))
+	}
+	if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+		v.fail(path, schemaPath+"/exclusiveMinimum", "must be greater than "+formatNumber(*s.ExclusiveMinimum))
+	}
+}
+
+// checkObject checks the properties of an object. The first object of a
+// partial validation is a merge patch, so its required properties may be
+// missing and its properties may be null to remove them.
+func (v *validation) checkObject(s *Schema, value map[string]any, path, schemaPath string) {
+	partial := v.partial
+	v.partial = false
+
+	if !partial {
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				v.fail(path+"/"+escapePointer(name), schemaPath+"/required", "is required")
+			}
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := value[name]
+		propertyPath := path + "/" + escapePointer(name)
+		if partial && property == nil {
+			continue
+		}
+		if schema, ok := s.Properties[name]; ok {
+			v.check(schema, property, propertyPath, schemaPath+"/properties/"+escapePointer(name))
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				v.fail(propertyPath, schemaPath+"/additionalProperties", "is not allowed")
+			}
+		case *Schema:
+			v.check(additional, property, propertyPath, schemaPath+"/additionalProperties")
+		}
+	}
+}
+
+// hasType reports whether a value decoded with UseNumber is one of the JSON types
+func hasType(types []string, value any) bool {
+	for _, t := range types {
+		switch value := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			// Integers must decode into Go ints, so 1.0 is not one
+			if _, err := value.Int64(); err == nil && t == "integer" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(options []any, value any) bool {
+	for _, option := range options {
+		if option == nil {
+			if value == nil {
+				return true
+			}
+			continue
+		}
+		if fmt.Sprint(option) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTypes names the JSON types a value may have, such as "a string or null"
+func describeTypes(types []string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "null":
+			names[i] = "null"
+		case "integer", "array", "object":
+			names[i] = "an " + t
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escapePointer escapes a JSON pointer token
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// patterns caches compiled schema patterns
+var patterns sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// #endregion
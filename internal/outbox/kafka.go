@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"northwind-api/internal/events"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Header carrying the event id, which consumers deduplicate by
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// KafkaSink writes events to a topic keyed by <resource>/<resource id>, so the
+// events of one resource go to one partition and are read in order
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink returns a sink writing to a topic on the brokers. Brokers are
+// connected to when events are first written.
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// Each batch is written as soon as it is handed over rather than
+		// waiting for more messages that are not coming
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, evs []events.Event) error {
+	msgs, err := kafkaMessages(evs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("failed to write events to Kafka: %w", err)
+	}
+	return nil
+}
+
+// kafkaMessages returns the messages events are written as
+func kafkaMessages(evs []events.Event) ([]kafka.Message, error) {
+	msgs := make([]kafka.Message, len(evs))
+	for i, e := range evs {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the %s event: %w", e.Type, err)
+		}
+
+		msgs[i] = kafka.Message{
+			Key:   []byte(e.Resource + "/" + e.ResourceId),
+			Value: body,
+			Time:  e.OccurredAt,
+			Headers: []kafka.Header{
+				{Key: IdempotencyKeyHeader, Value: []byte(e.Id)},
+				{Key: "Event-Type", Value: []byte(e.Type)},
+			},
+		}
+	}
+	return msgs, nil
+}
+
+// Close flushes and closes the writer
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"northwind-api/internal/events"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSSink publishes events to JetStream on the subject <prefix>.<event type>,
+// one at a time so they are stored in order. The event id is sent as the
+// Nats-Msg-Id, so JetStream drops an event published again within the
+// stream's duplicate window.
+type NATSSink struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+}
+
+// NewNATSSink connects to the NATS server at url
+func NewNATSSink(url, prefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("northwind-api"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	return &NATSSink{conn: conn, js: js, prefix: prefix}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(ctx context.Context, evs []events.Event) error {
+	for _, e := range evs {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode the %s event: %w", e.Type, err)
+		}
+
+		if _, err := s.js.Publish(ctx, s.prefix+"."+e.Type, body, jetstream.WithMsgID(e.Id)); err != nil {
+			return fmt.Errorf("failed to publish event %s to NATS: %w", e.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// Close drains the connection to the NATS server
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
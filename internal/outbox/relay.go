@@ -0,0 +1,146 @@
+// Package outbox relays the events recorded in the outbox table to the sinks
+// they are published to, such as the webhook dispatcher, NATS or Kafka. Events
+// are recorded by the transaction that makes their change, so a change is
+// never committed without its events, and relayed from there at least once,
+// surviving a crash between the commit and the publish.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/events"
+	"northwind-api/internal/repository"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Longest a batch may take to publish before it is given up and retried
+	publishTimeout = time.Minute
+	// Longest wait between attempts at a batch that keeps failing
+	maxBackoff = time.Minute
+	// How often published events past their retention are pruned
+	pruneInterval = time.Hour
+)
+
+// store is the outbox the relay publishes from, kept in the database by repository.DB
+type store interface {
+	RelayOutbox(ctx context.Context, limit int, publish func(context.Context, []events.Event) error) (int, error)
+	PruneOutbox(before time.Time) (int64, error)
+}
+
+// Relay publishes the events in the outbox to every sink, in the order
+// repository.DB.RelayOutbox hands them over. A batch is only marked published
+// once every sink has it, so when one sink fails the whole batch is published
+// again to all of them.
+type Relay struct {
+	db    store
+	sinks []Sink
+
+	batchSize    int
+	pollInterval time.Duration
+	retention    time.Duration
+}
+
+// New creates a relay to the sinks configured by the OUTBOX_ settings
+func New(db *repository.DB, sinks []Sink, cfg *appconfig.Config) *Relay {
+	return &Relay{
+		db:           db,
+		sinks:        sinks,
+		batchSize:    cfg.OutboxBatchSize,
+		pollInterval: cfg.OutboxPollInterval,
+		retention:    cfg.OutboxRetention,
+	}
+}
+
+// Start relays events in the background until ctx is cancelled, then closes the sinks
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		names := make([]string, len(r.sinks))
+		for i, sink := range r.sinks {
+			names[i] = sink.Name()
+		}
+		log.Info().Strs("sinks", names).Dur("poll_interval", r.pollInterval).Msg("Outbox relay started")
+
+		wait := r.pollInterval
+		lastPrune := time.Time{}
+		for {
+			if err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				// Back off while a sink is down rather than hammering it
+				wait = min(2*wait, maxBackoff)
+				log.Error().Err(err).Dur("retry_in", wait).Msg("Failed to relay outbox events")
+			} else {
+				wait = r.pollInterval
+			}
+
+			if time.Since(lastPrune) >= pruneInterval {
+				r.prune()
+				lastPrune = time.Now()
+			}
+
+			select {
+			case <-ctx.Done():
+				r.close()
+				log.Info().Msg("Outbox relay stopped")
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// RelayPending publishes every unpublished event, a batch at a time
+func (r *Relay) RelayPending(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := r.db.RelayOutbox(ctx, r.batchSize, r.publish)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Debug().Int("events", n).Msg("Relayed outbox events")
+		}
+		if n < r.batchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// publish hands a batch to every sink in turn
+func (r *Relay) publish(ctx context.Context, evs []events.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, evs); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// prune deletes the events published longer ago than the retention
+func (r *Relay) prune() {
+	n, err := r.db.PruneOutbox(time.Now().Add(-r.retention))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to prune the outbox")
+		return
+	}
+	if n > 0 {
+		log.Info().Int64("events", n).Msg("Pruned published outbox events")
+	}
+}
+
+// close closes the sinks holding connections
+func (r *Relay) close() {
+	for _, sink := range r.sinks {
+		if c, ok := sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Warn().Err(err).Str("sink", sink.Name()).Msg("Failed to close outbox sink")
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"northwind-api/internal/events"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeOutbox keeps events in memory the way repository.DB keeps them in the
+// outbox table, marking a batch published only once publish succeeds
+type fakeOutbox struct {
+	evs       []events.Event
+	published []bool
+}
+
+func newFakeOutbox(n int) *fakeOutbox {
+	o := &fakeOutbox{published: make([]bool, n)}
+	for i := range n {
+		o.evs = append(o.evs, events.Event{
+			Id:         fmt.Sprintf("evt_%d", i+1),
+			Type:       events.ProductUpdated,
+			Resource:   "product",
+			ResourceId: fmt.Sprint(i + 1),
+			OccurredAt: time.Now(),
+			Data:       []byte(`{}`),
+		})
+	}
+	return o
+}
+
+func (o *fakeOutbox) RelayOutbox(ctx context.Context, limit int, publish func(context.Context, []events.Event) error) (int, error) {
+	var batch []int
+	for i := range o.evs {
+		if !o.published[i] && len(batch) < limit {
+			batch = append(batch, i)
+		}
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	evs := make([]events.Event, len(batch))
+	for i, j := range batch {
+		evs[i] = o.evs[j]
+	}
+	if err := publish(ctx, evs); err != nil {
+		return 0, err
+	}
+	for _, j := range batch {
+		o.published[j] = true
+	}
+	return len(batch), nil
+}
+
+func (o *fakeOutbox) PruneOutbox(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakeSink records the events it publishes, failing the first calls it is told to
+type fakeSink struct {
+	name     string
+	failures int
+	received []string
+}
+
+func (s *fakeSink) Name() string {
+	return s.name
+}
+
+func (s *fakeSink) Publish(ctx context.Context, evs []events.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("broker unavailable")
+	}
+	for _, e := range evs {
+		s.received = append(s.received, e.Id)
+	}
+	return nil
+}
+
+func TestRelayPublishesInBatches(t *testing.T) {
+	db := newFakeOutbox(5)
+	sink := &fakeSink{name: "fake"}
+	r := &Relay{db: db, sinks: []Sink{sink}, batchSize: 2}
+
+	if err := r.RelayPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"evt_1", "evt_2", "evt_3", "evt_4", "evt_5"}
+	if !slices.Equal(sink.received, want) {
+		t.Errorf("sink received %v, want %v", sink.received, want)
+	}
+	if slices.Contains(db.published, false) {
+		t.Errorf("published = %v, want every event published", db.published)
+	}
+}
+
+func TestRelayRepublishesAfterPublishError(t *testing.T) {
+	db := newFakeOutbox(2)
+	first := &fakeSink{name: "first"}
+	second := &fakeSink{name: "second", failures: 1}
+	r := &Relay{db: db, sinks: []Sink{first, second}, batchSize: 10}
+
+	err := r.RelayPending(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "second sink") {
+		t.Fatalf("RelayPending() error = %v, want the second sink's failure", err)
+	}
+	if slices.Contains(db.published, true) {
+		t.Fatalf("published = %v, want nothing marked after a failed publish", db.published)
+	}
+
+	if err := r.RelayPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(db.published, false) {
+		t.Errorf("published = %v, want every event published", db.published)
+	}
+	// The sink that had the batch is given it again: at least once, not exactly once
+	if want := []string{"evt_1", "evt_2", "evt_1", "evt_2"}; !slices.Equal(first.received, want) {
+		t.Errorf("first sink received %v, want %v", first.received, want)
+	}
+	if want := []string{"evt_1", "evt_2"}; !slices.Equal(second.received, want) {
+		t.Errorf("second sink received %v, want %v", second.received, want)
+	}
+}
+
+func TestRelayStopsWhenCancelled(t *testing.T) {
+	db := newFakeOutbox(3)
+	sink := &fakeSink{name: "fake"}
+	r := &Relay{db: db, sinks: []Sink{sink}, batchSize: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.RelayPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.received) != 0 {
+		t.Errorf("sink received %v after cancellation, want nothing", sink.received)
+	}
+}
@@ -0,0 +1,67 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/events"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Sink is somewhere committed events are published. Publish is given a batch
+// of events in the order they are relayed and must not return until they
+// are published, or return an error if any are not. Events are published at
+// least once, so a sink may be given an event again and must pass on its id
+// for consumers to deduplicate by, or drop the repeat itself.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, evs []events.Event) error
+}
+
+// NewSinks builds the sinks configured in OUTBOX_SINKS. The webhooks sink is
+// built by the caller, as it is also the webhook dispatcher.
+func NewSinks(cfg *appconfig.Config, webhooks Sink) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.GetOutboxSinks() {
+		switch name {
+		case "webhooks":
+			sinks = append(sinks, webhooks)
+		case "log":
+			sinks = append(sinks, LogSink{})
+		case "nats":
+			sink, err := NewNATSSink(cfg.NATSURL, cfg.NATSSubjectPrefix)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "kafka":
+			sinks = append(sinks, NewKafkaSink(cfg.GetKafkaBrokers(), cfg.KafkaTopic))
+		default:
+			return nil, fmt.Errorf("unknown sink %q", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+// LogSink writes events to the application log
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Publish(ctx context.Context, evs []events.Event) error {
+	for _, e := range evs {
+		log.Info().
+			Str("event_id", e.Id).
+			Str("event_type", e.Type).
+			Str("resource_id", e.ResourceId).
+			Time("occurred_at", e.OccurredAt).
+			RawJSON("data", e.Data).
+			Msg("Event published")
+	}
+
+	return nil
+}
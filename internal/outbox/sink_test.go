@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/events"
+	"testing"
+	"time"
+)
+
+func TestNewSinks(t *testing.T) {
+	webhooks := &fakeSink{name: "webhooks"}
+
+	sinks, err := NewSinks(&appconfig.Config{OutboxSinks: "webhooks, log"}, webhooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sinks) != 2 || sinks[0] != Sink(webhooks) || sinks[1].Name() != "log" {
+		t.Errorf("sinks = %v, want the webhooks and log sinks", sinks)
+	}
+
+	if _, err := NewSinks(&appconfig.Config{OutboxSinks: "carrier-pigeon"}, webhooks); err == nil {
+		t.Error("NewSinks() accepted an unknown sink")
+	}
+}
+
+func TestKafkaMessages(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	msgs, err := kafkaMessages([]events.Event{{
+		Id: "evt_1", Type: events.OrderShipped, Resource: "order", ResourceId: "10248",
+		OccurredAt: at, Data: []byte(`{"order_id":10248}`),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := msgs[0]
+	// Keyed by resource so one resource's events share a partition and stay in order
+	if string(msg.Key) != "order/10248" {
+		t.Errorf("key = %s, want order/10248", msg.Key)
+	}
+	if !msg.Time.Equal(at) {
+		t.Errorf("time = %v, want %v", msg.Time, at)
+	}
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	if headers[IdempotencyKeyHeader] != "evt_1" || headers["Event-Type"] != events.OrderShipped {
+		t.Errorf("headers = %v, want the event id and type", headers)
+	}
+}
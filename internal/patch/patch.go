@@ -0,0 +1,176 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// Patch media types. Plain application/json bodies are read as merge patches.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ErrUnsupportedMediaType is returned for bodies that are neither kind of patch
+var ErrUnsupportedMediaType = errors.New("unsupported patch media type")
+
+// Document is a parsed RFC 7396 JSON Merge Patch or RFC 6902 JSON Patch
+type Document struct {
+	merge map[string]any
+	ops   []Operation
+}
+
+// Parse reads a patch body of the given Content-Type
+func Parse(contentType string, body []byte) (*Document, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && contentType != "" {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	switch mediaType {
+	case MergePatchType, "application/json", "":
+		var merge map[string]any
+		if err := decode(body, &merge); err != nil {
+			return nil, err
+		}
+		if merge == nil {
+			return nil, fmt.Errorf("merge patch must be a JSON object")
+		}
+		return &Document{merge: merge}, nil
+	case JSONPatchType:
+		var ops []Operation
+		if err := decode(body, &ops); err != nil {
+			return nil, err
+		}
+		for i, op := range ops {
+			if err := op.validate(); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+		return &Document{ops: ops}, nil
+	}
+
+	return nil, ErrUnsupportedMediaType
+}
+
+// decode unmarshals a single JSON value keeping numbers exact
+func decode(body []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid JSON: the body must contain a single JSON value")
+	}
+	return nil
+}
+
+// Fields returns the top-level fields the patch changes
+func (d *Document) Fields() map[string]bool {
+	fields := map[string]bool{}
+	for name := range d.merge {
+		fields[name] = true
+	}
+	for _, op := range d.ops {
+		// move also changes the field it takes the value from
+		paths := []string{op.Path}
+		switch op.Op {
+		case "test":
+			continue
+		case "move":
+			paths = append(paths, op.From)
+		}
+		for _, path := range paths {
+			tokens, err := parsePointer(path)
+			if err == nil && len(tokens) > 0 {
+				fields[tokens[0]] = true
+			}
+		}
+	}
+	return fields
+}
+
+// Apply patches the JSON encoding of current and decodes the result into a
+// new value. Fields removed by the patch come back as zero values, which the
+// model's Null types store as NULL. Patching a field the type does not have
+// is an error.
+func Apply[T any](d *Document, current *T) (*T, error) {
+	known := jsonFields(reflect.TypeFor[T]())
+	for field := range d.Fields() {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := decode(encoded, &doc); err != nil {
+		return nil, err
+	}
+
+	if d.merge != nil {
+		doc = Merge(doc, d.merge)
+	} else if doc, err = ApplyOperations(doc, d.ops); err != nil {
+		return nil, err
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	next := new(T)
+	if err := json.Unmarshal(patched, next); err != nil {
+		return nil, fmt.Errorf("patched document is invalid: %w", err)
+	}
+	return next, nil
+}
+
+// jsonFields returns the JSON names of a struct type's encoded fields
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
+
+// Merge applies an RFC 7396 merge patch to a decoded JSON document
+func Merge(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = Merge(targetObject[name], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,48 @@
+package patch
+
+import (
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"testing"
+)
+
+func TestApplyKeepsAmountsExact(t *testing.T) {
+	current := &model.Products{
+		ProductId:   1,
+		ProductName: "Chai",
+		UnitPrice:   model.NewNull(money.MustParse("18.0049")),
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantName    string
+		wantPrice   string
+	}{
+		{"untouched amount", MergePatchType, `{"product_name": "Chai Tea"}`, "Chai Tea", "18.0049"},
+		{"amount below the rounding", MergePatchType, `{"unit_price": "18.0040"}`, "Chai", "18.0040"},
+		{"JSON Patch", JSONPatchType, `[{"op": "replace", "path": "/unit_price", "value": "18.0041"}]`, "Chai", "18.0041"},
+		{"test against the exact amount", JSONPatchType,
+			`[{"op": "test", "path": "/unit_price", "value": "18.0049"}, {"op": "replace", "path": "/product_name", "value": "Chai Tea"}]`,
+			"Chai Tea", "18.0049"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.contentType, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			next, err := Apply(doc, current)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if next.ProductName != tt.wantName {
+				t.Errorf("product_name = %q, want %q", next.ProductName, tt.wantName)
+			}
+			if !next.UnitPrice.Valid || next.UnitPrice.V.String() != tt.wantPrice {
+				t.Errorf("unit_price = %v, want %s", next.UnitPrice, tt.wantPrice)
+			}
+		})
+	}
+}
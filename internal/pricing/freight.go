@@ -0,0 +1,60 @@
+package pricing
+
+import (
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"strings"
+)
+
+// specificity ranks how closely a rate targets a destination; higher is more specific
+func specificity(rate model.FreightRate) int {
+	score := 0
+	if rate.ShipCountry.Valid {
+		score += 2
+	}
+	if rate.ShipRegion.Valid {
+		score++
+	}
+	return score
+}
+
+// rateMatches reports whether a rate covers a destination and quantity
+func rateMatches(rate model.FreightRate, country, region string, quantity int) bool {
+	if rate.ShipCountry.Valid && !strings.EqualFold(rate.ShipCountry.V, country) {
+		return false
+	}
+	if rate.ShipRegion.Valid && !strings.EqualFold(rate.ShipRegion.V, region) {
+		return false
+	}
+	if quantity < rate.MinQuantity {
+		return false
+	}
+	if rate.MaxQuantity.Valid && quantity > rate.MaxQuantity.V {
+		return false
+	}
+
+	return true
+}
+
+// MatchFreightRate picks the most specific rate covering a destination and
+// quantity, preferring the highest band on ties. It returns nil if none match.
+func MatchFreightRate(rates []model.FreightRate, country, region string, quantity int) *model.FreightRate {
+	var best *model.FreightRate
+	for i := range rates {
+		rate := &rates[i]
+		if !rateMatches(*rate, country, region, quantity) {
+			continue
+		}
+		if best == nil || specificity(*rate) > specificity(*best) ||
+			(specificity(*rate) == specificity(*best) && rate.MinQuantity > best.MinQuantity) {
+			best = rate
+		}
+	}
+
+	return best
+}
+
+// Freight calculates the freight charged by a rate for a number of units
+func Freight(rate model.FreightRate, quantity int) money.Money {
+	return rate.BaseCharge.Add(rate.PerUnitCharge.MulInt(quantity)).Rounded()
+}
@@ -0,0 +1,127 @@
+package pricing
+
+import (
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"time"
+)
+
+// maxPercent caps percentage discounts at the whole line amount
+var maxPercent = money.FromInt(100)
+
+// applies reports whether a promotion can be used for a line ordered by a customer at a time
+func applies(p model.Promotion, customerId string, at time.Time, line model.QuoteLine) bool {
+	switch {
+	case !p.Active:
+		return false
+	case p.ValidFrom.Valid && at.Before(p.ValidFrom.V):
+		return false
+	case p.ValidTo.Valid && !at.Before(p.ValidTo.V):
+		return false
+	case p.ProductId.Valid && p.ProductId.V != line.ProductId:
+		return false
+	case p.CategoryId.Valid && p.CategoryId.V != line.CategoryId:
+		return false
+	case p.CustomerId.Valid && p.CustomerId.V != customerId:
+		return false
+	case line.Quantity < p.MinQuantity:
+		return false
+	}
+
+	return true
+}
+
+// discount returns the amount a promotion takes off a whole line
+func discount(p model.Promotion, line model.QuoteLine) money.Money {
+	amount := line.UnitPrice.MulInt(line.Quantity)
+
+	switch p.DiscountType {
+	case model.DiscountPercent:
+		return amount.Percent(p.DiscountValue.Min(maxPercent)).Rounded()
+	case model.DiscountFixed:
+		return p.DiscountValue.Min(line.UnitPrice).MulInt(line.Quantity).Rounded()
+	}
+
+	return 0
+}
+
+// ApplyPromotions prices each line with the single most generous promotion that
+// applies to it. Promotions do not stack, so quantity breaks are modelled as
+// several promotions with increasing min_quantity.
+func ApplyPromotions(customerId string, at time.Time, lines []model.QuoteLine, promotions []model.Promotion) {
+	for i := range lines {
+		line := &lines[i]
+		line.Discount = 0
+		line.PromotionId = 0
+
+		for _, p := range promotions {
+			if !applies(p, customerId, at, *line) {
+				continue
+			}
+			if d := discount(p, *line); d > line.Discount {
+				line.Discount = d
+				line.PromotionId = p.PromotionId
+			}
+		}
+
+		line.LineTotal = line.UnitPrice.MulInt(line.Quantity).Sub(line.Discount)
+	}
+}
+
+// BuildQuote prices a basket, applying promotions and totalling the lines
+func BuildQuote(customerId string, at time.Time, lines []model.QuoteLine, promotions []model.Promotion) *model.Quote {
+	ApplyPromotions(customerId, at, lines, promotions)
+
+	quote := &model.Quote{
+		CustomerId: customerId,
+		OrderDate:  at,
+		Lines:      lines,
+	}
+	totalQuote(quote)
+
+	return quote
+}
+
+// totalQuote recalculates the line totals and totals of a quote from its lines and freight
+func totalQuote(quote *model.Quote) {
+	quote.Subtotal, quote.Discount = 0, 0
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		line.LineTotal = line.UnitPrice.MulInt(line.Quantity).Sub(line.Discount)
+		quote.Subtotal = quote.Subtotal.Add(line.UnitPrice.MulInt(line.Quantity))
+		quote.Discount = quote.Discount.Add(line.Discount)
+	}
+	quote.Total = quote.Subtotal.Sub(quote.Discount).Add(quote.Freight)
+}
+
+// ConvertQuote converts a quote priced in the base currency into another
+// currency. Unit prices, discounts and freight are converted and rounded
+// first so that the converted totals still add up.
+func ConvertQuote(quote *model.Quote, code string, rate money.Rate) {
+	quote.Currency = code
+	quote.ExchangeRate = rate
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		line.UnitPrice = line.UnitPrice.Convert(rate).Rounded()
+		line.Discount = line.Discount.Convert(rate).Rounded()
+	}
+	quote.Freight = quote.Freight.Convert(rate).Rounded()
+	totalQuote(quote)
+}
+
+// Quantity returns the total number of units in a quote
+func Quantity(quote *model.Quote) int {
+	total := 0
+	for _, line := range quote.Lines {
+		total += line.Quantity
+	}
+	return total
+}
+
+// SetFreight adds freight to a quote and updates its total
+func SetFreight(quote *model.Quote, shipVia, rateId int, freight money.Money) {
+	quote.ShipVia = shipVia
+	quote.FreightRateId = rateId
+	quote.Freight = freight
+	totalQuote(quote)
+}
@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region bulk
+
+// Operations a bulk item can have
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+	BulkUpsert = "upsert"
+)
+
+// BulkItem is one operation of a bulk request: create inserts Value, update
+// patches the row with Id through Apply and delete removes the row with Id.
+// upsert looks Value up by the table's natural key, patching the row it finds
+// through Apply or inserting Value if there is none.
+type BulkItem[T any] struct {
+	Op    string
+	Id    int
+	Value *T
+	Apply func(*T) (*T, error)
+}
+
+// BulkResult is the outcome of a bulk item: the id of the row it affected and
+// the operation run on it, which for an upsert is create or update, or the
+// error it failed with
+type BulkResult struct {
+	Id  int
+	Op  string
+	Err error
+}
+
+// Bulk runs the items of a bulk request chunk by chunk. An atomic bulk runs
+// every chunk in one transaction, which Finish commits; otherwise each chunk
+// is committed as soon as it has run. Every item runs under a savepoint, so a
+// failed item is undone without aborting the others.
+type Bulk struct {
+	db     *DB
+	atomic bool
+	tx     *sql.Tx
+}
+
+// bulkTable describes how rows of a table model are created, patched and
+// deleted, and how a row is found by its natural key
+type bulkTable[T any] struct {
+	patch  patchTable
+	create func(q querier, v *T) (int, error)
+	delete func(db *DB, q querier, id int) error
+	lookup func(q querier, v *T) (int, bool, error)
+}
+
+var (
+	categoryBulk = bulkTable[model.Category]{categoryPatch, createCategory, deleteCategory, categoryByKey}
+	productBulk  = bulkTable[model.Products]{productPatch, createProduct, deleteProduct, productByKey}
+	supplierBulk = bulkTable[model.Suppliers]{supplierPatch, createSupplier, deleteSupplier, supplierByKey}
+)
+
+// NewBulk starts a bulk request; Close must be called when it is done with
+func (db *DB) NewBulk(atomic bool) *Bulk {
+	return &Bulk{db: db, atomic: atomic}
+}
+
+// POST /api/categories/bulk, POST /api/import/categories
+func (b *Bulk) Categories(items []BulkItem[model.Category]) ([]BulkResult, error) {
+	return runBulk(b, categoryBulk, items)
+}
+
+// POST /api/products/bulk, POST /api/import/products
+func (b *Bulk) Products(items []BulkItem[model.Products]) ([]BulkResult, error) {
+	return runBulk(b, productBulk, items)
+}
+
+// POST /api/import/suppliers
+func (b *Bulk) Suppliers(items []BulkItem[model.Suppliers]) ([]BulkResult, error) {
+	return runBulk(b, supplierBulk, items)
+}
+
+// Finish commits the chunks that have not been committed yet
+func (b *Bulk) Finish() error {
+	if b.tx == nil {
+		return nil
+	}
+	err := b.tx.Commit()
+	b.tx = nil
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Close rolls back the chunks that have not been committed, such as those of
+// an atomic bulk with a failed item
+func (b *Bulk) Close() error {
+	if b.tx == nil {
+		return nil
+	}
+	err := b.tx.Rollback()
+	b.tx = nil
+	if err != nil && err != sql.ErrTxDone {
+		return fmt.Errorf("failed to roll back transaction: %w", err)
+	}
+	return nil
+}
+
+// runBulk runs a chunk of items, returning a result for each. An error is only
+// returned when the chunk as a whole could not be run.
+func runBulk[T any](b *Bulk, t bulkTable[T], items []BulkItem[T]) ([]BulkResult, error) {
+	if b.tx == nil {
+		tx, err := b.db.Begin()
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		b.tx = tx
+	}
+
+	results := make([]BulkResult, len(items))
+	failed := 0
+	for i, item := range items {
+		if _, err := b.tx.Exec("SAVEPOINT bulk_item"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		id, op, err := runBulkItem(b.db, b.tx, t, item)
+		if err != nil {
+			if _, rbErr := b.tx.Exec("ROLLBACK TO SAVEPOINT bulk_item"); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+			}
+			results[i] = BulkResult{Id: id, Op: op, Err: err}
+			failed++
+			continue
+		}
+
+		if _, err := b.tx.Exec("RELEASE SAVEPOINT bulk_item"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		results[i] = BulkResult{Id: id, Op: op}
+	}
+	if !b.atomic {
+		if err := b.Finish(); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info().Str("table", t.patch.table).Int("items", len(items)).Int("failed", failed).Msg("Ran bulk chunk")
+	return results, nil
+}
+
+// runBulkItem runs one item, returning the id of the row it affected and the
+// operation run on it. The events of the change are recorded under the item's
+// savepoint, so a failed item records none.
+func runBulkItem[T any](db *DB, q querier, t bulkTable[T], item BulkItem[T]) (int, string, error) {
+	switch item.Op {
+	case BulkCreate:
+		id, err := createBulkRow(db, q, t, item.Value)
+		return id, item.Op, err
+	case BulkUpdate:
+		_, err := patchRowTx(db, q, t.patch, item.Id, item.Apply)
+		return item.Id, item.Op, err
+	case BulkDelete:
+		if err := t.delete(db, q, item.Id); err != nil {
+			return item.Id, item.Op, err
+		}
+		return item.Id, item.Op, recordDelete[T](q, item.Id)
+	case BulkUpsert:
+		id, found, err := t.lookup(q, item.Value)
+		if err != nil {
+			return 0, item.Op, err
+		}
+		if !found {
+			id, err = createBulkRow(db, q, t, item.Value)
+			return id, BulkCreate, err
+		}
+		_, err = patchRowTx(db, q, t.patch, id, item.Apply)
+		return id, BulkUpdate, err
+	}
+	return 0, item.Op, fmt.Errorf("unknown bulk operation %q", item.Op)
+}
+
+// createBulkRow inserts a row, returning its id, and records its creation
+func createBulkRow[T any](db *DB, q querier, t bulkTable[T], v *T) (int, error) {
+	id, err := t.create(q, v)
+	if err != nil || !emitsEvents[T]() {
+		return id, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", columnsOf[T](""), t.patch.table, t.patch.key)
+	created, err := scanRow[T](q.QueryRow(query, id))
+	if err != nil {
+		return id, fmt.Errorf("failed to query the created %s: %w", t.patch.noun, err)
+	}
+	return id, recordChange(db, q, nil, created)
+}
+
+// lookupKey returns the id of the one row a natural key query matches. A key
+// matching several rows is ambiguous and so an error.
+func lookupKey(q querier, noun, query string, args ...any) (int, bool, error) {
+	rows, err := q.Query(query+" LIMIT 2", args...)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up the %s: %w", noun, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, false, fmt.Errorf("failed to scan the %s id: %w", noun, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, false, fmt.Errorf("failed to look up the %s: %w", noun, err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return 0, false, nil
+	case 1:
+		return ids[0], true, nil
+	}
+	return 0, false, fmt.Errorf("invalid %s: more than one %s has the same key", noun, noun)
+}
+
+// categoryByKey finds a category by its name
+func categoryByKey(q querier, c *model.Category) (int, bool, error) {
+	return lookupKey(q, "category", "SELECT category_id FROM categories WHERE category_name = $1", c.Name)
+}
+
+// createCategory inserts a category, returning its id
+func createCategory(q querier, c *model.Category) (int, error) {
+	var id int
+	err := q.QueryRow("INSERT INTO categories (category_name, description) VALUES ($1, $2) RETURNING category_id",
+		c.Name, c.Description).Scan(&id)
+	if err != nil {
+		if msg, ok := constraintMessage(err); ok {
+			return 0, fmt.Errorf("invalid category: %s", msg)
+		}
+		return 0, fmt.Errorf("failed to create category: %w", err)
+	}
+	return id, nil
+}
+
+// deleteCategory deletes a category, leaving its products without one
+func deleteCategory(db *DB, q querier, id int) error {
+	if err := detachProducts(db, q, "category", "category_id", id); err != nil {
+		return err
+	}
+	return deleteRow(q, "categories", "category_id", "category", id)
+}
+
+// createProduct inserts a product, returning its id. Its unit_price opens its
+// price history, taking effect now; a product without a price has no history
+// until a price is scheduled.
+func createProduct(q querier, p *model.Products) (int, error) {
+	query := `
+		INSERT INTO products (product_name, supplier_id, category_id, quantity_per_unit, unit_price,
+			units_in_stock, units_on_order, reorder_level, discontinued)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING product_id
+	`
+
+	var id int
+	err := q.QueryRow(query, p.ProductName, p.SupplierId, p.CategoryId, p.QuantityPerUnit, p.UnitPrice,
+		p.UnitsInStock, p.UnitsOnOrder, p.ReorderLevel, p.Discontinued).Scan(&id)
+	if err != nil {
+		if msg, ok := constraintMessage(err); ok {
+			return 0, fmt.Errorf("invalid product: %s", msg)
+		}
+		return 0, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	if p.UnitPrice.Valid {
+		_, err := q.Exec("INSERT INTO product_prices (product_id, unit_price, effective_from) VALUES ($1, $2, NOW())", id, p.UnitPrice)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert the product's price: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// deleteProduct deletes a product that has never been ordered; products that
+// have been ordered are discontinued instead, so their order lines stay intact
+func deleteProduct(_ *DB, q querier, id int) error {
+	var ordered bool
+	err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM order_details WHERE product_id = $1)", id).Scan(&ordered)
+	if err != nil {
+		return fmt.Errorf("failed to check the products orders: %w", err)
+	}
+	if ordered {
+		return fmt.Errorf("invalid product: it has been ordered, discontinue it instead")
+	}
+	return deleteRow(q, "products", "product_id", "product", id)
+}
+
+// productByKey finds a product by its name and supplier; products without a
+// supplier are told apart by name alone
+func productByKey(q querier, p *model.Products) (int, bool, error) {
+	return lookupKey(q, "product",
+		"SELECT product_id FROM products WHERE product_name = $1 AND supplier_id IS NOT DISTINCT FROM $2",
+		p.ProductName, p.SupplierId)
+}
+
+// createSupplier inserts a supplier, returning its id
+func createSupplier(q querier, s *model.Suppliers) (int, error) {
+	query := `
+		INSERT INTO suppliers (company_name, contact_name, contact_title, address, city, region,
+			postal_code, country, phone, fax)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING supplier_id
+	`
+
+	var id int
+	err := q.QueryRow(query, s.CompanyName, s.ContactName, s.ContactTitle, s.Address, s.City, s.Region,
+		s.PostalCode, s.Country, s.Phone, s.Fax).Scan(&id)
+	if err != nil {
+		if msg, ok := constraintMessage(err); ok {
+			return 0, fmt.Errorf("invalid supplier: %s", msg)
+		}
+		return 0, fmt.Errorf("failed to create supplier: %w", err)
+	}
+	return id, nil
+}
+
+// deleteSupplier deletes a supplier, leaving its products without one
+func deleteSupplier(db *DB, q querier, id int) error {
+	if err := detachProducts(db, q, "supplier", "supplier_id", id); err != nil {
+		return err
+	}
+	return deleteRow(q, "suppliers", "supplier_id", "supplier", id)
+}
+
+// detachProducts clears the column of the products referring to a category or
+// supplier being deleted, recording the update of each product
+func detachProducts(db *DB, q querier, noun, column string, id int) error {
+	query := fmt.Sprintf("UPDATE products SET %[1]s = NULL WHERE %[1]s = $1 RETURNING %[2]s", column, productColumns)
+	rows, err := q.Query(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to detach the %s's products: %w", noun, err)
+	}
+	detached, err := scanRows[model.Products](rows)
+	if err != nil {
+		return fmt.Errorf("failed to scan the %s's products: %w", noun, err)
+	}
+
+	for i := range detached {
+		after := &detached[i]
+		before := *after
+		switch column {
+		case "category_id":
+			before.CategoryId = model.NewNull(id)
+		case "supplier_id":
+			before.SupplierId = model.NewNull(id)
+		}
+		if err := recordChange(db, q, &before, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// supplierByKey finds a supplier by its company name
+func supplierByKey(q querier, s *model.Suppliers) (int, bool, error) {
+	return lookupKey(q, "supplier", "SELECT supplier_id FROM suppliers WHERE company_name = $1", s.CompanyName)
+}
+
+// deleteRow deletes a row by its key, returning "<noun> not found" if there is none
+func deleteRow(q querier, table, key, noun string, id int) error {
+	result, err := q.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = $1", table, key), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete the %s: %w", noun, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s not found", noun)
+	}
+	return nil
+}
+
+// #endregion
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+	"strings"
+	"testing"
+)
+
+// detachDriver is a database/sql driver answering the update of products with
+// the products it detached, and recording the events written to the outbox
+type detachDriver struct {
+	products []int64
+	recorded []driver.Value
+}
+
+func (d *detachDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *detachDriver) Driver() driver.Driver                        { return nil }
+func (d *detachDriver) Prepare(query string) (driver.Stmt, error)    { return detachStmt{d, query}, nil }
+func (d *detachDriver) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+func (d *detachDriver) Close() error                                 { return nil }
+
+type detachStmt struct {
+	d     *detachDriver
+	query string
+}
+
+func (s detachStmt) Close() error  { return nil }
+func (s detachStmt) NumInput() int { return -1 }
+func (s detachStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "INSERT INTO outbox") {
+		// The event type and its payload
+		s.d.recorded = append(s.d.recorded, args[1], args[5])
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s detachStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows := &detachRows{columns: mappingOf[model.Products]().columns}
+	for _, id := range s.d.products {
+		row := make([]driver.Value, len(rows.columns))
+		for i, column := range rows.columns {
+			switch column {
+			case "product_id":
+				row[i] = id
+			case "product_name":
+				row[i] = []byte("Chai")
+			case "discontinued":
+				row[i] = false
+			}
+		}
+		rows.values = append(rows.values, row)
+	}
+	return rows, nil
+}
+
+type detachRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *detachRows) Columns() []string { return r.columns }
+func (r *detachRows) Close() error      { return nil }
+func (r *detachRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestDetachProductsRecordsUpdates(t *testing.T) {
+	d := &detachDriver{products: []int64{1, 2}}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	if err := detachProducts(&DB{}, db, "category", "category_id", 7); err != nil {
+		t.Fatalf("detachProducts() error = %v", err)
+	}
+
+	if len(d.recorded) != 4 {
+		t.Fatalf("recorded %v, want an event for each of 2 products", d.recorded)
+	}
+	for i, id := range []int{1, 2} {
+		eventType, payload := d.recorded[2*i], d.recorded[2*i+1]
+		if eventType != events.ProductUpdated {
+			t.Errorf("event %d = %v, want %s", i, eventType, events.ProductUpdated)
+		}
+		var product model.Products
+		if err := json.Unmarshal([]byte(payload.(string)), &product); err != nil {
+			t.Fatalf("decode payload %v: %v", payload, err)
+		}
+		if product.ProductId != id || product.CategoryId.Valid {
+			t.Errorf("event %d payload = %+v, want product %d without a category", i, product, id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region exchange rates
+
+// BaseCurrency returns the currency stored amounts are in
+func (db *DB) BaseCurrency() string {
+	return db.baseCurrency
+}
+
+// exchangeRate returns the rate of a currency effective at a time. The base
+// currency always has a rate of one.
+func (db *DB) exchangeRate(q querier, currency string, at time.Time) (money.Rate, error) {
+	if currency == db.baseCurrency {
+		return money.OneRate, nil
+	}
+
+	query := `
+		SELECT rate
+		FROM exchange_rates
+		WHERE currency = $1 AND effective_from <= $2
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var rate money.Rate
+	err := q.QueryRow(query, currency, at).Scan(&rate)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("no exchange rate for %s at %s", currency, at.Format("2006-01-02"))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query exchange rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+// GetExchangeRate returns the rate of a currency effective at a time
+func (db *DB) GetExchangeRate(currency string, at time.Time) (money.Rate, error) {
+	return db.exchangeRate(db, currency, at)
+}
+
+// GET /api/exchange-rates
+// Returns the latest rate of every currency effective at a time
+func (db *DB) GetExchangeRates(at time.Time) ([]model.ExchangeRate, error) {
+	return collect(db.StreamExchangeRates(at))
+}
+
+// GET /api/exchange-rates?format=
+func (db *DB) StreamExchangeRates(at time.Time) (*Cursor[model.ExchangeRate], error) {
+	query := `
+		SELECT DISTINCT ON (currency) ` + columnsOf[model.ExchangeRate]("") + `
+		FROM exchange_rates
+		WHERE effective_from <= $1
+		ORDER BY currency, effective_from DESC
+	`
+
+	return openCursor[model.ExchangeRate](db, "exchange rates", nil, query, at)
+}
+
+// POST /api/exchange-rates
+// Inserts rates, replacing any existing rate for the same currency and effective time
+func (db *DB) SaveExchangeRates(rates []model.ExchangeRate) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO exchange_rates (currency, rate, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (currency, effective_from) DO UPDATE SET rate = EXCLUDED.rate
+	`
+
+	for _, rate := range rates {
+		if _, err := tx.Exec(query, rate.Currency, rate.Rate, rate.EffectiveFrom); err != nil {
+			return fmt.Errorf("failed to save exchange rate for %s: %w", rate.Currency, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().Int("count", len(rates)).Msg("Successfully saved exchange rates")
+	return nil
+}
+
+// #endregion
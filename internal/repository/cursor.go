@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// #region cursors
+
+// Cursor reads the rows of a query one at a time, so exporting a large table
+// does not hold it in memory. It must be closed when it is done with.
+type Cursor[T any] struct {
+	noun  string
+	rows  *sql.Rows
+	m     *rowMapping
+	fix   func(*T)
+	value T
+	err   error
+}
+
+// openCursor runs a query selecting columnsOf[T]. fix, if given, completes
+// each row after it is scanned, such as defaulting an order's currency.
+func openCursor[T any](q querier, noun string, fix func(*T), query string, args ...any) (*Cursor[T], error) {
+	return openMapped(q, noun, fix, mappingOf[T](), query, args...)
+}
+
+// openMapped runs a query selecting the columns of m, a subset of T's mapping
+func openMapped[T any](q querier, noun string, fix func(*T), m *rowMapping, query string, args ...any) (*Cursor[T], error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", noun, err)
+	}
+	return &Cursor[T]{noun: noun, rows: rows, m: m, fix: fix}, nil
+}
+
+// Next scans the next row, returning false after the last row or an error
+func (c *Cursor[T]) Next() bool {
+	if c.err != nil || !c.rows.Next() {
+		return false
+	}
+
+	var zero T
+	c.value = zero
+	if c.err = c.rows.Scan(c.m.targets(&c.value)...); c.err != nil {
+		return false
+	}
+	if c.fix != nil {
+		c.fix(&c.value)
+	}
+	return true
+}
+
+// Value returns the row scanned by the last call to Next
+func (c *Cursor[T]) Value() *T {
+	return &c.value
+}
+
+// Err returns the error that stopped Next, if any
+func (c *Cursor[T]) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.rows.Err()
+}
+
+// Close releases the rows of the query
+func (c *Cursor[T]) Close() error {
+	return c.rows.Close()
+}
+
+// collect reads every row of a cursor and closes it
+func collect[T any](c *Cursor[T], err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	result := []T{}
+	for c.Next() {
+		result = append(result, *c.Value())
+	}
+	if err := c.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", c.noun, err)
+	}
+	return result, nil
+}
+
+// #endregion
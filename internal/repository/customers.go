@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+)
+
+// #region customers
+
+// customerColumns lists the customers columns in the order scanCustomer expects them
+var customerColumns = columnsOf[model.Customer]("")
+
+// scanCustomer scans a row selected with customerColumns into a customer.
+// Customers without a currency are in the base currency.
+func (db *DB) scanCustomer(row rowScanner) (*model.Customer, error) {
+	c, err := scanRow[model.Customer](row)
+	if err != nil {
+		return nil, err
+	}
+	if c.Currency == "" {
+		c.Currency = db.baseCurrency
+	}
+
+	return c, nil
+}
+
+// GET /api/customers/{customerId}
+func (db *DB) GetCustomerById(id string) (*model.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers
+		WHERE customer_id = $1
+	`
+
+	c, err := db.scanCustomer(db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("customer not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer: %w", err)
+	}
+
+	return c, nil
+}
+
+// GET /api/customers/{customerId}/orders
+// Columns, if given, limits the columns selected; the fields of the others stay zero
+func (db *DB) GetOrdersByCustomer(customerId string, limit, offset int, columns ...string) ([]model.Orders, int, error) {
+	m, err := mappingOf[model.Orders]().subset(columns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = db.QueryRow("SELECT COUNT(*) FROM orders WHERE customer_id = $1", customerId).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count customer orders: %w", err)
+	}
+
+	query := "SELECT " + m.list() + `
+		FROM orders
+		WHERE customer_id = $1
+		ORDER BY order_date DESC, order_id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	orders, err := collect(openMapped(db, "orders", db.fixOrder, m, query, customerId, limit, offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// GET /api/customers/{customerId}/orders?format=
+func (db *DB) StreamOrdersByCustomer(customerId string) (*Cursor[model.Orders], error) {
+	query := "SELECT " + orderColumns + `
+		FROM orders
+		WHERE customer_id = $1
+		ORDER BY order_date DESC, order_id DESC
+	`
+
+	return db.orderCursor(query, customerId)
+}
+
+// GET /api/customers/{customerId}/statement
+func (db *DB) GetCustomerStatement(customerId string, topProducts int) (*model.CustomerStatement, error) {
+	customer, err := db.GetCustomerById(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &model.CustomerStatement{
+		CustomerId:  customer.CustomerId,
+		CompanyName: customer.CompanyName,
+		Currency:    db.baseCurrency,
+		TopProducts: []model.ProductSales{},
+	}
+
+	// Order counts and dates come from the order headers
+	query := `
+		SELECT COUNT(*), MIN(order_date), MAX(order_date),
+			COUNT(*) FILTER (WHERE shipped_date IS NULL)
+		FROM orders
+		WHERE customer_id = $1
+	`
+	err = db.QueryRow(query, customerId).Scan(&statement.OrderCount, &statement.FirstOrderDate,
+		&statement.LastOrderDate, &statement.OutstandingOrders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer order summary: %w", err)
+	}
+
+	// Revenue comes from the order lines
+	query = `
+		SELECT COALESCE(SUM(od.unit_price * od.quantity - od.discount), 0),
+			COALESCE(SUM(od.unit_price * od.quantity - od.discount) FILTER (WHERE o.shipped_date IS NULL), 0)
+		FROM order_details od
+		JOIN orders o ON o.order_id = od.order_id
+		WHERE o.customer_id = $1
+	`
+	err = db.QueryRow(query, customerId).Scan(&statement.LifetimeRevenue, &statement.OutstandingValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer revenue: %w", err)
+	}
+
+	// Selected in the order of the ProductSales fields
+	query = `
+		SELECT p.product_id, p.product_name, SUM(od.quantity), SUM(od.unit_price * od.quantity - od.discount) AS revenue
+		FROM order_details od
+		JOIN orders o ON o.order_id = od.order_id
+		JOIN products p ON p.product_id = od.product_id
+		WHERE o.customer_id = $1
+		GROUP BY p.product_id, p.product_name
+		ORDER BY revenue DESC, p.product_id
+		LIMIT $2
+	`
+	rows, err := db.Query(query, customerId, topProducts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer top products: %w", err)
+	}
+
+	statement.TopProducts, err = scanRows[model.ProductSales](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan customer top products: %w", err)
+	}
+
+	return statement, nil
+}
+
+// #endregion
@@ -12,6 +12,16 @@ import (
 
 type DB struct {
 	*sql.DB
+
+	// Currency of stored amounts, used for customers and orders without a currency
+	baseCurrency string
+}
+
+// querier is implemented by both the database and its transactions
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 // Create new database connection
@@ -32,40 +42,27 @@ func New(cfg *appconfig.Config) (*DB, error) {
 	}
 
 	log.Info().Msg("Database successfully connected!")
-	return &DB{DB: db}, nil
+	return &DB{DB: db, baseCurrency: cfg.BaseCurrency}, nil
 }
 
 // #region categories
 
 // GET /api/categories
 func (db *DB) GetAllCategories() ([]model.Category, error) {
-	query := "SELECT * FROM categories"
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query categories: %w", err)
-	}
-	defer rows.Close()
-
-	var categories []model.Category
-	for rows.Next() {
-		var cat model.Category
-		err := rows.Scan(&cat.CategoryId, &cat.Name, &cat.Description)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan categories: %w", err)
-		}
-
-		categories = append(categories, cat)
-	}
+	return collect(db.StreamCategories())
+}
 
-	return categories, nil
+// GET /api/categories?format=
+func (db *DB) StreamCategories() (*Cursor[model.Category], error) {
+	query := "SELECT " + columnsOf[model.Category]("") + " FROM categories ORDER BY category_id"
+	return openCursor[model.Category](db, "categories", nil, query)
 }
 
 // GET /api/categories/{categoryID}
 func (db *DB) GetCategoryById(id int) (*model.Category, error) {
-	query := "SELECT * FROM categories WHERE category_id = $1"
+	query := "SELECT " + columnsOf[model.Category]("") + " FROM categories WHERE category_id = $1"
 
-	var cat model.Category
-	err := db.QueryRow(query, id).Scan(&cat.CategoryId, &cat.Name, &cat.Description)
+	cat, err := scanRow[model.Category](db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("category not found")
 	}
@@ -73,15 +70,14 @@ func (db *DB) GetCategoryById(id int) (*model.Category, error) {
 		return nil, fmt.Errorf("failed to query category: %w", err)
 	}
 
-	return &cat, nil
+	return cat, nil
 }
 
 // GET /api/categories/name
 func (db *DB) GetCategoryByName(name string) (*model.Category, error) {
-	query := "SELECT * FROM categories WHERE category_name = $1"
+	query := "SELECT " + columnsOf[model.Category]("") + " FROM categories WHERE category_name = $1"
 
-	var cat model.Category
-	err := db.QueryRow(query, name).Scan(&cat.CategoryId, &cat.Name, &cat.Description)
+	cat, err := scanRow[model.Category](db.QueryRow(query, name))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("category not found")
 	}
@@ -89,24 +85,33 @@ func (db *DB) GetCategoryByName(name string) (*model.Category, error) {
 		return nil, fmt.Errorf("failed to query category: %w", err)
 	}
 
-	return &cat, nil
+	return cat, nil
 }
 
 // POST /api/categories
 func (db *DB) CreateNewCategory(name, description string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO categories (category_id, category_name, description)
 		VALUES (DEFAULT, $1, $2)
-	`
+		RETURNING ` + columnsOf[model.Category]("")
 
-	_, err := db.Exec(query, name, description)
+	category, err := scanRow[model.Category](tx.QueryRow(query, name, description))
 	if err != nil {
 		return 0, fmt.Errorf("failed to create category: %w", err)
 	}
 
-	category, err := db.GetCategoryByName(name)
-	if err != nil {
-		return 0, fmt.Errorf("failure to get category after created: %w", err)
+	if err := recordChange(db, tx, nil, category); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return category.CategoryId, nil
@@ -120,29 +125,34 @@ func (db *DB) UpdateCategory(id, name, description string) error {
 		Str("description", description).
 		Msg("Updating category in database")
 
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE categories
 		SET category_name = $2, description = $3
 		WHERE category_id = $1
-	`
+		RETURNING ` + columnsOf[model.Category]("")
 
-	result, err := db.Exec(query, id, name, description)
+	category, err := scanRow[model.Category](tx.QueryRow(query, id, name, description))
+	if err == sql.ErrNoRows {
+		log.Warn().Str("category_id", id).Msg("No rows affected - category not found")
+		return fmt.Errorf("category not found")
+	}
 	if err != nil {
 		log.Error().Err(err).Str("category_id", id).Msg("Failed to execute update query")
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to update the category: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Error().Err(err).Str("category_id", id).Msg("Failed to get rows affected")
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	if err := recordChange(db, tx, category, category); err != nil {
+		return err
 	}
 
-	log.Info().Str("category_id", id).Int64("rows_affected", rowsAffected).Msg("Update query was executed")
-
-	if rowsAffected == 0 {
-		log.Warn().Str("category_id", id).Msg("No rows affected - category not found")
-		return fmt.Errorf("category not found")
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	log.Info().Str("category_id", id).Msg("Successfully updated the category in database")
@@ -169,19 +179,18 @@ func (db *DB) DeleteCategory(id string) error {
 		return fmt.Errorf("category not found")
 	}
 
-	// Delete the category
-	result, err := tx.Exec("DELETE FROM categories WHERE category_id = $1", id)
+	// Delete the category, verifying the deletion actually happened
+	var categoryId int
+	err = tx.QueryRow("DELETE FROM categories WHERE category_id = $1 RETURNING category_id", id).Scan(&categoryId)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("category not found")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete the category: %w", err)
 	}
 
-	// Verify the deletion actually happened
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("category not found")
+	if err := recordDelete[model.Category](tx, categoryId); err != nil {
+		return err
 	}
 
 	// Commit the transaction
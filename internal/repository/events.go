@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"fmt"
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+)
+
+// #region events
+
+// Changes are recorded as events in the outbox table by the transaction that
+// makes them, so an event exists if and only if its change was committed. The
+// outbox relay publishes them from there, and every instance of the service is
+// notified of them on EventsChannel as they are committed.
+
+// EventsChannel is the channel the outbox_id of each recorded event is sent
+// on with NOTIFY. Notifications are delivered when, and only if, the
+// transaction recording the event commits.
+const EventsChannel = "northwind_events"
+
+// recordChange records the events of a row of a table model being created,
+// when before is nil, or changed
+func recordChange[T any](db *DB, q querier, before, after *T) error {
+	evs, err := changeEvents(db, before, after)
+	if err != nil {
+		return err
+	}
+	return recordEvents(q, evs)
+}
+
+// recordDelete records the events of the row of a table model with id being deleted
+func recordDelete[T any](q querier, id int) error {
+	evs, err := deleteEvents[T](id)
+	if err != nil {
+		return err
+	}
+	return recordEvents(q, evs)
+}
+
+// recordEvents writes events to the outbox and notifies EventsChannel of them
+func recordEvents(q querier, evs []events.Event) error {
+	for _, e := range evs {
+		_, err := q.Exec(`
+			WITH recorded AS (
+				INSERT INTO outbox (event_id, event_type, resource, resource_id, occurred_at, payload)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING outbox_id
+			)
+			SELECT pg_notify($7, outbox_id::text) FROM recorded
+		`, e.Id, e.Type, e.Resource, e.ResourceId, e.OccurredAt, string(e.Data), EventsChannel)
+		if err != nil {
+			return fmt.Errorf("failed to record the %s event: %w", e.Type, err)
+		}
+	}
+	return nil
+}
+
+// emitsEvents reports whether changes to rows of a table model are events
+func emitsEvents[T any]() bool {
+	switch any((*T)(nil)).(type) {
+	case *model.Category, *model.Products, *model.Orders:
+		return true
+	}
+	return false
+}
+
+// changeEvents returns the events of a row of a table model being created,
+// when before is nil, or changed. Tables without events have none.
+func changeEvents[T any](db *DB, before, after *T) ([]events.Event, error) {
+	var evs eventList
+	switch row := any(after).(type) {
+	case *model.Category:
+		evs.add(pick(before == nil, events.CategoryCreated, events.CategoryUpdated), row.CategoryId, row)
+
+	case *model.Products:
+		evs.add(pick(before == nil, events.ProductCreated, events.ProductUpdated), row.ProductId, row)
+		// Only the change that takes stock to its reorder level is reported,
+		// not every change made while it stays there
+		if lowStock(row) && (before == nil || !lowStock(any(before).(*model.Products))) {
+			evs.add(events.StockLow, row.ProductId, row)
+		}
+
+	case *model.Orders:
+		db.fixOrder(row)
+		evs.add(pick(before == nil, events.OrderPlaced, events.OrderUpdated), row.OrderId, row)
+		if row.ShippedDate.Valid && (before == nil || !any(before).(*model.Orders).ShippedDate.Valid) {
+			evs.add(events.OrderShipped, row.OrderId, row)
+		}
+	}
+	return evs.list, evs.err
+}
+
+// deleteEvents returns the events of the row of a table model with id being
+// deleted. Tables without events have none.
+func deleteEvents[T any](id int) ([]events.Event, error) {
+	var evs eventList
+	switch any((*T)(nil)).(type) {
+	case *model.Category:
+		evs.add(events.CategoryDeleted, id, map[string]int{"category_id": id})
+	case *model.Products:
+		evs.add(events.ProductDeleted, id, map[string]int{"product_id": id})
+	}
+	return evs.list, evs.err
+}
+
+// lowStock reports whether a product that is still sold has no more units in
+// stock than its reorder level. A reorder level of zero means the product is
+// not reordered.
+func lowStock(p *model.Products) bool {
+	return !p.Discontinued && p.ReorderLevel.Valid && p.ReorderLevel.V > 0 &&
+		p.UnitsInStock.Valid && p.UnitsInStock.V <= p.ReorderLevel.V
+}
+
+// eventList collects events, keeping the first error
+type eventList struct {
+	list []events.Event
+	err  error
+}
+
+func (l *eventList) add(eventType string, resourceId any, data any) {
+	if l.err != nil {
+		return
+	}
+	e, err := events.New(eventType, resourceId, data)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.list = append(l.list, e)
+}
+
+// pick returns a if cond is true and b otherwise
+func pick(cond bool, a, b string) string {
+	if cond {
+		return a
+	}
+	return b
+}
+
+// #endregion
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+	"slices"
+	"testing"
+)
+
+func TestChangeEventsStockLow(t *testing.T) {
+	product := func(inStock int) *model.Products {
+		return &model.Products{
+			ProductId:    1,
+			ProductName:  "Chai",
+			UnitsInStock: model.NewNull(inStock),
+			ReorderLevel: model.NewNull(10),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		before, after *model.Products
+		want          []string
+	}{
+		{"stays above the reorder level", product(39), product(20), []string{events.ProductUpdated}},
+		{"falls to the reorder level", product(12), product(10), []string{events.ProductUpdated, events.StockLow}},
+		{"falls further once low", product(10), product(4), []string{events.ProductUpdated}},
+		{"created low", nil, product(3), []string{events.ProductCreated, events.StockLow}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evs, err := changeEvents(&DB{}, tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var types []string
+			for _, e := range evs {
+				types = append(types, e.Type)
+			}
+			if !slices.Equal(types, tt.want) {
+				t.Errorf("events = %v, want %v", types, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+	"northwind-api/internal/pricing"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region freight
+
+// shipperName returns a shipper's company name, or "shipper not found"
+func shipperName(q querier, shipperId int) (string, error) {
+	var name string
+	err := q.QueryRow("SELECT company_name FROM shippers WHERE shipper_id = $1", shipperId).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("shipper not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query shipper: %w", err)
+	}
+
+	return name, nil
+}
+
+// freightRates returns the rate table of a shipper
+func freightRates(q querier, shipperId int) ([]model.FreightRate, error) {
+	return collect(freightRateCursor(q, shipperId))
+}
+
+// freightRateCursor reads the rate table of a shipper
+func freightRateCursor(q querier, shipperId int) (*Cursor[model.FreightRate], error) {
+	query := "SELECT " + columnsOf[model.FreightRate]("") + `
+		FROM freight_rates
+		WHERE shipper_id = $1
+		ORDER BY ship_country NULLS FIRST, ship_region NULLS FIRST, min_quantity
+	`
+
+	return openCursor[model.FreightRate](q, "freight rates", nil, query, shipperId)
+}
+
+// GET /api/shippers/{shipperId}/rates
+func (db *DB) GetFreightRates(shipperId int) ([]model.FreightRate, error) {
+	if _, err := shipperName(db, shipperId); err != nil {
+		return nil, err
+	}
+
+	return freightRates(db, shipperId)
+}
+
+// GET /api/shippers/{shipperId}/rates?format=
+func (db *DB) StreamFreightRates(shipperId int) (*Cursor[model.FreightRate], error) {
+	if _, err := shipperName(db, shipperId); err != nil {
+		return nil, err
+	}
+
+	return freightRateCursor(db, shipperId)
+}
+
+// POST /api/shippers/{shipperId}/rates
+func (db *DB) CreateFreightRate(rate model.FreightRate) (int, error) {
+	if _, err := shipperName(db, rate.ShipperId); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO freight_rates (shipper_id, ship_country, ship_region, min_quantity, max_quantity,
+			base_charge, per_unit_charge)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING rate_id
+	`
+
+	var id int
+	err := db.QueryRow(query, rate.ShipperId, rate.ShipCountry, rate.ShipRegion,
+		rate.MinQuantity, rate.MaxQuantity, rate.BaseCharge, rate.PerUnitCharge).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create freight rate: %w", err)
+	}
+
+	log.Info().Int("rate_id", id).Int("shipper_id", rate.ShipperId).Msg("Successfully created freight rate")
+	return id, nil
+}
+
+// DELETE /api/shippers/{shipperId}/rates/{rateId}
+func (db *DB) DeleteFreightRate(shipperId, rateId int) error {
+	result, err := db.Exec("DELETE FROM freight_rates WHERE shipper_id = $1 AND rate_id = $2", shipperId, rateId)
+	if err != nil {
+		return fmt.Errorf("failed to delete the freight rate: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("freight rate not found")
+	}
+
+	log.Info().Int("rate_id", rateId).Msg("Successfully deleted freight rate")
+	return nil
+}
+
+// quoteFreight rates a shipment of `quantity` units with one shipper
+func quoteFreight(q querier, shipperId int, country, region string, quantity int) (*model.FreightQuote, error) {
+	name, err := shipperName(q, shipperId)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := freightRates(q, shipperId)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := pricing.MatchFreightRate(rates, country, region, quantity)
+	if rate == nil {
+		return nil, fmt.Errorf("no freight rate for shipper %d to %q covering %d units", shipperId, country, quantity)
+	}
+
+	return &model.FreightQuote{
+		ShipperId:   shipperId,
+		ShipperName: name,
+		RateId:      rate.RateId,
+		Quantity:    quantity,
+		Freight:     pricing.Freight(*rate, quantity),
+	}, nil
+}
+
+// POST /api/freight/quote
+// Quotes freight with one shipper, or with every shipper that can take the
+// shipment when shipperId is zero, cheapest first
+func (db *DB) QuoteFreight(shipperId int, country, region string, quantity int) ([]model.FreightQuote, error) {
+	if shipperId != 0 {
+		quote, err := quoteFreight(db, shipperId, country, region, quantity)
+		if err != nil {
+			return nil, err
+		}
+		return []model.FreightQuote{*quote}, nil
+	}
+
+	rows, err := db.Query("SELECT shipper_id FROM shippers ORDER BY shipper_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shippers: %w", err)
+	}
+	var shipperIds []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan shippers: %w", err)
+		}
+		shipperIds = append(shipperIds, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shippers: %w", err)
+	}
+
+	quotes := []model.FreightQuote{}
+	for _, id := range shipperIds {
+		rates, err := freightRates(db, id)
+		if err != nil {
+			return nil, err
+		}
+		if pricing.MatchFreightRate(rates, country, region, quantity) == nil {
+			continue
+		}
+
+		quote, err := quoteFreight(db, id, country, region, quantity)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, *quote)
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].Freight < quotes[j].Freight })
+	return quotes, nil
+}
+
+// #endregion
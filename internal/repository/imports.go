@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// #region imports
+
+// POST /api/import/products
+func (db *DB) CategoryIdsByName() (map[string]int, error) {
+	return idsByName(db, "category", "SELECT category_id, category_name FROM categories")
+}
+
+// POST /api/import/products
+func (db *DB) SupplierIdsByName() (map[string]int, error) {
+	return idsByName(db, "supplier", "SELECT supplier_id, company_name FROM suppliers")
+}
+
+// idsByName maps the lower cased names of a table's rows to their ids. A name
+// shared by several rows maps to 0, as it cannot say which row is meant.
+func idsByName(q querier, noun, query string) (map[string]int, error) {
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s names: %w", noun, err)
+	}
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan %s name: %w", noun, err)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := ids[name]; seen {
+			ids[name] = 0
+			continue
+		}
+		ids[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query %s names: %w", noun, err)
+	}
+	return ids, nil
+}
+
+// #endregion
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"northwind-api/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// #region batch lookups
+
+// The lookups below fetch the rows for many keys in one query, so resolving a
+// relation for every row of a result does not query once per row
+
+// rowsByKey selects the rows of a table whose key column is any of keys,
+// ordered by key and then by order, which must be a list of columns
+func rowsByKey[T any, K int | string](q querier, noun string, fix func(*T), table, key string, keys []K, order string) ([]T, error) {
+	if len(keys) == 0 {
+		return []T{}, nil
+	}
+	query := "SELECT " + columnsOf[T]("") + " FROM " + table +
+		" WHERE " + key + " = ANY($1) ORDER BY " + key
+	if order != "" {
+		query += ", " + order
+	}
+	return collect(openCursor(q, noun, fix, query, pq.Array(keys)))
+}
+
+// pageOf returns a page of a table in key order
+func pageOf[T any](q querier, noun string, fix func(*T), table, key string, limit, offset int) ([]T, error) {
+	query := "SELECT " + columnsOf[T]("") + " FROM " + table + " ORDER BY " + key + " LIMIT $1 OFFSET $2"
+	return collect(openCursor(q, noun, fix, query, limit, offset))
+}
+
+// fixCustomer puts customers without a currency in the base currency
+func (db *DB) fixCustomer(c *model.Customer) {
+	if c.Currency == "" {
+		c.Currency = db.baseCurrency
+	}
+}
+
+// fixOrder puts orders without a currency in the base currency
+func (db *DB) fixOrder(o *model.Orders) {
+	if o.Currency == "" {
+		o.Currency = db.baseCurrency
+	}
+}
+
+// POST /graphql
+func (db *DB) CategoriesByIds(ids []int) ([]model.Category, error) {
+	return rowsByKey[model.Category](db, "categories", nil, "categories", "category_id", ids, "")
+}
+
+// POST /graphql
+func (db *DB) SuppliersByIds(ids []int) ([]model.Suppliers, error) {
+	return rowsByKey[model.Suppliers](db, "suppliers", nil, "suppliers", "supplier_id", ids, "")
+}
+
+// POST /graphql
+func (db *DB) ProductsByIds(ids []int) ([]model.Products, error) {
+	return rowsByKey[model.Products](db, "products", nil, "products", "product_id", ids, "")
+}
+
+// POST /graphql
+// The products of each category, by category and then product id
+func (db *DB) ProductsByCategories(categoryIds []int) ([]model.Products, error) {
+	return rowsByKey[model.Products](db, "products", nil, "products", "category_id", categoryIds, "product_id")
+}
+
+// POST /graphql
+// The products of each supplier, by supplier and then product id
+func (db *DB) ProductsBySuppliers(supplierIds []int) ([]model.Products, error) {
+	return rowsByKey[model.Products](db, "products", nil, "products", "supplier_id", supplierIds, "product_id")
+}
+
+// POST /graphql
+// The price history of each product, by product and then start date
+func (db *DB) PricesByProducts(productIds []int) ([]model.ProductPrice, error) {
+	return rowsByKey[model.ProductPrice](db, "product prices", nil, "product_prices", "product_id", productIds, "effective_from")
+}
+
+// POST /graphql
+func (db *DB) CustomersByIds(ids []string) ([]model.Customer, error) {
+	return rowsByKey(db, "customers", db.fixCustomer, "customers", "customer_id", ids, "")
+}
+
+// POST /graphql
+func (db *DB) EmployeesByIds(ids []int) ([]model.Employees, error) {
+	return rowsByKey[model.Employees](db, "employees", nil, "employees", "employee_id", ids, "")
+}
+
+// POST /graphql
+func (db *DB) ShippersByIds(ids []int) ([]model.Shippers, error) {
+	return rowsByKey[model.Shippers](db, "shippers", nil, "shippers", "shipper_id", ids, "")
+}
+
+// POST /graphql
+// The rate table of each shipper, by shipper and then destination and quantity
+func (db *DB) FreightRatesByShippers(shipperIds []int) ([]model.FreightRate, error) {
+	return rowsByKey[model.FreightRate](db, "freight rates", nil, "freight_rates", "shipper_id", shipperIds,
+		"ship_country NULLS FIRST, ship_region NULLS FIRST, min_quantity")
+}
+
+// POST /graphql
+func (db *DB) OrdersByIds(ids []int) ([]model.Orders, error) {
+	return rowsByKey(db, "orders", db.fixOrder, "orders", "order_id", ids, "")
+}
+
+// POST /graphql
+// The orders of each customer, by customer and then newest first
+func (db *DB) OrdersByCustomers(customerIds []string) ([]model.Orders, error) {
+	return rowsByKey(db, "orders", db.fixOrder, "orders", "customer_id", customerIds, "order_date DESC, order_id DESC")
+}
+
+// POST /graphql
+// The lines of each order with their product names, by order and then product id
+func (db *DB) OrderLinesByOrders(orderIds []int) ([]model.OrderLine, error) {
+	if len(orderIds) == 0 {
+		return []model.OrderLine{}, nil
+	}
+	query := `
+		SELECT od.order_id, od.product_id, p.product_name, od.unit_price, od.quantity, od.discount
+		FROM order_details od
+		JOIN products p ON p.product_id = od.product_id
+		WHERE od.order_id = ANY($1)
+		ORDER BY od.order_id, od.product_id
+	`
+	return collect(openCursor[model.OrderLine](db, "order lines", nil, query, pq.Array(orderIds)))
+}
+
+// POST /graphql
+func (db *DB) GetProducts(limit, offset int) ([]model.Products, error) {
+	return pageOf[model.Products](db, "products", nil, "products", "product_id", limit, offset)
+}
+
+// POST /graphql
+func (db *DB) GetSuppliers(limit, offset int) ([]model.Suppliers, error) {
+	return pageOf[model.Suppliers](db, "suppliers", nil, "suppliers", "supplier_id", limit, offset)
+}
+
+// POST /graphql
+func (db *DB) GetCustomers(limit, offset int) ([]model.Customer, error) {
+	return pageOf(db, "customers", db.fixCustomer, "customers", "customer_id", limit, offset)
+}
+
+// rpc CustomerService.StreamCustomers
+func (db *DB) StreamCustomers() (*Cursor[model.Customer], error) {
+	query := "SELECT " + columnsOf[model.Customer]("") + " FROM customers ORDER BY customer_id"
+	return openCursor(db, "customers", db.fixCustomer, query)
+}
+
+// POST /graphql
+func (db *DB) GetEmployees(limit, offset int) ([]model.Employees, error) {
+	return pageOf[model.Employees](db, "employees", nil, "employees", "employee_id", limit, offset)
+}
+
+// POST /graphql
+func (db *DB) GetShippers() ([]model.Shippers, error) {
+	query := "SELECT " + columnsOf[model.Shippers]("") + " FROM shippers ORDER BY shipper_id"
+	return collect(openCursor[model.Shippers](db, "shippers", nil, query))
+}
+
+// #endregion
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// #region row mapping
+
+// rowMapping maps the columns of a query onto the `db:` tagged fields of a
+// model. A tag of "-" or no tag leaves a field unmapped, and the nullzero
+// option scans NULL as the field's zero value for fields that resolve NULL
+// themselves (such as a currency defaulting to the base currency).
+type rowMapping struct {
+	columns  []string
+	fields   [][]int
+	nullZero []bool
+}
+
+// rowMappings caches the mapping of each model type
+var rowMappings sync.Map
+
+// mappingOf returns the cached mapping of a model type
+func mappingOf[T any]() *rowMapping {
+	t := reflect.TypeFor[T]()
+	if m, ok := rowMappings.Load(t); ok {
+		return m.(*rowMapping)
+	}
+
+	m := &rowMapping{}
+	for _, field := range reflect.VisibleFields(t) {
+		tag, ok := field.Tag.Lookup("db")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		m.columns = append(m.columns, name)
+		m.fields = append(m.fields, field.Index)
+		m.nullZero = append(m.nullZero, options == "nullzero")
+	}
+
+	actual, _ := rowMappings.LoadOrStore(t, m)
+	return actual.(*rowMapping)
+}
+
+// columnsOf returns the comma separated column list of a model, qualified
+// with a table alias when one is given
+func columnsOf[T any](alias string) string {
+	columns := mappingOf[T]().columns
+	if alias == "" {
+		return strings.Join(columns, ", ")
+	}
+
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = alias + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// subset returns the mapping restricted to some of its columns, kept in the
+// model's column order, or the whole mapping when no columns are given.
+// Fields of columns left out are not scanned and stay zero.
+func (m *rowMapping) subset(columns []string) (*rowMapping, error) {
+	if len(columns) == 0 {
+		return m, nil
+	}
+	for _, column := range columns {
+		if !slices.Contains(m.columns, column) {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+	}
+
+	s := &rowMapping{}
+	for i, column := range m.columns {
+		if slices.Contains(columns, column) {
+			s.columns = append(s.columns, column)
+			s.fields = append(s.fields, m.fields[i])
+			s.nullZero = append(s.nullZero, m.nullZero[i])
+		}
+	}
+	return s, nil
+}
+
+// list returns the comma separated column list of the mapping
+func (m *rowMapping) list() string {
+	return strings.Join(m.columns, ", ")
+}
+
+// targets returns the scan destinations of a model's mapped fields, in column order
+func (m *rowMapping) targets(v any) []any {
+	value := reflect.ValueOf(v).Elem()
+
+	dest := make([]any, len(m.fields))
+	for i, index := range m.fields {
+		field := value.FieldByIndex(index).Addr()
+		if m.nullZero[i] {
+			dest[i] = nullZeroScanner{field}
+		} else {
+			dest[i] = field.Interface()
+		}
+	}
+	return dest
+}
+
+// scanRow scans a row selected with columnsOf[T] into a new T
+func scanRow[T any](row rowScanner) (*T, error) {
+	v := new(T)
+	if err := row.Scan(mappingOf[T]().targets(v)...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// scanRows scans every row selected with columnsOf[T] and closes the rows
+func scanRows[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	m := mappingOf[T]()
+	result := []T{}
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(m.targets(&v)...); err != nil {
+			return nil, err
+		}
+
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// nullZeroScanner scans NULL as the zero value of the field it points to
+type nullZeroScanner struct {
+	field reflect.Value
+}
+
+func (s nullZeroScanner) Scan(src any) error {
+	dest := s.field.Elem()
+	if src == nil {
+		dest.SetZero()
+		return nil
+	}
+	if scanner, ok := s.field.Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	value := reflect.ValueOf(src)
+	if b, ok := src.([]byte); ok && dest.Kind() == reflect.String {
+		value = reflect.ValueOf(string(b))
+	}
+	// Numbers convert to strings as runes, so only strings may become strings
+	if (dest.Kind() == reflect.String) != (value.Kind() == reflect.String) || !value.Type().ConvertibleTo(dest.Type()) {
+		return fmt.Errorf("cannot scan %T into %s", src, dest.Type())
+	}
+	dest.Set(value.Convert(dest.Type()))
+	return nil
+}
+
+// #endregion
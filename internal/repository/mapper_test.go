@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// rowDriver is a database/sql driver answering every query with one row, so
+// rows are scanned through database/sql as they are from Postgres
+type rowDriver struct {
+	columns []string
+	values  []driver.Value
+}
+
+func (d rowDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d rowDriver) Driver() driver.Driver                        { return nil }
+func (d rowDriver) Prepare(string) (driver.Stmt, error)          { return d, nil }
+func (d rowDriver) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+func (d rowDriver) Close() error                                 { return nil }
+func (d rowDriver) NumInput() int                                { return -1 }
+func (d rowDriver) Exec([]driver.Value) (driver.Result, error)   { return nil, driver.ErrSkip }
+func (d rowDriver) Query([]driver.Value) (driver.Rows, error) {
+	return &driverRows{rowDriver: d}, nil
+}
+
+type driverRows struct {
+	rowDriver
+	done bool
+}
+
+func (r *driverRows) Columns() []string { return r.columns }
+func (r *driverRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+// A fixed timestamp scanned into time columns
+var scannedAt = time.Date(1996, 7, 4, 9, 30, 0, 0, time.UTC)
+
+// driverValue returns the value Postgres hands back for a column scanned into
+// a field of type typ, along with the value the field should then hold
+func driverValue(t *testing.T, column string, typ reflect.Type) (driver.Value, any) {
+	switch typ {
+	case reflect.TypeFor[money.Money]():
+		return []byte("12.3450"), money.MustParse("12.345")
+	case reflect.TypeFor[money.Rate]():
+		rate, _ := money.ParseRate("1.2500")
+		return []byte("1.2500"), rate
+	case reflect.TypeFor[time.Time]():
+		return scannedAt, scannedAt
+	case reflect.TypeFor[pq.StringArray]():
+		return []byte("{order.*,product.updated}"), pq.StringArray{"order.*", "product.updated"}
+	case reflect.TypeFor[json.RawMessage]():
+		return []byte(`{"order_id":10248}`), json.RawMessage(`{"order_id":10248}`)
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		// Fixed length columns such as customer_id come back as bytes
+		return []byte("v_" + column), "v_" + column
+	case reflect.Int, reflect.Int64:
+		return int64(7), reflect.ValueOf(int64(7)).Convert(typ).Interface()
+	case reflect.Bool:
+		return true, true
+	}
+	t.Fatalf("no driver value for column %s of type %s", column, typ)
+	return nil, nil
+}
+
+// nullOf returns the type of the value a Null[T] field holds, or nil when the
+// field is not nullable
+func nullOf(typ reflect.Type) reflect.Type {
+	if typ.Kind() != reflect.Struct || !strings.HasPrefix(typ.Name(), "Null[") {
+		return nil
+	}
+	return typ.Field(0).Type
+}
+
+// testScanRoundTrip scans a row of NULLs and a row of values into a T,
+// checking the nullable fields are invalid and encode as JSON null for the
+// first and hold the values scanned for the second
+func testScanRoundTrip[T any](t *testing.T) {
+	m := mappingOf[T]()
+	typ := reflect.TypeFor[T]()
+	if len(m.columns) == 0 {
+		t.Fatalf("%s maps no columns", typ)
+	}
+
+	for _, null := range []bool{true, false} {
+		values := make([]driver.Value, len(m.columns))
+		want := make([]any, len(m.columns))
+		for i, column := range m.columns {
+			field := typ.FieldByIndex(m.fields[i])
+			inner := nullOf(field.Type)
+			switch {
+			case inner != nil && null:
+				values[i] = nil
+			case inner != nil:
+				values[i], _ = driverValue(t, column, inner)
+			case null && m.nullZero[i]:
+				values[i], want[i] = nil, reflect.Zero(field.Type).Interface()
+			case null && field.Type == reflect.TypeFor[money.Rate]():
+				values[i], want[i] = nil, money.OneRate
+			default:
+				values[i], want[i] = driverValue(t, column, field.Type)
+			}
+		}
+
+		db := sql.OpenDB(rowDriver{columns: m.columns, values: values})
+		got, err := scanRow[T](db.QueryRow("SELECT"))
+		db.Close()
+		if err != nil {
+			t.Fatalf("null=%v: scan: %v", null, err)
+		}
+
+		encoded, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("null=%v: encode: %v", null, err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &fields); err != nil {
+			t.Fatalf("null=%v: decode: %v", null, err)
+		}
+
+		value := reflect.ValueOf(got).Elem()
+		for i, column := range m.columns {
+			field := typ.FieldByIndex(m.fields[i])
+			actual := value.FieldByIndex(m.fields[i])
+
+			if nullOf(field.Type) == nil {
+				if !reflect.DeepEqual(actual.Interface(), want[i]) {
+					t.Errorf("null=%v: %s = %v, want %v", null, column, actual.Interface(), want[i])
+				}
+				continue
+			}
+
+			valid := actual.FieldByName("Valid").Bool()
+			inner := actual.FieldByName("V").Interface()
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			switch {
+			case null && valid:
+				t.Errorf("%s scanned from NULL is valid", column)
+			case null && string(fields[name]) != "null":
+				t.Errorf("%s scanned from NULL encodes as %s, want null", column, fields[name])
+			case !null && !valid:
+				t.Errorf("%s scanned from a value is not valid", column)
+			case !null && string(fields[name]) == "null":
+				t.Errorf("%s scanned from a value encodes as null", column)
+			case !null:
+				_, expected := driverValue(t, column, nullOf(field.Type))
+				if !reflect.DeepEqual(inner, expected) {
+					t.Errorf("%s = %v, want %v", column, inner, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestScanRoundTrip(t *testing.T) {
+	tests := []struct {
+		model string
+		test  func(*testing.T)
+	}{
+		{"Category", testScanRoundTrip[model.Category]},
+		{"Customer", testScanRoundTrip[model.Customer]},
+		{"Employees", testScanRoundTrip[model.Employees]},
+		{"OrderDetails", testScanRoundTrip[model.OrderDetails]},
+		{"Orders", testScanRoundTrip[model.Orders]},
+		{"Products", testScanRoundTrip[model.Products]},
+		{"Shippers", testScanRoundTrip[model.Shippers]},
+		{"Suppliers", testScanRoundTrip[model.Suppliers]},
+		{"OrderLine", testScanRoundTrip[model.OrderLine]},
+		{"ProductSales", testScanRoundTrip[model.ProductSales]},
+		{"ProductPrice", testScanRoundTrip[model.ProductPrice]},
+		{"Promotion", testScanRoundTrip[model.Promotion]},
+		{"FreightRate", testScanRoundTrip[model.FreightRate]},
+		{"ExchangeRate", testScanRoundTrip[model.ExchangeRate]},
+		{"Webhook", testScanRoundTrip[model.Webhook]},
+		{"WebhookDelivery", testScanRoundTrip[model.WebhookDelivery]},
+		{"OutboxEvent", testScanRoundTrip[model.OutboxEvent]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, tt.test)
+	}
+}
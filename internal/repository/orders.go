@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"maps"
+	"math"
+	"northwind-api/internal/model"
+	"northwind-api/internal/pricing"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region orders
+
+// orderColumns lists every orders column
+var orderColumns = columnsOf[model.Orders]("")
+
+// productColumns lists every products column
+var productColumns = columnsOf[model.Products]("")
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// GET /api/orders/{orderId}
+// Columns, if given, limits the columns selected; the fields of the others stay zero
+func (db *DB) GetOrderById(id int, columns ...string) (*model.Orders, error) {
+	m, err := mappingOf[model.Orders]().subset(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + m.list() + " FROM orders WHERE order_id = $1"
+	order := &model.Orders{}
+	err = db.QueryRow(query, id).Scan(m.targets(order)...)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("order not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order: %w", err)
+	}
+	db.fixOrder(order)
+
+	return order, nil
+}
+
+// orderCursor runs a query selecting orderColumns. Orders without a currency
+// are in the base currency.
+func (db *DB) orderCursor(query string, args ...any) (*Cursor[model.Orders], error) {
+	return openCursor(db, "orders", db.fixOrder, query, args...)
+}
+
+// GET /api/orders
+// Columns, if given, limits the columns selected; the fields of the others stay zero
+func (db *DB) GetOrders(limit, offset int, columns ...string) ([]model.Orders, int, error) {
+	m, err := mappingOf[model.Orders]().subset(columns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM orders").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := "SELECT " + m.list() + " FROM orders ORDER BY order_id LIMIT $1 OFFSET $2"
+	orders, err := collect(openMapped(db, "orders", db.fixOrder, m, query, limit, offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// GET /api/orders?format=
+func (db *DB) StreamOrders() (*Cursor[model.Orders], error) {
+	return db.orderCursor("SELECT " + orderColumns + " FROM orders ORDER BY order_id")
+}
+
+// GET /api/orders/late
+// Orders whose required date has passed without being shipped
+func (db *DB) GetLateOrders(asOf time.Time) ([]model.Orders, error) {
+	return collect(db.StreamLateOrders(asOf))
+}
+
+// GET /api/orders/late?format=
+func (db *DB) StreamLateOrders(asOf time.Time) (*Cursor[model.Orders], error) {
+	query := "SELECT " + orderColumns + `
+		FROM orders
+		WHERE shipped_date IS NULL AND required_date < $1
+		ORDER BY required_date, order_id
+	`
+
+	return db.orderCursor(query, asOf)
+}
+
+// GET /api/orders/at-risk
+// Unshipped orders that are required within the next `days` days
+func (db *DB) GetAtRiskOrders(asOf time.Time, days int) ([]model.Orders, error) {
+	return collect(db.StreamAtRiskOrders(asOf, days))
+}
+
+// GET /api/orders/at-risk?format=
+func (db *DB) StreamAtRiskOrders(asOf time.Time, days int) (*Cursor[model.Orders], error) {
+	query := "SELECT " + orderColumns + `
+		FROM orders
+		WHERE shipped_date IS NULL AND required_date >= $1 AND required_date < $2
+		ORDER BY required_date, order_id
+	`
+
+	return db.orderCursor(query, asOf, asOf.AddDate(0, 0, days))
+}
+
+// GetOrderLines returns the order_details rows of an order with their product names
+func (db *DB) GetOrderLines(orderId int) ([]model.OrderLine, error) {
+	// Every OrderLine column but product_name comes from order_details
+	query := `
+		SELECT od.order_id, od.product_id, p.product_name, od.unit_price, od.quantity, od.discount
+		FROM order_details od
+		JOIN products p ON p.product_id = od.product_id
+		WHERE od.order_id = $1
+		ORDER BY od.product_id
+	`
+
+	rows, err := db.Query(query, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order lines: %w", err)
+	}
+
+	lines, err := scanRows[model.OrderLine](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan order lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// mergeOrderLines combines lines for the same product, since order_details is
+// keyed by (order_id, product_id)
+func mergeOrderLines(lines []model.OrderDetails) []model.OrderDetails {
+	merged := make([]model.OrderDetails, 0, len(lines))
+	index := map[int]int{}
+	for _, line := range lines {
+		if i, ok := index[line.ProductId]; ok {
+			merged[i].Quantity += line.Quantity
+			continue
+		}
+		index[line.ProductId] = len(merged)
+		merged = append(merged, line)
+	}
+
+	return merged
+}
+
+// priceOrder validates an order's lines and prices them at the order date:
+// each product gets the price effective at that time and the best applicable
+// promotion. Missing ship_* fields on the order default to the customer's
+// address. With autoFreight the freight is rated from the ship_via shipper's
+// rate table, otherwise order.Freight is used as given. Amounts are in the
+// base currency; the quote carries the customer's currency and its rate at
+// the order date.
+func (db *DB) priceOrder(q querier, order *model.Orders, lines []model.OrderDetails, autoFreight bool) (*model.Quote, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("invalid order: at least one line is required")
+	}
+	lines = mergeOrderLines(lines)
+	for _, line := range lines {
+		if line.Quantity < 1 || line.Quantity > math.MaxInt16 {
+			return nil, fmt.Errorf("invalid order: quantity for product %d must be between 1 and %d", line.ProductId, math.MaxInt16)
+		}
+	}
+	if !order.CustomerId.Valid {
+		return nil, fmt.Errorf("invalid order: customer_id is required")
+	}
+	if !order.OrderDate.Valid {
+		order.OrderDate = model.NewNull(time.Now())
+	}
+	customerId, orderDate := order.CustomerId.V, order.OrderDate.V
+
+	customer, err := db.scanCustomer(q.QueryRow("SELECT "+customerColumns+" FROM customers WHERE customer_id = $1", customerId))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid order: customer %q not found", customerId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer: %w", err)
+	}
+	if !order.ShipName.Valid && !order.ShipAddress.Valid {
+		order.ShipName = model.NewNull(customer.CompanyName)
+		order.ShipAddress = customer.Address
+		order.ShipCity = customer.City
+		order.Region = customer.Region
+		order.ShipPostalCode = customer.PostalCode
+		order.ShipCountry = customer.Country
+	}
+
+	quoteLines := make([]model.QuoteLine, 0, len(lines))
+	for _, line := range lines {
+		quoteLine := model.QuoteLine{ProductId: line.ProductId, Quantity: line.Quantity}
+
+		var categoryId model.NullInt
+		var discontinued bool
+		err := q.QueryRow("SELECT product_name, category_id, discontinued FROM products WHERE product_id = $1", line.ProductId).
+			Scan(&quoteLine.ProductName, &categoryId, &discontinued)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid order: product %d not found", line.ProductId)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query product: %w", err)
+		}
+		if discontinued {
+			return nil, fmt.Errorf("invalid order: product %d is discontinued", line.ProductId)
+		}
+		quoteLine.CategoryId = categoryId.V
+
+		quoteLine.UnitPrice, err = effectivePrice(q, line.ProductId, orderDate)
+		if err != nil {
+			return nil, err
+		}
+
+		quoteLines = append(quoteLines, quoteLine)
+	}
+
+	promotions, err := activePromotions(q, customerId, orderDate)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := pricing.BuildQuote(customerId, orderDate, quoteLines, promotions)
+
+	quote.Currency = customer.Currency
+	quote.ExchangeRate, err = db.exchangeRate(q, customer.Currency, orderDate)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no exchange rate") {
+			return nil, fmt.Errorf("invalid order: %w", err)
+		}
+		return nil, err
+	}
+	order.Currency = quote.Currency
+	order.ExchangeRate = quote.ExchangeRate
+
+	if !autoFreight || !order.ShipVia.Valid {
+		pricing.SetFreight(quote, order.ShipVia.V, 0, order.Freight.V)
+		return quote, nil
+	}
+
+	freight, err := quoteFreight(q, order.ShipVia.V, order.ShipCountry.V, order.Region.V, pricing.Quantity(quote))
+	if err != nil {
+		if err.Error() == "shipper not found" || strings.HasPrefix(err.Error(), "no freight rate") {
+			return nil, fmt.Errorf("invalid order: %w", err)
+		}
+		return nil, err
+	}
+	pricing.SetFreight(quote, order.ShipVia.V, freight.RateId, freight.Freight)
+	order.Freight = model.NewNull(quote.Freight)
+
+	return quote, nil
+}
+
+// POST /api/orders/quote
+// Prices a basket the same way CreateOrder would, without placing the order.
+// The quote is converted into the customer's currency.
+func (db *DB) QuoteOrder(order model.Orders, lines []model.OrderDetails, autoFreight bool) (*model.Quote, error) {
+	quote, err := db.priceOrder(db, &order, lines, autoFreight)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing.ConvertQuote(quote, quote.Currency, quote.ExchangeRate)
+	return quote, nil
+}
+
+// POST /api/orders
+// Places an order priced by priceOrder. Any unit prices on the given lines are
+// ignored. Amounts are stored in the base currency along with the customer's
+// currency and the exchange rate in effect.
+func (db *DB) CreateOrder(order model.Orders, lines []model.OrderDetails, autoFreight bool) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quote, err := db.priceOrder(tx, &order, lines, autoFreight)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO orders (customer_id, employee_id, order_date, required_date, shipped_date, ship_via,
+			freight, ship_name, ship_address, region, ship_city, ship_postal_code, ship_country,
+			currency, exchange_rate)
+		VALUES ($1, $2, $3, $4, NULL, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING order_id
+	`
+
+	var orderId int
+	err = tx.QueryRow(query, order.CustomerId, order.EmployeeId, order.OrderDate, order.RequiredDate,
+		order.ShipVia, quote.Freight, order.ShipName, order.ShipAddress, order.Region, order.ShipCity,
+		order.ShipPostalCode, order.ShipCountry, quote.Currency, quote.ExchangeRate).Scan(&orderId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for _, line := range quote.Lines {
+		_, err := tx.Exec("INSERT INTO order_details (order_id, product_id, unit_price, quantity, discount) VALUES ($1, $2, $3, $4, $5)",
+			orderId, line.ProductId, line.UnitPrice, line.Quantity, line.Discount)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create order line: %w", err)
+		}
+	}
+
+	if err := takeStock(db, tx, quote.Lines); err != nil {
+		return 0, err
+	}
+
+	placed, err := scanRow[model.Orders](tx.QueryRow("SELECT "+orderColumns+" FROM orders WHERE order_id = $1", orderId))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the created order: %w", err)
+	}
+	if err := recordChange(db, tx, nil, placed); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().Int("order_id", orderId).Str("customer_id", order.CustomerId.V).Int("lines", len(quote.Lines)).Msg("Successfully created order")
+	return orderId, nil
+}
+
+// takeStock takes the units ordered out of stock, recording each product's
+// change so one falling to its reorder level is reported. Products are locked
+// in id order so concurrent orders cannot deadlock. Stock does not go below
+// zero when more units are ordered than are in stock, and products whose
+// stock is not tracked (NULL units in stock) are left alone.
+func takeStock(db *DB, q querier, lines []model.QuoteLine) error {
+	ordered := map[int]int{}
+	for _, line := range lines {
+		ordered[line.ProductId] += line.Quantity
+	}
+	productIds := slices.Sorted(maps.Keys(ordered))
+
+	for _, productId := range productIds {
+		before, err := scanRow[model.Products](q.QueryRow("SELECT "+productColumns+" FROM products WHERE product_id = $1 FOR UPDATE", productId))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("invalid order: product %d not found", productId)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query product: %w", err)
+		}
+		if !before.UnitsInStock.Valid {
+			continue
+		}
+
+		after, err := scanRow[model.Products](q.QueryRow(`
+			UPDATE products SET units_in_stock = GREATEST(units_in_stock - $2, 0)
+			WHERE product_id = $1
+			RETURNING `+productColumns, productId, ordered[productId]))
+		if err != nil {
+			return fmt.Errorf("failed to update the stock of product %d: %w", productId, err)
+		}
+		if err := recordChange(db, q, before, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// #endregion
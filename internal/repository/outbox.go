@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// #region outbox
+
+// outboxLockKey is the advisory lock held while relaying, so one instance of
+// the service relays at a time and the events keep their order
+const outboxLockKey int64 = 0x6e77_6f75_7462_6f78
+
+// RelayOutbox hands the oldest unpublished events, up to limit, to publish in
+// the order they were recorded and marks them published once it succeeds,
+// returning how many were published. Nothing is published while another
+// instance is relaying. An event whose publish fails, or that is published
+// but not marked because the service stopped, is published again by a later
+// call, so events are published at least once.
+//
+// Only the events of transactions older than every transaction still in
+// progress are relayed (pg_snapshot_xmin), so an event cannot be skipped past
+// by the events of later transactions while its own is yet to commit; a
+// long-running transaction holds the relay back until it ends. Changes to the
+// same row are serialised by its lock, so their events are relayed in the
+// order they committed.
+//
+// No transaction is open while publish runs, so a slow sink holds neither
+// locks nor a snapshot, only the connection the relay lock is held on.
+func (db *DB) RelayOutbox(ctx context.Context, limit int, publish func(context.Context, []events.Event) error) (int, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", outboxLockKey).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to lock the outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", outboxLockKey); err != nil {
+			// A connection returned to the pool still holding the lock
+			// would stop every instance relaying, so it is discarded
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	query := `
+		SELECT ` + columnsOf[model.OutboxEvent]("") + `
+		FROM outbox
+		WHERE published_at IS NULL AND xid < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY outbox_id
+		LIMIT $1
+	`
+	rows, err := conn.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	recorded, err := scanRows[model.OutboxEvent](rows)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	if len(recorded) == 0 {
+		return 0, nil
+	}
+
+	evs := make([]events.Event, len(recorded))
+	ids := make([]int64, len(recorded))
+	for i, r := range recorded {
+		evs[i] = r.Event
+		ids[i] = r.OutboxId
+	}
+
+	if err := publish(ctx, evs); err != nil {
+		return 0, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "UPDATE outbox SET published_at = NOW() WHERE outbox_id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to mark events published: %w", err)
+	}
+	return len(evs), nil
+}
+
+// GetOutboxEvents returns the recorded events with some outbox ids, in the order they were recorded
+func (db *DB) GetOutboxEvents(ids []int64) ([]model.OutboxEvent, error) {
+	query := "SELECT " + columnsOf[model.OutboxEvent]("") + " FROM outbox WHERE outbox_id = ANY($1) ORDER BY outbox_id"
+
+	rows, err := db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	return scanRows[model.OutboxEvent](rows)
+}
+
+// GetRecentOutboxEvents returns up to the last limit recorded events after an
+// outbox id, oldest first. An id of 0 returns the last limit events.
+func (db *DB) GetRecentOutboxEvents(after int64, limit int) ([]model.OutboxEvent, error) {
+	query := `
+		SELECT * FROM (
+			SELECT ` + columnsOf[model.OutboxEvent]("") + `
+			FROM outbox
+			WHERE outbox_id > $1
+			ORDER BY outbox_id DESC
+			LIMIT $2
+		) recent
+		ORDER BY outbox_id
+	`
+
+	rows, err := db.Query(query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	return scanRows[model.OutboxEvent](rows)
+}
+
+// PruneOutbox deletes the events published before a time, returning how many were deleted
+func (db *DB) PruneOutbox(before time.Time) (int64, error) {
+	result, err := db.Exec("DELETE FROM outbox WHERE published_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune the outbox: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// #endregion
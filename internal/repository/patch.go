@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"northwind-api/internal/model"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// #region patching
+
+// patchTable describes how rows of a table model are patched
+type patchTable struct {
+	table    string
+	key      string
+	noun     string
+	writable []string
+}
+
+var (
+	categoryPatch = patchTable{"categories", "category_id", "category",
+		[]string{"category_name", "description"}}
+	customerPatch = patchTable{"customers", "customer_id", "customer",
+		[]string{"company_name", "contact_name", "address", "city", "region", "postal_code", "country", "phone", "currency"}}
+	employeePatch = patchTable{"employees", "employee_id", "employee",
+		[]string{"last_name", "first_name", "title", "birth_date", "hire_date", "address", "state", "city", "postal_code", "country", "reports_to", "salary"}}
+	// Pricing fields of an order are fixed when it is placed
+	orderPatch = patchTable{"orders", "order_id", "order",
+		[]string{"employee_id", "required_date", "shipped_date", "ship_name", "ship_address", "region", "ship_city", "ship_postal_code", "ship_country"}}
+	// unit_price follows the price history, see ScheduleProductPrice
+	productPatch = patchTable{"products", "product_id", "product",
+		[]string{"product_name", "supplier_id", "category_id", "quantity_per_unit", "units_in_stock", "units_on_order", "reorder_level", "discontinued"}}
+	shipperPatch = patchTable{"shippers", "shipper_id", "shipper",
+		[]string{"company_name", "phone"}}
+	supplierPatch = patchTable{"suppliers", "supplier_id", "supplier",
+		[]string{"company_name", "contact_name", "contact_title", "address", "city", "region", "postal_code", "country", "phone", "fax"}}
+	promotionPatch = patchTable{"promotions", "promotion_id", "promotion",
+		[]string{"name", "discount_type", "discount_value", "min_quantity", "product_id", "category_id", "customer_id", "valid_from", "valid_to", "active"}}
+	freightRatePatch = patchTable{"freight_rates", "rate_id", "freight rate",
+		[]string{"ship_country", "ship_region", "min_quantity", "max_quantity", "base_charge", "per_unit_charge"}}
+)
+
+// patchRow locks a row of a table model, hands it to apply for the patched
+// version and updates only the columns that changed. Changing a column that
+// is not writable, or a change the database rejects, is an "invalid patch".
+// The events of the change are recorded in the same transaction.
+func patchRow[T any](db *DB, t patchTable, key any, apply func(*T) (*T, error)) (*T, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := patchRowTx(db, tx, t, key, apply)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// patchRowTx patches a row like patchRow within an open transaction
+func patchRowTx[T any](db *DB, q querier, t patchTable, key any, apply func(*T) (*T, error)) (*T, error) {
+	columns := columnsOf[T]("")
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1 FOR UPDATE", columns, t.table, t.key)
+	current, err := scanRow[T](q.QueryRow(query, key))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s not found", t.noun)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", t.noun, err)
+	}
+
+	next, err := apply(current)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, values := changedColumns(current, next)
+	if len(changed) == 0 {
+		return current, nil
+	}
+
+	assignments := make([]string, len(changed))
+	for i, column := range changed {
+		if !slices.Contains(t.writable, column) {
+			return nil, fmt.Errorf("invalid patch: %s cannot be changed", column)
+		}
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+1)
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d RETURNING %s",
+		t.table, strings.Join(assignments, ", "), t.key, len(changed)+1, columns)
+	updated, err := scanRow[T](q.QueryRow(query, append(values, key)...))
+	if err != nil {
+		if msg, ok := constraintMessage(err); ok {
+			return nil, fmt.Errorf("invalid patch: %s", msg)
+		}
+		return nil, fmt.Errorf("failed to update %s: %w", t.noun, err)
+	}
+
+	if err := recordChange(db, q, current, updated); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("table", t.table).Any("key", key).Strs("columns", changed).Msg("Successfully patched row")
+	return updated, nil
+}
+
+// constraintMessage returns the message of a database error caused by the
+// data rather than the query: a data exception or a constraint violation
+func constraintMessage(err error) (string, bool) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") {
+		return pqErr.Message, true
+	}
+	return "", false
+}
+
+// changedColumns returns the mapped columns whose values differ between two
+// versions of a model, with the new values. Values are compared as they are
+// written to the database, so a change JSON would round away, such as to the
+// fourth decimal place of an amount, is still seen.
+func changedColumns[T any](before, after *T) ([]string, []any) {
+	m := mappingOf[T]()
+	b, a := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+
+	var columns []string
+	var values []any
+	for i, index := range m.fields {
+		oldValue, newValue := b.FieldByIndex(index), a.FieldByIndex(index)
+		if sameValue(oldValue.Interface(), newValue.Interface()) {
+			continue
+		}
+
+		value := newValue.Interface()
+		if m.nullZero[i] && newValue.IsZero() {
+			value = nil
+		}
+		columns = append(columns, m.columns[i])
+		values = append(values, value)
+	}
+
+	return columns, values
+}
+
+// sameValue reports whether two values of a field are written to the database
+// as the same value. Times are equal when they are the same instant.
+func sameValue(a, b any) bool {
+	av, aErr := driver.DefaultParameterConverter.ConvertValue(a)
+	bv, bErr := driver.DefaultParameterConverter.ConvertValue(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	if at, ok := av.(time.Time); ok {
+		bt, ok := bv.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// PATCH /api/categories/{categoryId}
+func (db *DB) PatchCategory(id int, apply func(*model.Category) (*model.Category, error)) (*model.Category, error) {
+	return patchRow(db, categoryPatch, id, apply)
+}
+
+// PATCH /api/customers/{customerId}
+func (db *DB) PatchCustomer(id string, apply func(*model.Customer) (*model.Customer, error)) (*model.Customer, error) {
+	c, err := patchRow(db, customerPatch, id, apply)
+	if err != nil {
+		return nil, err
+	}
+	if c.Currency == "" {
+		c.Currency = db.baseCurrency
+	}
+	return c, nil
+}
+
+// PATCH /api/employees/{employeeId}
+func (db *DB) PatchEmployee(id int, apply func(*model.Employees) (*model.Employees, error)) (*model.Employees, error) {
+	return patchRow(db, employeePatch, id, apply)
+}
+
+// PATCH /api/orders/{orderId}
+func (db *DB) PatchOrder(id int, apply func(*model.Orders) (*model.Orders, error)) (*model.Orders, error) {
+	o, err := patchRow(db, orderPatch, id, apply)
+	if err != nil {
+		return nil, err
+	}
+	if o.Currency == "" {
+		o.Currency = db.baseCurrency
+	}
+	return o, nil
+}
+
+// PATCH /api/products/{productId}
+func (db *DB) PatchProduct(id int, apply func(*model.Products) (*model.Products, error)) (*model.Products, error) {
+	return patchRow(db, productPatch, id, apply)
+}
+
+// PATCH /api/shippers/{shipperId}
+func (db *DB) PatchShipper(id int, apply func(*model.Shippers) (*model.Shippers, error)) (*model.Shippers, error) {
+	return patchRow(db, shipperPatch, id, apply)
+}
+
+// PATCH /api/suppliers/{supplierId}
+func (db *DB) PatchSupplier(id int, apply func(*model.Suppliers) (*model.Suppliers, error)) (*model.Suppliers, error) {
+	return patchRow(db, supplierPatch, id, apply)
+}
+
+// PATCH /api/promotions/{promotionId}
+func (db *DB) PatchPromotion(id int, apply func(*model.Promotion) (*model.Promotion, error)) (*model.Promotion, error) {
+	return patchRow(db, promotionPatch, id, apply)
+}
+
+// PATCH /api/shippers/{shipperId}/rates/{rateId}
+func (db *DB) PatchFreightRate(shipperId, rateId int, apply func(*model.FreightRate) (*model.FreightRate, error)) (*model.FreightRate, error) {
+	return patchRow(db, freightRatePatch, rateId, func(rate *model.FreightRate) (*model.FreightRate, error) {
+		if rate.ShipperId != shipperId {
+			return nil, fmt.Errorf("freight rate not found")
+		}
+		return apply(rate)
+	})
+}
+
+// #endregion
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChangedColumns(t *testing.T) {
+	ordered := time.Date(1996, 7, 4, 0, 0, 0, 0, time.UTC)
+	product := model.Products{
+		ProductId:   1,
+		ProductName: "Chai",
+		UnitPrice:   model.NewNull(money.MustParse("18.0000")),
+	}
+	order := model.Orders{OrderId: 10248, OrderDate: model.NewNull(ordered)}
+
+	tests := []struct {
+		name    string
+		changed func() []string
+		want    []string
+	}{
+		{"unchanged", func() []string {
+			after := product
+			columns, _ := changedColumns(&product, &after)
+			return columns
+		}, nil},
+		{"amount changed below the rounding", func() []string {
+			after := product
+			after.UnitPrice = model.NewNull(money.MustParse("18.0040"))
+			columns, _ := changedColumns(&product, &after)
+			return columns
+		}, []string{"unit_price"}},
+		{"amount set to NULL", func() []string {
+			after := product
+			after.UnitPrice = model.NullMoney{}
+			columns, _ := changedColumns(&product, &after)
+			return columns
+		}, []string{"unit_price"}},
+		{"same instant in another location", func() []string {
+			after := order
+			after.OrderDate = model.NewNull(ordered.In(time.FixedZone("CEST", 2*60*60)))
+			columns, _ := changedColumns(&order, &after)
+			return columns
+		}, nil},
+		{"time changed", func() []string {
+			after := order
+			after.OrderDate = model.NewNull(ordered.Add(time.Hour))
+			columns, _ := changedColumns(&order, &after)
+			return columns
+		}, []string{"order_date"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.changed(); !slices.Equal(got, tt.want) {
+				t.Errorf("changed columns = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region prices
+
+// effectivePrice returns the price of a product effective at the given time,
+// falling back to products.unit_price for products without any price history
+func effectivePrice(q querier, productId int, at time.Time) (money.Money, error) {
+	query := `
+		SELECT unit_price
+		FROM product_prices
+		WHERE product_id = $1 AND effective_from <= $2 AND (effective_to IS NULL OR effective_to > $2)
+		ORDER BY effective_from DESC
+		LIMIT 1
+	`
+
+	var price model.NullMoney
+	err := q.QueryRow(query, productId, at).Scan(&price)
+	if err == sql.ErrNoRows {
+		err = q.QueryRow("SELECT unit_price FROM products WHERE product_id = $1", productId).Scan(&price)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("product not found")
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query effective price: %w", err)
+	}
+
+	return price.V, nil
+}
+
+// GET /api/products/{productId}/prices
+func (db *DB) GetProductPrices(productId int) ([]model.ProductPrice, error) {
+	return collect(db.StreamProductPrices(productId))
+}
+
+// GET /api/products/{productId}/prices?format=
+func (db *DB) StreamProductPrices(productId int) (*Cursor[model.ProductPrice], error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE product_id = $1)", productId).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the products existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	query := "SELECT " + columnsOf[model.ProductPrice]("") + `
+		FROM product_prices
+		WHERE product_id = $1
+		ORDER BY effective_from
+	`
+
+	return openCursor[model.ProductPrice](db, "product prices", nil, query, productId)
+}
+
+// BackdateTolerance is how far in the past a price change may take effect, so
+// a change made to take effect now is not refused for the moment it took to
+// get here
+const BackdateTolerance = time.Minute
+
+// checkEffectiveFrom refuses a price change taking effect in the past, which
+// would rewrite the prices past orders were placed at
+func checkEffectiveFrom(from, now time.Time) error {
+	if from.Before(now.Add(-BackdateTolerance)) {
+		return fmt.Errorf("invalid price change: effective_from is in the past")
+	}
+	return nil
+}
+
+// schedulePrice inserts a price period starting at `from` for a product. The
+// period that contains `from` is cut short, and the new period runs until the
+// next already scheduled change, if any. Periods in the past are left as
+// they are.
+func schedulePrice(tx *sql.Tx, productId int, price money.Money, from time.Time) (*model.PriceChange, error) {
+	if err := checkEffectiveFrom(from, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Lock the product so concurrent schedules for it are serialised
+	var locked int
+	err := tx.QueryRow("SELECT product_id FROM products WHERE product_id = $1 FOR UPDATE", productId).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	oldPrice, err := effectivePrice(tx, productId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &model.PriceChange{
+		ProductId:     productId,
+		OldPrice:      oldPrice,
+		NewPrice:      price,
+		EffectiveFrom: from,
+	}
+
+	// A period already starting at exactly this time just gets a new price
+	result, err := tx.Exec("UPDATE product_prices SET unit_price = $3 WHERE product_id = $1 AND effective_from = $2", productId, from, price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled price: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	} else if n > 0 {
+		return change, nil
+	}
+
+	var next sql.NullTime
+	err = tx.QueryRow("SELECT MIN(effective_from) FROM product_prices WHERE product_id = $1 AND effective_from > $2", productId, from).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query next scheduled price: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE product_prices
+		SET effective_to = $2
+		WHERE product_id = $1 AND effective_from < $2 AND (effective_to IS NULL OR effective_to > $2)
+	`, productId, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close current price period: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO product_prices (product_id, unit_price, effective_from, effective_to)
+		VALUES ($1, $2, $3, $4)
+	`, productId, price, from, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert scheduled price: %w", err)
+	}
+
+	return change, nil
+}
+
+// POST /api/products/{productId}/prices
+func (db *DB) ScheduleProductPrice(productId int, price money.Money, from time.Time) (*model.PriceChange, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	change, err := schedulePrice(tx, productId, price, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncCurrentPrices(db, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().Int("product_id", productId).Stringer("unit_price", price).Time("effective_from", from).Msg("Scheduled product price")
+	return change, nil
+}
+
+// POST /api/prices/adjustments
+// Schedules a percentage change for every product in a category and/or from a
+// supplier; a zero id does not filter on that column
+func (db *DB) AdjustPrices(categoryId, supplierId int, percent money.Money, from time.Time) ([]model.PriceChange, error) {
+	if err := checkEffectiveFrom(from, time.Now()); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT product_id
+		FROM products
+		WHERE ($1 = 0 OR category_id = $1) AND ($2 = 0 OR supplier_id = $2) AND NOT discontinued
+		ORDER BY product_id
+	`
+
+	rows, err := tx.Query(query, categoryId, supplierId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products to adjust: %w", err)
+	}
+
+	var productIds []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan products to adjust: %w", err)
+		}
+		productIds = append(productIds, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read products to adjust: %w", err)
+	}
+
+	changes := []model.PriceChange{}
+	for _, id := range productIds {
+		current, err := effectivePrice(tx, id, from)
+		if err != nil {
+			return nil, err
+		}
+
+		newPrice := current.Add(current.Percent(percent)).Rounded()
+		change, err := schedulePrice(tx, id, newPrice, from)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *change)
+	}
+
+	if err := syncCurrentPrices(db, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Info().Int("category_id", categoryId).Int("supplier_id", supplierId).Stringer("percent", percent).
+		Int("products", len(changes)).Msg("Scheduled price adjustment")
+	return changes, nil
+}
+
+// syncCurrentPrices copies the price effective now into products.unit_price,
+// which keeps acting as the current list price, recording the events of the
+// products whose price changed
+func syncCurrentPrices(db *DB, q querier) error {
+	rows, err := q.Query(`
+		UPDATE products p
+		SET unit_price = pp.unit_price
+		FROM product_prices pp
+		WHERE pp.product_id = p.product_id
+			AND pp.effective_from <= NOW() AND (pp.effective_to IS NULL OR pp.effective_to > NOW())
+			AND p.unit_price IS DISTINCT FROM pp.unit_price
+		RETURNING ` + columnsOf[model.Products]("p"))
+	if err != nil {
+		return fmt.Errorf("failed to sync current prices: %w", err)
+	}
+	products, err := scanRows[model.Products](rows)
+	if err != nil {
+		return fmt.Errorf("failed to sync current prices: %w", err)
+	}
+
+	for i := range products {
+		if err := recordChange(db, q, &products[i], &products[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncCurrentPrices brings products.unit_price in line with scheduled price changes that have come into effect
+func (db *DB) SyncCurrentPrices() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := syncCurrentPrices(db, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// #endregion
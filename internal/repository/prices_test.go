@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckEffectiveFrom(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		wantErr bool
+	}{
+		{"now", now, false},
+		{"later", now.Add(time.Hour), false},
+		{"within the tolerance", now.Add(-BackdateTolerance + time.Second), false},
+		{"backdated", now.Add(-BackdateTolerance - time.Second), true},
+		{"last year", now.AddDate(-1, 0, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEffectiveFrom(tt.from, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkEffectiveFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
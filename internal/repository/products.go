@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+)
+
+// #region products
+
+// The queries below take the columns to select, such as those asked for
+// with ?fields=. Without any every column is selected, and the fields of
+// columns left out stay zero.
+
+// GET /api/products
+func (db *DB) GetProductPage(limit, offset int, columns ...string) ([]model.Products, int, error) {
+	m, err := mappingOf[model.Products]().subset(columns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM products").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	query := "SELECT " + m.list() + " FROM products ORDER BY product_id LIMIT $1 OFFSET $2"
+	products, err := collect(openMapped[model.Products](db, "products", nil, m, query, limit, offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// GET /api/products?format=
+// rpc ProductService.StreamProducts
+func (db *DB) StreamProducts() (*Cursor[model.Products], error) {
+	query := "SELECT " + columnsOf[model.Products]("") + " FROM products ORDER BY product_id"
+	return openCursor[model.Products](db, "products", nil, query)
+}
+
+// GET /api/products/{productId}
+func (db *DB) GetProductById(id int, columns ...string) (*model.Products, error) {
+	m, err := mappingOf[model.Products]().subset(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + m.list() + " FROM products WHERE product_id = $1"
+	product := &model.Products{}
+	err = db.QueryRow(query, id).Scan(m.targets(product)...)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product: %w", err)
+	}
+
+	return product, nil
+}
+
+// #endregion
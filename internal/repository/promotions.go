@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"northwind-api/internal/model"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// #region promotions
+
+// promotionColumns lists the promotions columns in the order of the Promotion fields
+var promotionColumns = columnsOf[model.Promotion]("")
+
+// queryPromotions runs a query selecting promotionColumns and scans every row
+func queryPromotions(q querier, query string, args ...any) ([]model.Promotion, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotions: %w", err)
+	}
+
+	promotions, err := scanRows[model.Promotion](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// GET /api/promotions
+func (db *DB) GetAllPromotions() ([]model.Promotion, error) {
+	return collect(db.StreamPromotions())
+}
+
+// GET /api/promotions?format=
+func (db *DB) StreamPromotions() (*Cursor[model.Promotion], error) {
+	return openCursor[model.Promotion](db, "promotions", nil, "SELECT "+promotionColumns+" FROM promotions ORDER BY promotion_id")
+}
+
+// GET /api/promotions/{promotionId}
+func (db *DB) GetPromotionById(id int) (*model.Promotion, error) {
+	p, err := scanRow[model.Promotion](db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE promotion_id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("promotion not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotion: %w", err)
+	}
+
+	return p, nil
+}
+
+// activePromotions returns the promotions that may apply to a customer's order at a time
+func activePromotions(q querier, customerId string, at time.Time) ([]model.Promotion, error) {
+	query := "SELECT " + promotionColumns + `
+		FROM promotions
+		WHERE active
+			AND (valid_from IS NULL OR valid_from <= $2)
+			AND (valid_to IS NULL OR valid_to > $2)
+			AND (customer_id IS NULL OR customer_id = $1)
+	`
+
+	return queryPromotions(q, query, customerId, at)
+}
+
+// POST /api/promotions
+func (db *DB) CreatePromotion(p model.Promotion) (int, error) {
+	query := `
+		INSERT INTO promotions (name, discount_type, discount_value, min_quantity, product_id,
+			category_id, customer_id, valid_from, valid_to, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING promotion_id
+	`
+
+	var id int
+	err := db.QueryRow(query, p.Name, p.DiscountType, p.DiscountValue, p.MinQuantity,
+		p.ProductId, p.CategoryId, p.CustomerId, p.ValidFrom, p.ValidTo, p.Active).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	log.Info().Int("promotion_id", id).Str("name", p.Name).Msg("Successfully created promotion")
+	return id, nil
+}
+
+// DELETE /api/promotions/{promotionId}
+func (db *DB) DeletePromotion(id int) error {
+	result, err := db.Exec("DELETE FROM promotions WHERE promotion_id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete the promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("promotion not found")
+	}
+
+	log.Info().Int("promotion_id", id).Msg("Successfully deleted promotion")
+	return nil
+}
+
+// #endregion
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"northwind-api/internal/model"
+	"os"
+	"slices"
+	"testing"
+)
+
+// tableModels pairs each table with the mapping of the model its rows are read into
+var tableModels = map[string]*rowMapping{
+	"categories":         mappingOf[model.Category](),
+	"customers":          mappingOf[model.Customer](),
+	"employees":          mappingOf[model.Employees](),
+	"order_details":      mappingOf[model.OrderDetails](),
+	"orders":             mappingOf[model.Orders](),
+	"products":           mappingOf[model.Products](),
+	"shippers":           mappingOf[model.Shippers](),
+	"suppliers":          mappingOf[model.Suppliers](),
+	"product_prices":     mappingOf[model.ProductPrice](),
+	"promotions":         mappingOf[model.Promotion](),
+	"freight_rates":      mappingOf[model.FreightRate](),
+	"exchange_rates":     mappingOf[model.ExchangeRate](),
+	"webhooks":           mappingOf[model.Webhook](),
+	"webhook_deliveries": mappingOf[model.WebhookDelivery](),
+	"outbox":             mappingOf[model.OutboxEvent](),
+}
+
+// testDB connects to the database at TEST_DATABASE_URL, skipping the test
+// when it is not set or cannot be reached
+func testDB(t *testing.T) *sql.DB {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Skipf("cannot open the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Skipf("cannot reach the test database: %v", err)
+	}
+	return db
+}
+
+// TestSchemaMatchesModels checks every `db:` tag of the table models against
+// the columns of a database created from database.sql, so a renamed or
+// mistyped column fails here rather than on the first query that selects it
+func TestSchemaMatchesModels(t *testing.T) {
+	db := testDB(t)
+
+	rows, err := db.Query(`
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+	`)
+	if err != nil {
+		t.Fatalf("failed to query schema columns: %v", err)
+	}
+	defer rows.Close()
+
+	columns := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			t.Fatalf("failed to scan schema columns: %v", err)
+		}
+		columns[table] = append(columns[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read schema columns: %v", err)
+	}
+
+	for table, mapping := range tableModels {
+		if _, ok := columns[table]; !ok {
+			t.Errorf("missing table %s", table)
+			continue
+		}
+		for _, column := range mapping.columns {
+			if !slices.Contains(columns[table], column) {
+				t.Errorf("missing column %s.%s", table, column)
+			}
+		}
+	}
+}
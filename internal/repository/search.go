@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"fmt"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"strconv"
+	"strings"
+)
+
+// SearchFilter narrows a catalogue search
+type SearchFilter struct {
+	Query      string
+	CategoryId int
+	SupplierId int
+	MinPrice   *money.Money
+	MaxPrice   *money.Money
+	Limit      int
+	Offset     int
+}
+
+// priceRange is one bucket of the price facet; a zero max means unbounded
+type priceRange struct {
+	min, max int64
+}
+
+var searchPriceRanges = []priceRange{{0, 10}, {10, 25}, {25, 50}, {50, 100}, {100, 0}}
+
+func (p priceRange) label() string {
+	if p.max == 0 {
+		return strconv.FormatInt(p.min, 10) + "+"
+	}
+	return strconv.FormatInt(p.min, 10) + "-" + strconv.FormatInt(p.max, 10)
+}
+
+// searchMatchesCTE builds the "matches" CTE shared by the result and facet queries.
+// Products match when their name, category or supplier matches the full-text
+// query, or, to tolerate typos, when one of them is a close trigram match.
+// The match predicates mirror the expressions of the search indexes in database.sql.
+func searchMatchesCTE(filter SearchFilter) (string, []any) {
+	args := []any{filter.Query}
+	conditions := []string{`(
+		to_tsvector('english', p.product_name) @@ websearch_to_tsquery('english', $1)
+		OR to_tsvector('english', c.category_name || ' ' || COALESCE(c.description, '')) @@ websearch_to_tsquery('english', $1)
+		OR to_tsvector('english', s.company_name) @@ websearch_to_tsquery('english', $1)
+		OR $1 <% p.product_name
+		OR $1 <% c.category_name
+		OR $1 <% s.company_name
+	)`}
+
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if filter.CategoryId != 0 {
+		addCondition("p.category_id = $%d", filter.CategoryId)
+	}
+	if filter.SupplierId != 0 {
+		addCondition("p.supplier_id = $%d", filter.SupplierId)
+	}
+	if filter.MinPrice != nil {
+		addCondition("p.unit_price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition("p.unit_price <= $%d", *filter.MaxPrice)
+	}
+
+	cte := `
+		WITH matches AS (
+			SELECT p.product_id, p.product_name, p.unit_price, p.category_id, c.category_name,
+				p.supplier_id, s.company_name AS supplier_name,
+				ts_rank(
+					setweight(to_tsvector('english', p.product_name), 'A') ||
+					setweight(to_tsvector('english', COALESCE(c.category_name, '')), 'B') ||
+					setweight(to_tsvector('english', COALESCE(s.company_name, '')), 'B') ||
+					setweight(to_tsvector('english', COALESCE(c.description, '')), 'C'),
+					websearch_to_tsquery('english', $1)) AS fts_rank,
+				GREATEST(
+					word_similarity($1, p.product_name),
+					word_similarity($1, COALESCE(c.category_name, '')),
+					word_similarity($1, COALESCE(s.company_name, ''))) AS trgm_score
+			FROM products p
+			LEFT JOIN categories c ON c.category_id = p.category_id
+			LEFT JOIN suppliers s ON s.supplier_id = p.supplier_id
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		)
+	`
+
+	return cte, args
+}
+
+// escapeHTML returns the SQL escaping the HTML special characters of a text
+// column, so the highlights built from it carry no markup but their <mark> tags
+func escapeHTML(column string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"''", "&#39;"}} {
+		column = "replace(" + column + ", '" + r[0] + "', '" + r[1] + "')"
+	}
+	return column
+}
+
+// GET /api/search
+func (db *DB) SearchProducts(filter SearchFilter) (*model.SearchResults, error) {
+	cte, args := searchMatchesCTE(filter)
+
+	results := &model.SearchResults{
+		Query:   filter.Query,
+		Results: []model.SearchResult{},
+		Facets: model.SearchFacets{
+			Categories:  []model.SearchFacet{},
+			Suppliers:   []model.SearchFacet{},
+			PriceRanges: []model.SearchFacet{},
+		},
+	}
+
+	err := db.QueryRow(cte+"SELECT COUNT(*) FROM matches", args...).Scan(&results.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search matches: %w", err)
+	}
+
+	// Full-text matches rank above trigram-only matches
+	headline := func(column string) string {
+		return "ts_headline('english', " + escapeHTML(column) + ", websearch_to_tsquery('english', $1), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')"
+	}
+	query := cte + fmt.Sprintf(`
+		SELECT product_id, product_name, category_id, category_name, supplier_id, supplier_name,
+			unit_price, fts_rank + trgm_score, fts_rank = 0,
+			%s, %s, %s
+		FROM matches
+		ORDER BY fts_rank > 0 DESC, fts_rank DESC, trgm_score DESC, product_name
+		LIMIT $%d OFFSET $%d
+	`, headline("product_name"), headline("category_name"), headline("supplier_name"), len(args)+1, len(args)+2)
+
+	rows, err := db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res model.SearchResult
+		var productHeadline, categoryHeadline, supplierHeadline model.NullString
+		err := rows.Scan(&res.ProductId, &res.ProductName, &res.CategoryId, &res.CategoryName,
+			&res.SupplierId, &res.SupplierName, &res.UnitPrice, &res.Rank, &res.Fuzzy,
+			&productHeadline, &categoryHeadline, &supplierHeadline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search results: %w", err)
+		}
+
+		res.Highlights = map[string]string{
+			"product_name":  productHeadline.V,
+			"category_name": categoryHeadline.V,
+			"supplier_name": supplierHeadline.V,
+		}
+		results.Results = append(results.Results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	results.Facets.Categories, err = db.searchFacet(cte+`
+		SELECT category_id, category_name, COUNT(*)
+		FROM matches
+		WHERE category_id IS NOT NULL
+		GROUP BY category_id, category_name
+		ORDER BY COUNT(*) DESC, category_name
+	`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	results.Facets.Suppliers, err = db.searchFacet(cte+`
+		SELECT supplier_id, supplier_name, COUNT(*)
+		FROM matches
+		WHERE supplier_id IS NOT NULL
+		GROUP BY supplier_id, supplier_name
+		ORDER BY COUNT(*) DESC, supplier_name
+	`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	results.Facets.PriceRanges, err = db.searchPriceFacet(cte, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// searchFacet runs a facet query returning (id, label, count) rows
+func (db *DB) searchFacet(query string, args []any) ([]model.SearchFacet, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search facet: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []model.SearchFacet{}
+	for rows.Next() {
+		var facet model.SearchFacet
+		var label model.NullString
+		if err := rows.Scan(&facet.Id, &label, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan search facet: %w", err)
+		}
+		facet.Label = label.V
+
+		facets = append(facets, facet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search facet: %w", err)
+	}
+
+	return facets, nil
+}
+
+// searchPriceFacet counts the matches falling in each of searchPriceRanges
+func (db *DB) searchPriceFacet(cte string, args []any) ([]model.SearchFacet, error) {
+	var bucket strings.Builder
+	bucket.WriteString("CASE")
+	for i, r := range searchPriceRanges {
+		if r.max == 0 {
+			fmt.Fprintf(&bucket, " WHEN unit_price >= %d THEN %d", r.min, i)
+		} else {
+			fmt.Fprintf(&bucket, " WHEN unit_price < %d THEN %d", r.max, i)
+		}
+	}
+	bucket.WriteString(" END")
+
+	query := cte + `
+		SELECT bucket, COUNT(*)
+		FROM (SELECT ` + bucket.String() + ` AS bucket FROM matches WHERE unit_price IS NOT NULL) b
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price facet: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []model.SearchFacet{}
+	for rows.Next() {
+		var index, count int
+		if err := rows.Scan(&index, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan price facet: %w", err)
+		}
+
+		facets = append(facets, model.SearchFacet{Label: searchPriceRanges[index].label(), Count: count})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price facet: %w", err)
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"northwind-api/internal/events"
+	"northwind-api/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// #region webhooks
+
+var (
+	webhookColumns  = columnsOf[model.Webhook]("")
+	deliveryColumns = columnsOf[model.WebhookDelivery]("")
+)
+
+// GET /api/webhooks
+func (db *DB) GetWebhooks() ([]model.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhooks ORDER BY webhook_id"
+	return collect(openCursor[model.Webhook](db, "webhooks", nil, query))
+}
+
+// GET /api/webhooks/{webhookId}
+func (db *DB) GetWebhookById(id int) (*model.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE webhook_id = $1"
+
+	w, err := scanRow[model.Webhook](db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook: %w", err)
+	}
+
+	return w, nil
+}
+
+// POST /api/webhooks
+func (db *DB) CreateWebhook(w model.Webhook) (*model.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (url, events, description, secret, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookColumns
+
+	created, err := scanRow[model.Webhook](db.QueryRow(query, w.URL, w.Events, w.Description, w.Secret, w.Active))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	log.Info().Int("webhook_id", created.WebhookId).Str("url", created.URL).Strs("events", created.Events).Msg("Successfully created webhook")
+	return created, nil
+}
+
+// DELETE /api/webhooks/{webhookId}
+// Deleting a webhook deletes its deliveries, including those still pending
+func (db *DB) DeleteWebhook(id int) error {
+	return deleteRow(db, "webhooks", "webhook_id", "webhook", id)
+}
+
+// pageOfDeliveries returns a page of the deliveries matching a condition,
+// newest first, and how many there are
+func (db *DB) pageOfDeliveries(where string, limit, offset int, args ...any) ([]model.WebhookDelivery, int, error) {
+	var total int
+	err := db.QueryRow("SELECT COUNT(*) FROM webhook_deliveries WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	n := len(args)
+	query := fmt.Sprintf("SELECT %s FROM webhook_deliveries WHERE %s ORDER BY delivery_id DESC LIMIT $%d OFFSET $%d",
+		deliveryColumns, where, n+1, n+2)
+	deliveries, err := collect(openCursor[model.WebhookDelivery](db, "webhook deliveries", nil, query, append(args, limit, offset)...))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// GET /api/webhooks/{webhookId}/deliveries
+func (db *DB) GetWebhookDeliveries(webhookId, limit, offset int) ([]model.WebhookDelivery, int, error) {
+	return db.pageOfDeliveries("webhook_id = $1", limit, offset, webhookId)
+}
+
+// GET /api/webhooks/dead-letters
+// Deliveries that ran out of attempts, across every webhook
+func (db *DB) GetDeadDeliveries(limit, offset int) ([]model.WebhookDelivery, int, error) {
+	return db.pageOfDeliveries("status = $1", limit, offset, model.DeliveryDead)
+}
+
+// POST /api/webhooks/deliveries/{deliveryId}/redeliver
+// Queues a delivery to be sent again straight away with a fresh set of attempts,
+// whether it is dead or was delivered. A pending delivery is already queued, so
+// it is refused rather than having its attempts reset under the dispatcher.
+func (db *DB) RedeliverWebhookDelivery(id int) (*model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE delivery_id = $1 AND status IN ($3, $4)
+		RETURNING ` + deliveryColumns
+
+	d, err := scanRow[model.WebhookDelivery](db.QueryRow(query, id, model.DeliveryPending, model.DeliveryDead, model.DeliveryDelivered))
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM webhook_deliveries WHERE delivery_id = $1)", id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check the webhook delivery: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("webhook delivery is already pending")
+		}
+		return nil, fmt.Errorf("webhook delivery not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue the webhook delivery: %w", err)
+	}
+
+	log.Info().Int("delivery_id", id).Str("event_id", d.EventId).Msg("Queued webhook redelivery")
+	return d, nil
+}
+
+// #endregion
+
+// #region webhook dispatch
+
+// WebhookAttempt is a due delivery claimed for an attempt, with what is needed to send it
+type WebhookAttempt struct {
+	DeliveryId int
+	WebhookId  int
+	EventId    string
+	EventType  string
+	Payload    []byte
+	// The number of this attempt, starting at 1
+	Attempt int
+	URL     string
+	Secret  string
+}
+
+// EnqueueWebhookDeliveries queues a delivery of each event to every active
+// webhook whose filters match it, returning how many were queued. An event
+// queued before is not queued again.
+func (db *DB) EnqueueWebhookDeliveries(evs []events.Event) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at)
+		SELECT webhook_id, $1, $2, $3, $4, NOW()
+		FROM webhooks
+		WHERE active AND events && $5
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+
+	queued := 0
+	for _, e := range evs {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		result, err := tx.Exec(query, e.Id, e.Type, string(payload), model.DeliveryPending, pq.Array(events.Filters(e.Type)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to queue webhook deliveries: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		queued += int(n)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return queued, nil
+}
+
+// ClaimWebhookDeliveries claims up to limit due deliveries for an attempt each.
+// A claimed delivery is not due again until lease has passed, so one whose
+// attempt is never recorded (the process stopped mid-attempt) is retried then.
+// Deliveries claimed by another instance are skipped.
+func (db *DB) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]WebhookAttempt, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, last_attempt_at = NOW(),
+			next_attempt_at = NOW() + make_interval(secs => $3)
+		FROM webhooks w
+		WHERE w.webhook_id = d.webhook_id AND d.delivery_id IN (
+			SELECT delivery_id
+			FROM webhook_deliveries
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, delivery_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.delivery_id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+	`
+
+	rows, err := db.Query(query, model.DeliveryPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []WebhookAttempt
+	for rows.Next() {
+		var a WebhookAttempt
+		if err := rows.Scan(&a.DeliveryId, &a.WebhookId, &a.EventId, &a.EventType, &a.Payload, &a.Attempt, &a.URL, &a.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// MarkWebhookDelivered records the successful attempt of a delivery
+func (db *DB) MarkWebhookDelivered(id, status int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, last_status = $3, last_error = NULL, next_attempt_at = NULL, delivered_at = NOW()
+		WHERE delivery_id = $1
+	`
+	if _, err := db.Exec(query, id, model.DeliveryDelivered, status); err != nil {
+		return fmt.Errorf("failed to record the webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// RetryWebhookDelivery records the failed attempt of a delivery, which is
+// attempted again at retryAt, or is dead if retryAt is zero. A zero status
+// is an attempt that got no response.
+func (db *DB) RetryWebhookDelivery(id, status int, reason string, retryAt time.Time) error {
+	state, next := model.DeliveryPending, model.NullIfZero(retryAt)
+	if retryAt.IsZero() {
+		state = model.DeliveryDead
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, last_status = $3, last_error = $4, next_attempt_at = $5
+		WHERE delivery_id = $1
+	`
+	if _, err := db.Exec(query, id, state, model.NullIfZero(status), reason, next); err != nil {
+		return fmt.Errorf("failed to record the webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// #endregion
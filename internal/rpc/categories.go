@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"northwind-api/internal/model"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+	"northwind-api/internal/validate"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// categoryService implements pb.CategoryServiceServer
+type categoryService struct {
+	pb.UnimplementedCategoryServiceServer
+	db *repository.DB
+}
+
+// The fields of a category being created or replaced, validated like the
+// body of POST and PUT /api/categories
+type categoryFields struct {
+	Name        string `json:"category_name" validate:"required,max=15"`
+	Description string `json:"description" validate:"required"`
+}
+
+func (s *categoryService) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Category, error) {
+	category, err := s.db.GetCategoryById(int(req.CategoryId))
+	if err != nil {
+		return nil, statusOf(err, "get the category")
+	}
+	return categoryOf(category), nil
+}
+
+func (s *categoryService) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	categories, err := s.db.GetAllCategories()
+	if err != nil {
+		return nil, statusOf(err, "get categories")
+	}
+	return &pb.ListCategoriesResponse{Categories: convertAll(categories, categoryOf)}, nil
+}
+
+func (s *categoryService) StreamCategories(req *pb.StreamCategoriesRequest, stream pb.CategoryService_StreamCategoriesServer) error {
+	cursor, err := s.db.StreamCategories()
+	return streamCursor(cursor, err, stream.Send, categoryOf, "stream categories")
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.Category, error) {
+	fields := categoryFields{Name: req.CategoryName, Description: req.Description}
+	if errs := validate.Struct(&fields); len(errs) > 0 {
+		return nil, invalid(errs)
+	}
+
+	id, err := s.db.CreateNewCategory(fields.Name, fields.Description)
+	if err != nil {
+		return nil, statusOf(err, "create new category")
+	}
+
+	return categoryOf(&model.Category{
+		CategoryId:  id,
+		Name:        fields.Name,
+		Description: model.NewNull(fields.Description),
+	}), nil
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.Category, error) {
+	fields := categoryFields{Name: req.CategoryName, Description: req.Description}
+	if errs := validate.Struct(&fields); len(errs) > 0 {
+		return nil, invalid(errs)
+	}
+
+	if err := s.db.UpdateCategory(strconv.Itoa(int(req.CategoryId)), fields.Name, fields.Description); err != nil {
+		return nil, statusOf(err, "update the category")
+	}
+
+	return categoryOf(&model.Category{
+		CategoryId:  int(req.CategoryId),
+		Name:        fields.Name,
+		Description: model.NewNull(fields.Description),
+	}), nil
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	if err := s.db.DeleteCategory(strconv.Itoa(int(req.CategoryId))); err != nil {
+		return nil, statusOf(err, "delete the category")
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,232 @@
+package rpc
+
+import (
+	"fmt"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// #region paging and streaming
+
+// Paging defaults for list calls, the same as the HTTP API's
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageOf checks the page_size and offset of a list request, applying the default page size
+func pageOf(pageSize, offset int32) (limit, skip int, err error) {
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return 0, 0, fieldError("page_size", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+	}
+	if offset < 0 {
+		return 0, 0, fieldError("offset", "must not be negative")
+	}
+	return int(pageSize), int(offset), nil
+}
+
+// streamCursor sends every row of a cursor as a message and closes it, so a
+// large table is streamed without being held in memory
+func streamCursor[T, M any](c *repository.Cursor[T], err error, send func(*M) error, convert func(*T) *M, action string) error {
+	if err != nil {
+		return statusOf(err, action)
+	}
+	defer c.Close()
+
+	for c.Next() {
+		if err := send(convert(c.Value())); err != nil {
+			return err
+		}
+	}
+	if err := c.Err(); err != nil {
+		return statusOf(fmt.Errorf("failed to scan rows: %w", err), action)
+	}
+	return nil
+}
+
+// convertAll converts every row of a list
+func convertAll[T, M any](rows []T, convert func(*T) *M) []*M {
+	messages := make([]*M, len(rows))
+	for i := range rows {
+		messages[i] = convert(&rows[i])
+	}
+	return messages
+}
+
+// #endregion
+
+// #region values
+
+// optInt32 returns a nullable integer as an optional field
+func optInt32(n model.NullInt) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	v := int32(n.V)
+	return &v
+}
+
+// optMoney returns a nullable amount as an optional decimal string
+func optMoney(n model.NullMoney) *string {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V.String()
+	return &v
+}
+
+// timestampOf returns a nullable time as a timestamp, unset when NULL
+func timestampOf(n model.NullTime) *timestamppb.Timestamp {
+	if !n.Valid {
+		return nil
+	}
+	return timestamppb.New(n.V)
+}
+
+// timeOf returns a timestamp as a nullable time, NULL when unset
+func timeOf(ts *timestamppb.Timestamp) model.NullTime {
+	if ts == nil {
+		return model.NullTime{}
+	}
+	return model.NewNull(ts.AsTime())
+}
+
+// parseMoney parses a decimal string field, naming the field if it is invalid
+func parseMoney(field, s string) (money.Money, error) {
+	m, err := money.Parse(s)
+	if err != nil {
+		return 0, fieldError(field, "must be a decimal amount")
+	}
+	return m, nil
+}
+
+// #endregion
+
+// #region messages
+
+func categoryOf(c *model.Category) *pb.Category {
+	return &pb.Category{
+		CategoryId:   int32(c.CategoryId),
+		CategoryName: c.Name,
+		Description:  c.Description.Ptr(),
+	}
+}
+
+func productOf(p *model.Products) *pb.Product {
+	return &pb.Product{
+		ProductId:       int32(p.ProductId),
+		ProductName:     p.ProductName,
+		SupplierId:      optInt32(p.SupplierId),
+		CategoryId:      optInt32(p.CategoryId),
+		QuantityPerUnit: p.QuantityPerUnit.Ptr(),
+		UnitPrice:       optMoney(p.UnitPrice),
+		UnitsInStock:    optInt32(p.UnitsInStock),
+		UnitsOnOrder:    optInt32(p.UnitsOnOrder),
+		ReorderLevel:    optInt32(p.ReorderLevel),
+		Discontinued:    p.Discontinued,
+	}
+}
+
+func productPriceOf(p *model.ProductPrice) *pb.ProductPrice {
+	return &pb.ProductPrice{
+		PriceId:       int32(p.PriceId),
+		ProductId:     int32(p.ProductId),
+		UnitPrice:     p.UnitPrice.String(),
+		EffectiveFrom: timestamppb.New(p.EffectiveFrom),
+		EffectiveTo:   timestampOf(p.EffectiveTo),
+	}
+}
+
+func priceChangeOf(c *model.PriceChange) *pb.PriceChange {
+	return &pb.PriceChange{
+		ProductId:     int32(c.ProductId),
+		OldPrice:      c.OldPrice.String(),
+		NewPrice:      c.NewPrice.String(),
+		EffectiveFrom: timestamppb.New(c.EffectiveFrom),
+	}
+}
+
+func customerOf(c *model.Customer) *pb.Customer {
+	return &pb.Customer{
+		CustomerId:  c.CustomerId,
+		CompanyName: c.CompanyName,
+		ContactName: c.ContactName.Ptr(),
+		Address:     c.Address.Ptr(),
+		City:        c.City.Ptr(),
+		Region:      c.Region.Ptr(),
+		PostalCode:  c.PostalCode.Ptr(),
+		Country:     c.Country.Ptr(),
+		Phone:       c.Phone.Ptr(),
+		Currency:    c.Currency,
+	}
+}
+
+// orderOf converts an order without its lines
+func orderOf(o *model.Orders) *pb.Order {
+	return &pb.Order{
+		OrderId:        int32(o.OrderId),
+		CustomerId:     o.CustomerId.Ptr(),
+		EmployeeId:     optInt32(o.EmployeeId),
+		OrderDate:      timestampOf(o.OrderDate),
+		RequiredDate:   timestampOf(o.RequiredDate),
+		ShippedDate:    timestampOf(o.ShippedDate),
+		ShipVia:        optInt32(o.ShipVia),
+		Freight:        optMoney(o.Freight),
+		ShipName:       o.ShipName.Ptr(),
+		ShipAddress:    o.ShipAddress.Ptr(),
+		Region:         o.Region.Ptr(),
+		ShipCity:       o.ShipCity.Ptr(),
+		ShipPostalCode: o.ShipPostalCode.Ptr(),
+		ShipCountry:    o.ShipCountry.Ptr(),
+		Currency:       o.Currency,
+		ExchangeRate:   o.ExchangeRate.String(),
+	}
+}
+
+func orderLineOf(l *model.OrderLine) *pb.OrderLine {
+	return &pb.OrderLine{
+		ProductId:   int32(l.ProductId),
+		ProductName: l.ProductName,
+		UnitPrice:   l.UnitPrice.String(),
+		Quantity:    int32(l.Quantity),
+		Discount:    l.Discount.String(),
+	}
+}
+
+func quoteOf(q *model.Quote) *pb.Quote {
+	return &pb.Quote{
+		CustomerId:    q.CustomerId,
+		Currency:      q.Currency,
+		ExchangeRate:  q.ExchangeRate.String(),
+		OrderDate:     timestamppb.New(q.OrderDate),
+		Lines:         convertAll(q.Lines, quoteLineOf),
+		Subtotal:      q.Subtotal.String(),
+		Discount:      q.Discount.String(),
+		ShipVia:       int32(q.ShipVia),
+		FreightRateId: int32(q.FreightRateId),
+		Freight:       q.Freight.String(),
+		Total:         q.Total.String(),
+	}
+}
+
+func quoteLineOf(l *model.QuoteLine) *pb.QuoteLine {
+	return &pb.QuoteLine{
+		ProductId:   int32(l.ProductId),
+		ProductName: l.ProductName,
+		CategoryId:  int32(l.CategoryId),
+		UnitPrice:   l.UnitPrice.String(),
+		Quantity:    int32(l.Quantity),
+		Discount:    l.Discount.String(),
+		PromotionId: int32(l.PromotionId),
+		LineTotal:   l.LineTotal.String(),
+	}
+}
+
+// #endregion
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+)
+
+// customerService implements pb.CustomerServiceServer
+type customerService struct {
+	pb.UnimplementedCustomerServiceServer
+	db *repository.DB
+}
+
+func (s *customerService) GetCustomer(ctx context.Context, req *pb.GetCustomerRequest) (*pb.Customer, error) {
+	customer, err := s.db.GetCustomerById(req.CustomerId)
+	if err != nil {
+		return nil, statusOf(err, "get the customer")
+	}
+	return customerOf(customer), nil
+}
+
+func (s *customerService) ListCustomers(ctx context.Context, req *pb.ListCustomersRequest) (*pb.ListCustomersResponse, error) {
+	limit, offset, err := pageOf(req.PageSize, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := s.db.GetCustomers(limit, offset)
+	if err != nil {
+		return nil, statusOf(err, "get customers")
+	}
+	return &pb.ListCustomersResponse{Customers: convertAll(customers, customerOf)}, nil
+}
+
+func (s *customerService) StreamCustomers(req *pb.StreamCustomersRequest, stream pb.CustomerService_StreamCustomersServer) error {
+	cursor, err := s.db.StreamCustomers()
+	return streamCursor(cursor, err, stream.Send, customerOf, "stream customers")
+}
+
+func (s *customerService) ListCustomerOrders(ctx context.Context, req *pb.ListCustomerOrdersRequest) (*pb.ListOrdersResponse, error) {
+	limit, offset, err := pageOf(req.PageSize, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the customer exists so an unknown ID is NotFound rather than an empty page
+	if _, err := s.db.GetCustomerById(req.CustomerId); err != nil {
+		return nil, statusOf(err, "get the customer")
+	}
+
+	orders, total, err := s.db.GetOrdersByCustomer(req.CustomerId, limit, offset)
+	if err != nil {
+		return nil, statusOf(err, "get the customer's orders")
+	}
+	return &pb.ListOrdersResponse{Orders: convertAll(orders, orderOf), Total: int32(total)}, nil
+}
+
+func (s *customerService) StreamCustomerOrders(req *pb.StreamCustomerOrdersRequest, stream pb.CustomerService_StreamCustomerOrdersServer) error {
+	if _, err := s.db.GetCustomerById(req.CustomerId); err != nil {
+		return statusOf(err, "get the customer")
+	}
+
+	cursor, err := s.db.StreamOrdersByCustomer(req.CustomerId)
+	return streamCursor(cursor, err, stream.Send, orderOf, "stream the customer's orders")
+}
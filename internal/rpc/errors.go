@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"errors"
+	"northwind-api/internal/validate"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusOf maps a repository or validation error onto a gRPC status, the
+// way the HTTP handlers map them onto status codes. Unexpected errors are
+// logged and reported as "Failed to <action>".
+func statusOf(err error, action string) error {
+	var errs validate.Errors
+	msg := err.Error()
+	switch {
+	case errors.As(err, &errs):
+		return invalid(errs)
+	case strings.HasSuffix(msg, " not found") && !strings.Contains(msg, ":"):
+		return status.Error(codes.NotFound, strings.ToUpper(msg[:1])+msg[1:])
+	case strings.HasPrefix(msg, "invalid order"), strings.HasPrefix(msg, "invalid price change"), strings.HasPrefix(msg, "no freight rate"):
+		return status.Error(codes.InvalidArgument, msg)
+	case strings.Contains(msg, "already exists"):
+		return status.Error(codes.AlreadyExists, msg)
+	}
+	log.Error().Err(err).Msg("Error serving gRPC call, failed to " + action)
+	return status.Error(codes.Internal, "Failed to "+action)
+}
+
+// invalid returns an InvalidArgument status listing every invalid field as
+// a BadRequest detail
+func invalid(errs validate.Errors) error {
+	st := status.New(codes.InvalidArgument, "Validation failed: "+errs.Error())
+
+	details := &errdetails.BadRequest{}
+	for _, fe := range errs {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
+
+// fieldError returns an InvalidArgument status for a single invalid field
+func fieldError(field, message string) error {
+	return invalid(validate.Errors{{Field: field, Message: message}})
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// The interceptors below do for gRPC calls what middleware.Recovery and
+// middleware.Logging do for HTTP requests
+
+// recoveryUnary recovers from panics in unary calls and logs them
+func recoveryUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoveryStream recovers from panics in streaming calls and logs them
+func recoveryStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ss.Context(), info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// recovered logs a recovered panic with its stack trace and returns the error the call fails with
+func recovered(ctx context.Context, method string, p any) error {
+	log.Error().
+		Str("method", method).
+		Str("remote_addr", remoteAddr(ctx)).
+		Interface("panic", p).
+		Str("stack", string(debug.Stack())).
+		Msg("Panic recovered")
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// loggingUnary logs unary calls with structured logging
+func loggingUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, err, time.Since(start))
+	return resp, err
+}
+
+// loggingStream logs streaming calls with structured logging once they end
+func loggingStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, err, time.Since(start))
+	return err
+}
+
+// logCall logs a finished call with its status code
+func logCall(ctx context.Context, method string, err error, duration time.Duration) {
+	log.Info().
+		Str("method", method).
+		Str("remote_addr", remoteAddr(ctx)).
+		Str("code", status.Code(err).String()).
+		Dur("duration", duration).
+		Msg("gRPC call completed")
+}
+
+// remoteAddr returns the address of the caller, or "" if it is unknown
+func remoteAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
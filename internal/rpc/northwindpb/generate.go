@@ -0,0 +1,5 @@
+// Package northwindpb holds the protobuf messages and gRPC services of the
+// Northwind API, generated from northwind.proto
+package northwindpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative northwind.proto
@@ -0,0 +1,2653 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: northwind.proto
+
+// The Northwind API over gRPC. Amounts of money and exchange rates are exact
+// decimals written as strings, such as "18.00". Fields that are NULL in the
+// database are optional, and timestamps are left unset when NULL.
+
+package northwindpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Category struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryId    int32                  `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	CategoryName  string                 `protobuf:"bytes,2,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
+	Description   *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Category) Reset() {
+	*x = Category{}
+	mi := &file_northwind_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Category) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Category) ProtoMessage() {}
+
+func (x *Category) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Category.ProtoReflect.Descriptor instead.
+func (*Category) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Category) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *Category) GetCategoryName() string {
+	if x != nil {
+		return x.CategoryName
+	}
+	return ""
+}
+
+func (x *Category) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+type GetCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryId    int32                  `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCategoryRequest) Reset() {
+	*x = GetCategoryRequest{}
+	mi := &file_northwind_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCategoryRequest) ProtoMessage() {}
+
+func (x *GetCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCategoryRequest.ProtoReflect.Descriptor instead.
+func (*GetCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCategoryRequest) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+type ListCategoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesRequest) Reset() {
+	*x = ListCategoriesRequest{}
+	mi := &file_northwind_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesRequest) ProtoMessage() {}
+
+func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListCategoriesRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{2}
+}
+
+type ListCategoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Categories    []*Category            `protobuf:"bytes,1,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesResponse) Reset() {
+	*x = ListCategoriesResponse{}
+	mi := &file_northwind_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesResponse) ProtoMessage() {}
+
+func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesResponse.ProtoReflect.Descriptor instead.
+func (*ListCategoriesResponse) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListCategoriesResponse) GetCategories() []*Category {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type StreamCategoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamCategoriesRequest) Reset() {
+	*x = StreamCategoriesRequest{}
+	mi := &file_northwind_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamCategoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCategoriesRequest) ProtoMessage() {}
+
+func (x *StreamCategoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCategoriesRequest.ProtoReflect.Descriptor instead.
+func (*StreamCategoriesRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{4}
+}
+
+type CreateCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryName  string                 `protobuf:"bytes,1,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCategoryRequest) Reset() {
+	*x = CreateCategoryRequest{}
+	mi := &file_northwind_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCategoryRequest) ProtoMessage() {}
+
+func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCategoryRequest.ProtoReflect.Descriptor instead.
+func (*CreateCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateCategoryRequest) GetCategoryName() string {
+	if x != nil {
+		return x.CategoryName
+	}
+	return ""
+}
+
+func (x *CreateCategoryRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type UpdateCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryId    int32                  `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	CategoryName  string                 `protobuf:"bytes,2,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCategoryRequest) Reset() {
+	*x = UpdateCategoryRequest{}
+	mi := &file_northwind_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCategoryRequest) ProtoMessage() {}
+
+func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCategoryRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateCategoryRequest) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *UpdateCategoryRequest) GetCategoryName() string {
+	if x != nil {
+		return x.CategoryName
+	}
+	return ""
+}
+
+func (x *UpdateCategoryRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type DeleteCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryId    int32                  `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCategoryRequest) Reset() {
+	*x = DeleteCategoryRequest{}
+	mi := &file_northwind_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCategoryRequest) ProtoMessage() {}
+
+func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCategoryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteCategoryRequest) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+type Product struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProductId       int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName     string                 `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	SupplierId      *int32                 `protobuf:"varint,3,opt,name=supplier_id,json=supplierId,proto3,oneof" json:"supplier_id,omitempty"`
+	CategoryId      *int32                 `protobuf:"varint,4,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	QuantityPerUnit *string                `protobuf:"bytes,5,opt,name=quantity_per_unit,json=quantityPerUnit,proto3,oneof" json:"quantity_per_unit,omitempty"`
+	UnitPrice       *string                `protobuf:"bytes,6,opt,name=unit_price,json=unitPrice,proto3,oneof" json:"unit_price,omitempty"`
+	UnitsInStock    *int32                 `protobuf:"varint,7,opt,name=units_in_stock,json=unitsInStock,proto3,oneof" json:"units_in_stock,omitempty"`
+	UnitsOnOrder    *int32                 `protobuf:"varint,8,opt,name=units_on_order,json=unitsOnOrder,proto3,oneof" json:"units_on_order,omitempty"`
+	ReorderLevel    *int32                 `protobuf:"varint,9,opt,name=reorder_level,json=reorderLevel,proto3,oneof" json:"reorder_level,omitempty"`
+	Discontinued    bool                   `protobuf:"varint,10,opt,name=discontinued,proto3" json:"discontinued,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_northwind_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Product) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Product) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Product) GetSupplierId() int32 {
+	if x != nil && x.SupplierId != nil {
+		return *x.SupplierId
+	}
+	return 0
+}
+
+func (x *Product) GetCategoryId() int32 {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return 0
+}
+
+func (x *Product) GetQuantityPerUnit() string {
+	if x != nil && x.QuantityPerUnit != nil {
+		return *x.QuantityPerUnit
+	}
+	return ""
+}
+
+func (x *Product) GetUnitPrice() string {
+	if x != nil && x.UnitPrice != nil {
+		return *x.UnitPrice
+	}
+	return ""
+}
+
+func (x *Product) GetUnitsInStock() int32 {
+	if x != nil && x.UnitsInStock != nil {
+		return *x.UnitsInStock
+	}
+	return 0
+}
+
+func (x *Product) GetUnitsOnOrder() int32 {
+	if x != nil && x.UnitsOnOrder != nil {
+		return *x.UnitsOnOrder
+	}
+	return 0
+}
+
+func (x *Product) GetReorderLevel() int32 {
+	if x != nil && x.ReorderLevel != nil {
+		return *x.ReorderLevel
+	}
+	return 0
+}
+
+func (x *Product) GetDiscontinued() bool {
+	if x != nil {
+		return x.Discontinued
+	}
+	return false
+}
+
+type ProductPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PriceId       int32                  `protobuf:"varint,1,opt,name=price_id,json=priceId,proto3" json:"price_id,omitempty"`
+	ProductId     int32                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	UnitPrice     string                 `protobuf:"bytes,3,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	EffectiveFrom *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	// Unset while the price applies until further notice
+	EffectiveTo   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=effective_to,json=effectiveTo,proto3" json:"effective_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductPrice) Reset() {
+	*x = ProductPrice{}
+	mi := &file_northwind_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductPrice) ProtoMessage() {}
+
+func (x *ProductPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductPrice.ProtoReflect.Descriptor instead.
+func (*ProductPrice) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProductPrice) GetPriceId() int32 {
+	if x != nil {
+		return x.PriceId
+	}
+	return 0
+}
+
+func (x *ProductPrice) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductPrice) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *ProductPrice) GetEffectiveFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+func (x *ProductPrice) GetEffectiveTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveTo
+	}
+	return nil
+}
+
+type PriceChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	OldPrice      string                 `protobuf:"bytes,2,opt,name=old_price,json=oldPrice,proto3" json:"old_price,omitempty"`
+	NewPrice      string                 `protobuf:"bytes,3,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
+	EffectiveFrom *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceChange) Reset() {
+	*x = PriceChange{}
+	mi := &file_northwind_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceChange) ProtoMessage() {}
+
+func (x *PriceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceChange.ProtoReflect.Descriptor instead.
+func (*PriceChange) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PriceChange) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *PriceChange) GetOldPrice() string {
+	if x != nil {
+		return x.OldPrice
+	}
+	return ""
+}
+
+func (x *PriceChange) GetNewPrice() string {
+	if x != nil {
+		return x.NewPrice
+	}
+	return ""
+}
+
+func (x *PriceChange) GetEffectiveFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+type GetProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_northwind_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetProductRequest) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+// A page of rows in key order. A page_size of zero is the default of 20 and
+// the largest is 100. The same holds for the other list requests.
+type ListProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_northwind_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListProductsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_northwind_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type StreamProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamProductsRequest) Reset() {
+	*x = StreamProductsRequest{}
+	mi := &file_northwind_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamProductsRequest) ProtoMessage() {}
+
+func (x *StreamProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamProductsRequest.ProtoReflect.Descriptor instead.
+func (*StreamProductsRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{14}
+}
+
+type ListProductPricesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductPricesRequest) Reset() {
+	*x = ListProductPricesRequest{}
+	mi := &file_northwind_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductPricesRequest) ProtoMessage() {}
+
+func (x *ListProductPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductPricesRequest.ProtoReflect.Descriptor instead.
+func (*ListProductPricesRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListProductPricesRequest) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+type ListProductPricesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prices        []*ProductPrice        `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductPricesResponse) Reset() {
+	*x = ListProductPricesResponse{}
+	mi := &file_northwind_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductPricesResponse) ProtoMessage() {}
+
+func (x *ListProductPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductPricesResponse.ProtoReflect.Descriptor instead.
+func (*ListProductPricesResponse) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListProductPricesResponse) GetPrices() []*ProductPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type ScheduleProductPriceRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	UnitPrice string                 `protobuf:"bytes,2,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	// Unset for now
+	EffectiveFrom *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleProductPriceRequest) Reset() {
+	*x = ScheduleProductPriceRequest{}
+	mi := &file_northwind_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleProductPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleProductPriceRequest) ProtoMessage() {}
+
+func (x *ScheduleProductPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleProductPriceRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleProductPriceRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ScheduleProductPriceRequest) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ScheduleProductPriceRequest) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *ScheduleProductPriceRequest) GetEffectiveFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+// A customer. A customer without a currency of their own has the base currency.
+type Customer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CompanyName   string                 `protobuf:"bytes,2,opt,name=company_name,json=companyName,proto3" json:"company_name,omitempty"`
+	ContactName   *string                `protobuf:"bytes,3,opt,name=contact_name,json=contactName,proto3,oneof" json:"contact_name,omitempty"`
+	Address       *string                `protobuf:"bytes,4,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	City          *string                `protobuf:"bytes,5,opt,name=city,proto3,oneof" json:"city,omitempty"`
+	Region        *string                `protobuf:"bytes,6,opt,name=region,proto3,oneof" json:"region,omitempty"`
+	PostalCode    *string                `protobuf:"bytes,7,opt,name=postal_code,json=postalCode,proto3,oneof" json:"postal_code,omitempty"`
+	Country       *string                `protobuf:"bytes,8,opt,name=country,proto3,oneof" json:"country,omitempty"`
+	Phone         *string                `protobuf:"bytes,9,opt,name=phone,proto3,oneof" json:"phone,omitempty"`
+	Currency      string                 `protobuf:"bytes,10,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Customer) Reset() {
+	*x = Customer{}
+	mi := &file_northwind_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Customer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Customer) ProtoMessage() {}
+
+func (x *Customer) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Customer.ProtoReflect.Descriptor instead.
+func (*Customer) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Customer) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Customer) GetCompanyName() string {
+	if x != nil {
+		return x.CompanyName
+	}
+	return ""
+}
+
+func (x *Customer) GetContactName() string {
+	if x != nil && x.ContactName != nil {
+		return *x.ContactName
+	}
+	return ""
+}
+
+func (x *Customer) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *Customer) GetCity() string {
+	if x != nil && x.City != nil {
+		return *x.City
+	}
+	return ""
+}
+
+func (x *Customer) GetRegion() string {
+	if x != nil && x.Region != nil {
+		return *x.Region
+	}
+	return ""
+}
+
+func (x *Customer) GetPostalCode() string {
+	if x != nil && x.PostalCode != nil {
+		return *x.PostalCode
+	}
+	return ""
+}
+
+func (x *Customer) GetCountry() string {
+	if x != nil && x.Country != nil {
+		return *x.Country
+	}
+	return ""
+}
+
+func (x *Customer) GetPhone() string {
+	if x != nil && x.Phone != nil {
+		return *x.Phone
+	}
+	return ""
+}
+
+func (x *Customer) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetCustomerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerRequest) Reset() {
+	*x = GetCustomerRequest{}
+	mi := &file_northwind_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerRequest) ProtoMessage() {}
+
+func (x *GetCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetCustomerRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+type ListCustomersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomersRequest) Reset() {
+	*x = ListCustomersRequest{}
+	mi := &file_northwind_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomersRequest) ProtoMessage() {}
+
+func (x *ListCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomersRequest.ProtoReflect.Descriptor instead.
+func (*ListCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListCustomersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCustomersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListCustomersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Customers     []*Customer            `protobuf:"bytes,1,rep,name=customers,proto3" json:"customers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomersResponse) Reset() {
+	*x = ListCustomersResponse{}
+	mi := &file_northwind_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomersResponse) ProtoMessage() {}
+
+func (x *ListCustomersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomersResponse.ProtoReflect.Descriptor instead.
+func (*ListCustomersResponse) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListCustomersResponse) GetCustomers() []*Customer {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+type StreamCustomersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamCustomersRequest) Reset() {
+	*x = StreamCustomersRequest{}
+	mi := &file_northwind_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCustomersRequest) ProtoMessage() {}
+
+func (x *StreamCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCustomersRequest.ProtoReflect.Descriptor instead.
+func (*StreamCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{22}
+}
+
+type ListCustomerOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomerOrdersRequest) Reset() {
+	*x = ListCustomerOrdersRequest{}
+	mi := &file_northwind_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomerOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomerOrdersRequest) ProtoMessage() {}
+
+func (x *ListCustomerOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomerOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListCustomerOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListCustomerOrdersRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *ListCustomerOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCustomerOrdersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type StreamCustomerOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamCustomerOrdersRequest) Reset() {
+	*x = StreamCustomerOrdersRequest{}
+	mi := &file_northwind_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamCustomerOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCustomerOrdersRequest) ProtoMessage() {}
+
+func (x *StreamCustomerOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCustomerOrdersRequest.ProtoReflect.Descriptor instead.
+func (*StreamCustomerOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *StreamCustomerOrdersRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+// An order. Orders in lists and streams have no lines; GetOrder and
+// CreateOrder return them.
+type Order struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        int32                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CustomerId     *string                `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3,oneof" json:"customer_id,omitempty"`
+	EmployeeId     *int32                 `protobuf:"varint,3,opt,name=employee_id,json=employeeId,proto3,oneof" json:"employee_id,omitempty"`
+	OrderDate      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=order_date,json=orderDate,proto3" json:"order_date,omitempty"`
+	RequiredDate   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=required_date,json=requiredDate,proto3" json:"required_date,omitempty"`
+	ShippedDate    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=shipped_date,json=shippedDate,proto3" json:"shipped_date,omitempty"`
+	ShipVia        *int32                 `protobuf:"varint,7,opt,name=ship_via,json=shipVia,proto3,oneof" json:"ship_via,omitempty"`
+	Freight        *string                `protobuf:"bytes,8,opt,name=freight,proto3,oneof" json:"freight,omitempty"`
+	ShipName       *string                `protobuf:"bytes,9,opt,name=ship_name,json=shipName,proto3,oneof" json:"ship_name,omitempty"`
+	ShipAddress    *string                `protobuf:"bytes,10,opt,name=ship_address,json=shipAddress,proto3,oneof" json:"ship_address,omitempty"`
+	Region         *string                `protobuf:"bytes,11,opt,name=region,proto3,oneof" json:"region,omitempty"`
+	ShipCity       *string                `protobuf:"bytes,12,opt,name=ship_city,json=shipCity,proto3,oneof" json:"ship_city,omitempty"`
+	ShipPostalCode *string                `protobuf:"bytes,13,opt,name=ship_postal_code,json=shipPostalCode,proto3,oneof" json:"ship_postal_code,omitempty"`
+	ShipCountry    *string                `protobuf:"bytes,14,opt,name=ship_country,json=shipCountry,proto3,oneof" json:"ship_country,omitempty"`
+	Currency       string                 `protobuf:"bytes,15,opt,name=currency,proto3" json:"currency,omitempty"`
+	ExchangeRate   string                 `protobuf:"bytes,16,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	Lines          []*OrderLine           `protobuf:"bytes,17,rep,name=lines,proto3" json:"lines,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_northwind_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Order) GetOrderId() int32 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Order) GetCustomerId() string {
+	if x != nil && x.CustomerId != nil {
+		return *x.CustomerId
+	}
+	return ""
+}
+
+func (x *Order) GetEmployeeId() int32 {
+	if x != nil && x.EmployeeId != nil {
+		return *x.EmployeeId
+	}
+	return 0
+}
+
+func (x *Order) GetOrderDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OrderDate
+	}
+	return nil
+}
+
+func (x *Order) GetRequiredDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RequiredDate
+	}
+	return nil
+}
+
+func (x *Order) GetShippedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ShippedDate
+	}
+	return nil
+}
+
+func (x *Order) GetShipVia() int32 {
+	if x != nil && x.ShipVia != nil {
+		return *x.ShipVia
+	}
+	return 0
+}
+
+func (x *Order) GetFreight() string {
+	if x != nil && x.Freight != nil {
+		return *x.Freight
+	}
+	return ""
+}
+
+func (x *Order) GetShipName() string {
+	if x != nil && x.ShipName != nil {
+		return *x.ShipName
+	}
+	return ""
+}
+
+func (x *Order) GetShipAddress() string {
+	if x != nil && x.ShipAddress != nil {
+		return *x.ShipAddress
+	}
+	return ""
+}
+
+func (x *Order) GetRegion() string {
+	if x != nil && x.Region != nil {
+		return *x.Region
+	}
+	return ""
+}
+
+func (x *Order) GetShipCity() string {
+	if x != nil && x.ShipCity != nil {
+		return *x.ShipCity
+	}
+	return ""
+}
+
+func (x *Order) GetShipPostalCode() string {
+	if x != nil && x.ShipPostalCode != nil {
+		return *x.ShipPostalCode
+	}
+	return ""
+}
+
+func (x *Order) GetShipCountry() string {
+	if x != nil && x.ShipCountry != nil {
+		return *x.ShipCountry
+	}
+	return ""
+}
+
+func (x *Order) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Order) GetExchangeRate() string {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return ""
+}
+
+func (x *Order) GetLines() []*OrderLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+type OrderLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName   string                 `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	UnitPrice     string                 `protobuf:"bytes,3,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Discount      string                 `protobuf:"bytes,5,opt,name=discount,proto3" json:"discount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderLine) Reset() {
+	*x = OrderLine{}
+	mi := &file_northwind_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderLine) ProtoMessage() {}
+
+func (x *OrderLine) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderLine.ProtoReflect.Descriptor instead.
+func (*OrderLine) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *OrderLine) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *OrderLine) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *OrderLine) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *OrderLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderLine) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int32                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_northwind_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetOrderRequest) GetOrderId() int32 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type ListOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_northwind_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListOrdersResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Orders []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	// The number of orders on every page
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_northwind_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListOrdersResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type StreamOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOrdersRequest) Reset() {
+	*x = StreamOrdersRequest{}
+	mi := &file_northwind_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOrdersRequest) ProtoMessage() {}
+
+func (x *StreamOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOrdersRequest.ProtoReflect.Descriptor instead.
+func (*StreamOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{30}
+}
+
+// An order to place or quote. Without freight, freight is rated from the
+// shipper's rate table.
+type CreateOrderRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId     string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	EmployeeId     int32                  `protobuf:"varint,2,opt,name=employee_id,json=employeeId,proto3" json:"employee_id,omitempty"`
+	OrderDate      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=order_date,json=orderDate,proto3" json:"order_date,omitempty"`
+	RequiredDate   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=required_date,json=requiredDate,proto3" json:"required_date,omitempty"`
+	ShipVia        int32                  `protobuf:"varint,5,opt,name=ship_via,json=shipVia,proto3" json:"ship_via,omitempty"`
+	Freight        *string                `protobuf:"bytes,6,opt,name=freight,proto3,oneof" json:"freight,omitempty"`
+	ShipName       string                 `protobuf:"bytes,7,opt,name=ship_name,json=shipName,proto3" json:"ship_name,omitempty"`
+	ShipAddress    string                 `protobuf:"bytes,8,opt,name=ship_address,json=shipAddress,proto3" json:"ship_address,omitempty"`
+	Region         string                 `protobuf:"bytes,9,opt,name=region,proto3" json:"region,omitempty"`
+	ShipCity       string                 `protobuf:"bytes,10,opt,name=ship_city,json=shipCity,proto3" json:"ship_city,omitempty"`
+	ShipPostalCode string                 `protobuf:"bytes,11,opt,name=ship_postal_code,json=shipPostalCode,proto3" json:"ship_postal_code,omitempty"`
+	ShipCountry    string                 `protobuf:"bytes,12,opt,name=ship_country,json=shipCountry,proto3" json:"ship_country,omitempty"`
+	Lines          []*CreateOrderLine     `protobuf:"bytes,13,rep,name=lines,proto3" json:"lines,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_northwind_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CreateOrderRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetEmployeeId() int32 {
+	if x != nil {
+		return x.EmployeeId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetOrderDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OrderDate
+	}
+	return nil
+}
+
+func (x *CreateOrderRequest) GetRequiredDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RequiredDate
+	}
+	return nil
+}
+
+func (x *CreateOrderRequest) GetShipVia() int32 {
+	if x != nil {
+		return x.ShipVia
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetFreight() string {
+	if x != nil && x.Freight != nil {
+		return *x.Freight
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetShipName() string {
+	if x != nil {
+		return x.ShipName
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetShipAddress() string {
+	if x != nil {
+		return x.ShipAddress
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetShipCity() string {
+	if x != nil {
+		return x.ShipCity
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetShipPostalCode() string {
+	if x != nil {
+		return x.ShipPostalCode
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetShipCountry() string {
+	if x != nil {
+		return x.ShipCountry
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetLines() []*CreateOrderLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+type CreateOrderLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderLine) Reset() {
+	*x = CreateOrderLine{}
+	mi := &file_northwind_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderLine) ProtoMessage() {}
+
+func (x *CreateOrderLine) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderLine.ProtoReflect.Descriptor instead.
+func (*CreateOrderLine) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CreateOrderLine) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *CreateOrderLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type Quote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	ExchangeRate  string                 `protobuf:"bytes,3,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	OrderDate     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=order_date,json=orderDate,proto3" json:"order_date,omitempty"`
+	Lines         []*QuoteLine           `protobuf:"bytes,5,rep,name=lines,proto3" json:"lines,omitempty"`
+	Subtotal      string                 `protobuf:"bytes,6,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount      string                 `protobuf:"bytes,7,opt,name=discount,proto3" json:"discount,omitempty"`
+	ShipVia       int32                  `protobuf:"varint,8,opt,name=ship_via,json=shipVia,proto3" json:"ship_via,omitempty"`
+	FreightRateId int32                  `protobuf:"varint,9,opt,name=freight_rate_id,json=freightRateId,proto3" json:"freight_rate_id,omitempty"`
+	Freight       string                 `protobuf:"bytes,10,opt,name=freight,proto3" json:"freight,omitempty"`
+	Total         string                 `protobuf:"bytes,11,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quote) Reset() {
+	*x = Quote{}
+	mi := &file_northwind_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quote) ProtoMessage() {}
+
+func (x *Quote) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quote.ProtoReflect.Descriptor instead.
+func (*Quote) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Quote) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Quote) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Quote) GetExchangeRate() string {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return ""
+}
+
+func (x *Quote) GetOrderDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OrderDate
+	}
+	return nil
+}
+
+func (x *Quote) GetLines() []*QuoteLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *Quote) GetSubtotal() string {
+	if x != nil {
+		return x.Subtotal
+	}
+	return ""
+}
+
+func (x *Quote) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+func (x *Quote) GetShipVia() int32 {
+	if x != nil {
+		return x.ShipVia
+	}
+	return 0
+}
+
+func (x *Quote) GetFreightRateId() int32 {
+	if x != nil {
+		return x.FreightRateId
+	}
+	return 0
+}
+
+func (x *Quote) GetFreight() string {
+	if x != nil {
+		return x.Freight
+	}
+	return ""
+}
+
+func (x *Quote) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+type QuoteLine struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProductId   int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName string                 `protobuf:"bytes,2,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	CategoryId  int32                  `protobuf:"varint,3,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	UnitPrice   string                 `protobuf:"bytes,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Quantity    int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Discount    string                 `protobuf:"bytes,6,opt,name=discount,proto3" json:"discount,omitempty"`
+	// Zero when no promotion applied
+	PromotionId   int32  `protobuf:"varint,7,opt,name=promotion_id,json=promotionId,proto3" json:"promotion_id,omitempty"`
+	LineTotal     string `protobuf:"bytes,8,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteLine) Reset() {
+	*x = QuoteLine{}
+	mi := &file_northwind_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteLine) ProtoMessage() {}
+
+func (x *QuoteLine) ProtoReflect() protoreflect.Message {
+	mi := &file_northwind_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteLine.ProtoReflect.Descriptor instead.
+func (*QuoteLine) Descriptor() ([]byte, []int) {
+	return file_northwind_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *QuoteLine) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *QuoteLine) GetCategoryId() int32 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *QuoteLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+func (x *QuoteLine) GetPromotionId() int32 {
+	if x != nil {
+		return x.PromotionId
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetLineTotal() string {
+	if x != nil {
+		return x.LineTotal
+	}
+	return ""
+}
+
+var File_northwind_proto protoreflect.FileDescriptor
+
+const file_northwind_proto_rawDesc = "" +
+	"\n" +
+	"\x0fnorthwind.proto\x12\fnorthwind.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x87\x01\n" +
+	"\bCategory\x12\x1f\n" +
+	"\vcategory_id\x18\x01 \x01(\x05R\n" +
+	"categoryId\x12#\n" +
+	"\rcategory_name\x18\x02 \x01(\tR\fcategoryName\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x00R\vdescription\x88\x01\x01B\x0e\n" +
+	"\f_description\"5\n" +
+	"\x12GetCategoryRequest\x12\x1f\n" +
+	"\vcategory_id\x18\x01 \x01(\x05R\n" +
+	"categoryId\"\x17\n" +
+	"\x15ListCategoriesRequest\"P\n" +
+	"\x16ListCategoriesResponse\x126\n" +
+	"\n" +
+	"categories\x18\x01 \x03(\v2\x16.northwind.v1.CategoryR\n" +
+	"categories\"\x19\n" +
+	"\x17StreamCategoriesRequest\"^\n" +
+	"\x15CreateCategoryRequest\x12#\n" +
+	"\rcategory_name\x18\x01 \x01(\tR\fcategoryName\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"\x7f\n" +
+	"\x15UpdateCategoryRequest\x12\x1f\n" +
+	"\vcategory_id\x18\x01 \x01(\x05R\n" +
+	"categoryId\x12#\n" +
+	"\rcategory_name\x18\x02 \x01(\tR\fcategoryName\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\"8\n" +
+	"\x15DeleteCategoryRequest\x12\x1f\n" +
+	"\vcategory_id\x18\x01 \x01(\x05R\n" +
+	"categoryId\"\x8d\x04\n" +
+	"\aProduct\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12$\n" +
+	"\vsupplier_id\x18\x03 \x01(\x05H\x00R\n" +
+	"supplierId\x88\x01\x01\x12$\n" +
+	"\vcategory_id\x18\x04 \x01(\x05H\x01R\n" +
+	"categoryId\x88\x01\x01\x12/\n" +
+	"\x11quantity_per_unit\x18\x05 \x01(\tH\x02R\x0fquantityPerUnit\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"unit_price\x18\x06 \x01(\tH\x03R\tunitPrice\x88\x01\x01\x12)\n" +
+	"\x0eunits_in_stock\x18\a \x01(\x05H\x04R\funitsInStock\x88\x01\x01\x12)\n" +
+	"\x0eunits_on_order\x18\b \x01(\x05H\x05R\funitsOnOrder\x88\x01\x01\x12(\n" +
+	"\rreorder_level\x18\t \x01(\x05H\x06R\freorderLevel\x88\x01\x01\x12\"\n" +
+	"\fdiscontinued\x18\n" +
+	" \x01(\bR\fdiscontinuedB\x0e\n" +
+	"\f_supplier_idB\x0e\n" +
+	"\f_category_idB\x14\n" +
+	"\x12_quantity_per_unitB\r\n" +
+	"\v_unit_priceB\x11\n" +
+	"\x0f_units_in_stockB\x11\n" +
+	"\x0f_units_on_orderB\x10\n" +
+	"\x0e_reorder_level\"\xe9\x01\n" +
+	"\fProductPrice\x12\x19\n" +
+	"\bprice_id\x18\x01 \x01(\x05R\apriceId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x05R\tproductId\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x03 \x01(\tR\tunitPrice\x12A\n" +
+	"\x0eeffective_from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\reffectiveFrom\x12=\n" +
+	"\feffective_to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveTo\"\xa9\x01\n" +
+	"\vPriceChange\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12\x1b\n" +
+	"\told_price\x18\x02 \x01(\tR\boldPrice\x12\x1b\n" +
+	"\tnew_price\x18\x03 \x01(\tR\bnewPrice\x12A\n" +
+	"\x0eeffective_from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\reffectiveFrom\"2\n" +
+	"\x11GetProductRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\"J\n" +
+	"\x13ListProductsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\"I\n" +
+	"\x14ListProductsResponse\x121\n" +
+	"\bproducts\x18\x01 \x03(\v2\x15.northwind.v1.ProductR\bproducts\"\x17\n" +
+	"\x15StreamProductsRequest\"9\n" +
+	"\x18ListProductPricesRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\"O\n" +
+	"\x19ListProductPricesResponse\x122\n" +
+	"\x06prices\x18\x01 \x03(\v2\x1a.northwind.v1.ProductPriceR\x06prices\"\x9e\x01\n" +
+	"\x1bScheduleProductPriceRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x02 \x01(\tR\tunitPrice\x12A\n" +
+	"\x0eeffective_from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\reffectiveFrom\"\x9e\x03\n" +
+	"\bCustomer\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12!\n" +
+	"\fcompany_name\x18\x02 \x01(\tR\vcompanyName\x12&\n" +
+	"\fcontact_name\x18\x03 \x01(\tH\x00R\vcontactName\x88\x01\x01\x12\x1d\n" +
+	"\aaddress\x18\x04 \x01(\tH\x01R\aaddress\x88\x01\x01\x12\x17\n" +
+	"\x04city\x18\x05 \x01(\tH\x02R\x04city\x88\x01\x01\x12\x1b\n" +
+	"\x06region\x18\x06 \x01(\tH\x03R\x06region\x88\x01\x01\x12$\n" +
+	"\vpostal_code\x18\a \x01(\tH\x04R\n" +
+	"postalCode\x88\x01\x01\x12\x1d\n" +
+	"\acountry\x18\b \x01(\tH\x05R\acountry\x88\x01\x01\x12\x19\n" +
+	"\x05phone\x18\t \x01(\tH\x06R\x05phone\x88\x01\x01\x12\x1a\n" +
+	"\bcurrency\x18\n" +
+	" \x01(\tR\bcurrencyB\x0f\n" +
+	"\r_contact_nameB\n" +
+	"\n" +
+	"\b_addressB\a\n" +
+	"\x05_cityB\t\n" +
+	"\a_regionB\x0e\n" +
+	"\f_postal_codeB\n" +
+	"\n" +
+	"\b_countryB\b\n" +
+	"\x06_phone\"5\n" +
+	"\x12GetCustomerRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\"K\n" +
+	"\x14ListCustomersRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\"M\n" +
+	"\x15ListCustomersResponse\x124\n" +
+	"\tcustomers\x18\x01 \x03(\v2\x16.northwind.v1.CustomerR\tcustomers\"\x18\n" +
+	"\x16StreamCustomersRequest\"q\n" +
+	"\x19ListCustomerOrdersRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\">\n" +
+	"\x1bStreamCustomerOrdersRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\"\xcf\x06\n" +
+	"\x05Order\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x05R\aorderId\x12$\n" +
+	"\vcustomer_id\x18\x02 \x01(\tH\x00R\n" +
+	"customerId\x88\x01\x01\x12$\n" +
+	"\vemployee_id\x18\x03 \x01(\x05H\x01R\n" +
+	"employeeId\x88\x01\x01\x129\n" +
+	"\n" +
+	"order_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\torderDate\x12?\n" +
+	"\rrequired_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\frequiredDate\x12=\n" +
+	"\fshipped_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vshippedDate\x12\x1e\n" +
+	"\bship_via\x18\a \x01(\x05H\x02R\ashipVia\x88\x01\x01\x12\x1d\n" +
+	"\afreight\x18\b \x01(\tH\x03R\afreight\x88\x01\x01\x12 \n" +
+	"\tship_name\x18\t \x01(\tH\x04R\bshipName\x88\x01\x01\x12&\n" +
+	"\fship_address\x18\n" +
+	" \x01(\tH\x05R\vshipAddress\x88\x01\x01\x12\x1b\n" +
+	"\x06region\x18\v \x01(\tH\x06R\x06region\x88\x01\x01\x12 \n" +
+	"\tship_city\x18\f \x01(\tH\aR\bshipCity\x88\x01\x01\x12-\n" +
+	"\x10ship_postal_code\x18\r \x01(\tH\bR\x0eshipPostalCode\x88\x01\x01\x12&\n" +
+	"\fship_country\x18\x0e \x01(\tH\tR\vshipCountry\x88\x01\x01\x12\x1a\n" +
+	"\bcurrency\x18\x0f \x01(\tR\bcurrency\x12#\n" +
+	"\rexchange_rate\x18\x10 \x01(\tR\fexchangeRate\x12-\n" +
+	"\x05lines\x18\x11 \x03(\v2\x17.northwind.v1.OrderLineR\x05linesB\x0e\n" +
+	"\f_customer_idB\x0e\n" +
+	"\f_employee_idB\v\n" +
+	"\t_ship_viaB\n" +
+	"\n" +
+	"\b_freightB\f\n" +
+	"\n" +
+	"_ship_nameB\x0f\n" +
+	"\r_ship_addressB\t\n" +
+	"\a_regionB\f\n" +
+	"\n" +
+	"_ship_cityB\x13\n" +
+	"\x11_ship_postal_codeB\x0f\n" +
+	"\r_ship_country\"\xa4\x01\n" +
+	"\tOrderLine\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x03 \x01(\tR\tunitPrice\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x1a\n" +
+	"\bdiscount\x18\x05 \x01(\tR\bdiscount\",\n" +
+	"\x0fGetOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x05R\aorderId\"H\n" +
+	"\x11ListOrdersRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\"W\n" +
+	"\x12ListOrdersResponse\x12+\n" +
+	"\x06orders\x18\x01 \x03(\v2\x13.northwind.v1.OrderR\x06orders\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\x15\n" +
+	"\x13StreamOrdersRequest\"\x8f\x04\n" +
+	"\x12CreateOrderRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x1f\n" +
+	"\vemployee_id\x18\x02 \x01(\x05R\n" +
+	"employeeId\x129\n" +
+	"\n" +
+	"order_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\torderDate\x12?\n" +
+	"\rrequired_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\frequiredDate\x12\x19\n" +
+	"\bship_via\x18\x05 \x01(\x05R\ashipVia\x12\x1d\n" +
+	"\afreight\x18\x06 \x01(\tH\x00R\afreight\x88\x01\x01\x12\x1b\n" +
+	"\tship_name\x18\a \x01(\tR\bshipName\x12!\n" +
+	"\fship_address\x18\b \x01(\tR\vshipAddress\x12\x16\n" +
+	"\x06region\x18\t \x01(\tR\x06region\x12\x1b\n" +
+	"\tship_city\x18\n" +
+	" \x01(\tR\bshipCity\x12(\n" +
+	"\x10ship_postal_code\x18\v \x01(\tR\x0eshipPostalCode\x12!\n" +
+	"\fship_country\x18\f \x01(\tR\vshipCountry\x123\n" +
+	"\x05lines\x18\r \x03(\v2\x1d.northwind.v1.CreateOrderLineR\x05linesB\n" +
+	"\n" +
+	"\b_freight\"L\n" +
+	"\x0fCreateOrderLine\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xfe\x02\n" +
+	"\x05Quote\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12#\n" +
+	"\rexchange_rate\x18\x03 \x01(\tR\fexchangeRate\x129\n" +
+	"\n" +
+	"order_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\torderDate\x12-\n" +
+	"\x05lines\x18\x05 \x03(\v2\x17.northwind.v1.QuoteLineR\x05lines\x12\x1a\n" +
+	"\bsubtotal\x18\x06 \x01(\tR\bsubtotal\x12\x1a\n" +
+	"\bdiscount\x18\a \x01(\tR\bdiscount\x12\x19\n" +
+	"\bship_via\x18\b \x01(\x05R\ashipVia\x12&\n" +
+	"\x0ffreight_rate_id\x18\t \x01(\x05R\rfreightRateId\x12\x18\n" +
+	"\afreight\x18\n" +
+	" \x01(\tR\afreight\x12\x14\n" +
+	"\x05total\x18\v \x01(\tR\x05total\"\x87\x02\n" +
+	"\tQuoteLine\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x05R\tproductId\x12!\n" +
+	"\fproduct_name\x18\x02 \x01(\tR\vproductName\x12\x1f\n" +
+	"\vcategory_id\x18\x03 \x01(\x05R\n" +
+	"categoryId\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x04 \x01(\tR\tunitPrice\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x1a\n" +
+	"\bdiscount\x18\x06 \x01(\tR\bdiscount\x12!\n" +
+	"\fpromotion_id\x18\a \x01(\x05R\vpromotionId\x12\x1d\n" +
+	"\n" +
+	"line_total\x18\b \x01(\tR\tlineTotal2\xf9\x03\n" +
+	"\x0fCategoryService\x12G\n" +
+	"\vGetCategory\x12 .northwind.v1.GetCategoryRequest\x1a\x16.northwind.v1.Category\x12[\n" +
+	"\x0eListCategories\x12#.northwind.v1.ListCategoriesRequest\x1a$.northwind.v1.ListCategoriesResponse\x12S\n" +
+	"\x10StreamCategories\x12%.northwind.v1.StreamCategoriesRequest\x1a\x16.northwind.v1.Category0\x01\x12M\n" +
+	"\x0eCreateCategory\x12#.northwind.v1.CreateCategoryRequest\x1a\x16.northwind.v1.Category\x12M\n" +
+	"\x0eUpdateCategory\x12#.northwind.v1.UpdateCategoryRequest\x1a\x16.northwind.v1.Category\x12M\n" +
+	"\x0eDeleteCategory\x12#.northwind.v1.DeleteCategoryRequest\x1a\x16.google.protobuf.Empty2\xc1\x03\n" +
+	"\x0eProductService\x12D\n" +
+	"\n" +
+	"GetProduct\x12\x1f.northwind.v1.GetProductRequest\x1a\x15.northwind.v1.Product\x12U\n" +
+	"\fListProducts\x12!.northwind.v1.ListProductsRequest\x1a\".northwind.v1.ListProductsResponse\x12N\n" +
+	"\x0eStreamProducts\x12#.northwind.v1.StreamProductsRequest\x1a\x15.northwind.v1.Product0\x01\x12d\n" +
+	"\x11ListProductPrices\x12&.northwind.v1.ListProductPricesRequest\x1a'.northwind.v1.ListProductPricesResponse\x12\\\n" +
+	"\x14ScheduleProductPrice\x12).northwind.v1.ScheduleProductPriceRequest\x1a\x19.northwind.v1.PriceChange2\xc2\x03\n" +
+	"\x0fCustomerService\x12G\n" +
+	"\vGetCustomer\x12 .northwind.v1.GetCustomerRequest\x1a\x16.northwind.v1.Customer\x12X\n" +
+	"\rListCustomers\x12\".northwind.v1.ListCustomersRequest\x1a#.northwind.v1.ListCustomersResponse\x12Q\n" +
+	"\x0fStreamCustomers\x12$.northwind.v1.StreamCustomersRequest\x1a\x16.northwind.v1.Customer0\x01\x12_\n" +
+	"\x12ListCustomerOrders\x12'.northwind.v1.ListCustomerOrdersRequest\x1a .northwind.v1.ListOrdersResponse\x12X\n" +
+	"\x14StreamCustomerOrders\x12).northwind.v1.StreamCustomerOrdersRequest\x1a\x13.northwind.v1.Order0\x012\xf4\x02\n" +
+	"\fOrderService\x12>\n" +
+	"\bGetOrder\x12\x1d.northwind.v1.GetOrderRequest\x1a\x13.northwind.v1.Order\x12O\n" +
+	"\n" +
+	"ListOrders\x12\x1f.northwind.v1.ListOrdersRequest\x1a .northwind.v1.ListOrdersResponse\x12H\n" +
+	"\fStreamOrders\x12!.northwind.v1.StreamOrdersRequest\x1a\x13.northwind.v1.Order0\x01\x12D\n" +
+	"\vCreateOrder\x12 .northwind.v1.CreateOrderRequest\x1a\x13.northwind.v1.Order\x12C\n" +
+	"\n" +
+	"QuoteOrder\x12 .northwind.v1.CreateOrderRequest\x1a\x13.northwind.v1.QuoteB(Z&northwind-api/internal/rpc/northwindpbb\x06proto3"
+
+var (
+	file_northwind_proto_rawDescOnce sync.Once
+	file_northwind_proto_rawDescData []byte
+)
+
+func file_northwind_proto_rawDescGZIP() []byte {
+	file_northwind_proto_rawDescOnce.Do(func() {
+		file_northwind_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_northwind_proto_rawDesc), len(file_northwind_proto_rawDesc)))
+	})
+	return file_northwind_proto_rawDescData
+}
+
+var file_northwind_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_northwind_proto_goTypes = []any{
+	(*Category)(nil),                    // 0: northwind.v1.Category
+	(*GetCategoryRequest)(nil),          // 1: northwind.v1.GetCategoryRequest
+	(*ListCategoriesRequest)(nil),       // 2: northwind.v1.ListCategoriesRequest
+	(*ListCategoriesResponse)(nil),      // 3: northwind.v1.ListCategoriesResponse
+	(*StreamCategoriesRequest)(nil),     // 4: northwind.v1.StreamCategoriesRequest
+	(*CreateCategoryRequest)(nil),       // 5: northwind.v1.CreateCategoryRequest
+	(*UpdateCategoryRequest)(nil),       // 6: northwind.v1.UpdateCategoryRequest
+	(*DeleteCategoryRequest)(nil),       // 7: northwind.v1.DeleteCategoryRequest
+	(*Product)(nil),                     // 8: northwind.v1.Product
+	(*ProductPrice)(nil),                // 9: northwind.v1.ProductPrice
+	(*PriceChange)(nil),                 // 10: northwind.v1.PriceChange
+	(*GetProductRequest)(nil),           // 11: northwind.v1.GetProductRequest
+	(*ListProductsRequest)(nil),         // 12: northwind.v1.ListProductsRequest
+	(*ListProductsResponse)(nil),        // 13: northwind.v1.ListProductsResponse
+	(*StreamProductsRequest)(nil),       // 14: northwind.v1.StreamProductsRequest
+	(*ListProductPricesRequest)(nil),    // 15: northwind.v1.ListProductPricesRequest
+	(*ListProductPricesResponse)(nil),   // 16: northwind.v1.ListProductPricesResponse
+	(*ScheduleProductPriceRequest)(nil), // 17: northwind.v1.ScheduleProductPriceRequest
+	(*Customer)(nil),                    // 18: northwind.v1.Customer
+	(*GetCustomerRequest)(nil),          // 19: northwind.v1.GetCustomerRequest
+	(*ListCustomersRequest)(nil),        // 20: northwind.v1.ListCustomersRequest
+	(*ListCustomersResponse)(nil),       // 21: northwind.v1.ListCustomersResponse
+	(*StreamCustomersRequest)(nil),      // 22: northwind.v1.StreamCustomersRequest
+	(*ListCustomerOrdersRequest)(nil),   // 23: northwind.v1.ListCustomerOrdersRequest
+	(*StreamCustomerOrdersRequest)(nil), // 24: northwind.v1.StreamCustomerOrdersRequest
+	(*Order)(nil),                       // 25: northwind.v1.Order
+	(*OrderLine)(nil),                   // 26: northwind.v1.OrderLine
+	(*GetOrderRequest)(nil),             // 27: northwind.v1.GetOrderRequest
+	(*ListOrdersRequest)(nil),           // 28: northwind.v1.ListOrdersRequest
+	(*ListOrdersResponse)(nil),          // 29: northwind.v1.ListOrdersResponse
+	(*StreamOrdersRequest)(nil),         // 30: northwind.v1.StreamOrdersRequest
+	(*CreateOrderRequest)(nil),          // 31: northwind.v1.CreateOrderRequest
+	(*CreateOrderLine)(nil),             // 32: northwind.v1.CreateOrderLine
+	(*Quote)(nil),                       // 33: northwind.v1.Quote
+	(*QuoteLine)(nil),                   // 34: northwind.v1.QuoteLine
+	(*timestamppb.Timestamp)(nil),       // 35: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),               // 36: google.protobuf.Empty
+}
+var file_northwind_proto_depIdxs = []int32{
+	0,  // 0: northwind.v1.ListCategoriesResponse.categories:type_name -> northwind.v1.Category
+	35, // 1: northwind.v1.ProductPrice.effective_from:type_name -> google.protobuf.Timestamp
+	35, // 2: northwind.v1.ProductPrice.effective_to:type_name -> google.protobuf.Timestamp
+	35, // 3: northwind.v1.PriceChange.effective_from:type_name -> google.protobuf.Timestamp
+	8,  // 4: northwind.v1.ListProductsResponse.products:type_name -> northwind.v1.Product
+	9,  // 5: northwind.v1.ListProductPricesResponse.prices:type_name -> northwind.v1.ProductPrice
+	35, // 6: northwind.v1.ScheduleProductPriceRequest.effective_from:type_name -> google.protobuf.Timestamp
+	18, // 7: northwind.v1.ListCustomersResponse.customers:type_name -> northwind.v1.Customer
+	35, // 8: northwind.v1.Order.order_date:type_name -> google.protobuf.Timestamp
+	35, // 9: northwind.v1.Order.required_date:type_name -> google.protobuf.Timestamp
+	35, // 10: northwind.v1.Order.shipped_date:type_name -> google.protobuf.Timestamp
+	26, // 11: northwind.v1.Order.lines:type_name -> northwind.v1.OrderLine
+	25, // 12: northwind.v1.ListOrdersResponse.orders:type_name -> northwind.v1.Order
+	35, // 13: northwind.v1.CreateOrderRequest.order_date:type_name -> google.protobuf.Timestamp
+	35, // 14: northwind.v1.CreateOrderRequest.required_date:type_name -> google.protobuf.Timestamp
+	32, // 15: northwind.v1.CreateOrderRequest.lines:type_name -> northwind.v1.CreateOrderLine
+	35, // 16: northwind.v1.Quote.order_date:type_name -> google.protobuf.Timestamp
+	34, // 17: northwind.v1.Quote.lines:type_name -> northwind.v1.QuoteLine
+	1,  // 18: northwind.v1.CategoryService.GetCategory:input_type -> northwind.v1.GetCategoryRequest
+	2,  // 19: northwind.v1.CategoryService.ListCategories:input_type -> northwind.v1.ListCategoriesRequest
+	4,  // 20: northwind.v1.CategoryService.StreamCategories:input_type -> northwind.v1.StreamCategoriesRequest
+	5,  // 21: northwind.v1.CategoryService.CreateCategory:input_type -> northwind.v1.CreateCategoryRequest
+	6,  // 22: northwind.v1.CategoryService.UpdateCategory:input_type -> northwind.v1.UpdateCategoryRequest
+	7,  // 23: northwind.v1.CategoryService.DeleteCategory:input_type -> northwind.v1.DeleteCategoryRequest
+	11, // 24: northwind.v1.ProductService.GetProduct:input_type -> northwind.v1.GetProductRequest
+	12, // 25: northwind.v1.ProductService.ListProducts:input_type -> northwind.v1.ListProductsRequest
+	14, // 26: northwind.v1.ProductService.StreamProducts:input_type -> northwind.v1.StreamProductsRequest
+	15, // 27: northwind.v1.ProductService.ListProductPrices:input_type -> northwind.v1.ListProductPricesRequest
+	17, // 28: northwind.v1.ProductService.ScheduleProductPrice:input_type -> northwind.v1.ScheduleProductPriceRequest
+	19, // 29: northwind.v1.CustomerService.GetCustomer:input_type -> northwind.v1.GetCustomerRequest
+	20, // 30: northwind.v1.CustomerService.ListCustomers:input_type -> northwind.v1.ListCustomersRequest
+	22, // 31: northwind.v1.CustomerService.StreamCustomers:input_type -> northwind.v1.StreamCustomersRequest
+	23, // 32: northwind.v1.CustomerService.ListCustomerOrders:input_type -> northwind.v1.ListCustomerOrdersRequest
+	24, // 33: northwind.v1.CustomerService.StreamCustomerOrders:input_type -> northwind.v1.StreamCustomerOrdersRequest
+	27, // 34: northwind.v1.OrderService.GetOrder:input_type -> northwind.v1.GetOrderRequest
+	28, // 35: northwind.v1.OrderService.ListOrders:input_type -> northwind.v1.ListOrdersRequest
+	30, // 36: northwind.v1.OrderService.StreamOrders:input_type -> northwind.v1.StreamOrdersRequest
+	31, // 37: northwind.v1.OrderService.CreateOrder:input_type -> northwind.v1.CreateOrderRequest
+	31, // 38: northwind.v1.OrderService.QuoteOrder:input_type -> northwind.v1.CreateOrderRequest
+	0,  // 39: northwind.v1.CategoryService.GetCategory:output_type -> northwind.v1.Category
+	3,  // 40: northwind.v1.CategoryService.ListCategories:output_type -> northwind.v1.ListCategoriesResponse
+	0,  // 41: northwind.v1.CategoryService.StreamCategories:output_type -> northwind.v1.Category
+	0,  // 42: northwind.v1.CategoryService.CreateCategory:output_type -> northwind.v1.Category
+	0,  // 43: northwind.v1.CategoryService.UpdateCategory:output_type -> northwind.v1.Category
+	36, // 44: northwind.v1.CategoryService.DeleteCategory:output_type -> google.protobuf.Empty
+	8,  // 45: northwind.v1.ProductService.GetProduct:output_type -> northwind.v1.Product
+	13, // 46: northwind.v1.ProductService.ListProducts:output_type -> northwind.v1.ListProductsResponse
+	8,  // 47: northwind.v1.ProductService.StreamProducts:output_type -> northwind.v1.Product
+	16, // 48: northwind.v1.ProductService.ListProductPrices:output_type -> northwind.v1.ListProductPricesResponse
+	10, // 49: northwind.v1.ProductService.ScheduleProductPrice:output_type -> northwind.v1.PriceChange
+	18, // 50: northwind.v1.CustomerService.GetCustomer:output_type -> northwind.v1.Customer
+	21, // 51: northwind.v1.CustomerService.ListCustomers:output_type -> northwind.v1.ListCustomersResponse
+	18, // 52: northwind.v1.CustomerService.StreamCustomers:output_type -> northwind.v1.Customer
+	29, // 53: northwind.v1.CustomerService.ListCustomerOrders:output_type -> northwind.v1.ListOrdersResponse
+	25, // 54: northwind.v1.CustomerService.StreamCustomerOrders:output_type -> northwind.v1.Order
+	25, // 55: northwind.v1.OrderService.GetOrder:output_type -> northwind.v1.Order
+	29, // 56: northwind.v1.OrderService.ListOrders:output_type -> northwind.v1.ListOrdersResponse
+	25, // 57: northwind.v1.OrderService.StreamOrders:output_type -> northwind.v1.Order
+	25, // 58: northwind.v1.OrderService.CreateOrder:output_type -> northwind.v1.Order
+	33, // 59: northwind.v1.OrderService.QuoteOrder:output_type -> northwind.v1.Quote
+	39, // [39:60] is the sub-list for method output_type
+	18, // [18:39] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_northwind_proto_init() }
+func file_northwind_proto_init() {
+	if File_northwind_proto != nil {
+		return
+	}
+	file_northwind_proto_msgTypes[0].OneofWrappers = []any{}
+	file_northwind_proto_msgTypes[8].OneofWrappers = []any{}
+	file_northwind_proto_msgTypes[18].OneofWrappers = []any{}
+	file_northwind_proto_msgTypes[25].OneofWrappers = []any{}
+	file_northwind_proto_msgTypes[31].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_northwind_proto_rawDesc), len(file_northwind_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   35,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_northwind_proto_goTypes,
+		DependencyIndexes: file_northwind_proto_depIdxs,
+		MessageInfos:      file_northwind_proto_msgTypes,
+	}.Build()
+	File_northwind_proto = out.File
+	file_northwind_proto_goTypes = nil
+	file_northwind_proto_depIdxs = nil
+}
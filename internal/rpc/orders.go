@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"context"
+	"northwind-api/internal/model"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+	"northwind-api/internal/validate"
+	"time"
+)
+
+// orderService implements pb.OrderServiceServer
+type orderService struct {
+	pb.UnimplementedOrderServiceServer
+	db *repository.DB
+}
+
+// The fields of an order being placed or quoted, validated like the body of
+// POST /api/orders
+type orderFields struct {
+	CustomerId     string       `json:"customer_id" validate:"required,max=5"`
+	EmployeeId     int          `json:"employee_id" validate:"min=0"`
+	OrderDate      time.Time    `json:"order_date"`
+	RequiredDate   time.Time    `json:"required_date" validate:"gtefield=order_date"`
+	ShipVia        int          `json:"ship_via" validate:"min=0"`
+	Freight        *money.Money `json:"freight" validate:"min=0"`
+	ShipName       string       `json:"ship_name" validate:"max=40"`
+	ShipAddress    string       `json:"ship_address" validate:"max=60"`
+	Region         string       `json:"region" validate:"max=60"`
+	ShipCity       string       `json:"ship_city" validate:"max=15"`
+	ShipPostalCode string       `json:"ship_postal_code" validate:"max=10"`
+	ShipCountry    string       `json:"ship_country" validate:"max=15,country"`
+	Lines          []orderLine  `json:"lines" validate:"required"`
+}
+
+// A line of an order being placed or quoted
+type orderLine struct {
+	ProductId int `json:"product_id" validate:"gt=0"`
+	Quantity  int `json:"quantity" validate:"min=1,max=32767"`
+}
+
+// orderFieldsOf checks an order request and converts it into the order
+// header and lines the repository expects. autoFreight is true when the
+// request leaves freight to be rated.
+func orderFieldsOf(req *pb.CreateOrderRequest) (order model.Orders, lines []model.OrderDetails, autoFreight bool, err error) {
+	fields := orderFields{
+		CustomerId:     req.CustomerId,
+		EmployeeId:     int(req.EmployeeId),
+		ShipVia:        int(req.ShipVia),
+		ShipName:       req.ShipName,
+		ShipAddress:    req.ShipAddress,
+		Region:         req.Region,
+		ShipCity:       req.ShipCity,
+		ShipPostalCode: req.ShipPostalCode,
+		ShipCountry:    req.ShipCountry,
+	}
+	if req.OrderDate != nil {
+		fields.OrderDate = req.OrderDate.AsTime()
+	}
+	if req.RequiredDate != nil {
+		fields.RequiredDate = req.RequiredDate.AsTime()
+	}
+	if req.Freight != nil {
+		freight, err := parseMoney("freight", *req.Freight)
+		if err != nil {
+			return order, nil, false, err
+		}
+		fields.Freight = &freight
+	}
+	for _, line := range req.Lines {
+		fields.Lines = append(fields.Lines, orderLine{ProductId: int(line.ProductId), Quantity: int(line.Quantity)})
+	}
+
+	if errs := validate.Struct(&fields); len(errs) > 0 {
+		return order, nil, false, invalid(errs)
+	}
+
+	order = model.Orders{
+		CustomerId:     model.NullIfZero(fields.CustomerId),
+		EmployeeId:     model.NullIfZero(fields.EmployeeId),
+		OrderDate:      model.NullIfZero(fields.OrderDate),
+		RequiredDate:   model.NullIfZero(fields.RequiredDate),
+		ShipVia:        model.NullIfZero(fields.ShipVia),
+		Freight:        model.NullFromPtr(fields.Freight),
+		ShipName:       model.NullIfZero(fields.ShipName),
+		ShipAddress:    model.NullIfZero(fields.ShipAddress),
+		Region:         model.NullIfZero(fields.Region),
+		ShipCity:       model.NullIfZero(fields.ShipCity),
+		ShipPostalCode: model.NullIfZero(fields.ShipPostalCode),
+		ShipCountry:    model.NullIfZero(fields.ShipCountry),
+	}
+
+	lines = make([]model.OrderDetails, 0, len(fields.Lines))
+	for _, line := range fields.Lines {
+		lines = append(lines, model.OrderDetails{ProductId: line.ProductId, Quantity: line.Quantity})
+	}
+
+	return order, lines, fields.Freight == nil, nil
+}
+
+// orderWithLines gets an order with its lines
+func (s *orderService) orderWithLines(id int, action string) (*pb.Order, error) {
+	order, err := s.db.GetOrderById(id)
+	if err != nil {
+		return nil, statusOf(err, action)
+	}
+	lines, err := s.db.GetOrderLines(id)
+	if err != nil {
+		return nil, statusOf(err, action)
+	}
+
+	message := orderOf(order)
+	message.Lines = convertAll(lines, orderLineOf)
+	return message, nil
+}
+
+func (s *orderService) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	return s.orderWithLines(int(req.OrderId), "get the order")
+}
+
+func (s *orderService) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	limit, offset, err := pageOf(req.PageSize, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, total, err := s.db.GetOrders(limit, offset)
+	if err != nil {
+		return nil, statusOf(err, "get orders")
+	}
+	return &pb.ListOrdersResponse{Orders: convertAll(orders, orderOf), Total: int32(total)}, nil
+}
+
+func (s *orderService) StreamOrders(req *pb.StreamOrdersRequest, stream pb.OrderService_StreamOrdersServer) error {
+	cursor, err := s.db.StreamOrders()
+	return streamCursor(cursor, err, stream.Send, orderOf, "stream orders")
+}
+
+func (s *orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	order, lines, autoFreight, err := orderFieldsOf(req)
+	if err != nil {
+		return nil, err
+	}
+
+	orderId, err := s.db.CreateOrder(order, lines, autoFreight)
+	if err != nil {
+		return nil, statusOf(err, "create the order")
+	}
+
+	return s.orderWithLines(orderId, "get the created order")
+}
+
+func (s *orderService) QuoteOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Quote, error) {
+	order, lines, autoFreight, err := orderFieldsOf(req)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.db.QuoteOrder(order, lines, autoFreight)
+	if err != nil {
+		return nil, statusOf(err, "quote the order")
+	}
+	return quoteOf(quote), nil
+}
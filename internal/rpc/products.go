@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+	"northwind-api/internal/money"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+	"northwind-api/internal/validate"
+	"time"
+)
+
+// productService implements pb.ProductServiceServer
+type productService struct {
+	pb.UnimplementedProductServiceServer
+	db *repository.DB
+}
+
+// The fields of a scheduled price, validated like the body of POST
+// /api/products/{productId}/prices
+type priceFields struct {
+	UnitPrice money.Money `json:"unit_price" validate:"min=0"`
+}
+
+func (s *productService) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	product, err := s.db.GetProductById(int(req.ProductId))
+	if err != nil {
+		return nil, statusOf(err, "get the product")
+	}
+	return productOf(product), nil
+}
+
+func (s *productService) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	limit, offset, err := pageOf(req.PageSize, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.db.GetProducts(limit, offset)
+	if err != nil {
+		return nil, statusOf(err, "get products")
+	}
+	return &pb.ListProductsResponse{Products: convertAll(products, productOf)}, nil
+}
+
+func (s *productService) StreamProducts(req *pb.StreamProductsRequest, stream pb.ProductService_StreamProductsServer) error {
+	cursor, err := s.db.StreamProducts()
+	return streamCursor(cursor, err, stream.Send, productOf, "stream products")
+}
+
+func (s *productService) ListProductPrices(ctx context.Context, req *pb.ListProductPricesRequest) (*pb.ListProductPricesResponse, error) {
+	prices, err := s.db.GetProductPrices(int(req.ProductId))
+	if err != nil {
+		return nil, statusOf(err, "get the product's prices")
+	}
+	return &pb.ListProductPricesResponse{Prices: convertAll(prices, productPriceOf)}, nil
+}
+
+func (s *productService) ScheduleProductPrice(ctx context.Context, req *pb.ScheduleProductPriceRequest) (*pb.PriceChange, error) {
+	price, err := parseMoney("unit_price", req.UnitPrice)
+	if err != nil {
+		return nil, err
+	}
+	fields := priceFields{UnitPrice: price}
+	if errs := validate.Struct(&fields); len(errs) > 0 {
+		return nil, invalid(errs)
+	}
+
+	from := time.Now()
+	if req.EffectiveFrom != nil {
+		from = req.EffectiveFrom.AsTime()
+	}
+
+	change, err := s.db.ScheduleProductPrice(int(req.ProductId), price, from)
+	if err != nil {
+		return nil, statusOf(err, "schedule the product price")
+	}
+	return priceChangeOf(change), nil
+}
@@ -0,0 +1,82 @@
+// Package rpc serves the Northwind API over gRPC, alongside the HTTP API and
+// over the same repository
+package rpc
+
+import (
+	"context"
+	"net"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/repository"
+	pb "northwind-api/internal/rpc/northwindpb"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server is the gRPC server with the Northwind services and health checking
+// registered, and reflection when GRPC_REFLECTION is set
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// The services reported by the health service, besides the server as a whole ("")
+var services = []string{
+	pb.CategoryService_ServiceDesc.ServiceName,
+	pb.ProductService_ServiceDesc.ServiceName,
+	pb.CustomerService_ServiceDesc.ServiceName,
+	pb.OrderService_ServiceDesc.ServiceName,
+}
+
+// New creates a gRPC server over the database
+func New(db *repository.DB, cfg *appconfig.Config) *Server {
+	s := &Server{
+		grpc: grpc.NewServer(
+			grpc.MaxRecvMsgSize(int(cfg.MaxBodyBytes)),
+			grpc.ChainUnaryInterceptor(recoveryUnary, loggingUnary),
+			grpc.ChainStreamInterceptor(recoveryStream, loggingStream),
+		),
+		health: health.NewServer(),
+	}
+
+	pb.RegisterCategoryServiceServer(s.grpc, &categoryService{db: db})
+	pb.RegisterProductServiceServer(s.grpc, &productService{db: db})
+	pb.RegisterCustomerServiceServer(s.grpc, &customerService{db: db})
+	pb.RegisterOrderServiceServer(s.grpc, &orderService{db: db})
+
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	for _, service := range append([]string{""}, services...) {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	if cfg.GRPCReflection {
+		reflection.Register(s.grpc)
+	}
+
+	return s
+}
+
+// Serve accepts connections on the listener until the server is stopped
+func (s *Server) Serve(lis net.Listener) error {
+	log.Info().Str("addr", lis.Addr().String()).Msg("gRPC server starting")
+	return s.grpc.Serve(lis)
+}
+
+// ServeUntil serves until ctx is done, then stops the server gracefully
+func (s *Server) ServeUntil(ctx context.Context, lis net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+	return s.Serve(lis)
+}
+
+// Stop reports every service as not serving, so health checks fail while
+// in-flight calls finish, then stops the server
+func (s *Server) Stop() {
+	s.health.Shutdown()
+	s.grpc.GracefulStop()
+}
@@ -0,0 +1,260 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Media types of the spreadsheet formats
+const (
+	CSVType  = "text/csv"
+	XLSXType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX
+var ErrUnsupportedFormat = errors.New("unsupported spreadsheet format")
+
+// Read returns the rows of a CSV file or of the first sheet of an XLSX
+// workbook, the format chosen by media type or, failing that, file name.
+// Rows are padded to the width of the widest row.
+func Read(data []byte, mediaType, filename string) ([][]string, error) {
+	ext := strings.ToLower(path.Ext(filename))
+	var rows [][]string
+	var err error
+	switch {
+	case mediaType == CSVType || ext == ".csv":
+		rows, err = ReadCSV(bytes.NewReader(data))
+	case mediaType == XLSXType || ext == ".xlsx":
+		rows, err = ReadXLSX(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// ReadCSV reads every row of a CSV file, which may start with a UTF-8 byte
+// order mark and have rows of differing lengths
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
+
+// #region XLSX
+
+// The parts of an XLSX package needed to read cell values
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a run of text: a plain <t> or the <r><t> runs of rich text
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.T)
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the cell values of the first sheet of an XLSX workbook as
+// text. Numbers come back as Excel stores them and dates as serial numbers,
+// so columns holding dates should be formatted as text.
+func ReadXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+
+	var workbook xlsxWorkbook
+	if err := readXMLPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("invalid XLSX: the workbook has no sheets")
+	}
+
+	var rels xlsxRelationships
+	if err := readXMLPart(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.Id == workbook.Sheets[0].RelId {
+			sheetPath = rel.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("invalid XLSX: the first sheet is missing")
+	}
+	// Targets are relative to xl/ unless they start at the package root
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	var shared xlsxSharedStrings
+	if findPart(archive, "xl/sharedStrings.xml") != nil {
+		if err := readXMLPart(archive, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := readXMLPart(archive, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		// Empty rows are left out of the sheet, so place rows by number
+		index := i
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid XLSX: cell %s refers to a missing shared string", cell.Ref)
+				}
+				values[column] = shared.Items[n].String()
+			case "inlineStr":
+				values[column] = cell.Inline.String()
+			case "b":
+				values[column] = strconv.FormatBool(cell.Value == "1")
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows[index] = values
+	}
+
+	return rows, nil
+}
+
+// columnIndex returns the zero based column of a cell reference such as "AB12"
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid XLSX: invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+// findPart returns a file of the package, or nil if there is none
+func findPart(archive *zip.Reader, name string) *zip.File {
+	for _, f := range archive.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// readXMLPart decodes an XML file of the package
+func readXMLPart(archive *zip.Reader, name string, v any) error {
+	f := findPart(archive, name)
+	if f == nil {
+		return fmt.Errorf("invalid XLSX: %s is missing", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("invalid XLSX: %w", err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid XLSX: %s: %w", name, err)
+	}
+	return nil
+}
+
+// #endregion
@@ -0,0 +1,134 @@
+// Package stream fans the events committed by any instance of the service out
+// to the clients following them as server-sent events. Every instance listens
+// for the events recorded in the outbox, so a client is told of every change
+// whichever instance it is connected to, and keeps the latest of them for
+// clients that reconnect to resume from the last event they saw.
+package stream
+
+import (
+	"northwind-api/internal/events"
+	"slices"
+	"sync"
+)
+
+// Events held for a subscriber that has not read them before it is dropped
+// as too slow
+const subscriberBuffer = 256
+
+// Filter picks the events a subscriber is sent: those of any of the resources
+// about any of the resource ids. An empty list matches everything.
+type Filter struct {
+	Resources   []string
+	ResourceIds []string
+}
+
+// Matches reports whether the filter picks an event
+func (f Filter) Matches(e events.Event) bool {
+	return (len(f.Resources) == 0 || slices.Contains(f.Resources, e.Resource)) &&
+		(len(f.ResourceIds) == 0 || slices.Contains(f.ResourceIds, e.ResourceId))
+}
+
+// Hub hands the events it is given to its subscribers and keeps the latest of
+// them in a bounded replay buffer
+type Hub struct {
+	mu     sync.Mutex
+	size   int
+	buffer []events.Event
+	// Ids of the events in the buffer, so an event given again is not repeated
+	buffered map[string]bool
+	subs     map[*Subscription]struct{}
+}
+
+// NewHub creates a hub replaying up to size events
+func NewHub(size int) *Hub {
+	return &Hub{
+		size:     size,
+		buffered: map[string]bool{},
+		subs:     map[*Subscription]struct{}{},
+	}
+}
+
+// Subscription receives the events matching its filter until it is closed
+type Subscription struct {
+	hub    *Hub
+	filter Filter
+	events chan events.Event
+}
+
+// Events returns the channel events are received on. It is closed when the
+// subscription is, including when the hub drops it for falling behind.
+func (s *Subscription) Events() <-chan events.Event {
+	return s.events
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
+
+// Subscribe starts a subscription to the events matching filter. When
+// lastEventId is given, the buffered events after it that match are returned
+// to be sent ahead of those received; resumed is false when the event is no
+// longer buffered, so the events since cannot be replayed.
+func (h *Hub) Subscribe(filter Filter, lastEventId string) (sub *Subscription, replay []events.Event, resumed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{hub: h, filter: filter, events: make(chan events.Event, subscriberBuffer)}
+	h.subs[sub] = struct{}{}
+
+	if lastEventId == "" {
+		return sub, nil, true
+	}
+	i := slices.IndexFunc(h.buffer, func(e events.Event) bool { return e.Id == lastEventId })
+	if i < 0 {
+		return sub, nil, false
+	}
+	for _, e := range h.buffer[i+1:] {
+		if filter.Matches(e) {
+			replay = append(replay, e)
+		}
+	}
+	return sub, replay, true
+}
+
+// Publish buffers events and sends them to the subscribers they match.
+// Events already buffered are skipped. A subscriber whose channel is full is
+// dropped rather than holding up the others; it can resume from the buffer.
+func (h *Hub) Publish(evs ...events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, e := range evs {
+		if h.buffered[e.Id] {
+			continue
+		}
+		if len(h.buffer) == h.size {
+			delete(h.buffered, h.buffer[0].Id)
+			h.buffer = h.buffer[1:]
+		}
+		h.buffer = append(h.buffer, e)
+		h.buffered[e.Id] = true
+
+		for sub := range h.subs {
+			if !sub.filter.Matches(e) {
+				continue
+			}
+			select {
+			case sub.events <- e:
+			default:
+				h.drop(sub)
+			}
+		}
+	}
+}
+
+// drop removes a subscription and closes its channel, with the lock held
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,158 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"northwind-api/internal/events"
+	"northwind-api/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Notifications read at once, so a burst of events is fetched together
+	notificationBatch = 100
+	// How often an idle connection is checked, as a dropped one is otherwise
+	// only noticed when the next notification fails to arrive
+	pingInterval = 90 * time.Second
+)
+
+// Listen feeds the hub the events committed by every instance of the service,
+// which each notify repository.EventsChannel of the events they record, until
+// ctx is cancelled. The hub is first filled with the latest recorded events,
+// and the events recorded while the connection was down are caught up on
+// when it is reconnected.
+func Listen(ctx context.Context, hub *Hub, db *repository.DB, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventDisconnected:
+			log.Warn().Err(err).Msg("Event listener disconnected")
+		case pq.ListenerEventReconnected:
+			log.Info().Msg("Event listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Error().Err(err).Msg("Event listener failed to connect")
+		}
+	})
+	if err := listener.Listen(repository.EventsChannel); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for events: %w", err)
+	}
+
+	// Listening before loading the buffer means no event falls in between;
+	// events notified that were already loaded are skipped by the hub
+	l := &eventListener{hub: hub, db: db}
+	if err := l.catchUp(); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		defer listener.Close()
+		log.Info().Str("channel", repository.EventsChannel).Msg("Event listener started")
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Event listener stopped")
+				return
+			case n := <-listener.Notify:
+				// A nil notification means the connection was re-established
+				// and notifications may have been missed
+				if n == nil {
+					if err := l.catchUp(); err != nil {
+						log.Error().Err(err).Msg("Failed to catch up on events")
+					}
+					continue
+				}
+				l.receive(n, listener.Notify)
+			case <-time.After(pingInterval):
+				go listener.Ping()
+			}
+		}
+	}()
+	return nil
+}
+
+// eventListener fetches the events it is notified of into the hub
+type eventListener struct {
+	hub *Hub
+	db  *repository.DB
+	// The highest outbox id fetched
+	last int64
+}
+
+// catchUp fetches the events recorded after the last one fetched, or the
+// latest events when none have been, up to the size of the buffer
+func (l *eventListener) catchUp() error {
+	recorded, err := l.db.GetRecentOutboxEvents(l.last, l.hub.size)
+	if err != nil {
+		return fmt.Errorf("failed to load recent events: %w", err)
+	}
+
+	evs := make([]events.Event, len(recorded))
+	for i, r := range recorded {
+		evs[i] = r.Event
+		l.last = max(l.last, r.OutboxId)
+	}
+	l.hub.Publish(evs...)
+	return nil
+}
+
+// receive fetches the event of a notification along with those of any
+// notifications already waiting, handing them to the hub in the order they
+// were committed
+func (l *eventListener) receive(first *pq.Notification, notify <-chan *pq.Notification) {
+	var ids []int64
+	add := func(n *pq.Notification) {
+		id, err := strconv.ParseInt(n.Extra, 10, 64)
+		if err != nil {
+			log.Warn().Str("payload", n.Extra).Msg("Ignoring malformed event notification")
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	add(first)
+drain:
+	for len(ids) < notificationBatch {
+		select {
+		case n := <-notify:
+			if n == nil {
+				// Reconnected in the meantime; catch up instead of picking
+				// out the events notified
+				if err := l.catchUp(); err != nil {
+					log.Error().Err(err).Msg("Failed to catch up on events")
+				}
+				return
+			}
+			add(n)
+		default:
+			break drain
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	recorded, err := l.db.GetOutboxEvents(ids)
+	if err != nil {
+		log.Error().Err(err).Int("events", len(ids)).Msg("Failed to fetch notified events")
+		return
+	}
+
+	byId := make(map[int64]events.Event, len(recorded))
+	for _, r := range recorded {
+		byId[r.OutboxId] = r.Event
+		l.last = max(l.last, r.OutboxId)
+	}
+	evs := make([]events.Event, 0, len(recorded))
+	for _, id := range ids {
+		if e, ok := byId[id]; ok {
+			evs = append(evs, e)
+		}
+	}
+	l.hub.Publish(evs...)
+}
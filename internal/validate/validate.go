@@ -0,0 +1,411 @@
+package validate
+
+import (
+	"fmt"
+	"northwind-api/internal/currency"
+	"northwind-api/internal/money"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is a validation failure of one field, named by its JSON path
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists every field that failed validation
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Field + " " + fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// rule is one comma separated entry of a `validate:` tag, such as max=15
+type rule struct {
+	name  string
+	param string
+}
+
+// field is a validated struct field
+type field struct {
+	index []int
+	name  string
+	rules []rule
+}
+
+// fieldCache holds the validated fields of each struct type
+var fieldCache sync.Map
+
+var (
+	moneyType = reflect.TypeFor[money.Money]()
+	timeType  = reflect.TypeFor[time.Time]()
+)
+
+// fieldsOf returns the cached validated fields of a struct type. Struct and
+// slice-of-struct fields are included without rules so they are descended into.
+func fieldsOf(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	var fields []field
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		var rules []rule
+		if tag := f.Tag.Get("validate"); tag != "" {
+			for _, entry := range strings.Split(tag, ",") {
+				ruleName, param, _ := strings.Cut(entry, "=")
+				rules = append(rules, rule{name: ruleName, param: param})
+			}
+		}
+		if rules == nil && !isNested(f.Type) {
+			continue
+		}
+		fields = append(fields, field{index: f.Index, name: name, rules: rules})
+	}
+
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.([]field)
+}
+
+// isNested reports whether values of a type are validated field by field
+func isNested(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !isNull(t)
+}
+
+// isNull reports whether a type is a nullable wrapper with V and Valid fields
+func isNull(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	_, hasV := t.FieldByName("V")
+	return ok && hasV && valid.Type.Kind() == reflect.Bool
+}
+
+// Struct validates every field of a struct (or pointer to one) against its
+// `validate:` tags, descending into nested structs and slices of structs. A
+// slice of structs is validated element by element, with fields named [i].name.
+func Struct(v any) Errors {
+	var errs Errors
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Slice {
+		validateNested(value, "", &errs)
+		return errs
+	}
+	validateStruct(value, "", nil, &errs)
+	return errs
+}
+
+// Partial validates only the top-level fields named in fields (by JSON name),
+// along with fields whose rules compare against one of them
+func Partial(v any, fields map[string]bool) Errors {
+	var errs Errors
+	validateStruct(reflect.ValueOf(v), "", fields, &errs)
+	return errs
+}
+
+func validateStruct(v reflect.Value, prefix string, only map[string]bool, errs *Errors) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	for _, f := range fieldsOf(v.Type()) {
+		if only != nil && !only[f.name] && !referencesAny(f.rules, only) {
+			continue
+		}
+
+		path := prefix + f.name
+		value := v.FieldByIndex(f.index)
+		for _, r := range f.rules {
+			if message := check(r, value, v); message != "" {
+				*errs = append(*errs, FieldError{Field: path, Message: message})
+				break
+			}
+		}
+
+		validateNested(value, path, errs)
+	}
+}
+
+// validateNested descends into struct and slice-of-struct fields
+func validateNested(value reflect.Value, path string, errs *Errors) {
+	if !isNested(value.Type()) {
+		return
+	}
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			validateNested(value.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		return
+	}
+	validateStruct(value, path+".", nil, errs)
+}
+
+// referencesAny reports whether any rule compares against one of the named fields
+func referencesAny(rules []rule, names map[string]bool) bool {
+	for _, r := range rules {
+		if (r.name == "gtfield" || r.name == "gtefield") && names[r.param] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve unwraps pointers and Null types, reporting false for absent values
+func resolve(v reflect.Value) (reflect.Value, bool) {
+	for {
+		switch {
+		case v.Kind() == reflect.Pointer:
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		case isNull(v.Type()):
+			if !v.FieldByName("Valid").Bool() {
+				return v, false
+			}
+			v = v.FieldByName("V")
+		default:
+			return v, true
+		}
+	}
+}
+
+// check applies a rule to a value, returning a message if it fails
+func check(r rule, raw reflect.Value, parent reflect.Value) string {
+	v, present := resolve(raw)
+
+	if r.name == "required" {
+		if !present || isBlank(v) {
+			return "is required"
+		}
+		return ""
+	}
+	// Other rules only apply to values that were given
+	if !present || (v.Kind() == reflect.String && v.Len() == 0) || isZeroTime(v) {
+		return ""
+	}
+
+	switch r.name {
+	case "min", "max":
+		return checkBound(r, v)
+	case "gt":
+		limit, ok := number(v, r.param)
+		if ok && compare(v, limit) <= 0 {
+			return "must be greater than " + r.param
+		}
+	case "oneof":
+		options := strings.Fields(r.param)
+		for _, option := range options {
+			if fmt.Sprint(v.Interface()) == option {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", ")
+	case "country":
+		if !IsCountry(v.String()) {
+			return "must be an ISO 3166 country code or country name"
+		}
+	case "currency":
+		if !currency.ValidCode(v.String()) {
+			return "must be a three letter ISO 4217 code"
+		}
+	case "gtfield", "gtefield":
+		return checkField(r, v, parent)
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", r.name))
+	}
+
+	return ""
+}
+
+// isBlank reports whether a present value counts as missing for required
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return isZeroTime(v)
+}
+
+// isZeroTime reports whether v is an unset time.Time
+func isZeroTime(v reflect.Value) bool {
+	return v.Type() == timeType && v.Interface().(time.Time).IsZero()
+}
+
+// checkBound applies min or max: a length for strings and slices, a value for numbers
+func checkBound(r rule, v reflect.Value) string {
+	word := "at least"
+	if r.name == "max" {
+		word = "at most"
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+		n, err := strconv.Atoi(r.param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s=%s", r.name, r.param))
+		}
+		length := v.Len()
+		unit := "items"
+		if v.Kind() == reflect.String {
+			length = utf8.RuneCountInString(v.String())
+			unit = "characters"
+		}
+		if (r.name == "min" && length < n) || (r.name == "max" && length > n) {
+			return fmt.Sprintf("must be %s %d %s", word, n, unit)
+		}
+		return ""
+	}
+
+	limit, ok := number(v, r.param)
+	if !ok {
+		return ""
+	}
+	c := compare(v, limit)
+	if (r.name == "min" && c < 0) || (r.name == "max" && c > 0) {
+		return fmt.Sprintf("must be %s %s", word, r.param)
+	}
+	return ""
+}
+
+// number parses a rule parameter as a value of v's type
+func number(v reflect.Value, param string) (reflect.Value, bool) {
+	if v.Type() == moneyType {
+		m, err := money.Parse(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid money bound %q", param))
+		}
+		return reflect.ValueOf(m), true
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid integer bound %q", param))
+		}
+		return reflect.ValueOf(n).Convert(v.Type()), true
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid number bound %q", param))
+		}
+		return reflect.ValueOf(f).Convert(v.Type()), true
+	}
+	return reflect.Value{}, false
+}
+
+// compare orders two numbers or times of the same type
+func compare(a, b reflect.Value) int {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+	switch a.Kind() {
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	x, y := a.Int(), b.Int()
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// checkField compares a value with a sibling field named by its JSON name.
+// The comparison is skipped while the other field is absent.
+func checkField(r rule, v reflect.Value, parent reflect.Value) string {
+	var other reflect.Value
+	for _, f := range reflect.VisibleFields(parent.Type()) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == r.param {
+			other = parent.FieldByIndex(f.Index)
+			break
+		}
+	}
+	if !other.IsValid() {
+		panic(fmt.Sprintf("validate: unknown field %q", r.param))
+	}
+
+	other, present := resolve(other)
+	if !present || other.Type() != v.Type() || isZeroTime(other) {
+		return ""
+	}
+
+	c := compare(v, other)
+	if r.name == "gtfield" && c <= 0 {
+		if v.Type() == timeType {
+			return "must be after " + r.param
+		}
+		return "must be greater than " + r.param
+	}
+	if r.name == "gtefield" && c < 0 {
+		if v.Type() == timeType {
+			return "must not be before " + r.param
+		}
+		return "must be at least " + r.param
+	}
+	return ""
+}
+
+// IsCountry reports whether s is an ISO 3166-1 alpha-2 code or a country name
+func IsCountry(s string) bool {
+	s = strings.TrimSpace(s)
+	if _, ok := countries[strings.ToUpper(s)]; ok && len(s) == 2 {
+		return true
+	}
+	if _, ok := countryAliases[s]; ok {
+		return true
+	}
+	for _, name := range countries {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// newClient returns the client deliveries are sent with. Redirects are not
+// followed, and unless allowPrivate is set connections to loopback, private
+// and link-local addresses are refused when they are dialled, so a webhook
+// cannot be pointed at the network the service runs in, even by a host name
+// that resolves there.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would be dialled instead of the receiver, escaping the check
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refusePrivate is a dialer control refusing addresses that are not public
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !public(addr) {
+		return fmt.Errorf("refusing to connect to the non-public address %s", addr)
+	}
+	return nil
+}
+
+// public reports whether an address is reachable on the public internet
+func public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast()
+}
+
+// CheckURL checks a URL can be registered as a webhook: an absolute http or
+// https URL. Unless allowPrivate is set, a host that is a non-public address
+// or localhost is refused up front; host names are checked again when dialled.
+func CheckURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return fmt.Errorf("must not contain credentials")
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return fmt.Errorf("must not point at a loopback or private address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !public(addr) {
+		return fmt.Errorf("must not point at a loopback or private address")
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package webhook delivers events to the URLs partners register for them:
+// each delivery is signed with the webhook's secret, retried with exponential
+// backoff when it fails and dead-lettered once it runs out of attempts
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	appconfig "northwind-api/internal/config"
+	"northwind-api/internal/events"
+	"northwind-api/internal/repository"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Deliveries claimed and sent at once
+const batchSize = 20
+
+// store is the queue of deliveries the dispatcher works from, kept in the
+// database by repository.DB
+type store interface {
+	EnqueueWebhookDeliveries(evs []events.Event) (int, error)
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]repository.WebhookAttempt, error)
+	MarkWebhookDelivered(id, status int) error
+	RetryWebhookDelivery(id, status int, reason string, retryAt time.Time) error
+}
+
+// Dispatcher queues a delivery of each event it is told about to every webhook
+// whose filters match it and sends the deliveries in the background. Queued
+// deliveries live in the database, so they survive a restart and are shared
+// between instances of the service.
+type Dispatcher struct {
+	db     store
+	client *http.Client
+
+	maxAttempts  int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	timeout      time.Duration
+	pollInterval time.Duration
+
+	// Signals the worker that deliveries were queued
+	wake chan struct{}
+}
+
+// New creates a dispatcher configured by the WEBHOOK_ settings
+func New(db *repository.DB, cfg *appconfig.Config) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		client:       newClient(cfg.WebhookTimeout, cfg.WebhookAllowPrivateURLs),
+		maxAttempts:  cfg.WebhookMaxAttempts,
+		backoffBase:  cfg.WebhookBackoffBase,
+		backoffMax:   cfg.WebhookBackoffMax,
+		timeout:      cfg.WebhookTimeout,
+		pollInterval: cfg.WebhookPollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Name identifies the dispatcher as an outbox sink
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+// Publish queues the deliveries of committed events, implementing
+// outbox.Sink. An event published again, as the outbox may, is not queued
+// again, so each webhook is sent it once.
+func (d *Dispatcher) Publish(ctx context.Context, evs []events.Event) error {
+	queued, err := d.db.EnqueueWebhookDeliveries(evs)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		d.Wake()
+	}
+	return nil
+}
+
+// Wake has the worker look for due deliveries now rather than at its next poll
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the worker sending due deliveries in the background until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		log.Info().Dur("poll_interval", d.pollInterval).Int("max_attempts", d.maxAttempts).Msg("Webhook dispatcher started")
+
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.DeliverDue(ctx)
+
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Webhook dispatcher stopped")
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// DeliverDue sends every delivery that is due, a batch at a time
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	// A claimed delivery is left alone for long enough to finish its attempt
+	lease := 2*d.timeout + time.Minute
+
+	for ctx.Err() == nil {
+		attempts, err := d.db.ClaimWebhookDeliveries(batchSize, lease)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to claim webhook deliveries")
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, a := range attempts {
+			wg.Go(func() { d.deliver(ctx, a) })
+		}
+		wg.Wait()
+
+		if len(attempts) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery and records its outcome
+func (d *Dispatcher) deliver(ctx context.Context, a repository.WebhookAttempt) {
+	status, err := d.send(ctx, a)
+	if ctx.Err() != nil {
+		// Stopping; the delivery is claimed again once its lease runs out
+		return
+	}
+	logger := log.With().Int("delivery_id", a.DeliveryId).Int("webhook_id", a.WebhookId).
+		Str("event_type", a.EventType).Int("attempt", a.Attempt).Int("status", status).Logger()
+
+	if err == nil {
+		if err := d.db.MarkWebhookDelivered(a.DeliveryId, status); err != nil {
+			logger.Error().Err(err).Msg("Failed to record webhook delivery")
+			return
+		}
+		logger.Info().Msg("Delivered webhook")
+		return
+	}
+
+	var retryAt time.Time
+	if a.Attempt < d.maxAttempts {
+		retryAt = time.Now().Add(d.backoff(a.Attempt))
+	}
+	if err := d.db.RetryWebhookDelivery(a.DeliveryId, status, err.Error(), retryAt); err != nil {
+		logger.Error().Err(err).Msg("Failed to record webhook delivery")
+		return
+	}
+
+	if retryAt.IsZero() {
+		logger.Warn().Err(err).Msg("Webhook delivery ran out of attempts and was dead-lettered")
+	} else {
+		logger.Warn().Err(err).Time("retry_at", retryAt).Msg("Webhook delivery failed, retrying later")
+	}
+}
+
+// send POSTs a delivery to its webhook, returning the status of the response,
+// or 0 when there was none. Any 2xx response is a success.
+func (d *Dispatcher) send(ctx context.Context, a repository.WebhookAttempt) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(a.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build the request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Northwind-Webhooks/1.0")
+	req.Header.Set(EventHeader, a.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(a.DeliveryId))
+	req.Header.Set(SignatureHeader, Sign(a.Secret, time.Now(), a.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Read some of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns how long to wait after a failed attempt: the base doubled
+// for every attempt before it, up to the max
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.backoffBase
+	for i := 1; i < attempt && wait < d.backoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.backoffMax)
+}
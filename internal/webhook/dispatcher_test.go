@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"northwind-api/internal/events"
+	"northwind-api/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDelivery is a delivery held by fakeStore
+type fakeDelivery struct {
+	attempt    repository.WebhookAttempt
+	attempts   int
+	dead       bool
+	delivered  bool
+	lastStatus int
+	retryAt    time.Time
+}
+
+// fakeStore keeps deliveries in memory the way repository.DB keeps them in the
+// database. Every pending delivery is due, whatever its retry time.
+type fakeStore struct {
+	mu         sync.Mutex
+	deliveries map[int]*fakeDelivery
+}
+
+func newFakeStore(url, secret string) *fakeStore {
+	return &fakeStore{deliveries: map[int]*fakeDelivery{
+		1: {attempt: repository.WebhookAttempt{
+			DeliveryId: 1,
+			WebhookId:  7,
+			EventId:    "evt_1",
+			EventType:  events.OrderShipped,
+			Payload:    []byte(`{"id":"evt_1","type":"order.shipped"}`),
+			URL:        url,
+			Secret:     secret,
+		}},
+	}}
+}
+
+func (s *fakeStore) EnqueueWebhookDeliveries(evs []events.Event) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeStore) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]repository.WebhookAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []repository.WebhookAttempt
+	for _, d := range s.deliveries {
+		if d.dead || d.delivered || len(claimed) == limit {
+			continue
+		}
+		d.attempts++
+		a := d.attempt
+		a.Attempt = d.attempts
+		claimed = append(claimed, a)
+	}
+	return claimed, nil
+}
+
+func (s *fakeStore) MarkWebhookDelivered(id, status int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.deliveries[id]
+	d.delivered, d.lastStatus, d.retryAt = true, status, time.Time{}
+	return nil
+}
+
+func (s *fakeStore) RetryWebhookDelivery(id, status int, reason string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.deliveries[id]
+	d.dead, d.lastStatus, d.retryAt = retryAt.IsZero(), status, retryAt
+	return nil
+}
+
+// redeliver resets a delivery as repository.DB.RedeliverWebhookDelivery does
+func (s *fakeStore) redeliver(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.deliveries[id]
+	d.attempts, d.dead, d.delivered, d.retryAt = 0, false, false, time.Time{}
+}
+
+func (s *fakeStore) get(id int) fakeDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.deliveries[id]
+}
+
+// receiver is a webhook receiver responding with a status it can be told to change
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestDispatcher(db store, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		client:       newClient(5*time.Second, true),
+		maxAttempts:  maxAttempts,
+		backoffBase:  time.Minute,
+		backoffMax:   time.Hour,
+		timeout:      5 * time.Second,
+		pollInterval: time.Minute,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func TestDeliverSignsDelivery(t *testing.T) {
+	secret := "whsec_test"
+	recv := newReceiver(t, http.StatusNoContent)
+	db := newFakeStore(recv.URL, secret)
+	d := newTestDispatcher(db, 3)
+
+	d.DeliverDue(context.Background())
+
+	if recv.received() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", recv.received())
+	}
+	req, body := recv.requests[0], recv.bodies[0]
+	if err := Verify(secret, req.Header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	if got := req.Header.Get(EventHeader); got != events.OrderShipped {
+		t.Errorf("%s = %q, want %q", EventHeader, got, events.OrderShipped)
+	}
+	if got := req.Header.Get(DeliveryHeader); got != "1" {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, "1")
+	}
+	if got := db.get(1); !got.delivered || got.lastStatus != http.StatusNoContent {
+		t.Errorf("delivery = %+v, want delivered with status 204", got)
+	}
+}
+
+func TestDeliverRetriesThenDeadLetters(t *testing.T) {
+	recv := newReceiver(t, http.StatusServiceUnavailable)
+	db := newFakeStore(recv.URL, "whsec_test")
+	d := newTestDispatcher(db, 3)
+
+	// Each failed attempt waits twice as long as the one before
+	for _, wantWait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		start := time.Now()
+		d.DeliverDue(context.Background())
+
+		got := db.get(1)
+		if got.dead || got.delivered {
+			t.Fatalf("attempt %d: delivery = %+v, want pending", got.attempts, got)
+		}
+		if got.lastStatus != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: last status = %d, want 503", got.attempts, got.lastStatus)
+		}
+		if wait := got.retryAt.Sub(start); wait < wantWait || wait > wantWait+time.Second {
+			t.Errorf("attempt %d: retried after %v, want %v", got.attempts, wait, wantWait)
+		}
+	}
+
+	d.DeliverDue(context.Background())
+	if got := db.get(1); !got.dead || !got.retryAt.IsZero() {
+		t.Errorf("after %d attempts: delivery = %+v, want dead", got.attempts, got)
+	}
+
+	// A dead delivery is not attempted again
+	d.DeliverDue(context.Background())
+	if recv.received() != 3 {
+		t.Errorf("receiver got %d requests, want 3", recv.received())
+	}
+}
+
+func TestRedeliverDeadDelivery(t *testing.T) {
+	recv := newReceiver(t, http.StatusInternalServerError)
+	db := newFakeStore(recv.URL, "whsec_test")
+	d := newTestDispatcher(db, 1)
+
+	d.DeliverDue(context.Background())
+	if got := db.get(1); !got.dead {
+		t.Fatalf("delivery = %+v, want dead", got)
+	}
+
+	recv.respond(http.StatusOK)
+	db.redeliver(1)
+	d.DeliverDue(context.Background())
+
+	if got := db.get(1); !got.delivered || got.attempts != 1 {
+		t.Errorf("delivery = %+v, want delivered on the first attempt", got)
+	}
+	if recv.received() != 2 {
+		t.Fatalf("receiver got %d requests, want 2", recv.received())
+	}
+	if first, second := recv.requests[0].Header.Get(DeliveryHeader), recv.requests[1].Header.Get(DeliveryHeader); first != second {
+		t.Errorf("redelivery id = %s, want the same as the first delivery %s", second, first)
+	}
+}
+
+func TestSendRefusesPrivateAddresses(t *testing.T) {
+	recv := newReceiver(t, http.StatusOK)
+	db := newFakeStore(recv.URL, "whsec_test")
+	d := newTestDispatcher(db, 3)
+	d.client = newClient(5*time.Second, false)
+
+	status, err := d.send(context.Background(), db.get(1).attempt)
+	if err == nil || status != 0 {
+		t.Errorf("send() = %d, %v, want the loopback receiver refused", status, err)
+	}
+	if recv.received() != 0 {
+		t.Errorf("receiver got %d requests, want none", recv.received())
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{backoffBase: 30 * time.Second, backoffMax: 10 * time.Minute}
+
+	for attempt, want := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+		if got := d.backoff(attempt + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	// The event type, such as order.shipped
+	EventHeader = "X-Northwind-Event"
+	// The delivery id, which stays the same when a delivery is retried
+	DeliveryHeader = "X-Northwind-Delivery"
+	// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed by the secret>"
+	SignatureHeader = "X-Northwind-Signature"
+)
+
+// NewSecret returns a random secret for signing the deliveries of a webhook
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Sign returns the signature header of a body sent at a time. The timestamp
+// is signed along with the body so a captured delivery cannot be replayed
+// later.
+func Sign(secret string, at time.Time, body []byte) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks the signature header of a body received at now, which must
+// have been signed no more than tolerance before or after. Receivers can use
+// it to check a delivery came from this service.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("invalid signature header")
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp is outside the tolerance")
+	}
+
+	expected := mac(secret, t, body)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match")
+}
+
+// mac returns the HMAC-SHA256 of "<t>.<body>"
+func mac(secret, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"type":"order.shipped"}`)
+	sentAt := time.Unix(1_700_000_000, 0)
+	header := Sign(secret, sentAt, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", secret, header, body, sentAt.Add(time.Minute), false},
+		{"rotated secrets", secret, Sign("whsec_old", sentAt, body) + "," + header[len("t=1700000000,"):], body, sentAt, false},
+		{"wrong secret", "whsec_other", header, body, sentAt, true},
+		{"tampered body", secret, header, []byte(`{"type":"order.deleted"}`), sentAt, true},
+		{"too old", secret, header, body, sentAt.Add(6 * time.Minute), true},
+		{"from the future", secret, header, body, sentAt.Add(-6 * time.Minute), true},
+		{"no timestamp", secret, header[len("t=1700000000,"):], body, sentAt, true},
+		{"malformed", secret, "garbage", body, sentAt, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}